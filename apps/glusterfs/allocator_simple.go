@@ -56,6 +56,24 @@ func (s *SimpleAllocator) GetNodesFromDeviceSource(dsrc DeviceSource,
 	return device, done, nil
 }
 
+// GetNodesForPlacement returns the devices like GetNodesFromDeviceSource
+// but sorted according to the allocation strategy of the options.
+func (s *SimpleAllocator) GetNodesForPlacement(dsrc DeviceSource,
+	opts PlacementOpts, brickId string) (
+	<-chan string, chan<- struct{}, error) {
+
+	device, done := make(chan string), make(chan struct{})
+
+	devicelist, err := placementDevices(dsrc, opts, brickId)
+	if err != nil {
+		close(device)
+		return device, done, err
+	}
+
+	generateDevices(devicelist, device, done)
+	return device, done, nil
+}
+
 func generateDevices(devicelist SimpleDevices,
 	device chan<- string, done <-chan struct{}) {
 
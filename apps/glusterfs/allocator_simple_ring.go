@@ -155,7 +155,7 @@ func (s *SimpleAllocatorRing) GetDeviceList(uuid string) SimpleDevices {
 	// negative numbers
 	index64, err := strconv.ParseInt(uuid[:7], 16, 32)
 	if err != nil {
-		logger.Err(err)
+		allocLogger.Err(err)
 		return devices
 	}
 
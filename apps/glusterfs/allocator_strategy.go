@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+var (
+	// Strategy used for volumes which do not request one
+	DefaultAllocationStrategy = api.AllocationSimple
+
+	// Source of the random draws of the weighted strategy
+	allocationRandom = rand.Float64
+)
+
+// allocationStrategy returns the strategy the devices are ordered by
+// when placing bricks with the given options.
+func allocationStrategy(opts PlacementOpts) api.AllocationStrategy {
+	if s := opts.AllocationStrategy(); s != "" {
+		return s
+	}
+	return DefaultAllocationStrategy
+}
+
+// placementDevices returns the devices of the device source in the
+// order bricks should be tried on them. The devices are taken from
+// the simple ring for the given brick id and then sorted according
+// to the allocation strategy.
+func placementDevices(dsrc DeviceSource, opts PlacementOpts,
+	brickId string) (SimpleDevices, error) {
+
+	ring := NewSimpleAllocatorRing()
+	dnl, err := dsrc.Devices()
+	if err != nil {
+		return nil, err
+	}
+	for _, dan := range dnl {
+		ring.Add(&SimpleDevice{
+			zone:     dan.Node.Info.Zone,
+			nodeId:   dan.Node.Info.Id,
+			deviceId: dan.Device.Info.Id,
+		})
+	}
+	return sortDevices(ring.GetDeviceList(brickId), dnl,
+		allocationStrategy(opts)), nil
+}
+
+// sortDevices orders the devices for the strategy. The devices of
+// each zone are sorted on their own and put back in the positions the
+// ring gave to the zone, so consecutive devices keep coming from
+// different zones. Devices that the strategy ranks the same keep
+// their order.
+func sortDevices(devices SimpleDevices, dnl []DeviceAndNode,
+	strategy api.AllocationStrategy) SimpleDevices {
+
+	var key func(DeviceAndNode) float64
+	switch strategy {
+	case api.AllocationLeastUsed:
+		key = func(dan DeviceAndNode) float64 {
+			return -deviceFreePercent(dan.Device)
+		}
+	case api.AllocationPack:
+		key = func(dan DeviceAndNode) float64 {
+			return deviceFreePercent(dan.Device)
+		}
+	case api.AllocationWeighted:
+		key = func(dan DeviceAndNode) float64 {
+			return weightedDraw(allocationWeight(dan.Device, dan.Node))
+		}
+	default:
+		return devices
+	}
+
+	keys := map[string]float64{}
+	for _, dan := range dnl {
+		keys[dan.Device.Info.Id] = key(dan)
+	}
+
+	positions := map[int][]int{}
+	for i, d := range devices {
+		positions[d.zone] = append(positions[d.zone], i)
+	}
+	sorted := make(SimpleDevices, len(devices))
+	for _, zp := range positions {
+		zone := SimpleDevices{}
+		for _, i := range zp {
+			zone = append(zone, devices[i])
+		}
+		sort.Stable(devicesByKey{zone, keys})
+		for n, i := range zp {
+			sorted[i] = zone[n]
+		}
+	}
+	return sorted
+}
+
+// weightedDraw returns a random sort key for a device of the given
+// weight. Sorting devices by their keys picks each device first with
+// a probability proportional to its weight. Devices with a weight of
+// 0 are always sorted last.
+func weightedDraw(weight float64) float64 {
+	if weight <= 0 {
+		return math.Inf(1)
+	}
+	return -math.Log(1-allocationRandom()) / weight
+}
+
+type devicesByKey struct {
+	devices SimpleDevices
+	keys    map[string]float64
+}
+
+func (d devicesByKey) Len() int {
+	return len(d.devices)
+}
+
+func (d devicesByKey) Less(i, j int) bool {
+	return d.keys[d.devices[i].deviceId] < d.keys[d.devices[j].deviceId]
+}
+
+func (d devicesByKey) Swap(i, j int) {
+	d.devices[i], d.devices[j] = d.devices[j], d.devices[i]
+}
+
+func deviceFreePercent(d *DeviceEntry) float64 {
+	if d.Info.Storage.Total == 0 {
+		return 0
+	}
+	return 100 * float64(d.Info.Storage.Free) / float64(d.Info.Storage.Total)
+}
+
+// allocationWeight returns the weight set by the allocation weight tag
+// of the device, or of its node. Devices without a valid weight have
+// a weight of 1.
+func allocationWeight(d *DeviceEntry, n *NodeEntry) float64 {
+	v, ok := MergeTags(n, d)[TAG_ALLOCATION_WEIGHT]
+	if !ok {
+		return 1
+	}
+	w, err := strconv.ParseFloat(v, 64)
+	if err != nil || w < 0 {
+		allocLogger.Warning("Invalid %v tag %q on device %v",
+			TAG_ALLOCATION_WEIGHT, v, d.Info.Id)
+		return 1
+	}
+	return w
+}
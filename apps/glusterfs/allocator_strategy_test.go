@@ -0,0 +1,245 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+// newStrategyDeviceSource returns three nodes with two devices each.
+// The "a" devices have 40% of their space free, the "b" devices are
+// unused.
+func newStrategyDeviceSource() *TestDeviceSource {
+	dsrc := NewTestDeviceSource()
+	for _, n := range []string{"n1", "n2", "n3"} {
+		addDev := dsrc.MultiAdd(n)
+		addDev(n+"-a", "/dev/a", 100*GB)
+		addDev(n+"-b", "/dev/b", 100*GB)
+		dsrc.devices[n+"-a"].StorageAllocate(60 * GB)
+	}
+	return dsrc
+}
+
+func strategyOpts(s api.AllocationStrategy) *TestPlacementOpts {
+	return &TestPlacementOpts{
+		brickSize:       10 * GB,
+		brickSnapFactor: 1,
+		setSize:         3,
+		setCount:        1,
+		allocation:      s,
+	}
+}
+
+func deviceIds(devices SimpleDevices) []string {
+	ids := []string{}
+	for _, d := range devices {
+		ids = append(ids, d.deviceId)
+	}
+	return ids
+}
+
+func TestPlacementDevicesSimple(t *testing.T) {
+	dsrc := newStrategyDeviceSource()
+
+	devices, err := placementDevices(dsrc,
+		strategyOpts(api.AllocationSimple), "0000000")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(devices) == 6,
+		"expected len(devices) == 6, got:", len(devices))
+
+	// the simple strategy keeps the order of the ring
+	dnl, err := dsrc.Devices()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	expected := deviceIds(devices)
+	sorted := sortDevices(append(SimpleDevices{}, devices...), dnl,
+		api.AllocationSimple)
+	tests.Assert(t, strings.Join(deviceIds(sorted), ",") ==
+		strings.Join(expected, ","),
+		"expected ring order", expected, "got:", deviceIds(sorted))
+}
+
+func TestPlacementDevicesLeastUsed(t *testing.T) {
+	dsrc := newStrategyDeviceSource()
+
+	devices, err := placementDevices(dsrc,
+		strategyOpts(api.AllocationLeastUsed), "0000000")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(devices) == 6,
+		"expected len(devices) == 6, got:", len(devices))
+	for i, d := range devices {
+		if i < 3 {
+			tests.Assert(t, strings.HasSuffix(d.deviceId, "-b"),
+				"expected unused devices first, got:", deviceIds(devices))
+		} else {
+			tests.Assert(t, strings.HasSuffix(d.deviceId, "-a"),
+				"expected used devices last, got:", deviceIds(devices))
+		}
+	}
+}
+
+func TestPlacementDevicesPack(t *testing.T) {
+	dsrc := newStrategyDeviceSource()
+
+	devices, err := placementDevices(dsrc,
+		strategyOpts(api.AllocationPack), "0000000")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(devices) == 6,
+		"expected len(devices) == 6, got:", len(devices))
+	for i, d := range devices {
+		if i < 3 {
+			tests.Assert(t, strings.HasSuffix(d.deviceId, "-a"),
+				"expected used devices first, got:", deviceIds(devices))
+		} else {
+			tests.Assert(t, strings.HasSuffix(d.deviceId, "-b"),
+				"expected unused devices last, got:", deviceIds(devices))
+		}
+	}
+}
+
+func TestPlacementDevicesWeighted(t *testing.T) {
+	// a fixed draw orders the devices by their weight
+	defer tests.Patch(&allocationRandom,
+		func() float64 { return 0.5 }).Restore()
+
+	dsrc := newStrategyDeviceSource()
+	dsrc.devices["n2-b"].SetTags(map[string]string{
+		TAG_ALLOCATION_WEIGHT: "5",
+	})
+	dsrc.nodes["n3"].SetTags(map[string]string{
+		TAG_ALLOCATION_WEIGHT: "2",
+	})
+	// device tags take priority over the tags of the node
+	dsrc.devices["n3-a"].SetTags(map[string]string{
+		TAG_ALLOCATION_WEIGHT: "0",
+	})
+	// invalid weights are ignored
+	dsrc.devices["n1-a"].SetTags(map[string]string{
+		TAG_ALLOCATION_WEIGHT: "heavy",
+	})
+
+	devices, err := placementDevices(dsrc,
+		strategyOpts(api.AllocationWeighted), "0000000")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	ids := deviceIds(devices)
+	tests.Assert(t, len(ids) == 6, "expected len(ids) == 6, got:", ids)
+	tests.Assert(t, ids[0] == "n2-b", "expected n2-b first, got:", ids)
+	tests.Assert(t, ids[1] == "n3-b", "expected n3-b second, got:", ids)
+	tests.Assert(t, ids[5] == "n3-a", "expected n3-a last, got:", ids)
+}
+
+func TestPlacementDevicesWeightedRandom(t *testing.T) {
+	dsrc := NewTestDeviceSource()
+	addDev := dsrc.MultiAdd("n1")
+	addDev("heavy", "/dev/a", 100*GB)
+	addDev("light", "/dev/b", 100*GB)
+	dsrc.devices["heavy"].SetTags(map[string]string{
+		TAG_ALLOCATION_WEIGHT: "3",
+	})
+
+	// the heavy device is tried first three times out of four
+	heavy := 0
+	const draws = 4000
+	for i := 0; i < draws; i++ {
+		devices, err := placementDevices(dsrc,
+			strategyOpts(api.AllocationWeighted), "0000000")
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		if devices[0].deviceId == "heavy" {
+			heavy++
+		}
+	}
+	tests.Assert(t, heavy > draws*70/100 && heavy < draws*80/100,
+		"expected heavy device first in about 75% of draws, got:", heavy)
+}
+
+func TestPlacementDevicesKeepZones(t *testing.T) {
+	dsrc := NewTestDeviceSource()
+	for i, n := range []string{"n1", "n2", "n3", "n4"} {
+		addDev := dsrc.MultiAdd(n)
+		addDev(n+"-a", "/dev/a", 100*GB)
+		addDev(n+"-b", "/dev/b", 100*GB)
+		dsrc.nodes[n].Info.Zone = 1 + i%2
+		// only the devices of zone 1 are in use
+		if dsrc.nodes[n].Info.Zone == 1 {
+			dsrc.devices[n+"-a"].StorageAllocate(60 * GB)
+		}
+	}
+
+	devices, err := placementDevices(dsrc,
+		strategyOpts(api.AllocationLeastUsed), "0000000")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(devices) == 8,
+		"expected len(devices) == 8, got:", len(devices))
+	// the unused devices of zone 2 must not push the devices of
+	// zone 1 to the end of the list
+	zone1 := []string{}
+	for i, d := range devices {
+		if i > 0 {
+			tests.Assert(t, d.zone != devices[i-1].zone,
+				"expected zones to alternate, got:", deviceIds(devices))
+		}
+		if d.zone == 1 {
+			zone1 = append(zone1, d.deviceId)
+		}
+	}
+	for i, id := range zone1 {
+		tests.Assert(t, strings.HasSuffix(id, "-b") == (i < 2),
+			"expected unused devices first in zone, got:", zone1)
+	}
+}
+
+func TestStandardPlacerStrategies(t *testing.T) {
+	dsrc := newStrategyDeviceSource()
+	bp := NewStandardBrickPlacer()
+
+	ba, err := bp.PlaceAll(dsrc, strategyOpts(api.AllocationPack), nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(ba.BrickSets) == 1,
+		"expected len(ba.BrickSets) == 1, got:", len(ba.BrickSets))
+	for _, b := range ba.BrickSets[0].Bricks {
+		tests.Assert(t, strings.HasSuffix(b.Info.DeviceId, "-a"),
+			"expected brick on used device, got:", b.Info.DeviceId)
+	}
+
+	dsrc = newStrategyDeviceSource()
+	ba, err = bp.PlaceAll(dsrc, strategyOpts(api.AllocationLeastUsed), nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	for _, b := range ba.BrickSets[0].Bricks {
+		tests.Assert(t, strings.HasSuffix(b.Info.DeviceId, "-b"),
+			"expected brick on unused device, got:", b.Info.DeviceId)
+	}
+}
+
+func TestPlacerDefaultStrategy(t *testing.T) {
+	defer tests.Patch(&DefaultAllocationStrategy,
+		api.AllocationPack).Restore()
+
+	// the strategy of the options takes priority
+	dsrc := newStrategyDeviceSource()
+	bp := NewFailureDomainBrickPlacer()
+	ba, err := bp.PlaceAll(dsrc, strategyOpts(api.AllocationLeastUsed), nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	for _, b := range ba.BrickSets[0].Bricks {
+		tests.Assert(t, strings.HasSuffix(b.Info.DeviceId, "-b"),
+			"expected brick on unused device, got:", b.Info.DeviceId)
+	}
+
+	// otherwise the default strategy is used
+	dsrc = newStrategyDeviceSource()
+	ba, err = bp.PlaceAll(dsrc, strategyOpts(""), nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	for _, b := range ba.BrickSets[0].Bricks {
+		tests.Assert(t, strings.HasSuffix(b.Info.DeviceId, "-a"),
+			"expected brick on used device, got:", b.Info.DeviceId)
+	}
+}
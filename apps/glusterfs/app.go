@@ -16,6 +16,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 
 	"github.com/boltdb/bolt"
 	"github.com/gorilla/mux"
@@ -23,8 +24,10 @@ import (
 	"github.com/heketi/heketi/executors/kubeexec"
 	"github.com/heketi/heketi/executors/mockexec"
 	"github.com/heketi/heketi/executors/sshexec"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 	"github.com/heketi/rest"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -36,18 +39,38 @@ const (
 	BOLTDB_BUCKET_BRICK            = "BRICK"
 	BOLTDB_BUCKET_BLOCKVOLUME      = "BLOCKVOLUME"
 	BOLTDB_BUCKET_DBATTRIBUTE      = "DBATTRIBUTE"
+	BOLTDB_BUCKET_DEVICE_IDENTITY  = "DEVICEIDENTITY"
+	BOLTDB_BUCKET_GEOREPLICATION   = "GEOREPLICATION"
 	DB_CLUSTER_HAS_FILE_BLOCK_FLAG = "DB_CLUSTER_HAS_FILE_BLOCK_FLAG"
 )
 
 var (
 	logger     = utils.NewLogger("[heketi]", utils.LEVEL_INFO)
 	dbfilename = "heketi.db"
+
+	// loggers of the parts of the app, they log at the level of
+	// logger until their own level is set
+	apiLogger    = logger.Component("api")
+	opLogger     = logger.Component("operations")
+	allocLogger  = logger.Component("allocator")
+	healthLogger = logger.Component("health")
+	dbLogger     = logger.Component("db")
+
 	// global var to track active node health cache
 	// if multiple apps are started the content of this var is
 	// undefined.
 	// TODO: make a global not needed
 	currentNodeHealthCache *NodeHealthCache
 
+	// global var to track the active device health cache, see
+	// currentNodeHealthCache
+	currentDeviceHealthCache *DeviceHealthCache
+
+	// global var to track the webhooks of the active app, used by
+	// the operations run outside of a request. See
+	// currentNodeHealthCache
+	currentWebhookNotifier *WebhookNotifier
+
 	// global var to enable the use of the health cache + monitor
 	// when the GlusterFS App is created. This is mildly hacky but
 	// avoids having to update config files to enable the feature
@@ -65,6 +88,17 @@ type App struct {
 
 	// health monitor
 	nhealth *NodeHealthCache
+	dhealth *DeviceHealthCache
+
+	// metrics of the app
+	metrics *prometheus.Registry
+
+	// notifications sent to webhooks
+	webhooks *WebhookNotifier
+
+	// held while the limits of a tenant are checked and the
+	// volume or expansion they allow is saved in the db
+	tenantLock sync.Mutex
 
 	// For testing only.  Keep access to the object
 	// not through the interface
@@ -100,6 +134,12 @@ func NewApp(configIo io.Reader) *App {
 		logger.Err(err)
 	}
 
+	// Setup log format
+	err = utils.SetLogFormat(app.conf.LogFormat)
+	if err != nil {
+		logger.Err(err)
+	}
+
 	// Setup asynchronous manager
 	app.asyncManager = rest.NewAsyncHttpManager(ASYNC_ROUTE)
 
@@ -192,6 +232,10 @@ func NewApp(configIo io.Reader) *App {
 	// Set block settings
 	app.setBlockSettings()
 
+	if app.conf.EventBufferSize > 0 {
+		EventBufferSize = app.conf.EventBufferSize
+	}
+
 	//default monitor gluster node refresh time
 	var timer uint32 = 120
 	var startDelay uint32 = 10
@@ -201,11 +245,40 @@ func NewApp(configIo io.Reader) *App {
 	if app.conf.StartTimeMonitorGlusterNodes > 0 {
 		startDelay = app.conf.StartTimeMonitorGlusterNodes
 	}
+	app.webhooks = NewWebhookNotifier(app.conf.Webhooks)
+	app.webhooks.Start()
+	currentWebhookNotifier = app.webhooks
+
 	if MonitorGlusterNodes {
 		app.nhealth = NewNodeHealthCache(timer, startDelay, app.db, app.executor)
+		app.nhealth.OnHealthChange = app.nodeHealthChanged
+		if app.conf.MarkUnreachableNodes && !app.dbReadOnly {
+			var failedChecks uint32 = 3
+			if app.conf.UnreachableAfterFailedChecks > 0 {
+				failedChecks = app.conf.UnreachableAfterFailedChecks
+			}
+			app.nhealth.EnableNodeStates(app.db, int(failedChecks))
+			app.nhealth.OnStateChange = app.nodeStateChanged
+		}
 		app.nhealth.Monitor()
 		currentNodeHealthCache = app.nhealth
 	}
+	if MonitorGlusterNodes && app.conf.MonitorDevices {
+		var deviceTimer uint32 = 600
+		if app.conf.RefreshTimeMonitorDevices > 0 {
+			deviceTimer = app.conf.RefreshTimeMonitorDevices
+		}
+		app.dhealth = NewDeviceHealthCache(deviceTimer, startDelay, app.db, app.executor)
+		app.dhealth.OnStateChange = app.deviceHealthChanged
+		if app.conf.ThinPoolWarningPercent > 0 {
+			app.dhealth.ThinPoolWarning = float64(app.conf.ThinPoolWarningPercent)
+		}
+		app.dhealth.Monitor()
+		currentDeviceHealthCache = app.dhealth
+	}
+
+	app.metrics = prometheus.NewRegistry()
+	app.metrics.MustRegister(&metricsCollector{app: app})
 
 	// Show application has loaded
 	logger.Info("GlusterFS Application Loaded")
@@ -255,6 +328,11 @@ func (a *App) setFromEnvironmentalVariable() {
 		a.conf.Loglevel = env
 	}
 
+	env = os.Getenv("HEKETI_GLUSTERAPP_LOGFORMAT")
+	if env != "" {
+		a.conf.LogFormat = env
+	}
+
 	env = os.Getenv("HEKETI_IGNORE_STALE_OPERATIONS")
 	if env != "" {
 		a.conf.IgnoreStaleOperations, err = strconv.ParseBool(env)
@@ -292,6 +370,15 @@ func (a *App) setFromEnvironmentalVariable() {
 }
 
 func (a *App) setAdvSettings() {
+	if a.conf.Allocator != "" {
+		strategy := api.AllocationStrategy(a.conf.Allocator)
+		if err := api.ValidateAllocationStrategy(strategy); err != nil {
+			logger.LogError("Adv: %v, using %v", err, DefaultAllocationStrategy)
+		} else {
+			logger.Info("Adv: Allocation strategy set to %v", strategy)
+			DefaultAllocationStrategy = strategy
+		}
+	}
 	if a.conf.BrickMaxNum != 0 {
 		logger.Info("Adv: Max bricks per volume set to %v", a.conf.BrickMaxNum)
 
@@ -345,6 +432,20 @@ func (a *App) SetRoutes(router *mux.Router) error {
 			Pattern:     ASYNC_ROUTE + "/{id:[A-Fa-f0-9]+}",
 			HandlerFunc: a.asyncManager.HandlerStatus},
 
+		// Webhooks
+		rest.Route{
+			Name:        "WebhookList",
+			Method:      "GET",
+			Pattern:     "/webhooks",
+			HandlerFunc: a.WebhookList},
+
+		// Events
+		rest.Route{
+			Name:        "EventList",
+			Method:      "GET",
+			Pattern:     "/events",
+			HandlerFunc: a.EventList},
+
 		// Cluster
 		rest.Route{
 			Name:        "ClusterCreate",
@@ -356,6 +457,26 @@ func (a *App) SetRoutes(router *mux.Router) error {
 			Method:      "POST",
 			Pattern:     "/clusters/{id:[A-Fa-f0-9]+}/flags",
 			HandlerFunc: a.ClusterSetFlags},
+		rest.Route{
+			Name:        "ClusterSetTags",
+			Method:      "POST",
+			Pattern:     "/clusters/{id:[A-Fa-f0-9]+}/tags",
+			HandlerFunc: a.ClusterSetTags},
+		rest.Route{
+			Name:        "ClusterSetOvercommit",
+			Method:      "POST",
+			Pattern:     "/clusters/{id:[A-Fa-f0-9]+}/overcommit",
+			HandlerFunc: a.ClusterSetOvercommit},
+		rest.Route{
+			Name:        "ClusterStatus",
+			Method:      "GET",
+			Pattern:     "/clusters/{id:[A-Fa-f0-9]+}/status",
+			HandlerFunc: a.ClusterStatus},
+		rest.Route{
+			Name:        "ClusterCapacity",
+			Method:      "GET",
+			Pattern:     "/clusters/{id:[A-Fa-f0-9]+}/capacity",
+			HandlerFunc: a.ClusterCapacity},
 		rest.Route{
 			Name:        "ClusterInfo",
 			Method:      "GET",
@@ -398,6 +519,21 @@ func (a *App) SetRoutes(router *mux.Router) error {
 			Method:      "POST",
 			Pattern:     "/nodes/{id:[A-Fa-f0-9]+}/tags",
 			HandlerFunc: a.NodeSetTags},
+		rest.Route{
+			Name:        "NodeDiscoverDevices",
+			Method:      "GET",
+			Pattern:     "/nodes/{id:[A-Fa-f0-9]+}/discover-devices",
+			HandlerFunc: a.NodeDiscoverDevices},
+		rest.Route{
+			Name:        "NodeAddDiscoveredDevices",
+			Method:      "POST",
+			Pattern:     "/nodes/{id:[A-Fa-f0-9]+}/discover-devices",
+			HandlerFunc: a.NodeAddDiscoveredDevices},
+		rest.Route{
+			Name:        "NodeHealth",
+			Method:      "GET",
+			Pattern:     "/nodes/{id:[A-Fa-f0-9]+}/health",
+			HandlerFunc: a.NodeHealth},
 
 		// Devices
 		rest.Route{
@@ -430,6 +566,11 @@ func (a *App) SetRoutes(router *mux.Router) error {
 			Method:      "POST",
 			Pattern:     "/devices/{id:[A-Fa-f0-9]+}/tags",
 			HandlerFunc: a.DeviceSetTags},
+		rest.Route{
+			Name:        "DeviceHealth",
+			Method:      "GET",
+			Pattern:     "/devices/{id:[A-Fa-f0-9]+}/health",
+			HandlerFunc: a.DeviceHealth},
 
 		// Volume
 		rest.Route{
@@ -437,6 +578,11 @@ func (a *App) SetRoutes(router *mux.Router) error {
 			Method:      "POST",
 			Pattern:     "/volumes",
 			HandlerFunc: a.VolumeCreate},
+		rest.Route{
+			Name:        "VolumePlan",
+			Method:      "POST",
+			Pattern:     "/volumes/plan",
+			HandlerFunc: a.VolumePlan},
 		rest.Route{
 			Name:        "VolumeInfo",
 			Method:      "GET",
@@ -447,11 +593,66 @@ func (a *App) SetRoutes(router *mux.Router) error {
 			Method:      "POST",
 			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/expand",
 			HandlerFunc: a.VolumeExpand},
+		rest.Route{
+			Name:        "VolumeConvert",
+			Method:      "POST",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/convert",
+			HandlerFunc: a.VolumeConvert},
+		rest.Route{
+			Name:        "VolumeMigrate",
+			Method:      "POST",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/migrate",
+			HandlerFunc: a.VolumeMigrate},
+		rest.Route{
+			Name:        "VolumeMigrateCutover",
+			Method:      "POST",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/migrate/cutover",
+			HandlerFunc: a.VolumeMigrateCutover},
+		rest.Route{
+			Name:        "VolumeMigration",
+			Method:      "GET",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/migration",
+			HandlerFunc: a.VolumeMigration},
+		rest.Route{
+			Name:        "VolumeMigrateCancel",
+			Method:      "DELETE",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/migration",
+			HandlerFunc: a.VolumeMigrateCancel},
 		rest.Route{
 			Name:        "VolumeDelete",
 			Method:      "DELETE",
 			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}",
 			HandlerFunc: a.VolumeDelete},
+		rest.Route{
+			Name:        "VolumeSetTags",
+			Method:      "POST",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/tags",
+			HandlerFunc: a.VolumeSetTags},
+		rest.Route{
+			Name:        "VolumeStatus",
+			Method:      "GET",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/status",
+			HandlerFunc: a.VolumeStatus},
+		rest.Route{
+			Name:        "VolumeQuota",
+			Method:      "GET",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/quota",
+			HandlerFunc: a.VolumeQuota},
+		rest.Route{
+			Name:        "VolumeQuotaEnable",
+			Method:      "POST",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/quota",
+			HandlerFunc: a.VolumeQuotaEnable},
+		rest.Route{
+			Name:        "VolumeQuotaSetLimit",
+			Method:      "POST",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/quota/limits",
+			HandlerFunc: a.VolumeQuotaSetLimit},
+		rest.Route{
+			Name:        "VolumeQuotaRemoveLimit",
+			Method:      "DELETE",
+			Pattern:     "/volumes/{id:[A-Fa-f0-9]+}/quota/limits",
+			HandlerFunc: a.VolumeQuotaRemoveLimit},
 		rest.Route{
 			Name:        "VolumeList",
 			Method:      "GET",
@@ -481,12 +682,68 @@ func (a *App) SetRoutes(router *mux.Router) error {
 			Method:      "DELETE",
 			Pattern:     "/blockvolumes/{id:[A-Fa-f0-9]+}",
 			HandlerFunc: a.BlockVolumeDelete},
+		rest.Route{
+			Name:        "BlockVolumeSetTags",
+			Method:      "POST",
+			Pattern:     "/blockvolumes/{id:[A-Fa-f0-9]+}/tags",
+			HandlerFunc: a.BlockVolumeSetTags},
 		rest.Route{
 			Name:        "BlockVolumeList",
 			Method:      "GET",
 			Pattern:     "/blockvolumes",
 			HandlerFunc: a.BlockVolumeList},
 
+		// GeoReplication
+		rest.Route{
+			Name:        "GeoReplicationCreate",
+			Method:      "POST",
+			Pattern:     "/georeplication",
+			HandlerFunc: a.GeoReplicationCreate},
+		rest.Route{
+			Name:        "GeoReplicationInfo",
+			Method:      "GET",
+			Pattern:     "/georeplication/{id:[A-Fa-f0-9]+}",
+			HandlerFunc: a.GeoReplicationInfo},
+		rest.Route{
+			Name:        "GeoReplicationStatus",
+			Method:      "GET",
+			Pattern:     "/georeplication/{id:[A-Fa-f0-9]+}/status",
+			HandlerFunc: a.GeoReplicationStatus},
+		rest.Route{
+			Name:        "GeoReplicationAction",
+			Method:      "POST",
+			Pattern:     "/georeplication/{id:[A-Fa-f0-9]+}/action",
+			HandlerFunc: a.GeoReplicationAction},
+		rest.Route{
+			Name:        "GeoReplicationDelete",
+			Method:      "DELETE",
+			Pattern:     "/georeplication/{id:[A-Fa-f0-9]+}",
+			HandlerFunc: a.GeoReplicationDelete},
+		rest.Route{
+			Name:        "GeoReplicationList",
+			Method:      "GET",
+			Pattern:     "/georeplication",
+			HandlerFunc: a.GeoReplicationList},
+
+		// Tenants
+		rest.Route{
+			Name:        "TenantInfo",
+			Method:      "GET",
+			Pattern:     "/tenants/{id}",
+			HandlerFunc: a.TenantInfo},
+
+		// Health
+		rest.Route{
+			Name:        "Health",
+			Method:      "GET",
+			Pattern:     "/health",
+			HandlerFunc: a.Health},
+		rest.Route{
+			Name:        "HealthRefresh",
+			Method:      "POST",
+			Pattern:     "/health/refresh",
+			HandlerFunc: a.HealthRefresh},
+
 		// Backup
 		rest.Route{
 			Name:        "Backup",
@@ -522,7 +779,8 @@ func (a *App) SetRoutes(router *mux.Router) error {
 			Methods(route.Method).
 			Path(route.Pattern).
 			Name(route.Name).
-			Handler(route.HandlerFunc)
+			Handler(instrumentRoute(route.Name,
+				withRequestId(route.HandlerFunc)))
 
 	}
 
@@ -537,6 +795,18 @@ func (a *App) Close() {
 	if a.nhealth != nil {
 		a.nhealth.Stop()
 	}
+	if a.dhealth != nil {
+		a.dhealth.Stop()
+		if currentDeviceHealthCache == a.dhealth {
+			currentDeviceHealthCache = nil
+		}
+	}
+	if a.webhooks != nil {
+		a.webhooks.Stop()
+		if currentWebhookNotifier == a.webhooks {
+			currentWebhookNotifier = nil
+		}
+	}
 
 	// Close the DB
 	a.db.Close()
@@ -561,6 +831,16 @@ func (a *App) NotFoundHandler(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Invalid path or request", http.StatusNotFound)
 }
 
+// currentDevicePhysicalUsage returns the amount of data in KB written
+// to the thin pools of the device as last seen by the device health
+// monitor. The bool is false if it is not known.
+func currentDevicePhysicalUsage(deviceId string) (uint64, bool) {
+	if currentDeviceHealthCache == nil {
+		return 0, false
+	}
+	return currentDeviceHealthCache.PhysicalUsed(deviceId)
+}
+
 // currentNodeHealthStatus returns a map of node ids to the most
 // recently known health status (true is up, false is not up).
 // If a node is not found in the map its status is unknown.
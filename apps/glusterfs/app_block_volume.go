@@ -32,13 +32,13 @@ func (a *App) BlockVolumeCreate(w http.ResponseWriter, r *http.Request) {
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
 	if msg.Size < 1 {
 		http.Error(w, "Invalid volume size", http.StatusBadRequest)
-		logger.LogError("Invalid volume size")
+		apiLogger.LogError("Invalid volume size")
 		return
 	}
 
@@ -53,7 +53,7 @@ func (a *App) BlockVolumeCreate(w http.ResponseWriter, r *http.Request) {
 			return err
 		}
 		if len(clusters) == 0 {
-			err := logger.LogError("No clusters configured")
+			err := apiLogger.LogError("No clusters configured")
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return ErrNotFound
 		}
@@ -62,7 +62,7 @@ func (a *App) BlockVolumeCreate(w http.ResponseWriter, r *http.Request) {
 		for _, clusterid := range msg.Clusters {
 			_, err := NewClusterEntryFromId(tx, clusterid)
 			if err != nil {
-				err := logger.LogError("Cluster id %v not found", clusterid)
+				err := apiLogger.LogError("Cluster id %v not found", clusterid)
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				return err
 			}
@@ -76,6 +76,23 @@ func (a *App) BlockVolumeCreate(w http.ResponseWriter, r *http.Request) {
 
 	blockVolume := NewBlockVolumeEntryFromRequest(&msg)
 
+	blockVolume.Info.Tenant = requestTenant(r)
+	if t := a.tenantConfig(blockVolume.Info.Tenant); t != nil {
+		clusters, err := t.clusters(blockVolume.Info.Clusters)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			apiLogger.LogError(err.Error())
+			return
+		}
+		blockVolume.Info.Clusters = clusters
+
+		a.tenantLock.Lock()
+		defer a.tenantLock.Unlock()
+		if !a.checkTenantLimits(w, t, blockVolume.Info.Size, 1) {
+			return
+		}
+	}
+
 	bvc := NewBlockVolumeCreateOperation(blockVolume, a.db)
 	if err := AsyncHttpOperation(a, w, r, bvc); err != nil {
 		http.Error(w,
@@ -89,19 +106,49 @@ func (a *App) BlockVolumeList(w http.ResponseWriter, r *http.Request) {
 
 	var list api.BlockVolumeListResponse
 
-	err := a.db.View(func(tx *bolt.Tx) error {
-		var err error
+	q, err := newListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-		list.BlockVolumes, err = ListCompleteBlockVolumes(tx)
+	err = a.db.View(func(tx *bolt.Tx) error {
+		ids, err := ListCompleteBlockVolumes(tx)
 		if err != nil {
 			return err
 		}
 
+		list.BlockVolumes, list.Next, err = q.page(ids, func(id string) (bool, error) {
+			bv, err := NewBlockVolumeEntryFromId(tx, id)
+			if err != nil {
+				return false, err
+			}
+			return q.matchBlockVolume(bv), nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if q.Expand {
+			list.Info = []api.BlockVolumeInfoResponse{}
+			for _, id := range list.BlockVolumes {
+				bv, err := NewBlockVolumeEntryFromId(tx, id)
+				if err != nil {
+					return err
+				}
+				info, err := bv.NewInfoResponse(tx)
+				if err != nil {
+					return err
+				}
+				list.Info = append(list.Info, *info)
+			}
+		}
+
 		return nil
 	})
 
 	if err != nil {
-		logger.Err(err)
+		apiLogger.Err(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -181,3 +228,51 @@ func (a *App) BlockVolumeDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (a *App) BlockVolumeSetTags(w http.ResponseWriter, r *http.Request) {
+	// Get the id from the URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	var blockVolume *BlockVolumeEntry
+
+	// Unmarshal JSON
+	var msg api.TagsChangeRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		blockVolume, err = NewBlockVolumeEntryFromId(tx, id)
+		if err == ErrNotFound || (err == nil && !blockVolume.Visible()) {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		ApplyTags(blockVolume, msg)
+		if err := blockVolume.Save(tx); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		apiLogger.Err(err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(blockVolume.AllTags()); err != nil {
+		panic(err)
+	}
+}
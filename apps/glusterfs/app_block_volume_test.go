@@ -22,6 +22,7 @@ import (
 
 	"github.com/boltdb/bolt"
 	"github.com/gorilla/mux"
+	client "github.com/heketi/heketi/client/api/go-client"
 	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 	"github.com/heketi/tests"
@@ -591,3 +592,56 @@ func TestBlockVolumeDelete(t *testing.T) {
 	tests.Assert(t, r.StatusCode == http.StatusNotFound)
 	tests.Assert(t, err == nil)
 }
+
+func TestBlockVolumeTags(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		2*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	c := client.NewClientNoAuth(ts.URL)
+	bv1, err := c.BlockVolumeCreate(&api.BlockVolumeCreateRequest{
+		Size: 10,
+		Tags: map[string]string{"owner": "team-a"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, bv1.Tags["owner"] == "team-a",
+		`expected bv1.Tags["owner"] == "team-a", got:`, bv1.Tags)
+	bv2, err := c.BlockVolumeCreate(&api.BlockVolumeCreateRequest{Size: 10})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	err = c.BlockVolumeSetTags(bv2.Id, &api.TagsChangeRequest{
+		Change: api.UpdateTags,
+		Tags:   map[string]string{"owner": "team-b"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	info, err := c.BlockVolumeInfo(bv2.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.Tags["owner"] == "team-b",
+		`expected info.Tags["owner"] == "team-b", got:`, info.Tags)
+
+	r, err := http.Get(ts.URL + "/blockvolumes?tag=owner:team-b")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, r.StatusCode == http.StatusOK)
+	var list api.BlockVolumeListResponse
+	err = utils.GetJsonFromResponse(r, &list)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(list.BlockVolumes) == 1 && list.BlockVolumes[0] == bv2.Id,
+		"expected only bv2, got:", list.BlockVolumes)
+}
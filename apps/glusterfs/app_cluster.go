@@ -11,7 +11,9 @@ package glusterfs
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/boltdb/bolt"
 	"github.com/gorilla/mux"
@@ -28,6 +30,13 @@ func (a *App) ClusterCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
 	// Create a new ClusterInfo
 	entry := NewClusterEntryFromRequest(&msg)
 
@@ -95,24 +104,112 @@ func (a *App) ClusterSetFlags(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// ClusterSetOvercommit changes the overcommit ratio and physical
+// usage limit of a cluster. Only bricks placed afterwards use the
+// new settings.
+func (a *App) ClusterSetOvercommit(w http.ResponseWriter, r *http.Request) {
+	var msg api.ClusterSetOvercommitRequest
+
+	// Get the id from the URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		entry, err := NewClusterEntryFromId(tx, id)
+		if err == ErrNotFound {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		entry.Info.ClusterOvercommit = msg.ClusterOvercommit
+
+		err = entry.Save(tx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		apiLogger.Err(err)
+		return
+	}
+	apiLogger.Info("Cluster %v overcommit ratio set to %v, physical usage limit %v",
+		id, msg.OvercommitRatio, msg.PhysicalUsageLimit)
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+}
+
 func (a *App) ClusterList(w http.ResponseWriter, r *http.Request) {
 
 	var list api.ClusterListResponse
 
-	// Get all the cluster ids from the DB
-	err := a.db.View(func(tx *bolt.Tx) error {
-		var err error
+	q, err := newListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get the ids of the clusters matching the query from the DB
+	err = a.db.View(func(tx *bolt.Tx) error {
+		ids, err := ClusterList(tx)
+		if err != nil {
+			return err
+		}
 
-		list.Clusters, err = ClusterList(tx)
+		list.Clusters, list.Next, err = q.page(ids, func(id string) (bool, error) {
+			c, err := NewClusterEntryFromId(tx, id)
+			if err != nil {
+				return false, err
+			}
+			return q.matchCluster(c), nil
+		})
 		if err != nil {
 			return err
 		}
 
+		if q.Expand {
+			list.Info = []api.ClusterInfoResponse{}
+			for _, id := range list.Clusters {
+				c, err := NewClusterEntryFromId(tx, id)
+				if err != nil {
+					return err
+				}
+				info, err := c.NewClusterInfoResponse(tx)
+				if err != nil {
+					return err
+				}
+				err = UpdateClusterInfoComplete(tx, info)
+				if err != nil {
+					return err
+				}
+				list.Info = append(list.Info, *info)
+			}
+		}
+
 		return nil
 	})
 
 	if err != nil {
-		logger.Err(err)
+		apiLogger.Err(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -186,7 +283,7 @@ func (a *App) ClusterDelete(w http.ResponseWriter, r *http.Request) {
 			return err
 		} else if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return logger.Err(err)
+			return apiLogger.Err(err)
 		}
 
 		err = entry.Delete(tx)
@@ -206,8 +303,114 @@ func (a *App) ClusterDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Show that the key has been deleted
-	logger.Info("Deleted cluster [%s]", id)
+	apiLogger.Info("Deleted cluster [%s]", id)
+
+	// Write msg
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *App) ClusterSetTags(w http.ResponseWriter, r *http.Request) {
+	// Get the id from the URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	var cluster *ClusterEntry
+
+	// Unmarshal JSON
+	var msg api.TagsChangeRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		cluster, err = NewClusterEntryFromId(tx, id)
+		if err == ErrNotFound {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		ApplyTags(cluster, msg)
+		if err := cluster.Save(tx); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		apiLogger.Err(err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(cluster.AllTags()); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) ClusterStatus(w http.ResponseWriter, r *http.Request) {
+	// Get the id from the URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	status, err := ClusterStatus(a.db, a.requestExecutor(r), id)
+	if err == ErrNotFound {
+		http.Error(w, "Id not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		apiLogger.LogError("Unable to get status of cluster %v: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Write msg
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) ClusterCapacity(w http.ResponseWriter, r *http.Request) {
+	// Get the id from the URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	snapFactor := float32(1)
+	if v := r.URL.Query().Get("snapshot_factor"); v != "" {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil || f < 1 || f > VOLUME_CREATE_MAX_SNAPSHOT_FACTOR {
+			http.Error(w, fmt.Sprintf(
+				"invalid snapshot_factor: must be between 1 and %v",
+				VOLUME_CREATE_MAX_SNAPSHOT_FACTOR), http.StatusBadRequest)
+			return
+		}
+		snapFactor = float32(f)
+	}
+
+	capacity, err := ClusterCapacity(a.db, id, snapFactor)
+	if err == ErrNotFound {
+		http.Error(w, "Id not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		apiLogger.LogError("Unable to get capacity of cluster %v: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Write msg
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(capacity); err != nil {
+		panic(err)
+	}
 }
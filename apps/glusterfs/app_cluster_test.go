@@ -16,10 +16,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/boltdb/bolt"
 	"github.com/gorilla/mux"
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/executors"
 	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 	"github.com/heketi/tests"
@@ -459,3 +463,280 @@ func TestClusterDelete(t *testing.T) {
 	tests.Assert(t, err == nil, err)
 
 }
+
+func TestClusterTags(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	c := client.NewClientNoAuth(ts.URL)
+	c1, err := c.ClusterCreate(&api.ClusterCreateRequest{
+		ClusterFlags: api.ClusterFlags{Block: true, File: true},
+		Tags:         map[string]string{"owner": "team-a"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, c1.Tags["owner"] == "team-a",
+		`expected c1.Tags["owner"] == "team-a", got:`, c1.Tags)
+	c2, err := c.ClusterCreate(&api.ClusterCreateRequest{
+		ClusterFlags: api.ClusterFlags{Block: true, File: true},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	_, err = c.ClusterCreate(&api.ClusterCreateRequest{
+		Tags: map[string]string{"bad tag": "x"},
+	})
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	err = c.ClusterSetTags(c2.Id, &api.TagsChangeRequest{
+		Change: api.SetTags,
+		Tags:   map[string]string{"owner": "team-b", "cost-center": "7"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	info, err := c.ClusterInfo(c2.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(info.Tags) == 2, "expected len(info.Tags) == 2, got:", info.Tags)
+
+	r, err := http.Get(ts.URL + "/clusters?tag=owner:team-b")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, r.StatusCode == http.StatusOK)
+	var list api.ClusterListResponse
+	err = utils.GetJsonFromResponse(r, &list)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(list.Clusters) == 1 && list.Clusters[0] == c2.Id,
+		"expected only c2, got:", list.Clusters)
+
+	clusters, err := c.ClusterListQuery(&api.ListQuery{
+		Tags:   []string{"cost-center:7"},
+		Expand: true,
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(clusters.Info) == 1 && clusters.Info[0].Id == c2.Id,
+		"expected the info of c2, got:", clusters.Info)
+	tests.Assert(t, clusters.Info[0].Tags["owner"] == "team-b",
+		"expected the tags of c2, got:", clusters.Info[0].Tags)
+
+	err = c.ClusterSetTags("0123456789abcdef0123456789abcdef", &api.TagsChangeRequest{
+		Change: api.SetTags,
+		Tags:   map[string]string{"owner": "team-a"},
+	})
+	tests.Assert(t, err != nil, "expected err != nil")
+}
+
+func TestClusterCapacity(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,      // clusters
+		6,      // nodes_per_cluster
+		2,      // devices_per_node,
+		500*GB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	c := client.NewClientNoAuth(ts.URL)
+	clusters, err := c.ClusterList()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	clusterId := clusters.Clusters[0]
+
+	capacity, err := c.ClusterCapacity(clusterId, 1)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, capacity.Id == clusterId)
+	tests.Assert(t, capacity.Storage.Free == 12*500*GB,
+		"expected capacity.Storage.Free == 12*500*GB, got:", capacity.Storage.Free)
+	tests.Assert(t, len(capacity.Volumes) == 3,
+		"expected len(capacity.Volumes) == 3, got:", capacity.Volumes)
+	sizes := map[string]int{}
+	for _, v := range capacity.Volumes {
+		sizes[v.Name] = v.MaxSize
+	}
+	// the devices can not be filled completely because of the
+	// metadata of the thin pools
+	tests.Assert(t, sizes["replica3"] > 1000 && sizes["replica3"] < 2000,
+		"expected replica3 between 1000 and 2000, got:", sizes)
+	tests.Assert(t, sizes["arbiter"] > 1000,
+		"expected arbiter > 1000, got:", sizes)
+	tests.Assert(t, sizes["disperse4+2"] > sizes["replica3"],
+		"expected disperse4+2 > replica3, got:", sizes)
+
+	// snapshots need more space
+	snapCapacity, err := c.ClusterCapacity(clusterId, 1.5)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, snapCapacity.SnapshotFactor == 1.5)
+	tests.Assert(t, snapCapacity.Volumes[0].MaxSize < sizes["replica3"],
+		"expected less space with snapshots, got:", snapCapacity.Volumes)
+
+	// volumes take space from the cluster
+	_, err = c.VolumeCreate(&api.VolumeCreateRequest{
+		Size: sizes["replica3"] / 2,
+		Durability: api.VolumeDurabilityInfo{
+			Type:      api.DurabilityReplicate,
+			Replicate: api.ReplicaDurability{Replica: 3},
+		},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	capacity, err = c.ClusterCapacity(clusterId, 1)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, capacity.Storage.Free < 12*500*GB,
+		"expected capacity.Storage.Free < 12*500*GB, got:", capacity.Storage.Free)
+	tests.Assert(t, capacity.Volumes[0].MaxSize < sizes["replica3"],
+		"expected less space left, got:", capacity.Volumes)
+
+	_, err = c.ClusterCapacity(clusterId, 101)
+	tests.Assert(t, err != nil, "expected err != nil")
+	_, err = c.ClusterCapacity("0123456789abcdef0123456789abcdef", 1)
+	tests.Assert(t, err != nil, "expected err != nil")
+}
+
+func TestClusterOvercommit(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,      // clusters
+		3,      // nodes_per_cluster
+		1,      // devices_per_node,
+		100*GB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	c := client.NewClientNoAuth(ts.URL)
+	clusters, err := c.ClusterList()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	clusterId := clusters.Clusters[0]
+
+	volReq := &api.VolumeCreateRequest{
+		Size: 150,
+		Durability: api.VolumeDurabilityInfo{
+			Type:      api.DurabilityReplicate,
+			Replicate: api.ReplicaDurability{Replica: 3},
+		},
+	}
+
+	// the bricks do not fit on the devices
+	_, err = c.VolumeCreate(volReq)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// invalid settings are refused
+	req := &api.ClusterSetOvercommitRequest{}
+	req.OvercommitRatio = 0.5
+	err = c.ClusterSetOvercommit(clusterId, req)
+	tests.Assert(t, err != nil, "expected err != nil")
+	req.OvercommitRatio = 3
+	req.PhysicalUsageLimit = 101
+	err = c.ClusterSetOvercommit(clusterId, req)
+	tests.Assert(t, err != nil, "expected err != nil")
+	req.PhysicalUsageLimit = 0
+	err = c.ClusterSetOvercommit("0123456789abcdef0123456789abcdef", req)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	err = c.ClusterSetOvercommit(clusterId, req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	info, err := c.ClusterInfo(clusterId)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.OvercommitRatio == 3,
+		"expected info.OvercommitRatio == 3, got:", info.OvercommitRatio)
+
+	capacity, err := c.ClusterCapacity(clusterId, 1)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, capacity.Volumes[0].MaxSize > 150,
+		"expected replica3 > 150, got:", capacity.Volumes)
+
+	// the thin pools of the bricks are a third of their size and are
+	// created so that LVM extends them
+	var brickReqs []*executors.BrickRequest
+	var brickReqsLock sync.Mutex
+	app.xo.MockBrickCreate = func(host string, brick *executors.BrickRequest) (*executors.BrickInfo, error) {
+		brickReqsLock.Lock()
+		defer brickReqsLock.Unlock()
+		brickReqs = append(brickReqs, brick)
+		return &executors.BrickInfo{Path: brick.Path, Host: host}, nil
+	}
+	vol, err := c.VolumeCreate(volReq)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(brickReqs) == 3,
+		"expected 3 bricks created, got:", len(brickReqs))
+	for _, br := range brickReqs {
+		tests.Assert(t, br.TpSize < br.Size/2,
+			"expected thin pool smaller than brick, got:", br.TpSize, br.Size)
+		tests.Assert(t, br.Autoextend, "expected autoextend, got:", br)
+	}
+	var used uint64
+	app.db.View(func(tx *bolt.Tx) error {
+		for _, b := range vol.Bricks {
+			brick, err := NewBrickEntryFromId(tx, b.Id)
+			tests.Assert(t, err == nil, "expected err == nil, got:", err)
+			tests.Assert(t, brick.TpSize < brick.Info.Size/2,
+				"expected thin pool smaller than brick, got:", brick.TpSize)
+			used += brick.TotalSize()
+		}
+		return nil
+	})
+	capacity, err = c.ClusterCapacity(clusterId, 1)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, capacity.Storage.Used == used,
+		"expected capacity.Storage.Used == used, got:",
+		capacity.Storage.Used, used)
+
+	// devices whose thin pools are mostly full take no more bricks
+	app.xo.MockDeviceHealth = func(host, device, vgid string) (*executors.DeviceHealth, error) {
+		return &executors.DeviceHealth{
+			ThinPools: []executors.ThinPoolUsage{
+				{Name: "tp_a", DataPercent: 95, Size: 100 * GB},
+			},
+		}, nil
+	}
+	dc := NewDeviceHealthCache(1, 0, app.db, app.executor)
+	err = dc.Refresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	defer tests.Patch(&currentDeviceHealthCache, dc).Restore()
+
+	volReq.Size = 10
+	plan, err := c.VolumePlan(volReq)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, !plan.Feasible, "expected plan not to be feasible")
+	tests.Assert(t, len(plan.Rejected) == 1 &&
+		len(plan.Rejected[0].Devices) == 3,
+		"expected 3 rejected devices, got:", plan.Rejected)
+	tests.Assert(t, strings.Contains(plan.Rejected[0].Devices[0].Reason,
+		"used by data"), "expected physical usage reason, got:",
+		plan.Rejected[0].Devices[0].Reason)
+
+	// the devices take bricks again once overcommit is disabled
+	req.OvercommitRatio = 0
+	err = c.ClusterSetOvercommit(clusterId, req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	plan, err = c.VolumePlan(volReq)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, plan.Feasible, "expected plan to be feasible, got:", plan)
+}
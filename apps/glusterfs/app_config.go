@@ -28,6 +28,7 @@ type GlusterFSConfig struct {
 	SshConfig  sshexec.SshConfig   `json:"sshexec"`
 	KubeConfig kubeexec.KubeConfig `json:"kubeexec"`
 	Loglevel   string              `json:"loglevel"`
+	LogFormat  string              `json:"log_format"`
 
 	// advanced settings
 	BrickMaxSize    int    `json:"brick_max_size_gb"`
@@ -43,6 +44,18 @@ type GlusterFSConfig struct {
 	IgnoreStaleOperations          bool   `json:"ignore_stale_operations"`
 	RefreshTimeMonitorGlusterNodes uint32 `json:"refresh_time_monitor_gluster_nodes"`
 	StartTimeMonitorGlusterNodes   uint32 `json:"start_time_monitor_gluster_nodes"`
+	MarkUnreachableNodes           bool   `json:"mark_unreachable_nodes"`
+	UnreachableAfterFailedChecks   uint32 `json:"unreachable_after_failed_checks"`
+	MonitorDevices                 bool   `json:"monitor_devices"`
+	RefreshTimeMonitorDevices      uint32 `json:"refresh_time_monitor_devices"`
+	ThinPoolWarningPercent         uint32 `json:"thin_pool_warning_percent"`
+	EventBufferSize                uint64 `json:"event_buffer_size"`
+
+	// notifications
+	Webhooks []WebhookConfig `json:"webhooks"`
+
+	// limits of the capacity provisioned per tenant
+	Tenants []TenantConfig `json:"tenants"`
 
 	// operation retry amounts
 	RetryLimits RetryLimitConfig `json:"operation_retry_limits"`
@@ -12,9 +12,11 @@ package glusterfs
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/boltdb/bolt"
 	"github.com/gorilla/mux"
+	"github.com/heketi/heketi/executors"
 	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 )
@@ -31,7 +33,7 @@ func (a *App) DeviceAdd(w http.ResponseWriter, r *http.Request) {
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
@@ -71,7 +73,7 @@ func (a *App) DeviceAdd(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Log the devices are being added
-	logger.Info("Adding device %v to node %v", msg.Name, msg.NodeId)
+	apiLogger.Info("Adding device %v to node %v", msg.Name, msg.NodeId)
 
 	// Add device in an asynchronous function
 	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (seeOtherUrl string, e error) {
@@ -81,7 +83,7 @@ func (a *App) DeviceAdd(w http.ResponseWriter, r *http.Request) {
 				a.db.Update(func(tx *bolt.Tx) error {
 					err := device.Deregister(tx)
 					if err != nil {
-						logger.Err(err)
+						apiLogger.Err(err)
 						return err
 					}
 
@@ -91,63 +93,73 @@ func (a *App) DeviceAdd(w http.ResponseWriter, r *http.Request) {
 		}()
 
 		// Setup device on node
-		info, err := a.executor.DeviceSetup(node.ManageHostName(),
-			device.Info.Name, device.Info.Id, msg.DestroyData)
+		err := a.setupDevice(a.requestExecutor(r), node, device, msg.DestroyData)
 		if err != nil {
 			return "", err
 		}
 
-		// Create an entry for the device and set the size
-		device.StorageSet(info.Size)
-		device.SetExtentSize(info.ExtentSize)
+		apiLogger.Info("Added device %v", msg.Name)
 
-		// Setup garbage collector on error
-		defer func() {
-			if e != nil {
-				a.executor.DeviceTeardown(node.ManageHostName(),
-					device.Info.Name,
-					device.Info.Id)
-			}
-		}()
+		// Done
+		// Returning a null string instructs the async manager
+		// to return http status of 204 (No Content)
+		return "", nil
+	})
 
-		// Save on db
-		err = a.db.Update(func(tx *bolt.Tx) error {
+}
 
-			nodeEntry, err := NewNodeEntryFromId(tx, msg.NodeId)
-			if err != nil {
-				return err
-			}
+// setupDevice initializes the registered device on the node and
+// saves it in the db. On failure the device is torn down again but it
+// is up to the caller to deregister it.
+func (a *App) setupDevice(executor executors.Executor,
+	node *NodeEntry, device *DeviceEntry, destroy bool) (e error) {
 
-			// Add device to node
-			nodeEntry.DeviceAdd(device.Info.Id)
+	info, err := executor.DeviceSetup(node.ManageHostName(),
+		device.Info.Name, device.Info.Id, destroy)
+	if err != nil {
+		return err
+	}
 
-			// Commit
-			err = nodeEntry.Save(tx)
-			if err != nil {
-				return err
-			}
+	// Create an entry for the device and set the size
+	device.StorageSet(info.Size)
+	device.SetExtentSize(info.ExtentSize)
 
-			// Save drive
-			err = device.Save(tx)
-			if err != nil {
-				return err
-			}
+	// Setup garbage collector on error
+	defer func() {
+		if e != nil {
+			executor.DeviceTeardown(node.ManageHostName(),
+				device.Info.Name,
+				device.Info.Id)
+		}
+	}()
 
-			return nil
+	// Save on db
+	return a.db.Update(func(tx *bolt.Tx) error {
 
-		})
+		nodeEntry, err := NewNodeEntryFromId(tx, node.Info.Id)
 		if err != nil {
-			return "", err
+			return err
 		}
 
-		logger.Info("Added device %v", msg.Name)
+		// Add device to node
+		nodeEntry.DeviceAdd(device.Info.Id)
 
-		// Done
-		// Returning a null string instructs the async manager
-		// to return http status of 204 (No Content)
-		return "", nil
-	})
+		// Commit
+		err = nodeEntry.Save(tx)
+		if err != nil {
+			return err
+		}
 
+		// Record the identifiers of the disk now that they
+		// are known
+		err = device.SetIdentity(tx, info)
+		if err != nil {
+			return err
+		}
+
+		// Save drive
+		return device.Save(tx)
+	})
 }
 
 func (a *App) DeviceInfo(w http.ResponseWriter, r *http.Request) {
@@ -173,6 +185,10 @@ func (a *App) DeviceInfo(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return err
 		}
+		if a.dhealth != nil {
+			h := a.dhealth.Health(id)
+			info.Health = &h
+		}
 
 		return nil
 	})
@@ -194,6 +210,17 @@ func (a *App) DeviceDelete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	force := false
+	if v := r.URL.Query().Get("force"); v != "" {
+		var err error
+		force, err = strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "invalid force: must be true or false",
+				http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Check request
 	var (
 		device *DeviceEntry
@@ -208,13 +235,13 @@ func (a *App) DeviceDelete(w http.ResponseWriter, r *http.Request) {
 			return err
 		} else if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return logger.Err(err)
+			return apiLogger.Err(err)
 		}
 
 		// Check if we can delete the device
 		if device.HasBricks() {
 			http.Error(w, device.ConflictString(), http.StatusConflict)
-			logger.LogError(device.ConflictString())
+			apiLogger.LogError(device.ConflictString())
 			return ErrConflict
 		}
 
@@ -222,7 +249,7 @@ func (a *App) DeviceDelete(w http.ResponseWriter, r *http.Request) {
 		node, err = NewNodeEntryFromId(tx, device.NodeId)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return logger.Err(err)
+			return apiLogger.Err(err)
 		}
 
 		return nil
@@ -232,11 +259,32 @@ func (a *App) DeviceDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete device
-	logger.Info("Deleting device %v on node %v", device.Info.Id, device.NodeId)
+	apiLogger.Info("Deleting device %v on node %v", device.Info.Id, device.NodeId)
 	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
 
+		// Make sure the path still refers to the same disk before
+		// wiping it. A device which can not be read is only torn down
+		// if the caller forces it.
+		info, err := a.requestExecutor(r).GetDeviceInfo(node.ManageHostName(),
+			device.Info.Name, device.Info.Id)
+		if err != nil && !force {
+			return "", apiLogger.LogError("Unable to verify the disk of "+
+				"device %v before deleting it, delete it with force to "+
+				"tear it down anyway: %v", device.Info.Id, err)
+		} else if err != nil {
+			apiLogger.Warning("Unable to read device %v before deleting it, "+
+				"deleting it as forced: %v", device.Info.Id, err)
+		} else {
+			err = a.db.View(func(tx *bolt.Tx) error {
+				return device.CheckIdentity(tx, info)
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+
 		// Teardown device
-		err := a.executor.DeviceTeardown(node.ManageHostName(),
+		err = a.requestExecutor(r).DeviceTeardown(node.ManageHostName(),
 			device.Info.Name, device.Info.Id)
 		if err != nil {
 			return "", err
@@ -248,13 +296,13 @@ func (a *App) DeviceDelete(w http.ResponseWriter, r *http.Request) {
 			// Access node entry
 			node, err := NewNodeEntryFromId(tx, device.NodeId)
 			if err == ErrNotFound {
-				logger.Critical(
+				apiLogger.Critical(
 					"Node id %v pointed to by device %v, but it is not in the db",
 					device.NodeId,
 					device.Info.Id)
 				return err
 			} else if err != nil {
-				logger.Err(err)
+				apiLogger.Err(err)
 				return err
 			}
 
@@ -267,14 +315,14 @@ func (a *App) DeviceDelete(w http.ResponseWriter, r *http.Request) {
 			// Delete device from db
 			err = device.Delete(tx)
 			if err != nil {
-				logger.Err(err)
+				apiLogger.Err(err)
 				return err
 			}
 
 			// Deregister device
 			err = device.Deregister(tx)
 			if err != nil {
-				logger.Err(err)
+				apiLogger.Err(err)
 				return err
 			}
 
@@ -286,7 +334,7 @@ func (a *App) DeviceDelete(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Show that the key has been deleted
-		logger.Info("Deleted node [%s]", id)
+		apiLogger.Info("Deleted node [%s]", id)
 
 		return "", nil
 	})
@@ -309,7 +357,7 @@ func (a *App) DeviceSetState(w http.ResponseWriter, r *http.Request) {
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
@@ -332,10 +380,14 @@ func (a *App) DeviceSetState(w http.ResponseWriter, r *http.Request) {
 
 	// Set state
 	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
-		err = device.SetState(a.db, a.executor, msg.State)
+		from := device.State
+		err = device.SetState(a.db, a.requestExecutor(r), msg.State)
 		if err != nil {
 			return "", err
 		}
+		if from != api.EntryStateFailed && msg.State == api.EntryStateFailed {
+			a.deviceStateFailed(device)
+		}
 		return "", nil
 	})
 }
@@ -368,17 +420,40 @@ func (a *App) DeviceResync(w http.ResponseWriter, r *http.Request) {
 		return
 	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		logger.Err(err)
+		apiLogger.Err(err)
 		return
 	}
 
-	logger.Info("Checking for device %v changes", deviceId)
+	apiLogger.Info("Checking for device %v changes", deviceId)
 
 	// Check and update device in background
 	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (seeOtherUrl string, e error) {
 
 		// Get actual device info from manage host
-		info, err := a.executor.GetDeviceInfo(node.ManageHostName(), device.Info.Name, device.Info.Id)
+		info, err := a.requestExecutor(r).GetDeviceInfo(node.ManageHostName(), device.Info.Name, device.Info.Id)
+		if err != nil {
+			return "", err
+		}
+
+		// Refuse to touch a device whose path now refers to a
+		// different disk
+		var identityChanged bool
+		err = a.db.View(func(tx *bolt.Tx) error {
+			err := device.CheckIdentity(tx, info)
+			if err != nil {
+				return err
+			}
+			recorded, err := device.Identity(tx)
+			if err != nil {
+				return err
+			}
+			probed, err := device.ProbedIdentity(tx, info)
+			if err != nil {
+				return err
+			}
+			identityChanged = recorded != probed
+			return nil
+		})
 		if err != nil {
 			return "", err
 		}
@@ -388,12 +463,13 @@ func (a *App) DeviceResync(w http.ResponseWriter, r *http.Request) {
 		// allocated space, because every allocation decreases the free disk space returned
 		// by method GetDeviceInfo. In order to calculate a new total space we need to sum
 		// the free disk space and the space used by heketi.
-		if device.Info.Storage.Total == info.Size+device.Info.Storage.Used {
-			logger.Info("Device %v is up to date", device.Info.Id)
+		if device.Info.Storage.Total == info.Size+device.Info.Storage.Used &&
+			!identityChanged {
+			apiLogger.Info("Device %v is up to date", device.Info.Id)
 			return "", nil
 		}
 
-		logger.Debug("Free space of '%v' (%v) has changed %v -> %v", device.Info.Name, device.Info.Id,
+		apiLogger.Debug("Free space of '%v' (%v) has changed %v -> %v", device.Info.Name, device.Info.Id,
 			device.Info.Storage.Free, info.Size)
 
 		// Update device
@@ -402,23 +478,31 @@ func (a *App) DeviceResync(w http.ResponseWriter, r *http.Request) {
 			// Reload device in current transaction
 			device, err := NewDeviceEntryFromId(tx, deviceId)
 			if err != nil {
-				logger.Err(err)
+				apiLogger.Err(err)
 				return err
 			}
 
 			newFreeSize := info.Size
 			newTotalSize := newFreeSize + device.Info.Storage.Used
 
-			logger.Info("Updating device %v, total: %v -> %v, free: %v -> %v", device.Info.Name,
+			apiLogger.Info("Updating device %v, total: %v -> %v, free: %v -> %v", device.Info.Name,
 				device.Info.Storage.Total, newTotalSize, device.Info.Storage.Free, newFreeSize)
 
 			device.Info.Storage.Total = newTotalSize
 			device.Info.Storage.Free = newFreeSize
 
+			// Devices added before identifiers were recorded
+			// pick them up here
+			err = device.SetIdentity(tx, info)
+			if err != nil {
+				apiLogger.Err(err)
+				return err
+			}
+
 			// Save updated device
 			err = device.Save(tx)
 			if err != nil {
-				logger.Err(err)
+				apiLogger.Err(err)
 				return err
 			}
 
@@ -428,7 +512,7 @@ func (a *App) DeviceResync(w http.ResponseWriter, r *http.Request) {
 			return "", err
 		}
 
-		logger.Info("Updated device %v", deviceId)
+		apiLogger.Info("Updated device %v", deviceId)
 
 		return "", err
 	})
@@ -451,7 +535,7 @@ func (a *App) DeviceSetTags(w http.ResponseWriter, r *http.Request) {
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
@@ -472,7 +556,7 @@ func (a *App) DeviceSetTags(w http.ResponseWriter, r *http.Request) {
 		return nil
 	})
 	if err != nil {
-		logger.Err(err)
+		apiLogger.Err(err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
@@ -0,0 +1,210 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/boltdb/bolt"
+	"github.com/gorilla/mux"
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+func (a *App) NodeDiscoverDevices(w http.ResponseWriter, r *http.Request) {
+
+	// Get node id from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	node, err := a.nodeForDiscovery(w, id)
+	if err != nil {
+		return
+	}
+
+	devices, err := a.discoverDevices(a.requestExecutor(r), node)
+	if err != nil {
+		apiLogger.LogError("Unable to discover devices on node %v: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	info := &api.DeviceDiscoverResponse{
+		NodeId:  id,
+		Devices: make([]api.DiscoveredDevice, 0, len(devices)),
+	}
+	for _, d := range devices {
+		info.Devices = append(info.Devices, api.DiscoveredDevice{
+			Name:   d.Name,
+			Size:   d.Size,
+			Model:  d.Model,
+			Serial: d.Serial,
+		})
+	}
+
+	// Write msg
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) NodeAddDiscoveredDevices(w http.ResponseWriter, r *http.Request) {
+
+	// Get node id from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var msg api.DeviceDiscoverAddRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	node, err := a.nodeForDiscovery(w, id)
+	if err != nil {
+		return
+	}
+
+	apiLogger.Info("Adding all unused devices of node %v", id)
+
+	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
+		executor := a.requestExecutor(r)
+		devices, err := a.discoverDevices(executor, node)
+		if err != nil {
+			return "", err
+		}
+
+		// Try every device, one bad disk should not prevent
+		// the others from being added
+		failed := []string{}
+		for _, d := range devices {
+			req := &api.DeviceAddRequest{}
+			req.Name = d.Name
+			req.Tags = msg.Tags
+			req.NodeId = id
+			err := a.addDiscoveredDevice(executor, node,
+				NewDeviceEntryFromRequest(req), msg.DestroyData)
+			if err != nil {
+				apiLogger.LogError("Unable to add device %v to node %v: %v",
+					d.Name, id, err)
+				failed = append(failed, fmt.Sprintf("%v: %v", d.Name, err))
+				continue
+			}
+			apiLogger.Info("Added device %v", d.Name)
+		}
+		if len(failed) > 0 {
+			return "", fmt.Errorf("Failed to add devices: %v",
+				strings.Join(failed, "; "))
+		}
+
+		return "/nodes/" + id, nil
+	})
+}
+
+// nodeForDiscovery loads the node for the discovery handlers and
+// writes the http error if it can not be used.
+func (a *App) nodeForDiscovery(w http.ResponseWriter,
+	id string) (*NodeEntry, error) {
+
+	var node *NodeEntry
+	err := a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		node, err = NewNodeEntryFromId(tx, id)
+		if err == ErrNotFound {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		if !node.isOnline() {
+			err = fmt.Errorf("Node %v is not online", id)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return err
+		}
+
+		return nil
+	})
+	return node, err
+}
+
+// discoverDevices returns the unused devices of the node which are
+// not already known to heketi.
+func (a *App) discoverDevices(executor executors.Executor,
+	node *NodeEntry) ([]executors.DiscoveredDevice, error) {
+
+	found, err := executor.DeviceDiscover(node.ManageHostName())
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	err = a.db.View(func(tx *bolt.Tx) error {
+		for _, deviceId := range node.Devices {
+			device, err := NewDeviceEntryFromId(tx, deviceId)
+			if err != nil {
+				return err
+			}
+			known[device.Info.Name] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	devices := []executors.DiscoveredDevice{}
+	for _, d := range found {
+		if !known[d.Name] {
+			devices = append(devices, d)
+		}
+	}
+	return devices, nil
+}
+
+func (a *App) addDiscoveredDevice(executor executors.Executor,
+	node *NodeEntry, device *DeviceEntry, destroy bool) (e error) {
+
+	err := a.db.Update(func(tx *bolt.Tx) error {
+		return device.Register(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if e != nil {
+			a.db.Update(func(tx *bolt.Tx) error {
+				err := device.Deregister(tx)
+				if err != nil {
+					apiLogger.Err(err)
+				}
+				return err
+			})
+		}
+	}()
+
+	return a.setupDevice(executor, node, device, destroy)
+}
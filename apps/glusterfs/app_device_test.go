@@ -11,10 +11,12 @@ package glusterfs
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -997,3 +999,276 @@ func TestDeviceSetTags(t *testing.T) {
 	tests.Assert(t, r.StatusCode == http.StatusNotFound,
 		"expected r.StatusCode == http.StatusNotFound, got:", r.StatusCode)
 }
+
+func TestNodeDiscoverDevices(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	// Create a client
+	c := client.NewClientNoAuth(ts.URL)
+	tests.Assert(t, c != nil)
+
+	// Create Cluster
+	cluster_req := &api.ClusterCreateRequest{
+		ClusterFlags: api.ClusterFlags{
+			Block: true,
+			File:  true,
+		},
+	}
+	cluster, err := c.ClusterCreate(cluster_req)
+	tests.Assert(t, err == nil)
+
+	// Create Node
+	nodeReq := &api.NodeAddRequest{
+		Zone:      1,
+		ClusterId: cluster.Id,
+	}
+	nodeReq.Hostnames.Manage = sort.StringSlice{"manage.host"}
+	nodeReq.Hostnames.Storage = sort.StringSlice{"storage.host"}
+	node, err := c.NodeAdd(nodeReq)
+	tests.Assert(t, err == nil)
+
+	// Add a device by hand
+	deviceReq := &api.DeviceAddRequest{}
+	deviceReq.Name = "/dev/fake1"
+	deviceReq.NodeId = node.Id
+	err = c.DeviceAdd(deviceReq)
+	tests.Assert(t, err == nil)
+
+	// Known devices must not be reported again
+	discovered := []executors.DiscoveredDevice{
+		{Name: "/dev/fake1", Size: 500 * GB, Model: "disk", Serial: "s1"},
+		{Name: "/dev/fake2", Size: 500 * GB, Model: "disk", Serial: "s2"},
+		{Name: "/dev/fake3", Size: 1000 * GB, Model: "disk", Serial: "s3"},
+	}
+	app.xo.MockDeviceDiscover = func(host string) ([]executors.DiscoveredDevice, error) {
+		tests.Assert(t, host == "manage.host", host)
+		return discovered, nil
+	}
+
+	devices, err := c.NodeDiscoverDevices(node.Id)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, devices.NodeId == node.Id)
+	tests.Assert(t, len(devices.Devices) == 2, devices.Devices)
+	tests.Assert(t, devices.Devices[0].Name == "/dev/fake2")
+	tests.Assert(t, devices.Devices[0].Serial == "s2")
+	tests.Assert(t, devices.Devices[1].Name == "/dev/fake3")
+	tests.Assert(t, devices.Devices[1].Size == 1000*GB)
+
+	// Unknown node
+	_, err = c.NodeDiscoverDevices(utils.GenUUID())
+	tests.Assert(t, err != nil)
+
+	// Add all of them
+	req := &api.DeviceDiscoverAddRequest{
+		Tags: map[string]string{"discovered": "yes"},
+	}
+	info, err := c.NodeAddDiscoveredDevices(node.Id, req)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(info.DevicesInfo) == 3, info.DevicesInfo)
+	tagged := 0
+	for _, d := range info.DevicesInfo {
+		if d.Tags["discovered"] == "yes" {
+			tagged++
+		}
+	}
+	tests.Assert(t, tagged == 2, tagged)
+
+	// Nothing left to discover
+	devices, err = c.NodeDiscoverDevices(node.Id)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(devices.Devices) == 0, devices.Devices)
+
+	// A failing device is reported and not left registered
+	discovered = append(discovered,
+		executors.DiscoveredDevice{Name: "/dev/fake4", Size: 500 * GB})
+	app.xo.MockDeviceSetup = func(host, device, vgid string, destroy bool) (*executors.DeviceInfo, error) {
+		return nil, ErrDbAccess
+	}
+	_, err = c.NodeAddDiscoveredDevices(node.Id, &api.DeviceDiscoverAddRequest{})
+	tests.Assert(t, err != nil)
+	tests.Assert(t, strings.Contains(err.Error(), "/dev/fake4"), err)
+
+	devices, err = c.NodeDiscoverDevices(node.Id)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(devices.Devices) == 1, devices.Devices)
+
+	node, err = c.NodeInfo(node.Id)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(node.DevicesInfo) == 3, node.DevicesInfo)
+}
+
+func TestDeviceIdentity(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	// Create a client
+	c := client.NewClientNoAuth(ts.URL)
+	tests.Assert(t, c != nil)
+
+	// Create Cluster
+	cluster_req := &api.ClusterCreateRequest{
+		ClusterFlags: api.ClusterFlags{
+			Block: true,
+			File:  true,
+		},
+	}
+	cluster, err := c.ClusterCreate(cluster_req)
+	tests.Assert(t, err == nil)
+
+	// Create Node
+	nodeReq := &api.NodeAddRequest{
+		Zone:      1,
+		ClusterId: cluster.Id,
+	}
+	nodeReq.Hostnames.Manage = sort.StringSlice{"manage.host"}
+	nodeReq.Hostnames.Storage = sort.StringSlice{"storage.host"}
+	node, err := c.NodeAdd(nodeReq)
+	tests.Assert(t, err == nil)
+
+	// Both paths point to the same disk
+	wwns := map[string]string{
+		"/dev/fake1": "0x5000c500a1b2c3d4",
+		"/dev/fake2": "0x5000c500a1b2c3d4",
+	}
+	app.xo.MockDeviceSetup = func(host, device, vgid string, destroy bool) (*executors.DeviceInfo, error) {
+		d := &executors.DeviceInfo{}
+		d.Size = 500 * 1024 * 1024
+		d.ExtentSize = 4096
+		d.Wwn = wwns[device]
+		d.Serial = "serial-" + wwns[device]
+		d.ById = "/dev/disk/by-id/wwn-" + wwns[device]
+		d.PvUuid = "pv-" + wwns[device]
+		return d, nil
+	}
+
+	deviceReq := &api.DeviceAddRequest{}
+	deviceReq.Name = "/dev/fake1"
+	deviceReq.NodeId = node.Id
+	err = c.DeviceAdd(deviceReq)
+	tests.Assert(t, err == nil, err)
+
+	node, err = c.NodeInfo(node.Id)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(node.DevicesInfo) == 1)
+	deviceId := node.DevicesInfo[0].Id
+
+	device, err := c.DeviceInfo(deviceId)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, device.Identity.Wwn == "0x5000c500a1b2c3d4", device.Identity)
+	tests.Assert(t, device.Identity.Serial == "serial-0x5000c500a1b2c3d4", device.Identity)
+	tests.Assert(t, device.Identity.ById == "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4", device.Identity)
+	tests.Assert(t, device.Identity.PvUuid == "pv-0x5000c500a1b2c3d4", device.Identity)
+
+	// The same disk can not be added through another path
+	deviceReq.Name = "/dev/fake2"
+	err = c.DeviceAdd(deviceReq)
+	tests.Assert(t, err != nil)
+	tests.Assert(t, strings.Contains(err.Error(), "already used"), err)
+
+	node, err = c.NodeInfo(node.Id)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(node.DevicesInfo) == 1)
+
+	// Nor through its by-id path
+	deviceReq.Name = "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4"
+	err = c.DeviceAdd(deviceReq)
+	tests.Assert(t, err != nil)
+
+	// Identifiers the executor does not report are not compared
+	// nor forgotten
+	setup := app.xo.MockDeviceSetup
+	app.xo.MockDeviceSetup = func(host, device, vgid string, destroy bool) (*executors.DeviceInfo, error) {
+		d, err := setup(host, device, vgid, destroy)
+		d.Serial = ""
+		d.PvUuid = ""
+		return d, err
+	}
+	err = c.DeviceResync(deviceId)
+	tests.Assert(t, err == nil, err)
+	device, err = c.DeviceInfo(deviceId)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, device.Identity.Serial == "serial-0x5000c500a1b2c3d4", device.Identity)
+	tests.Assert(t, device.Identity.PvUuid == "pv-0x5000c500a1b2c3d4", device.Identity)
+	app.xo.MockDeviceSetup = setup
+
+	// The path now refers to another disk
+	wwns["/dev/fake1"] = "0x5000c500ffffffff"
+	err = c.DeviceResync(deviceId)
+	tests.Assert(t, err != nil)
+	tests.Assert(t, strings.Contains(err.Error(), "different disk"), err)
+
+	err = c.DeviceState(deviceId, &api.StateRequest{State: api.EntryStateOffline})
+	tests.Assert(t, err == nil, err)
+	err = c.DeviceState(deviceId, &api.StateRequest{State: api.EntryStateFailed})
+	tests.Assert(t, err == nil, err)
+
+	teardowns := 0
+	app.xo.MockDeviceTeardown = func(host, device, vgid string) error {
+		teardowns++
+		return nil
+	}
+	err = c.DeviceDelete(deviceId)
+	tests.Assert(t, err != nil)
+	tests.Assert(t, strings.Contains(err.Error(), "different disk"), err)
+	tests.Assert(t, teardowns == 0, teardowns)
+
+	// A disk which can not be read is only torn down if forced
+	setup = app.xo.MockDeviceSetup
+	app.xo.MockDeviceSetup = func(host, device, vgid string, destroy bool) (*executors.DeviceInfo, error) {
+		return nil, errors.New("vgdisplay failed")
+	}
+	err = c.DeviceDelete(deviceId)
+	tests.Assert(t, err != nil)
+	tests.Assert(t, strings.Contains(err.Error(), "Unable to verify"), err)
+	tests.Assert(t, teardowns == 0, teardowns)
+	app.xo.MockDeviceSetup = setup
+
+	// Back to the original disk
+	wwns["/dev/fake1"] = "0x5000c500a1b2c3d4"
+	err = c.DeviceResync(deviceId)
+	tests.Assert(t, err == nil, err)
+	err = c.DeviceDelete(deviceId)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, teardowns == 1, teardowns)
+
+	// Once deleted the disk is free to be added again
+	deviceReq.Name = "/dev/fake2"
+	err = c.DeviceAdd(deviceReq)
+	tests.Assert(t, err == nil, err)
+
+	node, err = c.NodeInfo(node.Id)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(node.DevicesInfo) == 1)
+	deviceId = node.DevicesInfo[0].Id
+	err = c.DeviceState(deviceId, &api.StateRequest{State: api.EntryStateOffline})
+	tests.Assert(t, err == nil, err)
+	err = c.DeviceState(deviceId, &api.StateRequest{State: api.EntryStateFailed})
+	tests.Assert(t, err == nil, err)
+	app.xo.MockDeviceSetup = func(host, device, vgid string, destroy bool) (*executors.DeviceInfo, error) {
+		return nil, errors.New("vgdisplay failed")
+	}
+	err = c.DeviceDeleteForce(deviceId)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, teardowns == 2, teardowns)
+}
@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+const (
+	// Longest time in seconds a request waits for new events
+	maxEventWait = 300
+)
+
+// EventList returns the events recorded after the sequence number
+// given by the since query parameter. If there are no such events
+// the request waits up to wait seconds for new events.
+func (a *App) EventList(w http.ResponseWriter, r *http.Request) {
+	var (
+		since uint64
+		wait  uint64
+		err   error
+	)
+	query := r.URL.Query()
+	if v := query.Get("since"); v != "" {
+		since, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("wait"); v != "" {
+		wait, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid wait: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if wait > maxEventWait {
+			wait = maxEventWait
+		}
+	}
+
+	timer := time.NewTimer(time.Duration(wait) * time.Second)
+	defer timer.Stop()
+	expired := wait == 0
+	var events *api.EventListResponse
+	for {
+		// get the channel before reading so that events committed
+		// in the meantime are not missed
+		changed := eventsChanged.Wait()
+		err = a.db.View(func(tx *bolt.Tx) error {
+			var err error
+			events, err = EventsSince(tx, since)
+			return err
+		})
+		if err != nil {
+			apiLogger.Err(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if expired || events.Truncated || len(events.Events) > 0 {
+			break
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			expired = true
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		panic(err)
+	}
+}
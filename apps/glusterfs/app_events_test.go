@@ -0,0 +1,358 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/gorilla/mux"
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+func TestEvents(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	c := client.NewClientNoAuth(ts.URL)
+	tests.Assert(t, c != nil)
+
+	events, err := c.Events(0, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(events.Events) == 0)
+	tests.Assert(t, events.LastSeq == 0)
+	tests.Assert(t, !events.Truncated)
+
+	cluster, err := c.ClusterCreate(&api.ClusterCreateRequest{
+		ClusterFlags: api.ClusterFlags{
+			Block: true,
+			File:  true,
+		},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	events, err = c.Events(0, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(events.Events) == 1)
+	e := events.Events[0]
+	tests.Assert(t, e.Seq == 1)
+	tests.Assert(t, e.Type == api.EventCreate)
+	tests.Assert(t, e.Resource == "cluster")
+	tests.Assert(t, e.Id == cluster.Id)
+	tests.Assert(t, events.LastSeq == 1)
+
+	// wait for the node to be added
+	last := events.LastSeq
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		nodeReq := &api.NodeAddRequest{
+			Zone:      1,
+			ClusterId: cluster.Id,
+		}
+		nodeReq.Hostnames.Manage = sort.StringSlice{"manage.host"}
+		nodeReq.Hostnames.Storage = sort.StringSlice{"storage.host"}
+		_, err := c.NodeAdd(nodeReq)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	}()
+
+	var node api.Event
+	err = c.Watch(last, func(e api.Event) bool {
+		tests.Assert(t, e.Seq > last)
+		last = e.Seq
+		if e.Resource == "node" && e.Type == api.EventCreate {
+			node = e
+			return false
+		}
+		return true
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, node.Id != "")
+
+	// the node is added to the cluster
+	events, err = c.Events(0, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	found := false
+	for _, e := range events.Events {
+		if e.Resource == "cluster" && e.Type == api.EventUpdate {
+			found = true
+		}
+	}
+	tests.Assert(t, found, events.Events)
+
+	// waiting without new events returns nothing
+	events, err = c.Events(events.LastSeq, time.Second)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(events.Events) == 0)
+
+	r, err := http.Get(ts.URL + "/events?since=abc")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, r.StatusCode == http.StatusBadRequest)
+}
+
+func TestEventsOperations(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	c := client.NewClientNoAuth(ts.URL)
+	tests.Assert(t, c != nil)
+
+	events, err := c.Events(0, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	last := events.LastSeq
+
+	req := &api.VolumeCreateRequest{}
+	req.Size = 10
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+	volume, err := c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	events, err = c.Events(last, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	var states []string
+	bricks := 0
+	volumes := 0
+	for _, e := range events.Events {
+		switch e.Resource {
+		case "operation":
+			tests.Assert(t, e.Operation == "Create Volume", e)
+			tests.Assert(t, e.Id != "")
+			states = append(states, e.State)
+		case "brick":
+			if e.Type == api.EventCreate {
+				bricks++
+			}
+		case "volume":
+			tests.Assert(t, e.Id == volume.Id)
+			volumes++
+		}
+	}
+	tests.Assert(t, len(states) == 2, states)
+	tests.Assert(t, states[0] == "started")
+	tests.Assert(t, states[1] == "succeeded")
+	tests.Assert(t, bricks == 3, "expected bricks == 3, got:", bricks)
+	tests.Assert(t, volumes >= 2, "expected volumes >= 2, got:", volumes)
+}
+
+func TestEventsRunOperation(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	operationStates := func(since uint64) ([]string, uint64) {
+		var events *api.EventListResponse
+		err := app.db.View(func(tx *bolt.Tx) error {
+			var err error
+			events, err = EventsSince(tx, since)
+			return err
+		})
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		states := []string{}
+		for _, e := range events.Events {
+			if e.Resource == "operation" {
+				tests.Assert(t, e.Operation == "Create Volume", e)
+				states = append(states, e.State)
+			}
+		}
+		return states, events.LastSeq
+	}
+	_, last := operationStates(0)
+
+	// operations run outside of a request record the same events
+	v := createSampleReplicaVolumeEntry(10, 3)
+	err = v.Create(app.db, app.executor)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	states, last := operationStates(last)
+	tests.Assert(t, len(states) == 2, states)
+	tests.Assert(t, states[0] == "started")
+	tests.Assert(t, states[1] == "succeeded")
+
+	app.xo.MockVolumeCreate = func(host string,
+		volume *executors.VolumeRequest) (*executors.Volume, error) {
+		return nil, fmt.Errorf("volume create failed")
+	}
+	v = createSampleReplicaVolumeEntry(10, 3)
+	err = v.Create(app.db, app.executor)
+	tests.Assert(t, err != nil, "expected err != nil")
+	states, _ = operationStates(last)
+	tests.Assert(t, len(states) == 2, states)
+	tests.Assert(t, states[0] == "started")
+	tests.Assert(t, states[1] == "failed")
+}
+
+func TestEventsTruncated(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	defer tests.Patch(&EventBufferSize, uint64(5)).Restore()
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	c := client.NewClientNoAuth(ts.URL)
+	tests.Assert(t, c != nil)
+
+	for i := 0; i < 8; i++ {
+		_, err := c.ClusterCreate(&api.ClusterCreateRequest{
+			ClusterFlags: api.ClusterFlags{
+				Block: true,
+				File:  true,
+			},
+		})
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	}
+
+	events, err := c.Events(0, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, events.Truncated)
+	tests.Assert(t, events.LastSeq == 8)
+	tests.Assert(t, len(events.Events) == 5)
+	tests.Assert(t, events.Events[0].Seq == 4)
+
+	// the oldest event kept follows the requested one
+	events, err = c.Events(3, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, !events.Truncated)
+	tests.Assert(t, len(events.Events) == 5)
+
+	err = c.Watch(1, func(e api.Event) bool {
+		t.Fatalf("unexpected event: %v", e)
+		return false
+	})
+	tests.Assert(t, err == client.ErrEventsTruncated, err)
+
+	// unknown sequence numbers
+	events, err = c.Events(100, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, events.Truncated)
+}
+
+func TestEventsNoBucket(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	c := client.NewClientNoAuth(ts.URL)
+	tests.Assert(t, c != nil)
+
+	// a db from before events were recorded has no events bucket
+	err := app.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket([]byte(BOLTDB_BUCKET_EVENTS))
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	events, err := c.Events(0, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(events.Events) == 0)
+	tests.Assert(t, events.LastSeq == 0)
+	tests.Assert(t, !events.Truncated)
+
+	events, err = c.Events(3, 0)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, events.Truncated)
+}
+
+func TestEventsNodeState(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	var last uint64
+	err := app.db.View(func(tx *bolt.Tx) error {
+		events, err := EventsSince(tx, 0)
+		last = events.LastSeq
+		return err
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	app.nodeStateChanged("abc", api.EntryStateOnline,
+		api.EntryStateUnreachable)
+
+	var events *api.EventListResponse
+	err = app.db.View(func(tx *bolt.Tx) error {
+		var err error
+		events, err = EventsSince(tx, last)
+		return err
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(events.Events) == 1, events.Events)
+	e := events.Events[0]
+	tests.Assert(t, e.Type == api.EventUpdate, e)
+	tests.Assert(t, e.Resource == "node", e)
+	tests.Assert(t, e.Id == "abc", e)
+	tests.Assert(t, e.State == "unreachable", e)
+}
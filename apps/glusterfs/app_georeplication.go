@@ -0,0 +1,255 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/boltdb/bolt"
+	"github.com/gorilla/mux"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+func (a *App) GeoReplicationCreate(w http.ResponseWriter, r *http.Request) {
+	var msg api.GeoReplicationCreateRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	// Register the session so that the slave can not be used by
+	// another session while this one is created
+	var session *GeoReplicationEntry
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		master, err := NewVolumeEntryFromId(tx, msg.MasterVolumeId)
+		if err == ErrNotFound || (err == nil && !master.Visible()) {
+			http.Error(w, "Master volume id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		slave, err := NewVolumeEntryFromId(tx, msg.SlaveVolumeId)
+		if err == ErrNotFound || (err == nil && !slave.Visible()) {
+			http.Error(w, "Slave volume id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		err = checkGeoReplicationVolumes(master, slave)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			apiLogger.LogError("Unable to create geo-replication session: %v", err)
+			return err
+		}
+		for _, id := range []string{master.Info.Id, slave.Info.Id} {
+			err = checkNotMigrating(tx, id)
+			if err == ErrMigrating {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return err
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return err
+			}
+		}
+		err = checkGeoReplicationSlave(tx, slave.Info.Id)
+		if err == ErrConflict {
+			http.Error(w, "Slave volume is already the slave of a "+
+				"geo-replication session", http.StatusConflict)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		session = NewGeoReplicationEntryFromVolumes(master, slave)
+		err = session.Save(tx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	apiLogger.Info("Creating geo-replication session %v from volume %v to volume %v",
+		session.Info.Id, session.Info.MasterVolumeId, session.Info.SlaveVolumeId)
+	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
+		err := session.Create(a.db, a.requestExecutor(r), msg.Start)
+		if err != nil {
+			a.db.Update(func(tx *bolt.Tx) error {
+				return session.Delete(tx)
+			})
+			return "", err
+		}
+
+		apiLogger.Info("Created geo-replication session %v", session.Info.Id)
+		return "/georeplication/" + session.Info.Id, nil
+	})
+}
+
+func (a *App) GeoReplicationList(w http.ResponseWriter, r *http.Request) {
+
+	var list api.GeoReplicationSessionListResponse
+
+	err := a.db.View(func(tx *bolt.Tx) error {
+		var err error
+
+		list.Sessions, err = GeoReplicationList(tx)
+		return err
+	})
+	if err != nil {
+		apiLogger.Err(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		panic(err)
+	}
+}
+
+// loadGeoReplication writes the error to the response if the session
+// can not be read from the db.
+func (a *App) loadGeoReplication(w http.ResponseWriter,
+	id string) (*GeoReplicationEntry, error) {
+
+	var session *GeoReplicationEntry
+	err := a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		session, err = NewGeoReplicationEntryFromId(tx, id)
+		if err == ErrNotFound {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	return session, err
+}
+
+func (a *App) GeoReplicationInfo(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	session, err := a.loadGeoReplication(w, id)
+	if err != nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(session.Info); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) GeoReplicationStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	session, err := a.loadGeoReplication(w, id)
+	if err != nil {
+		return
+	}
+
+	status, err := session.Status(a.db, a.requestExecutor(r))
+	if err != nil {
+		apiLogger.LogError("Unable to get status of geo-replication session %v: %v",
+			id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) GeoReplicationAction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var msg api.GeoReplicationActionRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	session, err := a.loadGeoReplication(w, id)
+	if err != nil {
+		return
+	}
+	// force lets the state heketi recorded be overridden, e.g. for a
+	// session stopped from the gluster cli
+	if !msg.Force {
+		if err := session.CheckAction(msg.Action); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			apiLogger.LogError(err.Error())
+			return
+		}
+	}
+
+	apiLogger.Info("Applying %v to geo-replication session %v", msg.Action, id)
+	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
+		err := session.Action(a.db, a.requestExecutor(r), msg.Action, msg.Force)
+		if err != nil {
+			return "", err
+		}
+		return "/georeplication/" + session.Info.Id, nil
+	})
+}
+
+func (a *App) GeoReplicationDelete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	session, err := a.loadGeoReplication(w, id)
+	if err != nil {
+		return
+	}
+
+	apiLogger.Info("Deleting geo-replication session %v", id)
+	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
+		err := session.Destroy(a.db, a.requestExecutor(r))
+		if err != nil {
+			return "", err
+		}
+
+		apiLogger.Info("Deleted geo-replication session %v", id)
+		return "", nil
+	})
+}
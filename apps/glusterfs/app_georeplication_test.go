@@ -0,0 +1,174 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gorilla/mux"
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+func TestGeoReplication(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		2,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	secretHosts := map[string]string{}
+	app.xo.MockGeoReplicationSecret = func(host string) (string, error) {
+		secretHosts["master"] = host
+		return "ssh-rsa AAAA root@" + host, nil
+	}
+	app.xo.MockGeoReplicationAddSecret = func(host string,
+		gr *executors.GeoReplicationRequest, secret string) error {
+
+		secretHosts["slave"] = host
+		secretHosts["session"] = gr.MasterVolume + "_" + gr.SlaveVolume
+		tests.Assert(t, secret == "ssh-rsa AAAA root@"+secretHosts["master"],
+			"unexpected secret:", secret)
+		return nil
+	}
+	var actions []string
+	app.xo.MockGeoReplicationAction = func(host string,
+		gr *executors.GeoReplicationRequest, action string) error {
+		actions = append(actions, action)
+		return nil
+	}
+	createErr := errors.New("slave volume is not empty")
+	app.xo.MockGeoReplicationCreate = func(host string,
+		gr *executors.GeoReplicationRequest) error {
+		return createErr
+	}
+
+	c := client.NewClientNoAuth(ts.URL)
+	clusters, err := c.ClusterList()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	vols := []*api.VolumeInfoResponse{}
+	for _, cluster := range clusters.Clusters {
+		req := &api.VolumeCreateRequest{}
+		req.Size = 100
+		req.Clusters = []string{cluster}
+		req.Durability.Type = api.DurabilityReplicate
+		req.Durability.Replicate.Replica = 3
+		vol, err := c.VolumeCreate(req)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		vols = append(vols, vol)
+	}
+	master, slave := vols[0], vols[1]
+
+	// a volume can not be replicated to itself
+	_, err = c.GeoReplicationCreate(&api.GeoReplicationCreateRequest{
+		MasterVolumeId: master.Id,
+		SlaveVolumeId:  master.Id,
+	})
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// failed sessions are not kept
+	req := &api.GeoReplicationCreateRequest{
+		MasterVolumeId: master.Id,
+		SlaveVolumeId:  slave.Id,
+		Start:          true,
+	}
+	_, err = c.GeoReplicationCreate(req)
+	tests.Assert(t, err != nil, "expected err != nil")
+	list, err := c.GeoReplicationList()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(list.Sessions) == 0, "expected no sessions, got:", list)
+
+	createErr = nil
+	session, err := c.GeoReplicationCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, session.State == api.GeoReplicationStarted,
+		"expected state started, got:", session.State)
+	tests.Assert(t, session.MasterVolume == master.Name)
+	tests.Assert(t, session.SlaveVolume == slave.Name)
+	tests.Assert(t, session.MasterCluster == master.Cluster)
+	tests.Assert(t, session.SlaveCluster == slave.Cluster)
+	tests.Assert(t, session.SlaveHost == slave.Mount.GlusterFS.Hosts[0])
+	tests.Assert(t, secretHosts["master"] != secretHosts["slave"],
+		"expected keys to be added on the slave cluster, got:", secretHosts)
+	tests.Assert(t, secretHosts["session"] == master.Name+"_"+slave.Name,
+		"expected keys of the session to be added, got:", secretHosts)
+	tests.Assert(t, len(actions) == 1 && actions[0] == "start",
+		"expected session to be started, got:", actions)
+
+	// the slave can only have one master
+	_, err = c.GeoReplicationCreate(req)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// the volumes can not be deleted while they are replicated
+	err = c.VolumeDelete(master.Id)
+	tests.Assert(t, err != nil, "expected err != nil")
+	err = c.VolumeDelete(slave.Id)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// actions follow the state of the session
+	_, err = c.GeoReplicationAction(session.Id,
+		&api.GeoReplicationActionRequest{Action: api.GeoReplicationResume})
+	tests.Assert(t, err != nil, "expected err != nil")
+	info, err := c.GeoReplicationAction(session.Id,
+		&api.GeoReplicationActionRequest{Action: api.GeoReplicationPause})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.State == api.GeoReplicationPaused,
+		"expected state paused, got:", info.State)
+	info, err = c.GeoReplicationAction(session.Id,
+		&api.GeoReplicationActionRequest{Action: api.GeoReplicationStop})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.State == api.GeoReplicationStopped,
+		"expected state stopped, got:", info.State)
+	_, err = c.GeoReplicationAction(session.Id,
+		&api.GeoReplicationActionRequest{Action: api.GeoReplicationStop})
+	tests.Assert(t, err != nil, "expected err != nil")
+	info, err = c.GeoReplicationAction(session.Id,
+		&api.GeoReplicationActionRequest{
+			Action: api.GeoReplicationStop,
+			Force:  true,
+		})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(actions) == 4, "expected 4 actions, got:", actions)
+
+	status, err := c.GeoReplicationStatus(session.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, status.Id == session.Id)
+	tests.Assert(t, len(status.Workers) == 1,
+		"expected 1 worker, got:", status.Workers)
+	tests.Assert(t, status.Workers[0].Status == "Active")
+
+	err = c.GeoReplicationDelete(session.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	_, err = c.GeoReplicationInfo(session.Id)
+	tests.Assert(t, err != nil, "expected err != nil")
+	err = c.VolumeDelete(master.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+}
@@ -0,0 +1,213 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/boltdb/bolt"
+	"github.com/gorilla/mux"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// nodeHealth returns the health of the node as last seen by the
+// node health monitor of the app.
+func (a *App) nodeHealth(nodeId string) api.NodeHealth {
+	if a.nhealth == nil {
+		return api.NodeHealth{State: api.NodeHealthUnknown}
+	}
+	s, ok := a.nhealth.NodeStatus(nodeId)
+	if !ok {
+		return api.NodeHealth{State: api.NodeHealthUnknown}
+	}
+	h := api.NodeHealth{
+		State:      api.NodeHealthDown,
+		LastUpdate: s.LastUpdate.Unix(),
+	}
+	if s.Up {
+		h.State = api.NodeHealthUp
+	}
+	return h
+}
+
+// nodeStateChanged is called by the node health monitor when it
+// changes the state of a node.
+func (a *App) nodeStateChanged(nodeId string,
+	from, to api.EntryState) {
+
+	apiLogger.Warning("Node %v changed state from %v to %v by health monitor",
+		nodeId, from, to)
+	recordStateEvent(a.db, "node", nodeId, to)
+}
+
+func (a *App) nodeHealthResponse(node *NodeEntry) api.NodeHealthResponse {
+	return api.NodeHealthResponse{
+		NodeHealth: a.nodeHealth(node.Info.Id),
+		NodeId:     node.Info.Id,
+		ClusterId:  node.Info.ClusterId,
+		Hostname:   node.ManageHostName(),
+	}
+}
+
+func (a *App) NodeHealth(w http.ResponseWriter, r *http.Request) {
+
+	// Get node id from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var info api.NodeHealthResponse
+	err := a.db.View(func(tx *bolt.Tx) error {
+		node, err := NewNodeEntryFromId(tx, id)
+		if err == ErrNotFound {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		info = a.nodeHealthResponse(node)
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	// Write msg
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) DeviceHealth(w http.ResponseWriter, r *http.Request) {
+
+	// Get device id from URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if a.dhealth == nil {
+		http.Error(w, "Device health monitor is not enabled",
+			http.StatusServiceUnavailable)
+		return
+	}
+
+	var info api.DeviceHealthResponse
+	err := a.db.View(func(tx *bolt.Tx) error {
+		device, err := NewDeviceEntryFromId(tx, id)
+		if err == ErrNotFound {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		info = a.deviceHealthResponse(device)
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	// Write msg
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) deviceHealthResponse(device *DeviceEntry) api.DeviceHealthResponse {
+	return api.DeviceHealthResponse{
+		DeviceHealth: a.dhealth.Health(device.Info.Id),
+		DeviceId:     device.Info.Id,
+		NodeId:       device.NodeId,
+		Name:         device.Info.Name,
+	}
+}
+
+func (a *App) Health(w http.ResponseWriter, r *http.Request) {
+
+	info := api.HealthResponse{
+		MonitorEnabled:       a.nhealth != nil,
+		Nodes:                []api.NodeHealthResponse{},
+		DeviceMonitorEnabled: a.dhealth != nil,
+	}
+	err := a.db.View(func(tx *bolt.Tx) error {
+		clusters, err := ClusterList(tx)
+		if err != nil {
+			return err
+		}
+		for _, clusterId := range clusters {
+			cluster, err := NewClusterEntryFromId(tx, clusterId)
+			if err != nil {
+				return err
+			}
+			for _, nodeId := range cluster.Info.Nodes {
+				node, err := NewNodeEntryFromId(tx, nodeId)
+				if err != nil {
+					return err
+				}
+				info.Nodes = append(info.Nodes, a.nodeHealthResponse(node))
+				if a.dhealth == nil {
+					continue
+				}
+				for _, deviceId := range node.Devices {
+					device, err := NewDeviceEntryFromId(tx, deviceId)
+					if err != nil {
+						return err
+					}
+					info.Devices = append(info.Devices,
+						a.deviceHealthResponse(device))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Write msg
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) HealthRefresh(w http.ResponseWriter, r *http.Request) {
+
+	if a.nhealth == nil && a.dhealth == nil {
+		http.Error(w, "No health monitor is enabled",
+			http.StatusServiceUnavailable)
+		return
+	}
+
+	apiLogger.Info("Refreshing health status on request")
+
+	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
+		if a.nhealth != nil {
+			err := a.nhealth.Refresh()
+			if err != nil {
+				return "", err
+			}
+		}
+		if a.dhealth != nil {
+			err := a.dhealth.Refresh()
+			if err != nil {
+				return "", err
+			}
+		}
+		return "/health", nil
+	})
+}
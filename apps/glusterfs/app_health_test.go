@@ -0,0 +1,211 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+func TestHealthMonitorDisabled(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		2,    // clusters
+		2,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	c := client.NewClientNoAuth(ts.URL)
+	health, err := c.Health()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, !health.MonitorEnabled, "expected monitor disabled")
+	tests.Assert(t, len(health.Nodes) == 4,
+		"expected len(health.Nodes) == 4, got:", len(health.Nodes))
+	for _, h := range health.Nodes {
+		tests.Assert(t, h.State == api.NodeHealthUnknown,
+			"expected h.State == api.NodeHealthUnknown, got:", h.State)
+		tests.Assert(t, h.ClusterId != "" && h.Hostname != "",
+			"expected cluster and hostname to be set, got:", h)
+	}
+
+	_, err = c.HealthRefresh()
+	tests.Assert(t, err != nil, "expected err != nil")
+	tests.Assert(t, strings.Contains(err.Error(), "No health monitor"),
+		"expected monitor error, got:", err)
+
+	tests.Assert(t, !health.DeviceMonitorEnabled, "expected device monitor disabled")
+	tests.Assert(t, len(health.Devices) == 0,
+		"expected len(health.Devices) == 0, got:", len(health.Devices))
+	node, err := c.NodeInfo(health.Nodes[0].NodeId)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	deviceId := node.DevicesInfo[0].Id
+	device, err := c.DeviceInfo(deviceId)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, device.Health == nil, "expected device.Health == nil")
+	_, err = c.DeviceHealth(deviceId)
+	tests.Assert(t, err != nil, "expected err != nil")
+}
+
+func TestHealthMonitor(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	// the monitor is started so that closing the app can stop it
+	// but it never runs on its own during the test
+	app.nhealth = NewNodeHealthCache(3600, 3600, app.db, app.executor)
+	app.nhealth.Monitor()
+
+	c := client.NewClientNoAuth(ts.URL)
+	health, err := c.Health()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, health.MonitorEnabled, "expected monitor enabled")
+	tests.Assert(t, len(health.Nodes) == 3,
+		"expected len(health.Nodes) == 3, got:", len(health.Nodes))
+	for _, h := range health.Nodes {
+		tests.Assert(t, h.State == api.NodeHealthUnknown,
+			"expected h.State == api.NodeHealthUnknown, got:", h.State)
+		tests.Assert(t, h.LastUpdate == 0,
+			"expected h.LastUpdate == 0, got:", h.LastUpdate)
+	}
+
+	downNode := health.Nodes[0].NodeId
+	downHost := health.Nodes[0].Hostname
+	app.xo.MockGlusterdCheck = func(host string) error {
+		if host == downHost {
+			return fmt.Errorf("glusterd not running")
+		}
+		return nil
+	}
+
+	health, err = c.HealthRefresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(health.Nodes) == 3,
+		"expected len(health.Nodes) == 3, got:", len(health.Nodes))
+	for _, h := range health.Nodes {
+		tests.Assert(t, h.LastUpdate != 0, "expected h.LastUpdate != 0")
+		if h.NodeId == downNode {
+			tests.Assert(t, h.State == api.NodeHealthDown,
+				"expected h.State == api.NodeHealthDown, got:", h.State)
+		} else {
+			tests.Assert(t, h.State == api.NodeHealthUp,
+				"expected h.State == api.NodeHealthUp, got:", h.State)
+		}
+	}
+
+	nh, err := c.NodeHealth(downNode)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, nh.State == api.NodeHealthDown,
+		"expected nh.State == api.NodeHealthDown, got:", nh.State)
+
+	info, err := c.NodeInfo(downNode)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.Health.State == api.NodeHealthDown,
+		"expected info.Health.State == api.NodeHealthDown, got:", info.Health.State)
+
+	_, err = c.NodeHealth("0123456789abcdef0123456789abcdef")
+	tests.Assert(t, err != nil, "expected err != nil")
+}
+
+func TestDeviceHealthMonitor(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		2,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	// the monitor is started so that closing the app can stop it
+	// but it never runs on its own during the test
+	app.dhealth = NewDeviceHealthCache(3600, 3600, app.db, app.executor)
+	app.dhealth.Monitor()
+
+	c := client.NewClientNoAuth(ts.URL)
+	health, err := c.HealthRefresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, !health.MonitorEnabled, "expected node monitor disabled")
+	tests.Assert(t, health.DeviceMonitorEnabled, "expected device monitor enabled")
+	tests.Assert(t, len(health.Devices) == 4,
+		"expected len(health.Devices) == 4, got:", len(health.Devices))
+	for _, h := range health.Devices {
+		tests.Assert(t, h.State == api.DeviceHealthOk,
+			"expected h.State == api.DeviceHealthOk, got:", h.State)
+	}
+
+	deviceId := health.Devices[0].DeviceId
+	dh, err := c.DeviceHealth(deviceId)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, dh.State == api.DeviceHealthOk,
+		"expected dh.State == api.DeviceHealthOk, got:", dh.State)
+	tests.Assert(t, dh.NodeId == health.Devices[0].NodeId,
+		"expected node ids to match, got:", dh.NodeId)
+
+	device, err := c.DeviceInfo(deviceId)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, device.Health != nil, "expected device.Health != nil")
+	tests.Assert(t, device.Health.State == api.DeviceHealthOk,
+		"expected device.Health.State == api.DeviceHealthOk, got:", device.Health.State)
+
+	_, err = c.DeviceHealth("0123456789abcdef0123456789abcdef")
+	tests.Assert(t, err != nil, "expected err != nil")
+}
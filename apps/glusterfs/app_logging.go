@@ -13,33 +13,80 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/executors"
 	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 )
 
-func (a *App) logLevelName() string {
-	switch logger.Level() {
-	case utils.LEVEL_NOLOG:
-		return "none"
-	case utils.LEVEL_CRITICAL:
-		return "critical"
-	case utils.LEVEL_ERROR:
-		return "error"
-	case utils.LEVEL_WARNING:
-		return "warning"
-	case utils.LEVEL_INFO:
-		return "info"
-	case utils.LEVEL_DEBUG:
-		return "debug"
-	default:
-		return "(unknown)"
+var (
+	// filter of the log messages set through the api, like the
+	// levels of the loggers it applies to the whole process
+	logFilterLock    sync.Mutex
+	currentLogFilter = api.LogFilter{Volumes: []string{}, Nodes: []string{}}
+)
+
+// loggers returns the loggers whose level can be set through the api
+// keyed by name. The parts of the app log at the level of the
+// "glusterfs" logger until their own level is set.
+func (a *App) loggers() map[string]*utils.Logger {
+	loggers := map[string]*utils.Logger{
+		"glusterfs":  logger,
+		"api":        apiLogger,
+		"operations": opLogger,
+		"allocator":  allocLogger,
+		"health":     healthLogger,
+		"db":         dbLogger,
+	}
+	if le, ok := a.executor.(executors.LoggingExecutor); ok {
+		for name, l := range le.Loggers() {
+			loggers[name] = l
+		}
 	}
+	return loggers
+}
+
+func (a *App) logLevelName() string {
+	return logger.Level().String()
+}
+
+// logFilterMatches returns the strings the log messages about the
+// volumes and nodes of the filter contain: the ids of the volumes,
+// and the ids and host names of the nodes.
+func (a *App) logFilterMatches(filter *api.LogFilter) ([]string, error) {
+	matches := []string{}
+	err := a.db.View(func(tx *bolt.Tx) error {
+		for _, id := range filter.Volumes {
+			if _, err := NewVolumeEntryFromId(tx, id); err != nil {
+				return err
+			}
+			matches = append(matches, id)
+		}
+		for _, id := range filter.Nodes {
+			node, err := NewNodeEntryFromId(tx, id)
+			if err != nil {
+				return err
+			}
+			matches = append(matches, id)
+			matches = append(matches, node.Info.Hostnames.Manage...)
+			matches = append(matches, node.Info.Hostnames.Storage...)
+		}
+		return nil
+	})
+	return matches, err
 }
 
 func (a *App) GetLogLevel(w http.ResponseWriter, r *http.Request) {
 	info := api.LogLevelInfo{LogLevel: map[string]string{}}
-	info.LogLevel["glusterfs"] = a.logLevelName()
+	for name, l := range a.loggers() {
+		info.LogLevel[name] = l.Level().String()
+	}
+	logFilterLock.Lock()
+	filter := currentLogFilter
+	logFilterLock.Unlock()
+	info.Filter = &filter
 
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
@@ -57,20 +104,60 @@ func (a *App) SetLogLevel(w http.ResponseWriter, r *http.Request) {
 			http.StatusBadRequest)
 		return
 	}
-	wantLevel, ok := msg.LogLevel["glusterfs"]
-	if !ok {
-		err := fmt.Errorf("Only \"glusterfs\" logger may be modified")
-		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	if len(msg.LogLevel) == 0 && msg.Filter == nil {
+		http.Error(w, "No log level or filter given",
+			http.StatusUnprocessableEntity)
 		return
 	}
 
-	err = SetLogLevel(wantLevel)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
-		return
+	// Check the whole request before changing anything
+	loggers := a.loggers()
+	levels := map[string]utils.LogLevel{}
+	for name, levelName := range msg.LogLevel {
+		if _, ok := loggers[name]; !ok {
+			err := fmt.Errorf("Unknown logger: %v", name)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		// treat empty string as a no-op like the configuration
+		if levelName == "" {
+			continue
+		}
+		level, err := utils.ParseLogLevel(levelName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		levels[name] = level
+	}
+	var matches []string
+	if msg.Filter != nil {
+		matches, err = a.logFilterMatches(msg.Filter)
+		if err == ErrNotFound {
+			http.Error(w, "Volume or node of the filter not found",
+				http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	for name, level := range levels {
+		loggers[name].SetLevel(level)
+	}
+	if msg.Filter != nil {
+		filter := api.LogFilter{
+			Volumes: append([]string{}, msg.Filter.Volumes...),
+			Nodes:   append([]string{}, msg.Filter.Nodes...),
+		}
+		logFilterLock.Lock()
+		currentLogFilter = filter
+		utils.SetLogFilter(matches)
+		logFilterLock.Unlock()
 	}
-	logger.Info("set new log level [%s]", msg.LogLevel)
-	logger.Debug("debug logging enabled")
+	apiLogger.Info("set new log level [%s]", msg.LogLevel)
+	apiLogger.Debug("debug logging enabled")
 
 	a.GetLogLevel(w, r)
 	return
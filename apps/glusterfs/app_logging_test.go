@@ -17,8 +17,10 @@ import (
 	"os"
 	"testing"
 
+	"github.com/boltdb/bolt"
 	"github.com/gorilla/mux"
 
+	client "github.com/heketi/heketi/client/api/go-client"
 	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 	"github.com/heketi/tests"
@@ -263,3 +265,137 @@ func TestLogLevelNameUnexpected(t *testing.T) {
 	tests.Assert(t, v == "(unknown)",
 		`expected v == "(unknown)", got`, v)
 }
+
+func TestSetLogLevelComponents(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// remember orig logging state
+	orig := logger.Level()
+	defer logger.SetLevel(orig)
+	for _, l := range []*utils.Logger{apiLogger, opLogger, allocLogger,
+		healthLogger, dbLogger} {
+		defer l.ResetLevel()
+		l.ResetLevel()
+	}
+	logger.SetLevel(utils.LEVEL_INFO)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	c := client.NewClientNoAuth(ts.URL)
+	info, err := c.LogLevelGet()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	for _, name := range []string{"glusterfs", "api", "operations",
+		"allocator", "health", "db"} {
+		tests.Assert(t, info.LogLevel[name] == "info", name, info.LogLevel)
+	}
+
+	err = c.LogLevelSet(&api.LogLevelInfo{
+		LogLevel: map[string]string{"operations": "debug", "db": "error"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, opLogger.Level() == utils.LEVEL_DEBUG)
+	tests.Assert(t, dbLogger.Level() == utils.LEVEL_ERROR)
+	tests.Assert(t, logger.Level() == utils.LEVEL_INFO)
+
+	// the other parts follow the level of glusterfs
+	err = c.LogLevelSet(&api.LogLevelInfo{
+		LogLevel: map[string]string{"glusterfs": "warning"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	info, err = c.LogLevelGet()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.LogLevel["api"] == "warning", info.LogLevel)
+	tests.Assert(t, info.LogLevel["operations"] == "debug", info.LogLevel)
+	tests.Assert(t, info.LogLevel["db"] == "error", info.LogLevel)
+
+	// nothing is changed if a logger is unknown
+	err = c.LogLevelSet(&api.LogLevelInfo{
+		LogLevel: map[string]string{"api": "debug", "bogus": "debug"},
+	})
+	tests.Assert(t, err != nil)
+	tests.Assert(t, apiLogger.Level() == utils.LEVEL_WARNING)
+
+	err = c.LogLevelSet(&api.LogLevelInfo{})
+	tests.Assert(t, err != nil)
+}
+
+func TestSetLogFilter(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+	defer utils.SetLogFilter(nil)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		2,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	var node *NodeEntry
+	err = app.db.View(func(tx *bolt.Tx) error {
+		nodes, err := NodeList(tx)
+		if err != nil {
+			return err
+		}
+		node, err = NewNodeEntryFromId(tx, nodes[0])
+		return err
+	})
+	tests.Assert(t, err == nil)
+
+	c := client.NewClientNoAuth(ts.URL)
+	err = c.LogLevelSet(&api.LogLevelInfo{
+		Filter: &api.LogFilter{Nodes: []string{node.Info.Id}},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	// the messages about the node are found by id or host name
+	matches := utils.GetLogFilter()
+	found := map[string]bool{}
+	for _, m := range matches {
+		found[m] = true
+	}
+	tests.Assert(t, found[node.Info.Id], matches)
+	tests.Assert(t, found[node.ManageHostName()], matches)
+	tests.Assert(t, found[node.StorageHostName()], matches)
+
+	info, err := c.LogLevelGet()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(info.Filter.Nodes) == 1, info.Filter)
+	tests.Assert(t, info.Filter.Nodes[0] == node.Info.Id)
+	tests.Assert(t, len(info.Filter.Volumes) == 0, info.Filter)
+
+	// unknown volumes are rejected
+	err = c.LogLevelSet(&api.LogLevelInfo{
+		Filter: &api.LogFilter{Volumes: []string{"abc"}},
+	})
+	tests.Assert(t, err != nil)
+	tests.Assert(t, len(utils.GetLogFilter()) == len(matches))
+
+	// an empty filter removes it
+	err = c.LogLevelSet(&api.LogLevelInfo{Filter: &api.LogFilter{}})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(utils.GetLogFilter()) == 0)
+	info, err = c.LogLevelGet()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(info.Filter.Nodes) == 0, info.Filter)
+}
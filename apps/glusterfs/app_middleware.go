@@ -34,12 +34,23 @@ func (a *App) Auth(w http.ResponseWriter, r *http.Request, next http.HandlerFunc
 	token := data.(*jwt.Token)
 	claims := token.Claims.(jwt.MapClaims)
 
-	// Check access
-	if "user" == claims["iss"] && r.URL.Path != "/volumes" {
+	// Check access, users may also read the usage of their tenant
+	if "user" == claims["iss"] && r.URL.Path != "/volumes" &&
+		!(r.Method == http.MethodGet &&
+			r.URL.Path == "/tenants/"+requestTenant(r)) {
 		http.Error(w, "Administrator access required", http.StatusUnauthorized)
 		return
 	}
 
+	// Users may list the ids of the volumes, but not the details of
+	// volumes which may belong to other tenants
+	if "user" == claims["iss"] && r.Method == http.MethodGet &&
+		r.URL.Path == "/volumes" && r.URL.Query().Get("expand") != "" {
+		http.Error(w, "Administrator access required to expand the volume list",
+			http.StatusUnauthorized)
+		return
+	}
+
 	// Everything is clean
 	next(w, r)
 }
@@ -66,9 +77,9 @@ func (a *App) BackupToKubernetesSecret(
 	// Backup database
 	err := kubeBackupDbToSecret(a.db)
 	if err != nil {
-		logger.Err(err)
+		apiLogger.Err(err)
 	} else {
-		logger.Info("Backup successful")
+		apiLogger.Info("Backup successful")
 	}
 }
 
@@ -19,6 +19,8 @@ import (
 	"testing"
 
 	//"github.com/boltdb/bolt"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/context"
 	wdb "github.com/heketi/heketi/pkg/db"
 	"github.com/heketi/tests"
 )
@@ -190,3 +192,32 @@ func TestBackupToKubeSecretBackupOnGet(t *testing.T) {
 	})
 	tests.Assert(t, incluster_count == 2)
 }
+
+func TestAuthUserVolumeList(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	auth := func(iss, url string) int {
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		context.Set(r, "jwt", &jwt.Token{
+			Claims: jwt.MapClaims{"iss": iss},
+		})
+		defer context.Clear(r)
+		w := httptest.NewRecorder()
+		app.Auth(w, r, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		return w.Code
+	}
+
+	// users only get the ids of the volumes
+	code := auth("user", "/volumes")
+	tests.Assert(t, code == http.StatusOK, "expected 200, got:", code)
+	code = auth("user", "/volumes?expand=true")
+	tests.Assert(t, code == http.StatusUnauthorized, "expected 401, got:", code)
+	code = auth("admin", "/volumes?expand=true")
+	tests.Assert(t, code == http.StatusOK, "expected 200, got:", code)
+}
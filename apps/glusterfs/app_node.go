@@ -31,7 +31,7 @@ func (a *App) NodeAdd(w http.ResponseWriter, r *http.Request) {
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
@@ -93,25 +93,25 @@ func (a *App) NodeAdd(w http.ResponseWriter, r *http.Request) {
 	// Get a node's hostname in the cluster to execute the Gluster peer command
 	// only if there is more than one node
 	if len(cluster.Info.Nodes) > 0 {
-		peer_node_hostname, err = GetVerifiedManageHostname(a.db, a.executor, cluster.Info.Id)
+		peer_node_hostname, err = GetVerifiedManageHostname(a.db, a.requestExecutor(r), cluster.Info.Id)
 		if err != nil {
-			logger.Err(err)
-			err := logger.LogError("None of the nodes in cluster has glusterd running")
+			apiLogger.Err(err)
+			err := apiLogger.LogError("None of the nodes in cluster has glusterd running")
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 	} else {
-		err := a.executor.GlusterdCheck(node.ManageHostName())
+		err := a.requestExecutor(r).GlusterdCheck(node.ManageHostName())
 		if err != nil {
-			logger.Err(err)
-			err := logger.LogError("New Node doesn't have glusterd running")
+			apiLogger.Err(err)
+			err := apiLogger.LogError("New Node doesn't have glusterd running")
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 	}
 
 	// Add node
-	logger.Info("Adding node %v", node.ManageHostName())
+	apiLogger.Info("Adding node %v", node.ManageHostName())
 	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (seeother string, e error) {
 
 		// Cleanup in case of failure
@@ -128,7 +128,7 @@ func (a *App) NodeAdd(w http.ResponseWriter, r *http.Request) {
 		// TODO: What happens if the peer_node is not responding.. we need to choose another.
 		// It will only choose the working one now. Hence done.
 		if peer_node_hostname != "" {
-			err := a.executor.PeerProbe(peer_node_hostname, node.StorageHostName())
+			err := a.requestExecutor(r).PeerProbe(peer_node_hostname, node.StorageHostName())
 			if err != nil {
 				return "", err
 			}
@@ -168,7 +168,7 @@ func (a *App) NodeAdd(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			return "", err
 		}
-		logger.Info("Added node " + node.Info.Id)
+		apiLogger.Info("Added node " + node.Info.Id)
 		return "/nodes/" + node.Info.Id, nil
 	})
 }
@@ -196,6 +196,7 @@ func (a *App) NodeInfo(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return err
 		}
+		info.Health = a.nodeHealth(id)
 
 		return nil
 	})
@@ -232,13 +233,13 @@ func (a *App) NodeDelete(w http.ResponseWriter, r *http.Request) {
 			return err
 		} else if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return logger.Err(err)
+			return apiLogger.Err(err)
 		}
 
 		// Check the node can be deleted
 		if !node.IsDeleteOk() {
 			http.Error(w, node.ConflictString(), http.StatusConflict)
-			logger.LogError(node.ConflictString())
+			apiLogger.LogError(node.ConflictString())
 			return ErrConflict
 		}
 
@@ -249,7 +250,7 @@ func (a *App) NodeDelete(w http.ResponseWriter, r *http.Request) {
 			return err
 		} else if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return logger.Err(err)
+			return apiLogger.Err(err)
 		}
 
 		// Get a node in the cluster to execute the Gluster peer command
@@ -259,7 +260,7 @@ func (a *App) NodeDelete(w http.ResponseWriter, r *http.Request) {
 			for index := range cluster.Info.Nodes {
 				peer_node, err = cluster.NodeEntryFromClusterIndex(tx, index)
 				if err != nil {
-					return logger.Err(err)
+					return apiLogger.Err(err)
 				}
 
 				// Cannot peer detach from the same node, we need to execute
@@ -276,12 +277,12 @@ func (a *App) NodeDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete node asynchronously
-	logger.Info("Deleting node %v [%v]", node.ManageHostName(), node.Info.Id)
+	apiLogger.Info("Deleting node %v [%v]", node.ManageHostName(), node.Info.Id)
 	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
 
 		// Remove from trusted pool
 		if peer_node != nil {
-			err := a.executor.PeerDetach(peer_node.ManageHostName(), node.StorageHostName())
+			err := a.requestExecutor(r).PeerDetach(peer_node.ManageHostName(), node.StorageHostName())
 			if err != nil {
 				return "", err
 			}
@@ -293,12 +294,12 @@ func (a *App) NodeDelete(w http.ResponseWriter, r *http.Request) {
 			// Get Cluster
 			cluster, err := NewClusterEntryFromId(tx, node.Info.ClusterId)
 			if err == ErrNotFound {
-				logger.Critical("Cluster id %v is expected be in db. Pointed to by node %v",
+				apiLogger.Critical("Cluster id %v is expected be in db. Pointed to by node %v",
 					node.Info.ClusterId,
 					node.Info.Id)
 				return err
 			} else if err != nil {
-				logger.Err(err)
+				apiLogger.Err(err)
 				return err
 			}
 			cluster.NodeDelete(node.Info.Id)
@@ -306,7 +307,7 @@ func (a *App) NodeDelete(w http.ResponseWriter, r *http.Request) {
 			// Save cluster
 			err = cluster.Save(tx)
 			if err != nil {
-				logger.Err(err)
+				apiLogger.Err(err)
 				return err
 			}
 
@@ -316,7 +317,7 @@ func (a *App) NodeDelete(w http.ResponseWriter, r *http.Request) {
 			// Delete node from db
 			err = node.Delete(tx)
 			if err != nil {
-				logger.Err(err)
+				apiLogger.Err(err)
 				return err
 			}
 
@@ -327,7 +328,7 @@ func (a *App) NodeDelete(w http.ResponseWriter, r *http.Request) {
 			return "", err
 		}
 		// Show that the key has been deleted
-		logger.Info("Deleted node [%s]", id)
+		apiLogger.Info("Deleted node [%s]", id)
 
 		return "", nil
 
@@ -350,7 +351,7 @@ func (a *App) NodeSetState(w http.ResponseWriter, r *http.Request) {
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
@@ -373,7 +374,7 @@ func (a *App) NodeSetState(w http.ResponseWriter, r *http.Request) {
 
 	// Set state
 	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
-		err = node.SetState(a.db, a.executor, msg.State)
+		err = node.SetState(a.db, a.requestExecutor(r), msg.State)
 		if err != nil {
 			return "", err
 		}
@@ -400,7 +401,7 @@ func (a *App) NodeSetTags(w http.ResponseWriter, r *http.Request) {
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
@@ -421,7 +422,7 @@ func (a *App) NodeSetTags(w http.ResponseWriter, r *http.Request) {
 		return nil
 	})
 	if err != nil {
-		logger.Err(err)
+		apiLogger.Err(err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
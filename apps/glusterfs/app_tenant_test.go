@@ -0,0 +1,178 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/middleware"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+func TestTenantLimits(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server, the requests are made for the tenant as if
+	// the JWT middleware had set it
+	tenant := "team-a"
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			router.ServeHTTP(w, middleware.WithTenant(r, tenant))
+		}))
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		2,    // clusters
+		3,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	c := client.NewClientNoAuth(ts.URL)
+	clusters, err := c.ClusterList()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	allowed := clusters.Clusters[0]
+	other := clusters.Clusters[1]
+
+	app.conf.Tenants = []TenantConfig{
+		TenantConfig{
+			Id: "team-a",
+			TenantLimits: api.TenantLimits{
+				MaxSize:    150,
+				MaxVolumes: 2,
+				Clusters:   []string{allowed},
+			},
+		},
+	}
+
+	newRequest := func(size int) *api.VolumeCreateRequest {
+		req := &api.VolumeCreateRequest{}
+		req.Size = size
+		req.Durability.Type = api.DurabilityReplicate
+		req.Durability.Replicate.Replica = 3
+		return req
+	}
+
+	// volumes are placed on the clusters of the tenant
+	vol, err := c.VolumeCreate(newRequest(100))
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, vol.Tenant == "team-a", "expected tenant team-a, got:",
+		vol.Tenant)
+	tests.Assert(t, vol.Cluster == allowed, "expected cluster", allowed,
+		"got:", vol.Cluster)
+
+	req := newRequest(10)
+	req.Clusters = []string{other}
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err != nil, "expected err != nil")
+	tests.Assert(t, strings.Contains(err.Error(), "not allowed to use cluster"),
+		"expected cluster error, got:", err)
+
+	// the size of new volumes and expansions is limited
+	_, err = c.VolumeCreate(newRequest(100))
+	tests.Assert(t, err != nil, "expected err != nil")
+	tests.Assert(t, strings.Contains(err.Error(), "the limit of 150 GiB"),
+		"expected size error, got:", err)
+
+	_, err = c.VolumeExpand(vol.Id, &api.VolumeExpandRequest{Size: 60})
+	tests.Assert(t, err != nil, "expected err != nil")
+	tests.Assert(t, strings.Contains(err.Error(), "the limit of 150 GiB"),
+		"expected size error, got:", err)
+
+	vol, err = c.VolumeExpand(vol.Id, &api.VolumeExpandRequest{Size: 40})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, vol.Size == 140, "expected size 140, got:", vol.Size)
+
+	// block volumes count against the tenant, the block hosting
+	// volume created for them does not
+	bv, err := c.BlockVolumeCreate(&api.BlockVolumeCreateRequest{Size: 5})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, bv.Tenant == "team-a", "expected tenant team-a, got:",
+		bv.Tenant)
+
+	info, err := c.TenantInfo("team-a")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.Usage.Size == 145, "expected 145 GiB, got:",
+		info.Usage.Size)
+	tests.Assert(t, info.Usage.Volumes == 1, "expected 1 volume, got:",
+		info.Usage.Volumes)
+	tests.Assert(t, info.Usage.BlockVolumes == 1,
+		"expected 1 block volume, got:", info.Usage.BlockVolumes)
+	tests.Assert(t, info.Limits.MaxSize == 150)
+
+	// the number of volumes is limited
+	_, err = c.BlockVolumeCreate(&api.BlockVolumeCreateRequest{Size: 1})
+	tests.Assert(t, err != nil, "expected err != nil")
+	tests.Assert(t, strings.Contains(err.Error(), "the limit is 2"),
+		"expected volume count error, got:", err)
+
+	// deleting volumes frees capacity for the tenant
+	err = c.BlockVolumeDelete(bv.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	info, err = c.TenantInfo("team-a")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.Usage.Size == 140, "expected 140 GiB, got:",
+		info.Usage.Size)
+	tests.Assert(t, info.Usage.BlockVolumes == 0,
+		"expected no block volumes, got:", info.Usage.BlockVolumes)
+
+	// tenants without limits are not restricted
+	tenant = "team-b"
+	_, err = c.VolumeCreate(newRequest(200))
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	_, err = c.TenantInfo("team-b")
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// with a default config the tenants which are not listed share
+	// its limits, new tenants can not escape them
+	app.conf.Tenants = append(app.conf.Tenants, TenantConfig{
+		Id: DefaultTenantId,
+		TenantLimits: api.TenantLimits{
+			MaxVolumes: 2,
+		},
+	})
+	tenant = "team-c"
+	_, err = c.VolumeCreate(newRequest(10))
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tenant = "team-d"
+	_, err = c.VolumeCreate(newRequest(10))
+	tests.Assert(t, err != nil, "expected err != nil")
+	tests.Assert(t, strings.Contains(err.Error(), "the limit is 2"),
+		"expected volume count error, got:", err)
+
+	info, err = c.TenantInfo("team-d")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.Id == DefaultTenantId, "expected default tenant, got:",
+		info.Id)
+	tests.Assert(t, info.Usage.Volumes == 2, "expected 2 volumes, got:",
+		info.Usage.Volumes)
+
+	// listed tenants keep their own limits
+	tenant = "team-a"
+	info, err = c.TenantInfo("team-a")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.Usage.Volumes == 1, "expected 1 volume, got:",
+		info.Usage.Volumes)
+}
@@ -26,30 +26,33 @@ const (
 	VOLUME_CREATE_MAX_SNAPSHOT_FACTOR = 100
 )
 
-func (a *App) VolumeCreate(w http.ResponseWriter, r *http.Request) {
+// volumeFromRequest reads and checks a volume create request. If the
+// request is not valid the error is written to w and nil is returned.
+func (a *App) volumeFromRequest(w http.ResponseWriter,
+	r *http.Request) (*api.VolumeCreateRequest, *VolumeEntry) {
 
 	var msg api.VolumeCreateRequest
 	err := utils.GetJsonFromRequest(r, &msg)
 	if err != nil {
 		http.Error(w, "request unable to be parsed", 422)
-		return
+		return nil, nil
 	}
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
-		return
+		apiLogger.LogError("validation failed: " + err.Error())
+		return nil, nil
 	}
 
 	switch {
 	case msg.Gid < 0:
 		http.Error(w, "Bad group id less than zero", http.StatusBadRequest)
-		logger.LogError("Bad group id less than zero")
-		return
+		apiLogger.LogError("Bad group id less than zero")
+		return nil, nil
 	case msg.Gid >= math.MaxInt32:
 		http.Error(w, "Bad group id equal or greater than 2**32", http.StatusBadRequest)
-		logger.LogError("Bad group id equal or greater than 2**32")
-		return
+		apiLogger.LogError("Bad group id equal or greater than 2**32")
+		return nil, nil
 	}
 
 	switch msg.Durability.Type {
@@ -60,28 +63,28 @@ func (a *App) VolumeCreate(w http.ResponseWriter, r *http.Request) {
 		msg.Durability.Type = api.DurabilityDistributeOnly
 	default:
 		http.Error(w, "Unknown durability type", http.StatusBadRequest)
-		logger.LogError("Unknown durability type")
-		return
+		apiLogger.LogError("Unknown durability type")
+		return nil, nil
 	}
 
 	if msg.Size < 1 {
 		http.Error(w, "Invalid volume size", http.StatusBadRequest)
-		logger.LogError("Invalid volume size")
-		return
+		apiLogger.LogError("Invalid volume size")
+		return nil, nil
 	}
 	if msg.Snapshot.Enable {
 		if msg.Snapshot.Factor < 1 || msg.Snapshot.Factor > VOLUME_CREATE_MAX_SNAPSHOT_FACTOR {
 			http.Error(w, "Invalid snapshot factor", http.StatusBadRequest)
-			logger.LogError("Invalid snapshot factor")
-			return
+			apiLogger.LogError("Invalid snapshot factor")
+			return nil, nil
 		}
 	}
 
 	if msg.Durability.Type == api.DurabilityReplicate {
 		if msg.Durability.Replicate.Replica > 3 {
 			http.Error(w, "Invalid replica value", http.StatusBadRequest)
-			logger.LogError("Invalid replica value")
-			return
+			apiLogger.LogError("Invalid replica value")
+			return nil, nil
 		}
 	}
 
@@ -97,8 +100,8 @@ func (a *App) VolumeCreate(w http.ResponseWriter, r *http.Request) {
 			http.Error(w,
 				fmt.Sprintf("Invalid dispersion combination: %v+%v", d.Data, d.Redundancy),
 				http.StatusBadRequest)
-			logger.LogError(fmt.Sprintf("Invalid dispersion combination: %v+%v", d.Data, d.Redundancy))
-			return
+			apiLogger.LogError(fmt.Sprintf("Invalid dispersion combination: %v+%v", d.Data, d.Redundancy))
+			return nil, nil
 		}
 	}
 
@@ -113,7 +116,7 @@ func (a *App) VolumeCreate(w http.ResponseWriter, r *http.Request) {
 		}
 		if len(clusters) == 0 {
 			http.Error(w, fmt.Sprintf("No clusters configured"), http.StatusBadRequest)
-			logger.LogError("No clusters configured")
+			apiLogger.LogError("No clusters configured")
 			return ErrNotFound
 		}
 
@@ -122,7 +125,7 @@ func (a *App) VolumeCreate(w http.ResponseWriter, r *http.Request) {
 			_, err := NewClusterEntryFromId(tx, clusterid)
 			if err != nil {
 				http.Error(w, fmt.Sprintf("Cluster id %v not found", clusterid), http.StatusBadRequest)
-				logger.LogError(fmt.Sprintf("Cluster id %v not found", clusterid))
+				apiLogger.LogError(fmt.Sprintf("Cluster id %v not found", clusterid))
 				return err
 			}
 		}
@@ -130,19 +133,65 @@ func (a *App) VolumeCreate(w http.ResponseWriter, r *http.Request) {
 		return nil
 	})
 	if err != nil {
-		return
+		return nil, nil
 	}
 
 	vol := NewVolumeEntryFromRequest(&msg)
 
-	if uint64(msg.Size)*GB < vol.Durability.MinVolumeSize() {
+	err = validateBrickPolicy(msg.BrickPolicy, vol.Durability)
+	if err != nil {
+		http.Error(w, "Invalid brick policy: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("Invalid brick policy: %v", err)
+		return nil, nil
+	}
+
+	if msg.Block && msg.RootQuota {
+		http.Error(w, "Quotas can not be set on block hosting volumes",
+			http.StatusBadRequest)
+		apiLogger.LogError("Quotas can not be set on block hosting volumes")
+		return nil, nil
+	}
+
+	if uint64(msg.Size)*GB < vol.minVolumeSize() {
 		http.Error(w, fmt.Sprintf("Requested volume size (%v GB) is "+
 			"smaller than the minimum supported volume size (%v)",
-			msg.Size, vol.Durability.MinVolumeSize()),
+			msg.Size, vol.minVolumeSize()),
 			http.StatusBadRequest)
-		logger.LogError(fmt.Sprintf("Requested volume size (%v GB) is "+
+		apiLogger.LogError(fmt.Sprintf("Requested volume size (%v GB) is "+
 			"smaller than the minimum supported volume size (%v)",
-			msg.Size, vol.Durability.MinVolumeSize()))
+			msg.Size, vol.minVolumeSize()))
+		return nil, nil
+	}
+
+	return &msg, vol
+}
+
+func (a *App) VolumeCreate(w http.ResponseWriter, r *http.Request) {
+
+	msg, vol := a.volumeFromRequest(w, r)
+	if vol == nil {
+		return
+	}
+
+	vol.Info.Tenant = requestTenant(r)
+	if t := a.tenantConfig(vol.Info.Tenant); t != nil {
+		clusters, err := t.clusters(vol.Info.Clusters)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			apiLogger.LogError(err.Error())
+			return
+		}
+		vol.Info.Clusters = clusters
+
+		a.tenantLock.Lock()
+		defer a.tenantLock.Unlock()
+		if !a.checkTenantLimits(w, t, vol.Info.Size, 1) {
+			return
+		}
+	}
+
+	if msg.DryRun {
+		a.writeVolumePlan(w, vol)
 		return
 	}
 
@@ -158,24 +207,79 @@ func (a *App) VolumeCreate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// VolumePlan reports where the bricks of a volume would be placed
+// without creating it.
+func (a *App) VolumePlan(w http.ResponseWriter, r *http.Request) {
+	_, vol := a.volumeFromRequest(w, r)
+	if vol == nil {
+		return
+	}
+	a.writeVolumePlan(w, vol)
+}
+
+func (a *App) writeVolumePlan(w http.ResponseWriter, vol *VolumeEntry) {
+	plan, err := vol.Plan(a.db)
+	if err != nil {
+		apiLogger.LogError("Unable to plan volume: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		panic(err)
+	}
+}
+
 func (a *App) VolumeList(w http.ResponseWriter, r *http.Request) {
 
 	var list api.VolumeListResponse
 
-	// Get all the cluster ids from the DB
-	err := a.db.View(func(tx *bolt.Tx) error {
-		var err error
+	q, err := newListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get the ids of the volumes matching the query from the DB
+	err = a.db.View(func(tx *bolt.Tx) error {
+		ids, err := ListCompleteVolumes(tx)
+		if err != nil {
+			return err
+		}
 
-		list.Volumes, err = ListCompleteVolumes(tx)
+		list.Volumes, list.Next, err = q.page(ids, func(id string) (bool, error) {
+			v, err := NewVolumeEntryFromId(tx, id)
+			if err != nil {
+				return false, err
+			}
+			return q.matchVolume(v), nil
+		})
 		if err != nil {
 			return err
 		}
 
+		if q.Expand {
+			list.Info = []api.VolumeInfoResponse{}
+			for _, id := range list.Volumes {
+				v, err := NewVolumeEntryFromId(tx, id)
+				if err != nil {
+					return err
+				}
+				info, err := v.NewInfoResponse(tx)
+				if err != nil {
+					return err
+				}
+				list.Info = append(list.Info, *info)
+			}
+		}
+
 		return nil
 	})
 
 	if err != nil {
-		logger.Err(err)
+		apiLogger.Err(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -247,6 +351,24 @@ func (a *App) VolumeDelete(w http.ResponseWriter, r *http.Request) {
 			return err
 		}
 
+		err = checkNotMigrating(tx, id)
+		if err == ErrMigrating {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		err = checkNoGeoReplication(tx, id)
+		if err == ErrGeoReplicated {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
 		if !volume.Info.Block {
 			// further checks only needed for block-hosting volumes
 			return nil
@@ -260,7 +382,7 @@ func (a *App) VolumeDelete(w http.ResponseWriter, r *http.Request) {
 			return nil
 		}
 
-		err = logger.LogError("Cannot delete a block hosting volume containing block volumes")
+		err = apiLogger.LogError("Cannot delete a block hosting volume containing block volumes")
 		http.Error(w, err.Error(), http.StatusConflict)
 		return err
 	})
@@ -278,7 +400,7 @@ func (a *App) VolumeDelete(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *App) VolumeExpand(w http.ResponseWriter, r *http.Request) {
-	logger.Debug("In VolumeExpand")
+	apiLogger.Debug("In VolumeExpand")
 
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -289,11 +411,11 @@ func (a *App) VolumeExpand(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "request unable to be parsed", 422)
 		return
 	}
-	logger.Debug("Msg: %v", msg)
+	apiLogger.Debug("Msg: %v", msg)
 	err = msg.Validate()
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
@@ -301,7 +423,7 @@ func (a *App) VolumeExpand(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid volume size", http.StatusBadRequest)
 		return
 	}
-	logger.Debug("Size: %v", msg.Size)
+	apiLogger.Debug("Size: %v", msg.Size)
 
 	var volume *VolumeEntry
 	err = a.db.View(func(tx *bolt.Tx) error {
@@ -316,6 +438,15 @@ func (a *App) VolumeExpand(w http.ResponseWriter, r *http.Request) {
 			return err
 		}
 
+		err = checkNotMigrating(tx, id)
+		if err == ErrMigrating {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
 		return nil
 
 	})
@@ -323,6 +454,16 @@ func (a *App) VolumeExpand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the expansion counts against the tenant the volume was
+	// created for
+	if t := a.tenantConfig(volume.Info.Tenant); t != nil {
+		a.tenantLock.Lock()
+		defer a.tenantLock.Unlock()
+		if !a.checkTenantLimits(w, t, msg.Size, 0) {
+			return
+		}
+	}
+
 	ve := NewVolumeExpandOperation(volume, a.db, msg.Size)
 	if err := AsyncHttpOperation(a, w, r, ve); err != nil {
 		http.Error(w,
@@ -332,6 +473,264 @@ func (a *App) VolumeExpand(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (a *App) VolumeConvert(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var msg api.VolumeConvertRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	var volume *VolumeEntry
+	err = a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		volume, err = NewVolumeEntryFromId(tx, id)
+		if err == ErrNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		err = checkNotMigrating(tx, id)
+		if err == ErrMigrating {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	err = volume.checkConvert(msg.Arbiter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("Unable to convert volume %v: %v", id, err)
+		return
+	}
+
+	vc := NewVolumeConvertOperation(volume, a.db, msg.Arbiter)
+	if err := AsyncHttpOperation(a, w, r, vc); err != nil {
+		http.Error(w,
+			fmt.Sprintf("Failed to convert volume: %v", err),
+			http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *App) VolumeMigrate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var msg api.VolumeMigrateRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	var volume *VolumeEntry
+	err = a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		volume, err = NewVolumeEntryFromId(tx, id)
+		if err == ErrNotFound || (err == nil && !volume.Visible()) {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		_, err = NewClusterEntryFromId(tx, msg.ClusterId)
+		if err == ErrNotFound {
+			http.Error(w, "Cluster id not found", http.StatusNotFound)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		op, err := volumeMigrationOp(tx, id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		if op != nil {
+			// the migration is resumed by the operation
+			return nil
+		}
+		err = volume.checkMigrate(msg.ClusterId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			apiLogger.LogError("Unable to migrate volume %v: %v", id, err)
+			return err
+		}
+		// sessions refer to the volume by its name on its cluster
+		err = checkNoGeoReplication(tx, id)
+		if err == ErrGeoReplicated {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	vm := NewVolumeMigrateOperation(volume, a.db, msg.ClusterId)
+	if err := AsyncHttpOperation(a, w, r, vm); err != nil {
+		http.Error(w,
+			fmt.Sprintf("Failed to migrate volume: %v", err),
+			http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *App) VolumeMigration(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var info *api.VolumeMigrationInfo
+	err := a.db.View(func(tx *bolt.Tx) error {
+		op, err := volumeMigrationOp(tx, id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		if op == nil {
+			http.Error(w, "Migration not found", http.StatusNotFound)
+			return ErrNotFound
+		}
+		info, err = NewVolumeMigrationInfo(tx, op, id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(info); err != nil {
+		panic(err)
+	}
+}
+
+// volumeMigrationState writes an error to w and returns nil if the
+// volume is not being migrated.
+func (a *App) volumeMigrationState(w http.ResponseWriter,
+	id string) (*VolumeEntry, api.MigrationState) {
+
+	var (
+		volume *VolumeEntry
+		state  api.MigrationState
+	)
+	err := a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		volume, err = NewVolumeEntryFromId(tx, id)
+		if err == ErrNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		op, err := volumeMigrationOp(tx, id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		if op == nil {
+			http.Error(w, "Migration not found", http.StatusNotFound)
+			return ErrNotFound
+		}
+		state, _, err = migrationFromOp(op)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, ""
+	}
+	return volume, state
+}
+
+func (a *App) VolumeMigrateCutover(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	volume, state := a.volumeMigrationState(w, id)
+	if volume == nil {
+		return
+	}
+	if state == api.MigrationCreating || state == api.MigrationCopying {
+		err := fmt.Errorf("Migration of volume %v is %v, "+
+			"it can only be cut over once ready", id, state)
+		http.Error(w, err.Error(), http.StatusConflict)
+		apiLogger.Err(err)
+		return
+	}
+
+	vc := NewVolumeMigrateCutoverOperation(volume, a.db)
+	if err := AsyncHttpOperation(a, w, r, vc); err != nil {
+		http.Error(w,
+			fmt.Sprintf("Failed to cut over volume migration: %v", err),
+			http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *App) VolumeMigrateCancel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	volume, state := a.volumeMigrationState(w, id)
+	if volume == nil {
+		return
+	}
+	if state == api.MigrationCutover || state == api.MigrationRetiring {
+		err := fmt.Errorf("Migration of volume %v is being cut over "+
+			"and can not be cancelled", id)
+		http.Error(w, err.Error(), http.StatusConflict)
+		apiLogger.Err(err)
+		return
+	}
+
+	vc := NewVolumeMigrateCancelOperation(volume, a.db)
+	if err := AsyncHttpOperation(a, w, r, vc); err != nil {
+		http.Error(w,
+			fmt.Sprintf("Failed to cancel volume migration: %v", err),
+			http.StatusInternalServerError)
+		return
+	}
+}
+
 func (a *App) VolumeClone(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	vol_id := vars["id"]
@@ -346,7 +745,7 @@ func (a *App) VolumeClone(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		http.Error(w, "validation failed: "+err.Error(),
 			http.StatusBadRequest)
-		logger.LogError("validation failed: " + err.Error())
+		apiLogger.LogError("validation failed: " + err.Error())
 		return
 	}
 
@@ -378,3 +777,247 @@ func (a *App) VolumeClone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (a *App) VolumeSetTags(w http.ResponseWriter, r *http.Request) {
+	// Get the id from the URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+	var volume *VolumeEntry
+
+	// Unmarshal JSON
+	var msg api.TagsChangeRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	err = a.db.Update(func(tx *bolt.Tx) error {
+		volume, err = NewVolumeEntryFromId(tx, id)
+		if err == ErrNotFound || (err == nil && !volume.Visible()) {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		ApplyTags(volume, msg)
+		if err := volume.Save(tx); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		apiLogger.Err(err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(volume.AllTags()); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) VolumeStatus(w http.ResponseWriter, r *http.Request) {
+	// Get the id from the URL
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var volume *VolumeEntry
+	err := a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		volume, err = NewVolumeEntryFromId(tx, id)
+		if err == ErrNotFound || (err == nil && !volume.Visible()) {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	status, err := volume.Status(a.db, a.requestExecutor(r))
+	if err != nil {
+		apiLogger.LogError("Unable to get status of volume %v: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Write msg
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) VolumeQuota(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var volume *VolumeEntry
+	err := a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		volume, err = NewVolumeEntryFromId(tx, id)
+		if err == ErrNotFound || (err == nil && !volume.Visible()) {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	quota, err := volume.Quota(a.db, a.requestExecutor(r))
+	if err != nil {
+		apiLogger.LogError("Unable to get quota of volume %v: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(quota); err != nil {
+		panic(err)
+	}
+}
+
+// quotaVolume returns the volume whose quotas are changed, or writes
+// the error to the response if they can not be changed.
+func (a *App) quotaVolume(w http.ResponseWriter, id string) (*VolumeEntry, error) {
+	var volume *VolumeEntry
+	err := a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		volume, err = NewVolumeEntryFromId(tx, id)
+		if err == ErrNotFound || (err == nil && !volume.Visible()) {
+			http.Error(w, "Id not found", http.StatusNotFound)
+			return ErrNotFound
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+
+		if volume.Info.Block {
+			err = apiLogger.LogError("Quotas can not be set on block hosting volumes")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return err
+		}
+
+		err = checkNotMigrating(tx, id)
+		if err == ErrMigrating {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return err
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		return nil
+	})
+	return volume, err
+}
+
+func (a *App) VolumeQuotaEnable(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var msg api.VolumeQuotaRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	volume, err := a.quotaVolume(w, id)
+	if err != nil {
+		return
+	}
+
+	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
+		err := volume.QuotaEnable(a.db, a.requestExecutor(r), msg.Enable)
+		if err != nil {
+			return "", err
+		}
+		return "/volumes/" + id + "/quota", nil
+	})
+}
+
+func (a *App) VolumeQuotaSetLimit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var msg api.VolumeQuotaLimitRequest
+	err := utils.GetJsonFromRequest(r, &msg)
+	if err != nil {
+		http.Error(w, "request unable to be parsed", 422)
+		return
+	}
+	err = msg.Validate()
+	if err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apiLogger.LogError("validation failed: " + err.Error())
+		return
+	}
+
+	volume, err := a.quotaVolume(w, id)
+	if err != nil {
+		return
+	}
+
+	apiLogger.Info("Setting quota limit of %v on volume %v", msg.Path, id)
+	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
+		err := volume.QuotaSetLimit(a.db, a.requestExecutor(r), &msg)
+		if err != nil {
+			return "", err
+		}
+		return "/volumes/" + id + "/quota", nil
+	})
+}
+
+func (a *App) VolumeQuotaRemoveLimit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	path := r.URL.Query().Get("path")
+	if err := api.ValidateQuotaPath(path); err != nil {
+		http.Error(w, "validation failed: path: "+err.Error(),
+			http.StatusBadRequest)
+		apiLogger.LogError("validation failed: path: " + err.Error())
+		return
+	}
+
+	volume, err := a.quotaVolume(w, id)
+	if err != nil {
+		return
+	}
+
+	apiLogger.Info("Removing quota limit of %v on volume %v", path, id)
+	a.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
+		err := volume.QuotaRemoveLimit(a.db, a.requestExecutor(r), path)
+		if err != nil {
+			return "", err
+		}
+		return "", nil
+	})
+}
@@ -11,6 +11,7 @@ package glusterfs
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -27,6 +28,7 @@ import (
 	"github.com/boltdb/bolt"
 	"github.com/gorilla/mux"
 	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/executors"
 	"github.com/heketi/heketi/pkg/db"
 	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
@@ -1274,3 +1276,1025 @@ func TestVolumeCreateWithOptions(t *testing.T) {
 	tests.Assert(t, info.GlusterVolumeOptions[0] == "test-option")
 
 }
+
+func TestVolumeTags(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	c := client.NewClientNoAuth(ts.URL)
+	v1, err := c.VolumeCreate(&api.VolumeCreateRequest{
+		Size: 10,
+		Tags: map[string]string{"owner": "team-a", "cost-center": "42"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(v1.Tags) == 2, "expected len(v1.Tags) == 2, got:", v1.Tags)
+
+	v2, err := c.VolumeCreate(&api.VolumeCreateRequest{
+		Size: 10,
+		Tags: map[string]string{"owner": "team-b"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	err = c.VolumeSetTags(v2.Id, &api.TagsChangeRequest{
+		Change: api.UpdateTags,
+		Tags:   map[string]string{"cost-center": "7"},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	info, err := c.VolumeInfo(v2.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(info.Tags) == 2, "expected len(info.Tags) == 2, got:", info.Tags)
+	tests.Assert(t, info.Tags["cost-center"] == "7",
+		`expected info.Tags["cost-center"] == "7", got:`, info.Tags)
+
+	listVolumes := func(query string) []string {
+		r, err := http.Get(ts.URL + "/volumes" + query)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, r.StatusCode == http.StatusOK,
+			"expected r.StatusCode == http.StatusOK, got:", r.StatusCode)
+		var list api.VolumeListResponse
+		err = utils.GetJsonFromResponse(r, &list)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		return list.Volumes
+	}
+
+	l := listVolumes("?tag=owner:team-a")
+	tests.Assert(t, len(l) == 1 && l[0] == v1.Id, "expected only v1, got:", l)
+	l = listVolumes("?tag=cost-center")
+	tests.Assert(t, len(l) == 2, "expected two volumes, got:", l)
+	l = listVolumes("?tag=owner:team-b&tag=cost-center:7")
+	tests.Assert(t, len(l) == 1 && l[0] == v2.Id, "expected only v2, got:", l)
+	l = listVolumes("?tag=owner:team-c")
+	tests.Assert(t, len(l) == 0, "expected no volumes, got:", l)
+	l = listVolumes("")
+	tests.Assert(t, len(l) == 2, "expected two volumes, got:", l)
+
+	r, err := http.Get(ts.URL + "/volumes?tag=bad%20tag")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, r.StatusCode == http.StatusBadRequest,
+		"expected r.StatusCode == http.StatusBadRequest, got:", r.StatusCode)
+
+	err = c.VolumeSetTags(v1.Id, &api.TagsChangeRequest{
+		Change: api.DeleteTags,
+		Tags:   map[string]string{"owner": ""},
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	l = listVolumes("?tag=owner")
+	tests.Assert(t, len(l) == 1 && l[0] == v2.Id, "expected only v2, got:", l)
+
+	// the tags are part of the db dump
+	dump, err := dbDumpInternal(app.db)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, dump.Volumes[v2.Id].Info.Tags["owner"] == "team-b",
+		"expected volume tags in db dump, got:", dump.Volumes[v2.Id].Info.Tags)
+
+	err = c.VolumeSetTags("0123456789abcdef0123456789abcdef", &api.TagsChangeRequest{
+		Change: api.SetTags,
+		Tags:   map[string]string{"owner": "team-a"},
+	})
+	tests.Assert(t, err != nil, "expected err != nil")
+}
+
+func TestVolumeStatus(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	// Setup database
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		2*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	v := createSampleReplicaVolumeEntry(100, 3)
+	err = v.Create(app.db, app.executor)
+	tests.Assert(t, err == nil)
+
+	// Collect the names gluster uses for the bricks
+	var names []string
+	err = app.db.View(func(tx *bolt.Tx) error {
+		for _, brickId := range v.BricksIds() {
+			brick, err := NewBrickEntryFromId(tx, brickId)
+			if err != nil {
+				return err
+			}
+			node, err := NewNodeEntryFromId(tx, brick.Info.NodeId)
+			if err != nil {
+				return err
+			}
+			names = append(names,
+				node.StorageHostName()+":"+brick.Info.Path)
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(names) == 3, "expected len(names) == 3, got", len(names))
+
+	app.xo.MockVolumeStatus = func(host string, volume string) (*executors.VolumeStatus, error) {
+		vs := &executors.VolumeStatus{VolumeName: volume}
+		for _, name := range names {
+			s := strings.SplitN(name, ":", 2)
+			vs.Nodes = append(vs.Nodes, executors.BrickStatus{
+				Hostname: s[0],
+				Path:     s[1],
+				Status:   1,
+				Port:     "49152",
+				Pid:      1234,
+			})
+		}
+		// self-heal daemon is listed with the bricks
+		vs.Nodes = append(vs.Nodes, executors.BrickStatus{
+			Hostname: "Self-heal Daemon",
+			Path:     "localhost",
+			Status:   1,
+		})
+		return vs, nil
+	}
+	app.xo.MockHealInfo = func(host string, volume string) (*executors.HealInfo, error) {
+		hi := &executors.HealInfo{}
+		for _, name := range names {
+			hi.Bricks.BrickList = append(hi.Bricks.BrickList,
+				executors.BrickHealStatus{
+					Name:            name,
+					Status:          "Connected",
+					NumberOfEntries: "0",
+				})
+		}
+		return hi, nil
+	}
+
+	c := client.NewClientNoAuth(ts.URL)
+	tests.Assert(t, c != nil)
+
+	// all bricks online without pending heals
+	status, err := c.VolumeStatus(v.Info.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, status.Id == v.Info.Id)
+	tests.Assert(t, !status.Degraded)
+	tests.Assert(t, len(status.Bricks) == 3)
+	for _, b := range status.Bricks {
+		tests.Assert(t, b.Online)
+		tests.Assert(t, b.Pid == 1234)
+		tests.Assert(t, b.Port == 49152)
+		tests.Assert(t, b.PendingHeals != nil && *b.PendingHeals == 0)
+	}
+
+	cstatus, err := c.ClusterStatus(v.Info.Cluster)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, cstatus.VolumeCount == 1)
+	tests.Assert(t, len(cstatus.DegradedVolumes) == 0)
+
+	// take down a brick and leave pending heals on the others
+	app.xo.MockVolumeStatus = func(host string, volume string) (*executors.VolumeStatus, error) {
+		vs := &executors.VolumeStatus{VolumeName: volume}
+		for i, name := range names {
+			s := strings.SplitN(name, ":", 2)
+			bs := executors.BrickStatus{
+				Hostname: s[0],
+				Path:     s[1],
+				Status:   1,
+				Port:     "49152",
+				Pid:      1234,
+			}
+			if i == 0 {
+				bs.Status = 0
+				bs.Port = "N/A"
+				bs.Pid = -1
+			}
+			vs.Nodes = append(vs.Nodes, bs)
+		}
+		return vs, nil
+	}
+	app.xo.MockHealInfo = func(host string, volume string) (*executors.HealInfo, error) {
+		hi := &executors.HealInfo{}
+		for i, name := range names {
+			bh := executors.BrickHealStatus{
+				Name:            name,
+				Status:          "Connected",
+				NumberOfEntries: "7",
+			}
+			if i == 0 {
+				bh.Status = "Transport endpoint is not connected"
+				bh.NumberOfEntries = "-"
+			}
+			hi.Bricks.BrickList = append(hi.Bricks.BrickList, bh)
+		}
+		return hi, nil
+	}
+
+	status, err = c.VolumeStatus(v.Info.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, status.Degraded)
+	for _, b := range status.Bricks {
+		if b.Host+":"+b.Path == names[0] {
+			tests.Assert(t, !b.Online)
+			tests.Assert(t, b.Pid == 0)
+			tests.Assert(t, b.PendingHeals == nil)
+		} else {
+			tests.Assert(t, b.Online)
+			tests.Assert(t, b.PendingHeals != nil && *b.PendingHeals == 7)
+		}
+	}
+
+	cstatus, err = c.ClusterStatus(v.Info.Cluster)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(cstatus.DegradedVolumes) == 1)
+	tests.Assert(t, cstatus.DegradedVolumes[0].Id == v.Info.Id)
+
+	// status failures are reported in the cluster roll-up
+	app.xo.MockVolumeStatus = func(host string, volume string) (*executors.VolumeStatus, error) {
+		return nil, errors.New("volume status failed")
+	}
+	_, err = c.VolumeStatus(v.Info.Id)
+	tests.Assert(t, err != nil)
+
+	cstatus, err = c.ClusterStatus(v.Info.Cluster)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(cstatus.DegradedVolumes) == 1)
+	tests.Assert(t, cstatus.DegradedVolumes[0].Error != "")
+
+	// unknown ids
+	_, err = c.VolumeStatus("12345")
+	tests.Assert(t, err != nil)
+	_, err = c.ClusterStatus("12345")
+	tests.Assert(t, err != nil)
+}
+
+func TestVolumePlan(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,      // clusters
+		4,      // nodes_per_cluster
+		1,      // devices_per_node,
+		500*GB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	var clusterId string
+	var offlineNode string
+	err = app.db.Update(func(tx *bolt.Tx) error {
+		cids, err := ClusterList(tx)
+		if err != nil {
+			return err
+		}
+		clusterId = cids[0]
+		c, err := NewClusterEntryFromId(tx, clusterId)
+		if err != nil {
+			return err
+		}
+		n, err := NewNodeEntryFromId(tx, c.Info.Nodes[0])
+		if err != nil {
+			return err
+		}
+		offlineNode = n.Info.Id
+		n.State = api.EntryStateOffline
+		return n.Save(tx)
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	c := client.NewClientNoAuth(ts.URL)
+	req := &api.VolumeCreateRequest{}
+	req.Size = 100
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+
+	plan, err := c.VolumePlan(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, plan.Feasible, "expected plan.Feasible, got:", plan)
+	tests.Assert(t, plan.Cluster == clusterId,
+		"expected plan.Cluster == clusterId, got:", plan.Cluster)
+	tests.Assert(t, len(plan.BrickSets) == 1,
+		"expected len(plan.BrickSets) == 1, got:", plan.BrickSets)
+	nodes := map[string]bool{}
+	for _, b := range plan.BrickSets[0].Bricks {
+		tests.Assert(t, b.Size == 100*GB, "expected b.Size == 100*GB, got:", b)
+		tests.Assert(t, b.Node != offlineNode, "expected online node, got:", b)
+		nodes[b.Node] = true
+	}
+	tests.Assert(t, len(nodes) == 3, "expected 3 nodes, got:", nodes)
+
+	// nothing was saved
+	err = app.db.View(func(tx *bolt.Tx) error {
+		vols, err := VolumeList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(vols) == 0, "expected no volumes, got:", vols)
+		bricks, err := BrickList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(bricks) == 0, "expected no bricks, got:", bricks)
+		devices, err := DeviceList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		for _, id := range devices {
+			d, err := NewDeviceEntryFromId(tx, id)
+			tests.Assert(t, err == nil, "expected err == nil, got:", err)
+			tests.Assert(t, d.Info.Storage.Free == 500*GB,
+				"expected device to be unused, got:", d.Info.Storage)
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	// the same plan is returned by a dry run of the create request
+	req.DryRun = true
+	body, err := json.Marshal(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	r, err := http.Post(ts.URL+"/volumes", "application/json",
+		bytes.NewReader(body))
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, r.StatusCode == http.StatusOK,
+		"expected r.StatusCode == http.StatusOK, got:", r.StatusCode)
+	var dryRun api.VolumePlanResponse
+	err = utils.GetJsonFromResponse(r, &dryRun)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, dryRun.Feasible && dryRun.Cluster == clusterId,
+		"expected a feasible plan, got:", dryRun)
+
+	// a volume which does not fit explains why
+	req.DryRun = false
+	req.Size = 1000
+	plan, err = c.VolumePlan(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, !plan.Feasible, "expected !plan.Feasible, got:", plan)
+	tests.Assert(t, len(plan.BrickSets) == 0,
+		"expected no brick sets, got:", plan.BrickSets)
+	tests.Assert(t, len(plan.Rejected) == 1,
+		"expected len(plan.Rejected) == 1, got:", plan.Rejected)
+	rejected := plan.Rejected[0]
+	tests.Assert(t, rejected.Id == clusterId)
+	tests.Assert(t, rejected.Error == ErrMaxBricks.Error() ||
+		rejected.Error == ErrMinimumBrickSize.Error(),
+		"expected ErrMaxBricks or ErrMinimumBrickSize, got:", rejected.Error)
+	tests.Assert(t, rejected.BrickSize > 0 && rejected.BrickSize < 1000*GB,
+		"expected a smaller brick size, got:", rejected.BrickSize)
+	tests.Assert(t, len(rejected.Devices) == 4,
+		"expected len(rejected.Devices) == 4, got:", rejected.Devices)
+	for _, d := range rejected.Devices {
+		if d.Node == offlineNode {
+			tests.Assert(t, d.Reason == rejectNodeOffline,
+				"expected offline node, got:", d)
+		} else {
+			tests.Assert(t, strings.HasPrefix(d.Reason, "not enough free space") ||
+				d.Reason == rejectSameNode,
+				"expected no space or same node, got:", d)
+		}
+	}
+}
+
+func TestVolumeCreateBrickPolicy(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		4,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	c := client.NewClientNoAuth(ts.URL)
+	req := &api.VolumeCreateRequest{}
+	req.Size = 400
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+
+	// policies outside of the limits of the server are refused
+	req.BrickPolicy = &api.BrickPolicy{MaxBrickSize: 5 * 1024}
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err != nil, "expected err != nil")
+	req.BrickPolicy = &api.BrickPolicy{MaxBrickSets: 11}
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err != nil, "expected err != nil")
+	req.BrickPolicy = &api.BrickPolicy{MinBrickSize: 100, MaxBrickSize: 50}
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// the volume is smaller than its smallest brick
+	req.BrickPolicy = &api.BrickPolicy{MinBrickSize: 500}
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	req.BrickPolicy = &api.BrickPolicy{MaxBrickSize: 100}
+	vol, err := c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(vol.Bricks) == 12,
+		"expected len(vol.Bricks) == 12, got:", len(vol.Bricks))
+	tests.Assert(t, vol.BrickPolicy != nil && vol.BrickPolicy.MaxBrickSize == 100,
+		"expected brick policy, got:", vol.BrickPolicy)
+
+	// expansions keep the size of the bricks
+	vol, err = c.VolumeExpand(vol.Id, &api.VolumeExpandRequest{Size: 200})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(vol.Bricks) == 18,
+		"expected len(vol.Bricks) == 18, got:", len(vol.Bricks))
+	for _, b := range vol.Bricks {
+		tests.Assert(t, b.Size == 100*GB,
+			"expected b.Size == 100*GB, got:", b.Size)
+	}
+
+	// a single brick set can not be expanded
+	req.BrickPolicy = &api.BrickPolicy{MaxBrickSets: 1}
+	vol, err = c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(vol.Bricks) == 3,
+		"expected len(vol.Bricks) == 3, got:", len(vol.Bricks))
+	_, err = c.VolumeExpand(vol.Id, &api.VolumeExpandRequest{Size: 100})
+	tests.Assert(t, err != nil, "expected err != nil")
+}
+
+func TestVolumeConvert(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	app.xo.MockVolumeInfo = func(host string, volume string) (*executors.Volume, error) {
+		return mockVolumeInfoFromDb(app.db, volume)
+	}
+	var vcrs []executors.VolumeConvertRequest
+	app.xo.MockVolumeConvert = func(host string, r *executors.VolumeConvertRequest) (*executors.Volume, error) {
+		vcrs = append(vcrs, *r)
+		return &executors.Volume{}, nil
+	}
+
+	c := client.NewClientNoAuth(ts.URL)
+	req := &api.VolumeCreateRequest{}
+	req.Size = 100
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 2
+	vol, err := c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(vol.Bricks) == 2,
+		"expected len(vol.Bricks) == 2, got:", len(vol.Bricks))
+
+	// only conversions to replica 3 are supported
+	_, err = c.VolumeConvert(vol.Id, &api.VolumeConvertRequest{Replica: 4})
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	vol, err = c.VolumeConvert(vol.Id, &api.VolumeConvertRequest{Replica: 3})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, vol.Durability.Replicate.Replica == 3,
+		"expected replica 3, got:", vol.Durability.Replicate.Replica)
+	tests.Assert(t, len(vol.Bricks) == 3,
+		"expected len(vol.Bricks) == 3, got:", len(vol.Bricks))
+	tests.Assert(t, len(vcrs) == 1, "expected one request, got:", vcrs)
+	tests.Assert(t, len(vcrs[0].AddBricks) == 1 && len(vcrs[0].RemoveBricks) == 0,
+		"expected one brick added, got:", vcrs[0])
+	nodes := map[string]bool{}
+	for _, b := range vol.Bricks {
+		nodes[b.NodeId] = true
+		tests.Assert(t, b.Size == vol.Bricks[0].Size,
+			"expected bricks of the same size, got:", vol.Bricks)
+	}
+	tests.Assert(t, len(nodes) == 3, "expected bricks on 3 nodes, got:", nodes)
+
+	// the volume already has replica 3
+	_, err = c.VolumeConvert(vol.Id, &api.VolumeConvertRequest{Replica: 3})
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	oldBricks := map[string]bool{}
+	for _, b := range vol.Bricks {
+		oldBricks[b.Id] = true
+	}
+	vcrs = nil
+	vol, err = c.VolumeConvert(vol.Id,
+		&api.VolumeConvertRequest{Replica: 3, Arbiter: true})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, vol.Durability.Replicate.Replica == 3,
+		"expected replica 3, got:", vol.Durability.Replicate.Replica)
+	tests.Assert(t, len(vol.Bricks) == 3,
+		"expected len(vol.Bricks) == 3, got:", len(vol.Bricks))
+	// the brick is removed before the arbiter brick is added
+	tests.Assert(t, len(vcrs) == 2, "expected two requests, got:", vcrs)
+	tests.Assert(t, len(vcrs[0].AddBricks) == 0 && len(vcrs[0].RemoveBricks) == 1,
+		"expected one brick removed, got:", vcrs[0])
+	tests.Assert(t, vcrs[1].Arbiter, "expected arbiter request, got:", vcrs[1])
+	tests.Assert(t, len(vcrs[1].AddBricks) == 1 && len(vcrs[1].RemoveBricks) == 0,
+		"expected one brick added, got:", vcrs[1])
+	arbiter := false
+	for _, o := range vol.GlusterVolumeOptions {
+		arbiter = arbiter || o == HEKETI_ARBITER_KEY+" true"
+	}
+	tests.Assert(t, arbiter, "expected arbiter option, got:",
+		vol.GlusterVolumeOptions)
+	newBricks := 0
+	for _, b := range vol.Bricks {
+		if !oldBricks[b.Id] {
+			newBricks++
+			tests.Assert(t, b.Size < vol.Bricks[0].Size ||
+				b.Size < vol.Bricks[1].Size,
+				"expected a small arbiter brick, got:", vol.Bricks)
+		}
+	}
+	tests.Assert(t, newBricks == 1, "expected one new brick, got:", newBricks)
+
+	// arbiter volumes can not be converted
+	_, err = c.VolumeConvert(vol.Id,
+		&api.VolumeConvertRequest{Replica: 3, Arbiter: true})
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// the replaced brick is gone and no operation is left pending
+	err = app.db.View(func(tx *bolt.Tx) error {
+		bl, err := BrickList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(bl) == 3, "expected len(bl) == 3, got:", len(bl))
+		pl, err := PendingOperationList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(pl) == 0, "expected len(pl) == 0, got:", len(pl))
+		return nil
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+}
+
+func TestVolumeMigrate(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		2,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	var syncs []*executors.VolumeSyncRequest
+	var syncsReadOnly []bool
+	syncErr := errors.New("rsync failed")
+	readOnly := false
+	app.xo.MockVolumeSync = func(host string, r *executors.VolumeSyncRequest) error {
+		syncs = append(syncs, r)
+		syncsReadOnly = append(syncsReadOnly, readOnly)
+		return syncErr
+	}
+	app.xo.MockVolumeReadOnly = func(host string, volume string, ro bool) error {
+		readOnly = ro
+		return nil
+	}
+
+	c := client.NewClientNoAuth(ts.URL)
+	clusters, err := c.ClusterList()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	src, dst := clusters.Clusters[0], clusters.Clusters[1]
+
+	req := &api.VolumeCreateRequest{}
+	req.Size = 100
+	req.Clusters = []string{src}
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+	vol, err := c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	// the volume can not be migrated to its own cluster
+	_, err = c.VolumeMigrate(vol.Id, &api.VolumeMigrateRequest{ClusterId: src})
+	tests.Assert(t, err != nil, "expected err != nil")
+	_, err = c.VolumeMigration(vol.Id)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// the volume is created on the target cluster but can not be copied
+	_, err = c.VolumeMigrate(vol.Id, &api.VolumeMigrateRequest{ClusterId: dst})
+	tests.Assert(t, err != nil, "expected err != nil")
+	m, err := c.VolumeMigration(vol.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, m.State == api.MigrationCopying,
+		"expected state copying, got:", m.State)
+	tests.Assert(t, m.ClusterId == dst, "expected cluster", dst, "got:", m.ClusterId)
+	tests.Assert(t, len(syncs) == 1, "expected one sync, got:", syncs)
+	tests.Assert(t, syncs[0].Name == vol.Name,
+		"expected sync of", vol.Name, "got:", syncs[0].Name)
+
+	// the copy is hidden and the migration does not stop the server
+	vl, err := c.VolumeList()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(vl.Volumes) == 1, "expected 1 volume, got:", vl.Volumes)
+	tests.Assert(t, !HasPendingOperations(app.db),
+		"expected migrations not to count as pending operations")
+
+	// the volume can not be changed while it is migrated
+	err = c.VolumeDelete(vol.Id)
+	tests.Assert(t, err != nil, "expected err != nil")
+	_, err = c.VolumeExpand(vol.Id, &api.VolumeExpandRequest{Size: 10})
+	tests.Assert(t, err != nil, "expected err != nil")
+	_, err = c.VolumeMigrateCutover(vol.Id)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// resume the migration
+	syncErr = nil
+	m, err = c.VolumeMigrate(vol.Id, &api.VolumeMigrateRequest{ClusterId: dst})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, m.State == api.MigrationReady,
+		"expected state ready, got:", m.State)
+
+	tests.Assert(t, !syncsReadOnly[1], "expected copy of writable volume")
+
+	// a failed final copy leaves the volume writable on its cluster
+	syncErr = errors.New("rsync failed")
+	_, err = c.VolumeMigrateCutover(vol.Id)
+	tests.Assert(t, err != nil, "expected err != nil")
+	tests.Assert(t, len(syncs) == 3, "expected a final sync, got:", syncs)
+	tests.Assert(t, syncsReadOnly[2], "expected copy of read-only volume")
+	tests.Assert(t, !readOnly, "expected volume to be writable again")
+	m, err = c.VolumeMigration(vol.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, m.State == api.MigrationReady,
+		"expected state ready, got:", m.State)
+
+	// the final copy is made once the volume can no longer be written
+	syncErr = nil
+	info, err := c.VolumeMigrateCutover(vol.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(syncs) == 4, "expected a final sync, got:", syncs)
+	tests.Assert(t, syncsReadOnly[3], "expected copy of read-only volume")
+	tests.Assert(t, info.Id == vol.Id, "expected id", vol.Id, "got:", info.Id)
+	tests.Assert(t, info.Name == vol.Name, "expected name", vol.Name, "got:", info.Name)
+	tests.Assert(t, info.Cluster == dst, "expected cluster", dst, "got:", info.Cluster)
+	tests.Assert(t, len(info.Bricks) == 3,
+		"expected len(info.Bricks) == 3, got:", len(info.Bricks))
+	for _, b := range info.Bricks {
+		tests.Assert(t, b.VolumeId == vol.Id,
+			"expected brick of", vol.Id, "got:", b.VolumeId)
+	}
+	_, err = c.VolumeMigration(vol.Id)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	ci, err := c.ClusterInfo(src)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(ci.Volumes) == 0, "expected no volumes, got:", ci.Volumes)
+	ci, err = c.ClusterInfo(dst)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(ci.Volumes) == 1 && ci.Volumes[0] == vol.Id,
+		"expected volume", vol.Id, "got:", ci.Volumes)
+
+	err = app.db.View(func(tx *bolt.Tx) error {
+		bl, err := BrickList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(bl) == 3, "expected len(bl) == 3, got:", len(bl))
+		vl, err := VolumeList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(vl) == 1, "expected len(vl) == 1, got:", len(vl))
+		pl, err := PendingOperationList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(pl) == 0, "expected len(pl) == 0, got:", len(pl))
+		return nil
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	// a migration can be cancelled before the cutover
+	m, err = c.VolumeMigrate(vol.Id, &api.VolumeMigrateRequest{ClusterId: src})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	err = c.VolumeMigrateCancel(vol.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	info, err = c.VolumeInfo(vol.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, info.Cluster == dst, "expected cluster", dst, "got:", info.Cluster)
+	ci, err = c.ClusterInfo(src)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(ci.Volumes) == 0, "expected no volumes, got:", ci.Volumes)
+	err = app.db.View(func(tx *bolt.Tx) error {
+		bl, err := BrickList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(bl) == 3, "expected len(bl) == 3, got:", len(bl))
+		pl, err := PendingOperationList(tx)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(pl) == 0, "expected len(pl) == 0, got:", len(pl))
+		for _, id := range ci.Nodes {
+			node, err := NewNodeEntryFromId(tx, id)
+			tests.Assert(t, err == nil, "expected err == nil, got:", err)
+			for _, d := range node.Devices {
+				device, err := NewDeviceEntryFromId(tx, d)
+				tests.Assert(t, err == nil, "expected err == nil, got:", err)
+				tests.Assert(t, device.Info.Storage.Used == 0,
+					"expected no space used, got:", device.Info.Storage.Used)
+			}
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+}
+
+func TestVolumeQuota(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	enabled := false
+	limits := map[string]uint64{}
+	app.xo.MockVolumeQuotaEnable = func(host, volume string) error {
+		enabled = true
+		return nil
+	}
+	app.xo.MockVolumeQuotaDisable = func(host, volume string) error {
+		enabled = false
+		limits = map[string]uint64{}
+		return nil
+	}
+	app.xo.MockVolumeQuotaSetLimit = func(host, volume string,
+		ql *executors.QuotaLimitRequest) error {
+		tests.Assert(t, enabled, "expected quota to be enabled")
+		limits[ql.Path] = ql.HardLimit
+		return nil
+	}
+	app.xo.MockVolumeQuotaRemoveLimit = func(host, volume, path string) error {
+		delete(limits, path)
+		return nil
+	}
+	app.xo.MockVolumeQuotaList = func(host, volume string) ([]executors.QuotaLimit, error) {
+		l := []executors.QuotaLimit{}
+		for path, limit := range limits {
+			l = append(l, executors.QuotaLimit{
+				Path:      path,
+				HardLimit: limit,
+				Available: limit,
+			})
+		}
+		return l, nil
+	}
+	app.xo.MockVolumeInfo = func(host, volume string) (*executors.Volume, error) {
+		vinfo := &executors.Volume{VolumeName: volume}
+		if enabled {
+			vinfo.Options.OptionList = []executors.Option{
+				executors.Option{Name: "features.quota", Value: "on"},
+			}
+		}
+		return vinfo, nil
+	}
+
+	c := client.NewClientNoAuth(ts.URL)
+
+	// the root quota is set when the volume is created
+	req := &api.VolumeCreateRequest{}
+	req.Size = 100
+	req.RootQuota = true
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+	vol, err := c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, vol.RootQuota, "expected root quota")
+	tests.Assert(t, limits["/"] == 95*1024*1024*1024,
+		"expected root limit of 95GiB, got:", limits)
+
+	// and raised with the size of the volume
+	vol, err = c.VolumeExpand(vol.Id, &api.VolumeExpandRequest{Size: 100})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, limits["/"] == 190*1024*1024*1024,
+		"expected root limit of 190GiB, got:", limits)
+
+	// quotas are not allowed on block hosting volumes
+	req = &api.VolumeCreateRequest{}
+	req.Size = 100
+	req.Block = true
+	req.RootQuota = true
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	quota, err := c.VolumeQuotaSetLimit(vol.Id, &api.VolumeQuotaLimitRequest{
+		Path:      "/team-a",
+		HardLimit: 1024,
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, quota.Enabled, "expected quota to be enabled")
+	tests.Assert(t, len(quota.Limits) == 2, "expected 2 limits, got:", quota.Limits)
+
+	_, err = c.VolumeQuotaSetLimit(vol.Id, &api.VolumeQuotaLimitRequest{
+		Path:      "/team-a/../..",
+		HardLimit: 1024,
+	})
+	tests.Assert(t, err != nil, "expected err != nil")
+	err = c.VolumeQuotaRemoveLimit(vol.Id, "team-a")
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	err = c.VolumeQuotaRemoveLimit(vol.Id, "/team-a")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(limits) == 1, "expected 1 limit, got:", limits)
+
+	// a limit set on the root directory replaces the root quota
+	_, err = c.VolumeQuotaSetLimit(vol.Id, &api.VolumeQuotaLimitRequest{
+		Path:      "/",
+		HardLimit: 2048,
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	vol, err = c.VolumeInfo(vol.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, !vol.RootQuota, "expected no root quota")
+	_, err = c.VolumeExpand(vol.Id, &api.VolumeExpandRequest{Size: 100})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, limits["/"] == 2048, "expected root limit kept, got:", limits)
+
+	quota, err = c.VolumeQuotaEnable(vol.Id, &api.VolumeQuotaRequest{Enable: false})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, !quota.Enabled, "expected quota to be disabled")
+	tests.Assert(t, len(quota.Limits) == 0, "expected no limits, got:", quota.Limits)
+}
+
+func TestVolumeListQuery(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		2,    // clusters
+		3,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	c := client.NewClientNoAuth(ts.URL)
+	clusters, err := c.ClusterList()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	// five replica volumes on the first cluster and a distributed
+	// volume on the second
+	ids := map[string]string{}
+	for i := 0; i < 5; i++ {
+		req := &api.VolumeCreateRequest{}
+		req.Size = 10
+		req.Name = fmt.Sprintf("app-%v", i)
+		req.Clusters = []string{clusters.Clusters[0]}
+		req.Durability.Type = api.DurabilityReplicate
+		req.Durability.Replicate.Replica = 3
+		vol, err := c.VolumeCreate(req)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		ids[vol.Name] = vol.Id
+	}
+	req := &api.VolumeCreateRequest{}
+	req.Size = 10
+	req.Name = "db"
+	req.Clusters = []string{clusters.Clusters[1]}
+	req.Durability.Type = api.DurabilityDistributeOnly
+	req.Tags = map[string]string{"owner": "team-a"}
+	vol, err := c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	ids[vol.Name] = vol.Id
+
+	// a volume is found by name with its info in one request
+	list, err := c.VolumeListQuery(&api.ListQuery{Name: "app-3", Expand: true})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(list.Volumes) == 1 && list.Volumes[0] == ids["app-3"],
+		"expected only app-3, got:", list.Volumes)
+	tests.Assert(t, len(list.Info) == 1 && list.Info[0].Name == "app-3",
+		"expected the info of app-3, got:", list.Info)
+	tests.Assert(t, list.Next == "", "expected no next page, got:", list.Next)
+
+	count := func(q *api.ListQuery) int {
+		list, err := c.VolumeListQuery(q)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(list.Info) == 0, "expected no info, got:", list.Info)
+		return len(list.Volumes)
+	}
+	tests.Assert(t, count(&api.ListQuery{NamePrefix: "app-"}) == 5)
+	tests.Assert(t, count(&api.ListQuery{Name: "app"}) == 0)
+	tests.Assert(t, count(&api.ListQuery{Cluster: clusters.Clusters[1]}) == 1)
+	tests.Assert(t, count(&api.ListQuery{
+		Durability: api.DurabilityReplicate}) == 5)
+	tests.Assert(t, count(&api.ListQuery{
+		NamePrefix: "app-",
+		Durability: api.DurabilityDistributeOnly}) == 0)
+	tests.Assert(t, count(&api.ListQuery{Tags: []string{"owner:team-a"}}) == 1)
+	notBlock := false
+	tests.Assert(t, count(&api.ListQuery{Block: &notBlock}) == 6)
+
+	// the list is paged in the order of the ids
+	all := []string{}
+	q := &api.ListQuery{Limit: 4, Expand: true}
+	pages := 0
+	for {
+		list, err := c.VolumeListQuery(q)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, len(list.Volumes) <= 4,
+			"expected at most 4 volumes, got:", list.Volumes)
+		tests.Assert(t, len(list.Info) == len(list.Volumes))
+		all = append(all, list.Volumes...)
+		pages++
+		if list.Next == "" {
+			break
+		}
+		q.Continue = list.Next
+	}
+	tests.Assert(t, pages == 2, "expected 2 pages, got:", pages)
+	tests.Assert(t, len(all) == 6, "expected 6 volumes, got:", all)
+	for i := 1; i < len(all); i++ {
+		tests.Assert(t, all[i-1] < all[i], "expected sorted ids, got:", all)
+	}
+
+	// an exact page does not return a token
+	list, err = c.VolumeListQuery(&api.ListQuery{Limit: 6})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(list.Volumes) == 6 && list.Next == "",
+		"expected all volumes and no token, got:", list)
+
+	for _, query := range []string{
+		"?durability=mirror",
+		"?block=maybe",
+		"?limit=0",
+		"?expand=yes",
+		"?tag=bad%20tag",
+	} {
+		r, err := http.Get(ts.URL + "/volumes" + query)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, r.StatusCode == http.StatusBadRequest,
+			"expected http.StatusBadRequest for", query, "got:", r.StatusCode)
+	}
+}
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// notifyOperation sends the notifications for a completed
+// operation to the webhooks of wn. Nothing is sent if wn is nil.
+func notifyOperation(wn *WebhookNotifier, op Operation, err error) {
+	if wn == nil {
+		return
+	}
+	if err != nil {
+		wn.Notify(api.WebhookOperationFailed, "operation",
+			operationId(op),
+			fmt.Sprintf("%v failed: %v", op.Label(), err))
+		return
+	}
+
+	switch o := op.(type) {
+	case *VolumeCreateOperation:
+		wn.Notify(api.WebhookVolumeCreated, "volume",
+			o.vol.Info.Id, "")
+	case *VolumeCloneOperation:
+		wn.Notify(api.WebhookVolumeCreated, "volume",
+			o.clone.Info.Id, "")
+	case *VolumeDeleteOperation:
+		wn.Notify(api.WebhookVolumeDeleted, "volume",
+			o.vol.Info.Id, "")
+	}
+}
+
+// nodeHealthChanged is called by the node health monitor when a
+// node passes or fails a check for the first time in a row.
+func (a *App) nodeHealthChanged(nodeId string, up bool) {
+	if !up {
+		a.webhooks.Notify(api.WebhookNodeUnhealthy, "node", nodeId,
+			"node failed its health check")
+	}
+}
+
+// deviceHealthChanged is called by the device health monitor when
+// the health state of a device changes.
+func (a *App) deviceHealthChanged(s DeviceHealthStatus,
+	from, to api.DeviceHealthState, warnings []string) {
+
+	if to == api.DeviceHealthFailed {
+		a.webhooks.Notify(api.WebhookDeviceFailed, "device", s.DeviceId,
+			fmt.Sprintf("device %v on node %v failed: %v",
+				s.Name, s.NodeId, warnings))
+	}
+}
+
+// deviceStateFailed is called when a device was set failed, which
+// also removed its bricks.
+func (a *App) deviceStateFailed(d *DeviceEntry) {
+	a.webhooks.Notify(api.WebhookDeviceFailed, "device", d.Info.Id,
+		fmt.Sprintf("device %v on node %v was set failed",
+			d.Info.Name, d.NodeId))
+}
+
+func (a *App) WebhookList(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(a.webhooks.Status()); err != nil {
+		panic(err)
+	}
+}
@@ -83,6 +83,8 @@ func NewBlockVolumeEntryFromRequest(req *api.BlockVolumeCreateRequest) *BlockVol
 	// If Clusters is zero, then it will be assigned during volume creation
 	vol.Info.Clusters = req.Clusters
 	vol.Info.Hacount = req.Hacount
+	vol.Info.Selector = req.Selector
+	vol.Info.Tags = copyTags(req.Tags)
 
 	return vol
 }
@@ -129,6 +131,9 @@ func (v *BlockVolumeEntry) NewInfoResponse(tx *bolt.Tx) (*api.BlockVolumeInfoRes
 	info.Name = v.Info.Name
 	info.Hacount = v.Info.Hacount
 	info.BlockHostingVolume = v.Info.BlockHostingVolume
+	info.Selector = v.Info.Selector
+	info.Tenant = v.Info.Tenant
+	info.Tags = copyTags(v.Info.Tags)
 
 	return info, nil
 }
@@ -175,11 +180,11 @@ func (v *BlockVolumeEntry) eligibleClustersAndVolumes(db wdb.RODB) (
 		return
 	}
 	if len(possibleClusters) == 0 {
-		logger.LogError("No clusters eligible to satisfy create block volume request")
+		opLogger.LogError("No clusters eligible to satisfy create block volume request")
 		e = ErrNoSpace
 		return
 	}
-	logger.Debug("Using the following clusters: %+v", possibleClusters)
+	opLogger.Debug("Using the following clusters: %+v", possibleClusters)
 
 	var possibleVolumes []string
 	for _, clusterId := range possibleClusters {
@@ -203,7 +208,7 @@ func (v *BlockVolumeEntry) eligibleClustersAndVolumes(db wdb.RODB) (
 		}
 	}
 
-	logger.Debug("Using the following possible block hosting volumes: %+v", possibleVolumes)
+	opLogger.Debug("Using the following possible block hosting volumes: %+v", possibleVolumes)
 
 	for _, vol := range possibleVolumes {
 		err := db.View(func(tx *bolt.Tx) error {
@@ -289,7 +294,7 @@ func (v *BlockVolumeEntry) blockHostingVolumeName(db wdb.RODB) (name string, e e
 	e = db.View(func(tx *bolt.Tx) error {
 		volume, err := NewVolumeEntryFromId(tx, v.Info.BlockHostingVolume)
 		if err != nil {
-			logger.LogError("Unable to load block hosting volume: %v", err)
+			opLogger.LogError("Unable to load block hosting volume: %v", err)
 			return err
 		}
 		name = volume.Info.Name
@@ -307,11 +312,11 @@ func (v *BlockVolumeEntry) deleteBlockVolumeExec(db wdb.RODB,
 		return err
 	}
 
-	logger.Debug("Using executor host [%v]", executorhost)
+	opLogger.Debug("Using executor host [%v]", executorhost)
 
 	err = executor.BlockVolumeDestroy(executorhost, hvname, v.Info.Name)
 	if err != nil {
-		logger.LogError("Unable to delete volume: %v", err)
+		opLogger.LogError("Unable to delete volume: %v", err)
 		return err
 	}
 	return nil
@@ -322,32 +327,32 @@ func (v *BlockVolumeEntry) removeComponents(db wdb.DB) error {
 		// Remove volume from cluster
 		cluster, err := NewClusterEntryFromId(tx, v.Info.Cluster)
 		if err != nil {
-			logger.Err(err)
+			opLogger.Err(err)
 			// Do not return here.. keep going
 		}
 		cluster.BlockVolumeDelete(v.Info.Id)
 		err = cluster.Save(tx)
 		if err != nil {
-			logger.Err(err)
+			opLogger.Err(err)
 			// Do not return here.. keep going
 		}
 
 		blockHostingVolume, err := NewVolumeEntryFromId(tx, v.Info.BlockHostingVolume)
 		if err != nil {
-			logger.Err(err)
+			opLogger.Err(err)
 			// Do not return here.. keep going
 		}
 
 		blockHostingVolume.BlockVolumeDelete(v.Info.Id)
 		if err != nil {
-			logger.Err(err)
+			opLogger.Err(err)
 			// Do not return here.. keep going
 		}
 		blockHostingVolume.Info.BlockInfo.FreeSize = blockHostingVolume.Info.BlockInfo.FreeSize + v.Info.Size
 		blockHostingVolume.Save(tx)
 
 		if err != nil {
-			logger.Err(err)
+			opLogger.Err(err)
 			// Do not return here.. keep going
 		}
 
@@ -358,7 +363,7 @@ func (v *BlockVolumeEntry) removeComponents(db wdb.DB) error {
 }
 
 func (v *BlockVolumeEntry) Destroy(db wdb.DB, executor executors.Executor) error {
-	logger.Info("Destroying volume %v", v.Info.Id)
+	opLogger.Info("Destroying volume %v", v.Info.Id)
 
 	return RunOperation(
 		NewBlockVolumeDeleteOperation(v, db),
@@ -371,7 +376,7 @@ func (v *BlockVolumeEntry) Destroy(db wdb.DB, executor executors.Executor) error
 // database operation fails.
 func canHostBlockVolume(tx *bolt.Tx, bv *BlockVolumeEntry, vol *VolumeEntry) (bool, error) {
 	if vol.Info.BlockInfo.FreeSize < bv.Info.Size {
-		logger.Warning("Free size is less than the block volume requested")
+		opLogger.Warning("Free size is less than the block volume requested")
 		return false, nil
 	}
 
@@ -381,11 +386,45 @@ func canHostBlockVolume(tx *bolt.Tx, bv *BlockVolumeEntry, vol *VolumeEntry) (bo
 			return false, err
 		}
 		if bv.Info.Name == existingbv.Info.Name {
-			logger.Warning("Name %v already in use in file volume %v",
+			opLogger.Warning("Name %v already in use in file volume %v",
 				bv.Info.Name, vol.Info.Name)
 			return false, nil
 		}
 	}
 
+	if bv.Info.Selector != nil {
+		for _, brickId := range vol.Bricks {
+			brick, err := NewBrickEntryFromId(tx, brickId)
+			if err != nil {
+				return false, err
+			}
+			device, err := NewDeviceEntryFromId(tx, brick.Info.DeviceId)
+			if err != nil {
+				return false, err
+			}
+			node, err := NewNodeEntryFromId(tx, brick.Info.NodeId)
+			if err != nil {
+				return false, err
+			}
+			if !selectorMatchesDevice(bv.Info.Selector, device, node) {
+				opLogger.Debug("Brick %v of volume %v does not match "+
+					"the placement selector", brickId, vol.Info.Name)
+				return false, nil
+			}
+		}
+	}
+
 	return true, nil
 }
+
+func (v *BlockVolumeEntry) AllTags() map[string]string {
+	if v.Info.Tags == nil {
+		return map[string]string{}
+	}
+	return v.Info.Tags
+}
+
+func (v *BlockVolumeEntry) SetTags(t map[string]string) error {
+	v.Info.Tags = t
+	return nil
+}
@@ -56,7 +56,7 @@ func (v *BlockVolumeEntry) createBlockVolumeRequest(db wdb.RODB,
 	var blockHostingVolumeName string
 
 	err := db.View(func(tx *bolt.Tx) error {
-		logger.Debug("Getting info for block hosting volume %v", blockHostingVolumeId)
+		opLogger.Debug("Getting info for block hosting volume %v", blockHostingVolumeId)
 		bhvol, err := NewVolumeEntryFromId(tx, blockHostingVolumeId)
 		if err != nil {
 			return err
@@ -87,7 +87,7 @@ func (v *BlockVolumeEntry) createBlockVolumeRequest(db wdb.RODB,
 		return nil
 	})
 	if err != nil {
-		logger.Err(err)
+		opLogger.Err(err)
 		return nil, "", err
 	}
 
@@ -97,7 +97,7 @@ func (v *BlockVolumeEntry) createBlockVolumeRequest(db wdb.RODB,
 		return nil, "", err
 	}
 
-	logger.Debug("Using executor host [%v]", executorhost)
+	opLogger.Debug("Using executor host [%v]", executorhost)
 
 	// Setup volume information in the request
 	vr := &executors.BlockVolumeRequest{}
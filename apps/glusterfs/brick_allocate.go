@@ -16,6 +16,7 @@ import (
 	"github.com/lpabon/godbc"
 
 	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 )
 
@@ -93,8 +94,14 @@ func (bs *BrickSet) Full() bool {
 // Drop returns a new brick set with the brick at the given
 // index removed. Does not preserve brick positioning and
 // is not suitable for position dependent allocations.
+// A set without a brick at the given index, such as a replica 2
+// set being converted to replica 3, is returned unchanged.
 func (bs *BrickSet) Drop(index int) *BrickSet {
 	bs2 := NewBrickSet(bs.SetSize)
+	if index >= len(bs.Bricks) {
+		bs2.Bricks = bs.Bricks
+		return bs2
+	}
 	bs2.Bricks = append(bs.Bricks[:index], bs.Bricks[index+1:]...)
 	return bs2
 }
@@ -190,9 +197,11 @@ func tryAllocateBrickOnDevice(
 	}
 
 	if !deviceOk {
+		rejectDevice(opts, device, rejectSameNode)
 		return nil
 	}
 	if pred != nil && !pred(bs, device) {
+		rejectDevice(opts, device, rejectFiltered)
 		return nil
 	}
 
@@ -201,9 +210,10 @@ func tryAllocateBrickOnDevice(
 	brick := device.NewBrickEntry(brickSize, snapFactor,
 		opts.BrickGid(), opts.BrickOwner())
 	if brick == nil {
-		logger.Debug(
+		allocLogger.Debug(
 			"Unable to place a brick of size %v & factor %v on device %v",
 			brickSize, snapFactor, device.Info.Id)
+		rejectNoSpace(opts, device, brickSize, snapFactor)
 	}
 	return brick
 }
@@ -246,7 +256,7 @@ func populateBrickSet(
 	bs := NewBrickSet(ssize)
 	ds := NewDeviceSet(ssize)
 	for i := 0; i < ssize; i++ {
-		logger.Debug("%v / %v", i, ssize)
+		allocLogger.Debug("%v / %v", i, ssize)
 
 		brick, device, err := findDeviceAndBrickForSet(
 			opts, fetchDevice, pred, deviceCh, bs)
@@ -280,8 +290,9 @@ func allocateBricks(
 	err := db.View(func(tx *bolt.Tx) error {
 		var err error
 		dsrc := NewClusterDeviceSource(tx, cluster)
-		placer := PlacerForVolume(v)
-		r, err = placer.PlaceAll(dsrc, opts, nil)
+		placer := PlacerForVolume(v, dsrc)
+		r, err = placer.PlaceAll(dsrc, opts,
+			SelectorDeviceFilter(v.Info.Selector, dsrc, nil))
 		return err
 	})
 	return r, err
@@ -292,6 +303,7 @@ type ClusterDeviceSource struct {
 	deviceCache map[string]*DeviceEntry
 	nodeCache   map[string]*NodeEntry
 	clusterId   string
+	overcommit  *api.ClusterOvercommit
 }
 
 func NewClusterDeviceSource(tx *bolt.Tx,
@@ -310,6 +322,7 @@ func (cds *ClusterDeviceSource) Devices() ([]DeviceAndNode, error) {
 	if err != nil {
 		return nil, err
 	}
+	cds.overcommit = &cluster.Info.ClusterOvercommit
 
 	nodeUp := currentNodeHealthStatus()
 
@@ -336,6 +349,7 @@ func (cds *ClusterDeviceSource) Devices() ([]DeviceAndNode, error) {
 			if !device.isOnline() {
 				continue
 			}
+			cds.setOvercommit(device)
 
 			valid = append(valid, DeviceAndNode{
 				Device: device,
@@ -365,11 +379,26 @@ func (cds *ClusterDeviceSource) Device(id string) (*DeviceEntry, error) {
 		if err != nil {
 			return nil, err
 		}
+		if cds.overcommit == nil {
+			cluster, err := NewClusterEntryFromId(cds.tx, cds.clusterId)
+			if err != nil {
+				return nil, err
+			}
+			cds.overcommit = &cluster.Info.ClusterOvercommit
+		}
+		cds.setOvercommit(device)
 		cds.deviceCache[id] = device
 	}
 	return device, nil
 }
 
+// setOvercommit applies the overcommit settings of the cluster to
+// a device loaded by the device source.
+func (cds *ClusterDeviceSource) setOvercommit(device *DeviceEntry) {
+	used, known := currentDevicePhysicalUsage(device.Info.Id)
+	device.SetOvercommit(*cds.overcommit, used, known)
+}
+
 func (cds *ClusterDeviceSource) Node(id string) (*NodeEntry, error) {
 	node, ok := cds.nodeCache[id]
 	if !ok {
@@ -419,6 +448,10 @@ func (vp *VolumePlacementOpts) AverageFileSize() uint64 {
 	return vp.v.GetAverageFileSize()
 }
 
+func (vp *VolumePlacementOpts) AllocationStrategy() api.AllocationStrategy {
+	return vp.v.Info.Allocation
+}
+
 type StandardBrickPlacer struct{}
 
 func NewStandardBrickPlacer() *StandardBrickPlacer {
@@ -438,14 +471,14 @@ func (bp *StandardBrickPlacer) PlaceAll(
 
 	numBrickSets := opts.SetCount()
 	for sn := 0; sn < numBrickSets; sn++ {
-		logger.Info("Allocating brick set #%v", sn)
+		allocLogger.Info("Allocating brick set #%v", sn)
 
 		// Generate an id for the brick, this is used as a
 		// random index into the ring(s)
 		brickId := utils.GenUUID()
 
 		a := NewSimpleAllocator()
-		deviceCh, done, err := a.GetNodesFromDeviceSource(dsrc, brickId)
+		deviceCh, done, err := a.GetNodesForPlacement(dsrc, opts, brickId)
 		defer close(done)
 		if err != nil {
 			return r, err
@@ -480,7 +513,7 @@ func (bp *StandardBrickPlacer) Replace(
 			"brick replace index out of bounds (got %v, set size %v)",
 			index, bs.SetSize)
 	}
-	logger.Info("Replace brick in brick set %v with index %v",
+	allocLogger.Info("Replace brick in brick set %v with index %v",
 		bs, index)
 
 	// we return a brick allocation for symmetry with PlaceAll
@@ -492,7 +525,7 @@ func (bp *StandardBrickPlacer) Replace(
 
 	brickId := utils.GenUUID()
 	a := NewSimpleAllocator()
-	deviceCh, done, err := a.GetNodesFromDeviceSource(dsrc, brickId)
+	deviceCh, done, err := a.GetNodesForPlacement(dsrc, opts, brickId)
 	defer close(done)
 	if err != nil {
 		return r, err
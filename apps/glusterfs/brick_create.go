@@ -33,7 +33,7 @@ func CreateBricks(db wdb.RODB, executor executors.Executor, brick_entries []*Bri
 	// any of errored, it would be cought here
 	err := sg.Result()
 	if err != nil {
-		logger.Err(err)
+		opLogger.Err(err)
 
 		// Destroy all bricks and cleanup
 		DestroyBricks(db, executor, brick_entries)
@@ -70,7 +70,7 @@ func DestroyBricks(db wdb.RODB, executor executors.Executor, brick_entries []*Br
 	// any of errored, it would be cought here
 	err := sg.Result()
 	if err != nil {
-		logger.Err(err)
+		opLogger.Err(err)
 	}
 
 	return reclaimed, err
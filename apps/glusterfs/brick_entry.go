@@ -174,11 +174,13 @@ func (b *BrickEntry) Create(db wdb.RODB, executor executors.Executor) error {
 	req.VgId = b.Info.DeviceId
 	req.PoolMetadataSize = b.PoolMetadataSize
 	req.Path = b.Info.Path
+	// thin pools of overcommitted devices are smaller than their brick
+	req.Autoextend = b.TpSize < b.Info.Size
 	// remove this some time post-refactoring
 	godbc.Require(req.Path == utils.BrickPath(req.VgId, req.Name))
 
 	// Create brick on node
-	logger.Info("Creating brick %v", b.Info.Id)
+	opLogger.Info("Creating brick %v", b.Info.Id)
 	_, err = executor.BrickCreate(host, req)
 	if err != nil {
 		return err
@@ -218,7 +220,7 @@ func (b *BrickEntry) Destroy(db wdb.RODB, executor executors.Executor) (bool, er
 	req.Path = strings.TrimSuffix(b.Info.Path, "/brick")
 
 	// Delete brick on node
-	logger.Info("Deleting brick %v", b.Info.Id)
+	opLogger.Info("Deleting brick %v", b.Info.Id)
 	spaceReclaimed, err := executor.BrickDestroy(host, req)
 	if err != nil {
 		return spaceReclaimed, err
@@ -305,7 +307,7 @@ func (b *BrickEntry) RemoveFromDevice(tx *bolt.Tx) error {
 	// Access device
 	device, err := NewDeviceEntryFromId(tx, b.Info.DeviceId)
 	if err != nil {
-		logger.Err(err)
+		opLogger.Err(err)
 		return err
 	}
 
@@ -315,7 +317,7 @@ func (b *BrickEntry) RemoveFromDevice(tx *bolt.Tx) error {
 	// Save device
 	err = device.Save(tx)
 	if err != nil {
-		logger.Err(err)
+		opLogger.Err(err)
 		return err
 	}
 
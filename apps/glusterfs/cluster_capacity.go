@@ -0,0 +1,151 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"github.com/boltdb/bolt"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// capacityVolumeTypes are the kinds of volumes for which the
+// capacity of a cluster is reported
+var capacityVolumeTypes = []api.VolumeCapacity{
+	api.VolumeCapacity{
+		Name: "replica3",
+		Durability: api.VolumeDurabilityInfo{
+			Type:      api.DurabilityReplicate,
+			Replicate: api.ReplicaDurability{Replica: 3},
+		},
+	},
+	api.VolumeCapacity{
+		Name: "arbiter",
+		Durability: api.VolumeDurabilityInfo{
+			Type:      api.DurabilityReplicate,
+			Replicate: api.ReplicaDurability{Replica: 3},
+		},
+		Arbiter: true,
+	},
+	api.VolumeCapacity{
+		Name: "disperse4+2",
+		Durability: api.VolumeDurabilityInfo{
+			Type:     api.DurabilityEC,
+			Disperse: api.DisperseDurability{Data: 4, Redundancy: 2},
+		},
+	},
+}
+
+// ClusterCapacity returns the raw storage of the cluster and the size
+// of the largest volume of each type that could be created in it now.
+// The sizes are found by running the placer on the devices of the
+// cluster without saving any of the bricks, so they take the same
+// limits into account as a volume create request would.
+func ClusterCapacity(db wdb.RODB, clusterId string,
+	snapFactor float32) (*api.ClusterCapacityResponse, error) {
+
+	capacity := &api.ClusterCapacityResponse{
+		Id:             clusterId,
+		SnapshotFactor: snapFactor,
+		Volumes:        []api.VolumeCapacity{},
+	}
+
+	err := db.View(func(tx *bolt.Tx) error {
+		cluster, err := NewClusterEntryFromId(tx, clusterId)
+		if err != nil {
+			return err
+		}
+		for _, nodeId := range cluster.Info.Nodes {
+			node, err := NewNodeEntryFromId(tx, nodeId)
+			if err != nil {
+				return err
+			}
+			for _, deviceId := range node.Devices {
+				device, err := NewDeviceEntryFromId(tx, deviceId)
+				if err != nil {
+					return err
+				}
+				capacity.Storage.Total += device.Info.Storage.Total
+				capacity.Storage.Free += device.Info.Storage.Free
+				capacity.Storage.Used += device.Info.Storage.Used
+			}
+		}
+
+		// overcommitted clusters may hold volumes larger than the
+		// free space of their devices
+		limit := int(capacity.Storage.Free / GB)
+		if r := cluster.Info.OvercommitRatio; r > 1 {
+			limit = int(float64(limit) * r)
+		}
+
+		for _, vc := range capacityVolumeTypes {
+			req := &api.VolumeCreateRequest{}
+			req.Durability = vc.Durability
+			if snapFactor > 1 {
+				req.Snapshot.Enable = true
+				req.Snapshot.Factor = snapFactor
+			}
+			if vc.Arbiter {
+				req.GlusterVolumeOptions = []string{
+					HEKETI_ARBITER_KEY + " true"}
+			}
+			vc.MaxSize, err = maxVolumeSize(tx, clusterId, req, limit)
+			if err != nil {
+				return err
+			}
+			capacity.Volumes = append(capacity.Volumes, vc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return capacity, nil
+}
+
+// maxVolumeSize searches for the largest size, up to limit, of a
+// volume created from req which can be placed in the cluster.
+func maxVolumeSize(tx *bolt.Tx, clusterId string,
+	req *api.VolumeCreateRequest, limit int) (int, error) {
+
+	// a volume of size lo can always be placed, one of size hi never
+	lo, hi := 0, limit+1
+	for hi-lo > 1 {
+		size := lo + (hi-lo)/2
+		req.Size = size
+		ok, err := canPlaceVolume(tx, clusterId, req)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			lo = size
+		} else {
+			hi = size
+		}
+	}
+	return lo, nil
+}
+
+// canPlaceVolume returns true if the bricks for a volume created from
+// req can be placed in the cluster.
+func canPlaceVolume(tx *bolt.Tx, clusterId string,
+	req *api.VolumeCreateRequest) (bool, error) {
+
+	v := NewVolumeEntryFromRequest(req)
+	_, _, err := v.simulateAllocBricks(tx, clusterId)
+	switch err {
+	case nil:
+		return true, nil
+	case ErrNoSpace, ErrMaxBricks, ErrMinimumBrickSize:
+		return false, nil
+	default:
+		return false, err
+	}
+}
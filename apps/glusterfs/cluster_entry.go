@@ -52,6 +52,7 @@ func NewClusterEntryFromRequest(req *api.ClusterCreateRequest) *ClusterEntry {
 	entry.Info.Id = utils.GenUUID()
 	entry.Info.Block = req.Block
 	entry.Info.File = req.File
+	entry.Info.Tags = copyTags(req.Tags)
 
 	return entry
 }
@@ -87,7 +88,7 @@ func (c *ClusterEntry) Delete(tx *bolt.Tx) error {
 
 	// Check if the cluster still has nodes or volumes
 	if len(c.Info.Nodes) > 0 || len(c.Info.Volumes) > 0 {
-		logger.Warning(c.ConflictString())
+		opLogger.Warning(c.ConflictString())
 		return ErrConflict
 	}
 
@@ -98,6 +99,7 @@ func (c *ClusterEntry) NewClusterInfoResponse(tx *bolt.Tx) (*api.ClusterInfoResp
 
 	info := &api.ClusterInfoResponse{}
 	*info = c.Info
+	info.Tags = copyTags(c.Info.Tags)
 
 	return info, nil
 }
@@ -229,3 +231,15 @@ func (c *ClusterEntry) DeleteBricksWithEmptyPath(tx *bolt.Tx) error {
 	}
 	return nil
 }
+
+func (c *ClusterEntry) AllTags() map[string]string {
+	if c.Info.Tags == nil {
+		return map[string]string{}
+	}
+	return c.Info.Tags
+}
+
+func (c *ClusterEntry) SetTags(t map[string]string) error {
+	c.Info.Tags = t
+	return nil
+}
@@ -29,10 +29,12 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 	blockvolEntryList := make(map[string]BlockVolumeEntry, 0)
 	dbattributeEntryList := make(map[string]DbAttributeEntry, 0)
 	pendingOpEntryList := make(map[string]PendingOperationEntry, 0)
+	deviceIdentityEntryList := make(map[string]DeviceIdentityEntry, 0)
+	geoReplicationEntryList := make(map[string]GeoReplicationEntry, 0)
 
 	err := db.View(func(tx *bolt.Tx) error {
 
-		logger.Debug("volume bucket")
+		dbLogger.Debug("volume bucket")
 
 		// Volume Bucket
 		volumes, err := VolumeList(tx)
@@ -41,7 +43,7 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 		}
 
 		for _, volume := range volumes {
-			logger.Debug("adding volume entry %v", volume)
+			dbLogger.Debug("adding volume entry %v", volume)
 			volEntry, err := NewVolumeEntryFromId(tx, volume)
 			if err != nil {
 				return err
@@ -50,14 +52,14 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 		}
 
 		// Brick Bucket
-		logger.Debug("brick bucket")
+		dbLogger.Debug("brick bucket")
 		bricks, err := BrickList(tx)
 		if err != nil {
 			return err
 		}
 
 		for _, brick := range bricks {
-			logger.Debug("adding brick entry %v", brick)
+			dbLogger.Debug("adding brick entry %v", brick)
 			brickEntry, err := NewBrickEntryFromId(tx, brick)
 			if err != nil {
 				return err
@@ -66,14 +68,14 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 		}
 
 		// Cluster Bucket
-		logger.Debug("cluster bucket")
+		dbLogger.Debug("cluster bucket")
 		clusters, err := ClusterList(tx)
 		if err != nil {
 			return err
 		}
 
 		for _, cluster := range clusters {
-			logger.Debug("adding cluster entry %v", cluster)
+			dbLogger.Debug("adding cluster entry %v", cluster)
 			clusterEntry, err := NewClusterEntryFromId(tx, cluster)
 			if err != nil {
 				return err
@@ -82,20 +84,20 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 		}
 
 		// Node Bucket
-		logger.Debug("node bucket")
+		dbLogger.Debug("node bucket")
 		nodes, err := NodeList(tx)
 		if err != nil {
 			return err
 		}
 
 		for _, node := range nodes {
-			logger.Debug("adding node entry %v", node)
+			dbLogger.Debug("adding node entry %v", node)
 			// Some entries are added for easy lookup of existing entries
 			// Refer to http://lists.gluster.org/pipermail/heketi-devel/2017-May/000107.html
 			// Don't output them to JSON. However, these entries must be created when
 			// importing nodes into db from JSON.
 			if strings.HasPrefix(node, "MANAGE") || strings.HasPrefix(node, "STORAGE") {
-				logger.Debug("ignoring registry key %v", node)
+				dbLogger.Debug("ignoring registry key %v", node)
 			} else {
 				nodeEntry, err := NewNodeEntryFromId(tx, node)
 				if err != nil {
@@ -106,20 +108,20 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 		}
 
 		// Device Bucket
-		logger.Debug("device bucket")
+		dbLogger.Debug("device bucket")
 		devices, err := DeviceList(tx)
 		if err != nil {
 			return err
 		}
 
 		for _, device := range devices {
-			logger.Debug("adding device entry %v", device)
+			dbLogger.Debug("adding device entry %v", device)
 			// Some entries are added for easy lookup of existing entries
 			// Refer to http://lists.gluster.org/pipermail/heketi-devel/2017-May/000107.html
 			// Don't output them to JSON. However, these entries must be created when
 			// importing devices into db from JSON.
 			if strings.HasPrefix(device, "DEVICE") {
-				logger.Debug("ignoring registry key %v", device)
+				dbLogger.Debug("ignoring registry key %v", device)
 			} else {
 				deviceEntry, err := NewDeviceEntryFromId(tx, device)
 				if err != nil {
@@ -129,18 +131,38 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 			}
 		}
 
+		if b := tx.Bucket([]byte(BOLTDB_BUCKET_DEVICE_IDENTITY)); b == nil {
+			dbLogger.Warning("unable to find device identity bucket... skipping")
+		} else {
+			// Device Identity Bucket
+			dbLogger.Debug("device identity bucket")
+			identities, err := DeviceIdentityList(tx)
+			if err != nil {
+				return err
+			}
+
+			for _, identity := range identities {
+				dbLogger.Debug("adding device identity entry %v", identity)
+				identityEntry, err := NewDeviceIdentityEntryFromId(tx, identity)
+				if err != nil {
+					return err
+				}
+				deviceIdentityEntryList[identityEntry.DeviceId] = *identityEntry
+			}
+		}
+
 		if b := tx.Bucket([]byte(BOLTDB_BUCKET_BLOCKVOLUME)); b == nil {
-			logger.Warning("unable to find block volume bucket... skipping")
+			dbLogger.Warning("unable to find block volume bucket... skipping")
 		} else {
 			// BlockVolume Bucket
-			logger.Debug("blockvolume bucket")
+			dbLogger.Debug("blockvolume bucket")
 			blockvolumes, err := BlockVolumeList(tx)
 			if err != nil {
 				return err
 			}
 
 			for _, blockvolume := range blockvolumes {
-				logger.Debug("adding blockvolume entry %v", blockvolume)
+				dbLogger.Debug("adding blockvolume entry %v", blockvolume)
 				blockvolEntry, err := NewBlockVolumeEntryFromId(tx, blockvolume)
 				if err != nil {
 					return err
@@ -149,10 +171,30 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 			}
 		}
 
+		if b := tx.Bucket([]byte(BOLTDB_BUCKET_GEOREPLICATION)); b == nil {
+			dbLogger.Warning("unable to find geo-replication bucket... skipping")
+		} else {
+			// GeoReplication Bucket
+			dbLogger.Debug("geo-replication bucket")
+			sessions, err := GeoReplicationList(tx)
+			if err != nil {
+				return err
+			}
+
+			for _, session := range sessions {
+				dbLogger.Debug("adding geo-replication entry %v", session)
+				sessionEntry, err := NewGeoReplicationEntryFromId(tx, session)
+				if err != nil {
+					return err
+				}
+				geoReplicationEntryList[sessionEntry.Info.Id] = *sessionEntry
+			}
+		}
+
 		has_pendingops := false
 
 		if b := tx.Bucket([]byte(BOLTDB_BUCKET_DBATTRIBUTE)); b == nil {
-			logger.Warning("unable to find dbattribute bucket... skipping")
+			dbLogger.Warning("unable to find dbattribute bucket... skipping")
 		} else {
 			// DbAttributes Bucket
 			dbattributes, err := DbAttributeList(tx)
@@ -161,7 +203,7 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 			}
 
 			for _, dbattribute := range dbattributes {
-				logger.Debug("adding dbattribute entry %v", dbattribute)
+				dbLogger.Debug("adding dbattribute entry %v", dbattribute)
 				dbattributeEntry, err := NewDbAttributeEntryFromKey(tx, dbattribute)
 				if err != nil {
 					return err
@@ -202,6 +244,8 @@ func dbDumpInternal(db *bolt.DB) (Db, error) {
 	dump.BlockVolumes = blockvolEntryList
 	dump.DbAttributes = dbattributeEntryList
 	dump.PendingOperations = pendingOpEntryList
+	dump.DeviceIdentities = deviceIdentityEntryList
+	dump.GeoReplications = geoReplicationEntryList
 
 	return dump, nil
 }
@@ -270,20 +314,20 @@ func DbCreate(jsonfile string, dbfile string) error {
 		return initializeBuckets(tx)
 	})
 	if err != nil {
-		logger.Err(err)
+		dbLogger.Err(err)
 		return nil
 	}
 
 	err = dbhandle.Update(func(tx *bolt.Tx) error {
 		for _, cluster := range dump.Clusters {
-			logger.Debug("adding cluster entry %v", cluster.Info.Id)
+			dbLogger.Debug("adding cluster entry %v", cluster.Info.Id)
 			err := cluster.Save(tx)
 			if err != nil {
 				return fmt.Errorf("Could not save cluster bucket: %v", err.Error())
 			}
 		}
 		for _, volume := range dump.Volumes {
-			logger.Debug("adding volume entry %v", volume.Info.Id)
+			dbLogger.Debug("adding volume entry %v", volume.Info.Id)
 			// When serializing to JSON we skipped volume.Durability
 			// Hence, while creating volume entry, we populate it
 			durability := volume.Info.Durability.Type
@@ -310,52 +354,67 @@ func DbCreate(jsonfile string, dbfile string) error {
 			}
 		}
 		for _, brick := range dump.Bricks {
-			logger.Debug("adding brick entry %v", brick.Info.Id)
+			dbLogger.Debug("adding brick entry %v", brick.Info.Id)
 			err := brick.Save(tx)
 			if err != nil {
 				return fmt.Errorf("Could not save brick bucket: %v", err.Error())
 			}
 		}
 		for _, node := range dump.Nodes {
-			logger.Debug("adding node entry %v", node.Info.Id)
+			dbLogger.Debug("adding node entry %v", node.Info.Id)
 			err := node.Save(tx)
 			if err != nil {
 				return fmt.Errorf("Could not save node bucket: %v", err.Error())
 			}
-			logger.Debug("registering node entry %v", node.Info.Id)
+			dbLogger.Debug("registering node entry %v", node.Info.Id)
 			err = node.Register(tx)
 			if err != nil {
 				return fmt.Errorf("Could not register node: %v", err.Error())
 			}
 		}
+		// Identities are needed to register the devices
+		for _, identity := range dump.DeviceIdentities {
+			dbLogger.Debug("adding device identity entry %v", identity.DeviceId)
+			err := identity.Save(tx)
+			if err != nil {
+				return fmt.Errorf("Could not save device identity bucket: %v", err.Error())
+			}
+		}
 		for _, device := range dump.Devices {
-			logger.Debug("adding device entry %v", device.Info.Id)
+			dbLogger.Debug("adding device entry %v", device.Info.Id)
 			err := device.Save(tx)
 			if err != nil {
 				return fmt.Errorf("Could not save device bucket: %v", err.Error())
 			}
-			logger.Debug("registering device entry %v", device.Info.Id)
+			dbLogger.Debug("registering device entry %v", device.Info.Id)
 			err = device.Register(tx)
 			if err != nil {
 				return fmt.Errorf("Could not register device: %v", err.Error())
 			}
 		}
 		for _, blockvolume := range dump.BlockVolumes {
-			logger.Debug("adding blockvolume entry %v", blockvolume.Info.Id)
+			dbLogger.Debug("adding blockvolume entry %v", blockvolume.Info.Id)
 			err := blockvolume.Save(tx)
 			if err != nil {
 				return fmt.Errorf("Could not save blockvolume bucket: %v", err.Error())
 			}
 		}
 		for _, dbattribute := range dump.DbAttributes {
-			logger.Debug("adding dbattribute entry %v", dbattribute.Key)
+			dbLogger.Debug("adding dbattribute entry %v", dbattribute.Key)
 			err := dbattribute.Save(tx)
 			if err != nil {
 				return fmt.Errorf("Could not save dbattribute bucket: %v", err.Error())
 			}
 		}
+		for _, session := range dump.GeoReplications {
+			dbLogger.Debug("adding geo-replication entry %v", session.Info.Id)
+			err := session.Save(tx)
+			if err != nil {
+				return fmt.Errorf("Could not save geo-replication bucket: %v", err.Error())
+			}
+		}
 		for _, pendingop := range dump.PendingOperations {
-			logger.Debug("adding pending operation entry %v", pendingop.Id)
+			dbLogger.Debug("adding pending operation entry %v", pendingop.Id)
 			err := pendingop.Save(tx)
 			if err != nil {
 				return fmt.Errorf("Could not save pending operation bucket: %v", err.Error())
@@ -363,7 +422,7 @@ func DbCreate(jsonfile string, dbfile string) error {
 		}
 		// always record a new generation id on db import as the db contents
 		// were no longer fully under heketi's control
-		logger.Debug("recording new DB generation ID")
+		dbLogger.Debug("recording new DB generation ID")
 		if err := recordNewDBGenerationID(tx); err != nil {
 			return fmt.Errorf("Could not record DB generation ID: %v", err.Error())
 		}
@@ -395,7 +454,7 @@ func DeleteBricksWithEmptyPath(db *bolt.DB, all bool, clusterIDs []string, nodeI
 
 	err := db.Update(func(tx *bolt.Tx) error {
 		if true == all {
-			logger.Debug("deleting all bricks with empty path")
+			dbLogger.Debug("deleting all bricks with empty path")
 			clusters, err := ClusterList(tx)
 			if err != nil {
 				return err
@@ -405,7 +464,7 @@ func DeleteBricksWithEmptyPath(db *bolt.DB, all bool, clusterIDs []string, nodeI
 				if err != nil {
 					return err
 				}
-				logger.Debug("deleting bricks with empty path in cluster %v", clusterEntry.Info.Id)
+				dbLogger.Debug("deleting bricks with empty path in cluster %v", clusterEntry.Info.Id)
 				err = clusterEntry.DeleteBricksWithEmptyPath(tx)
 				if err != nil {
 					return err
@@ -419,7 +478,7 @@ func DeleteBricksWithEmptyPath(db *bolt.DB, all bool, clusterIDs []string, nodeI
 			if err != nil {
 				return err
 			}
-			logger.Debug("deleting bricks with empty path in cluster %v from given list of clusters", clusterEntry.Info.Id)
+			dbLogger.Debug("deleting bricks with empty path in cluster %v from given list of clusters", clusterEntry.Info.Id)
 			err = clusterEntry.DeleteBricksWithEmptyPath(tx)
 			if err != nil {
 				return err
@@ -430,7 +489,7 @@ func DeleteBricksWithEmptyPath(db *bolt.DB, all bool, clusterIDs []string, nodeI
 			if err != nil {
 				return err
 			}
-			logger.Debug("deleting bricks with empty path in node %v from given list of nodes", nodeEntry.Info.Id)
+			dbLogger.Debug("deleting bricks with empty path in node %v from given list of nodes", nodeEntry.Info.Id)
 			err = nodeEntry.DeleteBricksWithEmptyPath(tx)
 			if err != nil {
 				return err
@@ -441,7 +500,7 @@ func DeleteBricksWithEmptyPath(db *bolt.DB, all bool, clusterIDs []string, nodeI
 			if err != nil {
 				return err
 			}
-			logger.Debug("deleting bricks with empty path in device %v from given list of devices", deviceEntry.Info.Id)
+			dbLogger.Debug("deleting bricks with empty path in device %v from given list of devices", deviceEntry.Info.Id)
 			err = deviceEntry.DeleteBricksWithEmptyPath(tx)
 			if err != nil {
 				return err
@@ -459,14 +518,14 @@ func deleteChangeOwnerEntry(tx *bolt.Tx, action PendingOperationAction, dryRun b
 	switch action.Change {
 
 	case OpAddBrick:
-		logger.Debug("Found a pending add brick change with id: %v", action.Id)
-		logger.Info("Deleting brick with id: %v", action.Id)
+		dbLogger.Debug("Found a pending add brick change with id: %v", action.Id)
+		dbLogger.Info("Deleting brick with id: %v", action.Id)
 		brickEntry, err := NewBrickEntryFromId(tx, action.Id)
 		if err != nil {
 			return err
 		}
-		logger.Info("brickentry %+v", brickEntry)
-		logger.Info("USER ACTION REQUIRED: cleanup brick or create brick(in case of expand op) with path:%v on node:%v", brickEntry.Info.Path, brickEntry.Info.NodeId)
+		dbLogger.Info("brickentry %+v", brickEntry)
+		dbLogger.Info("USER ACTION REQUIRED: cleanup brick or create brick(in case of expand op) with path:%v on node:%v", brickEntry.Info.Path, brickEntry.Info.NodeId)
 		if !dryRun {
 			err = brickEntry.Delete(tx)
 			if err != nil {
@@ -474,14 +533,14 @@ func deleteChangeOwnerEntry(tx *bolt.Tx, action PendingOperationAction, dryRun b
 			}
 		}
 	case OpDeleteBrick:
-		logger.Debug("Found a pending delete brick change with id: %v", action.Id)
-		logger.Info("Deleting brick with id: %v", action.Id)
+		dbLogger.Debug("Found a pending delete brick change with id: %v", action.Id)
+		dbLogger.Info("Deleting brick with id: %v", action.Id)
 		brickEntry, err := NewBrickEntryFromId(tx, action.Id)
 		if err != nil {
 			return err
 		}
-		logger.Info("brickEntry %+v", brickEntry)
-		logger.Info("USER ACTION REQUIRED: cleanup brick with path:%v on node:%v", brickEntry.Info.Path, brickEntry.Info.NodeId)
+		dbLogger.Info("brickEntry %+v", brickEntry)
+		dbLogger.Info("USER ACTION REQUIRED: cleanup brick with path:%v on node:%v", brickEntry.Info.Path, brickEntry.Info.NodeId)
 		if !dryRun {
 			err = brickEntry.Delete(tx)
 			if err != nil {
@@ -489,14 +548,14 @@ func deleteChangeOwnerEntry(tx *bolt.Tx, action PendingOperationAction, dryRun b
 			}
 		}
 	case OpAddVolume:
-		logger.Debug("Found a pending add volume change with id: %v", action.Id)
-		logger.Info("Deleting volume with id: %v", action.Id)
+		dbLogger.Debug("Found a pending add volume change with id: %v", action.Id)
+		dbLogger.Info("Deleting volume with id: %v", action.Id)
 		volumeEntry, err := NewVolumeEntryFromId(tx, action.Id)
 		if err != nil {
 			return err
 		}
-		logger.Info("volumeEntry %+v", volumeEntry)
-		logger.Info("USER ACTION REQUIRED: cleanup volume:%v on cluster:%v", volumeEntry.Info.Name, volumeEntry.Info.Cluster)
+		dbLogger.Info("volumeEntry %+v", volumeEntry)
+		dbLogger.Info("USER ACTION REQUIRED: cleanup volume:%v on cluster:%v", volumeEntry.Info.Name, volumeEntry.Info.Cluster)
 		if !dryRun {
 			err = volumeEntry.Delete(tx)
 			if err != nil {
@@ -504,14 +563,14 @@ func deleteChangeOwnerEntry(tx *bolt.Tx, action PendingOperationAction, dryRun b
 			}
 		}
 	case OpDeleteVolume:
-		logger.Debug("Found a pending delete volume change with id: %v", action.Id)
-		logger.Info("Deleting volume with id: %v", action.Id)
+		dbLogger.Debug("Found a pending delete volume change with id: %v", action.Id)
+		dbLogger.Info("Deleting volume with id: %v", action.Id)
 		volumeEntry, err := NewVolumeEntryFromId(tx, action.Id)
 		if err != nil {
 			return err
 		}
-		logger.Info("volumeEntry %+v", volumeEntry)
-		logger.Info("USER ACTION REQUIRED: cleanup volume:%v on cluster:%v", volumeEntry.Info.Name, volumeEntry.Info.Cluster)
+		dbLogger.Info("volumeEntry %+v", volumeEntry)
+		dbLogger.Info("USER ACTION REQUIRED: cleanup volume:%v on cluster:%v", volumeEntry.Info.Name, volumeEntry.Info.Cluster)
 		if !dryRun {
 			err = volumeEntry.Delete(tx)
 			if err != nil {
@@ -519,28 +578,46 @@ func deleteChangeOwnerEntry(tx *bolt.Tx, action PendingOperationAction, dryRun b
 			}
 		}
 	case OpExpandVolume:
-		logger.Debug("Found a pending expand volume change with id: %v", action.Id)
+		dbLogger.Debug("Found a pending expand volume change with id: %v", action.Id)
 		volumeEntry, err := NewVolumeEntryFromId(tx, action.Id)
 		if err != nil {
 			return err
 		}
-		logger.Info("USER ACTION REQUIRED: complete volume expand operation on %v using bricks listed above", volumeEntry.Info.Name)
+		dbLogger.Info("USER ACTION REQUIRED: complete volume expand operation on %v using bricks listed above", volumeEntry.Info.Name)
 		if !dryRun {
-			logger.Info("volumeEntry %+v", volumeEntry)
+			dbLogger.Info("volumeEntry %+v", volumeEntry)
 			err = volumeEntry.Delete(tx)
 			if err != nil {
 				return err
 			}
 		}
+	case OpConvertVolume:
+		dbLogger.Debug("Found a pending convert volume change with id: %v", action.Id)
+		volumeEntry, err := NewVolumeEntryFromId(tx, action.Id)
+		if err != nil {
+			return err
+		}
+		dbLogger.Info("USER ACTION REQUIRED: check the replica count of volume %v on Gluster against the bricks listed above", volumeEntry.Info.Name)
+		if action.ConvertBricksRemoved() {
+			dbLogger.Info("The replaced bricks of volume %v have already been removed on Gluster", volumeEntry.Info.Name)
+		}
+	case OpMigrateVolume:
+		dbLogger.Debug("Found a pending migrate volume change with id: %v", action.Id)
+		volumeEntry, err := NewVolumeEntryFromId(tx, action.Id)
+		if err != nil {
+			return err
+		}
+		state, _ := action.MigrationState()
+		dbLogger.Info("Volume %v is being migrated from cluster %v, migration state: %v", volumeEntry.Info.Name, volumeEntry.Info.Cluster, state)
 	case OpAddBlockVolume:
-		logger.Debug("Found a pending add blockvolume change with id: %v", action.Id)
-		logger.Info("Deleting blockvolume with id: %v", action.Id)
+		dbLogger.Debug("Found a pending add blockvolume change with id: %v", action.Id)
+		dbLogger.Info("Deleting blockvolume with id: %v", action.Id)
 		blockVolumeEntry, err := NewBlockVolumeEntryFromId(tx, action.Id)
 		if err != nil {
 			return err
 		}
-		logger.Info("blockVolumeEntry %+v", blockVolumeEntry)
-		logger.Info("USER ACTION REQUIRED: cleanup blockvolume:%v on hostingvolume:%v", blockVolumeEntry.Info.Name, blockVolumeEntry.Info.BlockHostingVolume)
+		dbLogger.Info("blockVolumeEntry %+v", blockVolumeEntry)
+		dbLogger.Info("USER ACTION REQUIRED: cleanup blockvolume:%v on hostingvolume:%v", blockVolumeEntry.Info.Name, blockVolumeEntry.Info.BlockHostingVolume)
 		if !dryRun {
 			err = blockVolumeEntry.Delete(tx)
 			if err != nil {
@@ -548,14 +625,14 @@ func deleteChangeOwnerEntry(tx *bolt.Tx, action PendingOperationAction, dryRun b
 			}
 		}
 	case OpDeleteBlockVolume:
-		logger.Debug("Found a pending delete blockvolume change with id: %v", action.Id)
-		logger.Info("Deleting blockvolume with id: %v", action.Id)
+		dbLogger.Debug("Found a pending delete blockvolume change with id: %v", action.Id)
+		dbLogger.Info("Deleting blockvolume with id: %v", action.Id)
 		blockVolumeEntry, err := NewBlockVolumeEntryFromId(tx, action.Id)
 		if err != nil {
 			return err
 		}
-		logger.Info("blockVolumeEntry %+v", blockVolumeEntry)
-		logger.Info("USER ACTION REQUIRED: cleanup blockvolume:%v on hostingvolume:%v", blockVolumeEntry.Info.Name, blockVolumeEntry.Info.BlockHostingVolume)
+		dbLogger.Info("blockVolumeEntry %+v", blockVolumeEntry)
+		dbLogger.Info("USER ACTION REQUIRED: cleanup blockvolume:%v on hostingvolume:%v", blockVolumeEntry.Info.Name, blockVolumeEntry.Info.BlockHostingVolume)
 		if !dryRun {
 			err = blockVolumeEntry.Delete(tx)
 			if err != nil {
@@ -563,14 +640,14 @@ func deleteChangeOwnerEntry(tx *bolt.Tx, action PendingOperationAction, dryRun b
 			}
 		}
 	case OpRemoveDevice:
-		logger.Debug("Found a pending remove device change with id: %v", action.Id)
-		logger.Info("Deleting device with id: %v", action.Id)
+		dbLogger.Debug("Found a pending remove device change with id: %v", action.Id)
+		dbLogger.Info("Deleting device with id: %v", action.Id)
 		deviceEntry, err := NewDeviceEntryFromId(tx, action.Id)
 		if err != nil {
 			return err
 		}
-		logger.Info("deviceEntry %+v", deviceEntry)
-		logger.Info("USER ACTION REQUIRED: cleanup device:%v on node:%v", deviceEntry.Info.Name, deviceEntry.NodeId)
+		dbLogger.Info("deviceEntry %+v", deviceEntry)
+		dbLogger.Info("USER ACTION REQUIRED: cleanup device:%v on node:%v", deviceEntry.Info.Name, deviceEntry.NodeId)
 		if !dryRun {
 			err = deviceEntry.Delete(tx)
 			if err != nil {
@@ -578,7 +655,7 @@ func deleteChangeOwnerEntry(tx *bolt.Tx, action PendingOperationAction, dryRun b
 			}
 		}
 	default:
-		logger.Debug("Not a known change type: %v", action.Change)
+		dbLogger.Debug("Not a known change type: %v", action.Change)
 	}
 	return nil
 }
@@ -587,25 +664,31 @@ func deleteChangeEntriesInOp(tx *bolt.Tx, pendingOpEntry *PendingOperationEntry,
 	switch pendingOpEntry.Type {
 
 	case OperationCreateVolume:
-		logger.Info("Found a pending volume create operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
+		dbLogger.Info("Found a pending volume create operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
 
 	case OperationDeleteVolume:
-		logger.Info("Found a pending volume delete operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
+		dbLogger.Info("Found a pending volume delete operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
 
 	case OperationExpandVolume:
-		logger.Info("Found a pending volume expand operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
+		dbLogger.Info("Found a pending volume expand operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
 
 	case OperationCreateBlockVolume:
-		logger.Info("Found a pending blockvolume create operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
+		dbLogger.Info("Found a pending blockvolume create operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
 
 	case OperationDeleteBlockVolume:
-		logger.Info("Found a pending blockvolume delete operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
+		dbLogger.Info("Found a pending blockvolume delete operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
 
 	case OperationRemoveDevice:
-		logger.Info("Found a pending device remove operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
+		dbLogger.Info("Found a pending device remove operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
+
+	case OperationConvertVolume:
+		dbLogger.Info("Found a pending volume convert operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
+
+	case OperationMigrateVolume:
+		dbLogger.Info("Found a pending volume migrate operation with id: %v and timestamp: %v", pendingOpEntry.Id, pendingOpEntry.Timestamp)
 
 	default:
-		logger.Debug("Not a known pending Operation type: %v", pendingOpEntry.Type)
+		dbLogger.Debug("Not a known pending Operation type: %v", pendingOpEntry.Type)
 	}
 	for _, action := range pendingOpEntry.Actions {
 		err := deleteChangeOwnerEntry(tx, action, dryRun)
@@ -621,13 +704,13 @@ func DeletePendingEntries(db *bolt.DB, dryRun bool, force bool) error {
 
 	err := db.Update(func(tx *bolt.Tx) error {
 		if b := tx.Bucket([]byte(BOLTDB_BUCKET_PENDING_OPS)); b == nil {
-			return logger.LogError("unable to find pending ops bucket... exiting")
+			return dbLogger.LogError("unable to find pending ops bucket... exiting")
 		}
 		var pendingOpsFoundCount int
 		var pendingOpsDeletedCount int
 
-		logger.Info("traversing through pending ops bucket to delete pending entries")
-		logger.Debug("pendingops bucket")
+		dbLogger.Info("traversing through pending ops bucket to delete pending entries")
+		dbLogger.Debug("pendingops bucket")
 		pendingops, err := PendingOperationList(tx)
 		if err != nil {
 			return err
@@ -638,12 +721,26 @@ func DeletePendingEntries(db *bolt.DB, dryRun bool, force bool) error {
 				return err
 			}
 			pendingOpsFoundCount++
-			logger.Info("\nPending Operation %v Start", pendingOpsFoundCount)
-			// Special case for expand volume operation
+			dbLogger.Info("\nPending Operation %v Start", pendingOpsFoundCount)
+			// Special case for expand and convert volume operations
 			// Always dry-run
 			if pendingOpEntry.Type == OperationExpandVolume {
-				logger.Info("USER ACTION REQUIRED: Found an expand volume operation, it won't be cleaned")
-				logger.Info("USER ACTION REQUIRED: Note the add brick action printed below and complete the action on Gluster if not completed already")
+				dbLogger.Info("USER ACTION REQUIRED: Found an expand volume operation, it won't be cleaned")
+				dbLogger.Info("USER ACTION REQUIRED: Note the add brick action printed below and complete the action on Gluster if not completed already")
+				err = deleteChangeEntriesInOp(tx, pendingOpEntry, true)
+				if err != nil {
+					return err
+				}
+			} else if pendingOpEntry.Type == OperationConvertVolume {
+				dbLogger.Info("USER ACTION REQUIRED: Found a convert volume operation, it won't be cleaned")
+				dbLogger.Info("USER ACTION REQUIRED: Note the brick actions printed below and compare them with the bricks of the volume on Gluster")
+				err = deleteChangeEntriesInOp(tx, pendingOpEntry, true)
+				if err != nil {
+					return err
+				}
+			} else if migrationCutOver(pendingOpEntry) {
+				dbLogger.Info("USER ACTION REQUIRED: Found a volume migration past its cutover, it won't be cleaned")
+				dbLogger.Info("USER ACTION REQUIRED: Start the server and confirm the cutover of the volume again to complete it")
 				err = deleteChangeEntriesInOp(tx, pendingOpEntry, true)
 				if err != nil {
 					return err
@@ -654,25 +751,28 @@ func DeletePendingEntries(db *bolt.DB, dryRun bool, force bool) error {
 					return err
 				}
 			}
-			// Again, skip deleting main op if it is expand volume
-			if !dryRun && pendingOpEntry.Type != OperationExpandVolume {
+			// Again, skip deleting main op if it is expand or convert volume
+			// or a migration that can only be completed
+			if !dryRun && pendingOpEntry.Type != OperationExpandVolume &&
+				pendingOpEntry.Type != OperationConvertVolume &&
+				!migrationCutOver(pendingOpEntry) {
 				err = pendingOpEntry.Delete(tx)
 				if err != nil {
 					return err
 				}
 				pendingOpsDeletedCount++
 			}
-			logger.Info("\nPending Operation %v End", pendingOpsFoundCount)
+			dbLogger.Info("\nPending Operation %v End", pendingOpsFoundCount)
 		}
-		logger.Info("Found %v pending entries and deleted %v", pendingOpsFoundCount, pendingOpsDeletedCount)
+		dbLogger.Info("Found %v pending entries and deleted %v", pendingOpsFoundCount, pendingOpsDeletedCount)
 
 		// Here onwards, we should not find any entry with pending id set if dry-run is not used
 		// If we do find any entries with pending ID, then it is case of db corruption
 		// Warn users if force flag is not set
 		// Clean the entries if force flag is set
 		if !dryRun {
-			logger.Info("traversing through other buckets to ensure no pending entries are left")
-			logger.Debug("volume bucket")
+			dbLogger.Info("traversing through other buckets to ensure no pending entries are left")
+			dbLogger.Debug("volume bucket")
 			volumes, err := VolumeList(tx)
 			if err != nil {
 				return err
@@ -684,9 +784,9 @@ func DeletePendingEntries(db *bolt.DB, dryRun bool, force bool) error {
 					return err
 				}
 				if volEntry.Pending.Id != "" {
-					logger.Info("found untracked pending volume entry %v, use force flag to delete it", volume)
+					dbLogger.Info("found untracked pending volume entry %v, use force flag to delete it", volume)
 					if force {
-						logger.Info("deleting untracked pending volume entry %v", volume)
+						dbLogger.Info("deleting untracked pending volume entry %v", volume)
 						err = volEntry.Delete(tx)
 						if err != nil {
 							return err
@@ -695,7 +795,7 @@ func DeletePendingEntries(db *bolt.DB, dryRun bool, force bool) error {
 				}
 			}
 
-			logger.Debug("brick bucket")
+			dbLogger.Debug("brick bucket")
 			bricks, err := BrickList(tx)
 			if err != nil {
 				return err
@@ -707,9 +807,9 @@ func DeletePendingEntries(db *bolt.DB, dryRun bool, force bool) error {
 					return err
 				}
 				if brickEntry.Pending.Id != "" {
-					logger.Info("found untracked pending brick entry %v, use force flag to delete it", brick)
+					dbLogger.Info("found untracked pending brick entry %v, use force flag to delete it", brick)
 					if force {
-						logger.Info("deleting untracked pending brick entry %v", brick)
+						dbLogger.Info("deleting untracked pending brick entry %v", brick)
 						err = brickEntry.Delete(tx)
 						if err != nil {
 							return err
@@ -719,7 +819,7 @@ func DeletePendingEntries(db *bolt.DB, dryRun bool, force bool) error {
 			}
 
 			// BlockVolume Bucket
-			logger.Debug("blockvolume bucket")
+			dbLogger.Debug("blockvolume bucket")
 			blockvolumes, err := BlockVolumeList(tx)
 			if err != nil {
 				return err
@@ -731,9 +831,9 @@ func DeletePendingEntries(db *bolt.DB, dryRun bool, force bool) error {
 					return err
 				}
 				if blockvolEntry.Pending.Id != "" {
-					logger.Info("found untracked pending blockvolume entry %v, use force flag to delete it", blockvolume)
+					dbLogger.Info("found untracked pending blockvolume entry %v, use force flag to delete it", blockvolume)
 					if force {
-						logger.Info("deleting untracked pending blockvolume entry %v", blockvolume)
+						dbLogger.Info("deleting untracked pending blockvolume entry %v", blockvolume)
 						err = blockvolEntry.Delete(tx)
 						if err != nil {
 							return err
@@ -29,59 +29,79 @@ type Db struct {
 	BlockVolumes      map[string]BlockVolumeEntry      `json:"blockvolumeentries"`
 	DbAttributes      map[string]DbAttributeEntry      `json:"dbattributeentries"`
 	PendingOperations map[string]PendingOperationEntry `json:"pendingoperations"`
+	DeviceIdentities  map[string]DeviceIdentityEntry   `json:"deviceidentityentries"`
+	GeoReplications   map[string]GeoReplicationEntry   `json:"georeplicationentries"`
 }
 
 func initializeBuckets(tx *bolt.Tx) error {
 	// Create Cluster Bucket
 	_, err := tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_CLUSTER))
 	if err != nil {
-		logger.LogError("Unable to create cluster bucket in DB")
+		dbLogger.LogError("Unable to create cluster bucket in DB")
 		return err
 	}
 
 	// Create Node Bucket
 	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_NODE))
 	if err != nil {
-		logger.LogError("Unable to create node bucket in DB")
+		dbLogger.LogError("Unable to create node bucket in DB")
 		return err
 	}
 
 	// Create Volume Bucket
 	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_VOLUME))
 	if err != nil {
-		logger.LogError("Unable to create volume bucket in DB")
+		dbLogger.LogError("Unable to create volume bucket in DB")
 		return err
 	}
 
 	// Create Device Bucket
 	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_DEVICE))
 	if err != nil {
-		logger.LogError("Unable to create device bucket in DB")
+		dbLogger.LogError("Unable to create device bucket in DB")
 		return err
 	}
 
 	// Create Brick Bucket
 	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_BRICK))
 	if err != nil {
-		logger.LogError("Unable to create brick bucket in DB")
+		dbLogger.LogError("Unable to create brick bucket in DB")
 		return err
 	}
 
 	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_BLOCKVOLUME))
 	if err != nil {
-		logger.LogError("Unable to create blockvolume bucket in DB")
+		dbLogger.LogError("Unable to create blockvolume bucket in DB")
 		return err
 	}
 
 	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_DBATTRIBUTE))
 	if err != nil {
-		logger.LogError("Unable to create dbattribute bucket in DB")
+		dbLogger.LogError("Unable to create dbattribute bucket in DB")
 		return err
 	}
 
 	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_PENDING_OPS))
 	if err != nil {
-		logger.LogError("Unable to create pending ops bucket in DB")
+		dbLogger.LogError("Unable to create pending ops bucket in DB")
+		return err
+	}
+
+	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_DEVICE_IDENTITY))
+	if err != nil {
+		dbLogger.LogError("Unable to create device identity bucket in DB")
+		return err
+	}
+
+	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_GEOREPLICATION))
+	if err != nil {
+		dbLogger.LogError("Unable to create geo-replication bucket in DB")
+		return err
+	}
+
+	_, err = tx.CreateBucketIfNotExists([]byte(BOLTDB_BUCKET_EVENTS))
+	if err != nil {
+		dbLogger.LogError("Unable to create events bucket in DB")
 		return err
 	}
 
@@ -94,43 +114,43 @@ func UpgradeDB(tx *bolt.Tx) error {
 
 	err := ClusterEntryUpgrade(tx)
 	if err != nil {
-		logger.LogError("Failed to upgrade db for cluster entries")
+		dbLogger.LogError("Failed to upgrade db for cluster entries")
 		return err
 	}
 
 	err = NodeEntryUpgrade(tx)
 	if err != nil {
-		logger.LogError("Failed to upgrade db for node entries")
+		dbLogger.LogError("Failed to upgrade db for node entries")
 		return err
 	}
 
 	err = VolumeEntryUpgrade(tx)
 	if err != nil {
-		logger.LogError("Failed to upgrade db for volume entries")
+		dbLogger.LogError("Failed to upgrade db for volume entries")
 		return err
 	}
 
 	err = DeviceEntryUpgrade(tx)
 	if err != nil {
-		logger.LogError("Failed to upgrade db for device entries")
+		dbLogger.LogError("Failed to upgrade db for device entries")
 		return err
 	}
 
 	err = BrickEntryUpgrade(tx)
 	if err != nil {
-		logger.LogError("Failed to upgrade db for brick entries: %v", err)
+		dbLogger.LogError("Failed to upgrade db for brick entries: %v", err)
 		return err
 	}
 
 	err = PendingOperationUpgrade(tx)
 	if err != nil {
-		logger.LogError("Failed to upgrade db for pending operations: %v", err)
+		dbLogger.LogError("Failed to upgrade db for pending operations: %v", err)
 		return err
 	}
 
 	err = upgradeDBGenerationID(tx)
 	if err != nil {
-		logger.LogError("Failed to record DB Generation ID: %v", err)
+		dbLogger.LogError("Failed to record DB Generation ID: %v", err)
 		return err
 	}
 
@@ -163,14 +183,14 @@ func OpenDB(dbfilename string, ReadOnly bool) (dbhandle *bolt.DB, err error) {
 	if ReadOnly {
 		dbhandle, err = bolt.Open(dbfilename, 0666, &bolt.Options{ReadOnly: true})
 		if err != nil {
-			logger.LogError("Unable to open database in read only mode: %v", err)
+			dbLogger.LogError("Unable to open database in read only mode: %v", err)
 		}
 		return dbhandle, err
 	}
 
 	dbhandle, err = bolt.Open(dbfilename, 0600, &bolt.Options{Timeout: 3 * time.Second})
 	if err != nil {
-		logger.LogError("Unable to open database: %v", err)
+		dbLogger.LogError("Unable to open database: %v", err)
 	}
 	return dbhandle, err
 
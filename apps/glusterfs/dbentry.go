@@ -11,6 +11,7 @@ package glusterfs
 
 import (
 	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/lpabon/godbc"
 )
 
@@ -30,7 +31,7 @@ func EntryRegister(tx *bolt.Tx, entry DbEntry, key string, value []byte) ([]byte
 	b := tx.Bucket([]byte(entry.BucketName()))
 	if b == nil {
 		err := ErrDbAccess
-		logger.Err(err)
+		dbLogger.Err(err)
 		return nil, err
 	}
 
@@ -43,7 +44,7 @@ func EntryRegister(tx *bolt.Tx, entry DbEntry, key string, value []byte) ([]byte
 	// Key does not exist.  We can save it
 	err := b.Put([]byte(key), value)
 	if err != nil {
-		logger.Err(err)
+		dbLogger.Err(err)
 		return nil, err
 	}
 
@@ -78,25 +79,30 @@ func EntrySave(tx *bolt.Tx, entry DbEntry, key string) error {
 	b := tx.Bucket([]byte(entry.BucketName()))
 	if b == nil {
 		err := ErrDbAccess
-		logger.Err(err)
+		dbLogger.Err(err)
 		return err
 	}
 
 	// Save device entry to db
 	buffer, err := entry.Marshal()
 	if err != nil {
-		logger.Err(err)
+		dbLogger.Err(err)
 		return err
 	}
 
+	event := api.EventUpdate
+	if b.Get([]byte(key)) == nil {
+		event = api.EventCreate
+	}
+
 	// Save data using the id as the key
 	err = b.Put([]byte(key), buffer)
 	if err != nil {
-		logger.Err(err)
+		dbLogger.Err(err)
 		return err
 	}
 
-	return nil
+	return recordEntryEvent(tx, entry, key, event)
 }
 
 func EntryDelete(tx *bolt.Tx, entry DbEntry, key string) error {
@@ -107,18 +113,18 @@ func EntryDelete(tx *bolt.Tx, entry DbEntry, key string) error {
 	b := tx.Bucket([]byte(entry.BucketName()))
 	if b == nil {
 		err := ErrDbAccess
-		logger.Err(err)
+		dbLogger.Err(err)
 		return err
 	}
 
 	// Delete key
 	err := b.Delete([]byte(key))
 	if err != nil {
-		logger.LogError("Unable to delete key [%v] in db: %v", key, err.Error())
+		dbLogger.LogError("Unable to delete key [%v] in db: %v", key, err.Error())
 		return err
 	}
 
-	return nil
+	return recordEntryEvent(tx, entry, key, api.EventDelete)
 }
 
 func EntryLoad(tx *bolt.Tx, entry DbEntry, key string) error {
@@ -128,7 +134,7 @@ func EntryLoad(tx *bolt.Tx, entry DbEntry, key string) error {
 	b := tx.Bucket([]byte(entry.BucketName()))
 	if b == nil {
 		err := ErrDbAccess
-		logger.Err(err)
+		dbLogger.Err(err)
 		return err
 	}
 
@@ -139,7 +145,7 @@ func EntryLoad(tx *bolt.Tx, entry DbEntry, key string) error {
 
 	err := entry.Unmarshal(val)
 	if err != nil {
-		logger.Err(err)
+		dbLogger.Err(err)
 		return err
 	}
 
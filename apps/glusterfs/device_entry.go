@@ -34,6 +34,20 @@ type DeviceEntry struct {
 	Bricks     sort.StringSlice
 	NodeId     string
 	ExtentSize uint64
+
+	// set by the device source from the cluster of the device,
+	// it is not saved
+	overcommit deviceOvercommit
+}
+
+// deviceOvercommit holds the overcommit settings of the cluster of
+// a device and how much of the device is actually used by data.
+type deviceOvercommit struct {
+	ratio         float64
+	physicalLimit float64
+	// data written to the thin pools of the device in KB
+	physicalUsed  uint64
+	physicalKnown bool
 }
 
 func DeviceList(tx *bolt.Tx) ([]string, error) {
@@ -84,12 +98,68 @@ func (d *DeviceEntry) registerKey() string {
 	return "DEVICE" + d.NodeId + d.Info.Name
 }
 
+// identityKeys returns the registration keys of the persistent
+// identifiers of the disk. WWNs and PV UUIDs are unique across
+// nodes, which also catches the same LUN being added on two nodes.
+func (d *DeviceEntry) identityKeys(id api.DeviceIdentity) []string {
+	keys := []string{}
+	if id.ById != "" && id.ById != d.Info.Name {
+		keys = append(keys, "DEVICE"+d.NodeId+id.ById)
+	}
+	if id.Wwn != "" {
+		keys = append(keys, "DEVICEWWN"+id.Wwn)
+	}
+	if id.PvUuid != "" {
+		keys = append(keys, "DEVICEPV"+id.PvUuid)
+	}
+	return keys
+}
+
 func (d *DeviceEntry) Register(tx *bolt.Tx) error {
 	godbc.Require(tx != nil)
 
+	conflictId, err := d.register(tx, d.registerKey())
+	if err != nil {
+		return err
+	} else if conflictId != "" {
+		return fmt.Errorf("Device %v is already used on node %v by device %v",
+			d.Info.Name,
+			d.NodeId,
+			conflictId)
+	}
+
+	id, err := d.Identity(tx)
+	if err != nil {
+		return err
+	}
+	return d.registerIdentity(tx, id)
+}
+
+// registerIdentity registers the persistent identifiers of the disk
+// so the same disk can not be added again under a different path.
+// Identifiers already registered to this device are accepted.
+func (d *DeviceEntry) registerIdentity(tx *bolt.Tx, id api.DeviceIdentity) error {
+	for _, key := range d.identityKeys(id) {
+		conflictId, err := d.register(tx, key)
+		if err != nil {
+			return err
+		} else if conflictId != "" && conflictId != d.Id() {
+			return fmt.Errorf("Disk behind device %v on node %v is already used by device %v",
+				d.Info.Name,
+				d.NodeId,
+				conflictId)
+		}
+	}
+
+	return nil
+}
+
+// register saves the key for the device. If the key belongs to
+// another existing device the id of that device is returned.
+func (d *DeviceEntry) register(tx *bolt.Tx, key string) (string, error) {
 	val, err := EntryRegister(tx,
 		d,
-		d.registerKey(),
+		key,
 		[]byte(d.Id()))
 	if err == ErrKeyExists {
 
@@ -101,21 +171,18 @@ func (d *DeviceEntry) Register(tx *bolt.Tx) error {
 		if err == ErrNotFound {
 			// (stale) There is actually no conflict, we can allow
 			// the registration
-			return nil
+			return "", nil
 		} else if err != nil {
-			return logger.Err(err)
+			return "", opLogger.Err(err)
 		}
 
-		return fmt.Errorf("Device %v is already used on node %v by device %v",
-			d.Info.Name,
-			d.NodeId,
-			conflictId)
+		return conflictId, nil
 
 	} else if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return "", nil
 }
 
 func (d *DeviceEntry) Deregister(tx *bolt.Tx) error {
@@ -126,6 +193,123 @@ func (d *DeviceEntry) Deregister(tx *bolt.Tx) error {
 		return err
 	}
 
+	identity, err := NewDeviceIdentityEntryFromId(tx, d.Info.Id)
+	if err == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Identifiers may have been registered by another device
+	// first, leave those alone
+	b := tx.Bucket([]byte(d.BucketName()))
+	if b == nil {
+		return ErrDbAccess
+	}
+	for _, key := range d.identityKeys(identity.Info) {
+		if val := b.Get([]byte(key)); val != nil && string(val) != d.Id() {
+			continue
+		}
+		err := EntryDelete(tx, d, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return identity.Delete(tx)
+}
+
+// Identity returns the recorded identifiers of the disk backing the
+// device. The result is empty for devices added before identifiers
+// were recorded.
+func (d *DeviceEntry) Identity(tx *bolt.Tx) (api.DeviceIdentity, error) {
+	godbc.Require(tx != nil)
+
+	if tx.Bucket([]byte(BOLTDB_BUCKET_DEVICE_IDENTITY)) == nil {
+		return api.DeviceIdentity{}, nil
+	}
+
+	entry, err := NewDeviceIdentityEntryFromId(tx, d.Info.Id)
+	if err == ErrNotFound {
+		return api.DeviceIdentity{}, nil
+	} else if err != nil {
+		return api.DeviceIdentity{}, err
+	}
+	return entry.Info, nil
+}
+
+// SetIdentity records and registers the persistent identifiers of
+// the disk as reported by the executor.
+func (d *DeviceEntry) SetIdentity(tx *bolt.Tx, info *executors.DeviceInfo) error {
+	godbc.Require(tx != nil)
+
+	entry := NewDeviceIdentityEntryFromInfo(d.Info.Id, info)
+	if entry.Info == (api.DeviceIdentity{}) {
+		return nil
+	}
+
+	var err error
+	entry.Info, err = d.ProbedIdentity(tx, info)
+	if err != nil {
+		return err
+	}
+
+	err = d.registerIdentity(tx, entry.Info)
+	if err != nil {
+		return err
+	}
+	return entry.Save(tx)
+}
+
+// ProbedIdentity returns the identifiers of the disk as reported by
+// the executor, keeping the recorded identifiers the executor did
+// not report.
+func (d *DeviceEntry) ProbedIdentity(tx *bolt.Tx,
+	info *executors.DeviceInfo) (api.DeviceIdentity, error) {
+
+	id, err := d.Identity(tx)
+	if err != nil {
+		return api.DeviceIdentity{}, err
+	}
+	probed := NewDeviceIdentityEntryFromInfo(d.Info.Id, info).Info
+	for _, f := range []struct{ recorded, current *string }{
+		{&id.Wwn, &probed.Wwn},
+		{&id.Serial, &probed.Serial},
+		{&id.PvUuid, &probed.PvUuid},
+		{&id.ById, &probed.ById},
+	} {
+		if *f.current != "" {
+			*f.recorded = *f.current
+		}
+	}
+	return id, nil
+}
+
+// CheckIdentity returns an error if the disk currently behind the
+// device path is not the disk which was added to heketi. Only the
+// identifiers both recorded when the device was added and reported
+// now are compared.
+func (d *DeviceEntry) CheckIdentity(tx *bolt.Tx, info *executors.DeviceInfo) error {
+	id, err := d.Identity(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range []struct {
+		name, recorded, current string
+	}{
+		{"WWN", id.Wwn, info.Wwn},
+		{"serial", id.Serial, info.Serial},
+		{"PV UUID", id.PvUuid, info.PvUuid},
+		{"by-id path", id.ById, info.ById},
+	} {
+		if c.recorded != "" && c.current != "" && c.recorded != c.current {
+			return opLogger.LogError("Device %v (%v) on node %v now refers "+
+				"to a different disk: %v was %v, found %v",
+				d.Info.Name, d.Info.Id, d.NodeId,
+				c.name, c.recorded, c.current)
+		}
+	}
 	return nil
 }
 
@@ -165,14 +349,14 @@ func (d *DeviceEntry) Delete(tx *bolt.Tx) error {
 
 	// Don't delete device unless it is in failed state
 	if d.State != api.EntryStateFailed {
-		return logger.LogError("device: %v is not in failed state", d.Info.Id)
+		return opLogger.LogError("device: %v is not in failed state", d.Info.Id)
 	}
 
 	// Check if the device still has bricks
 	// Ideally, if the device is in failed state it should have no bricks
 	// This is just for bricks with empty paths
 	if d.HasBricks() {
-		logger.LogError(d.ConflictString())
+		opLogger.LogError(d.ConflictString())
 		return ErrConflict
 	}
 
@@ -211,7 +395,7 @@ func (d *DeviceEntry) SetState(db wdb.DB,
 	case api.EntryStateFailed:
 		if err := d.Remove(db, e); err != nil {
 			if err == ErrNoReplacement {
-				return logger.LogError("Unable to delete device [%v] as no device was found to replace it", d.Id())
+				return opLogger.LogError("Unable to delete device [%v] as no device was found to replace it", d.Id())
 			}
 			return err
 		}
@@ -278,6 +462,12 @@ func (d *DeviceEntry) NewInfoResponse(tx *bolt.Tx) (*api.DeviceInfoResponse, err
 	info.Bricks = make([]api.BrickInfo, 0)
 	info.Tags = copyTags(d.Info.Tags)
 
+	identity, err := d.Identity(tx)
+	if err != nil {
+		return nil, err
+	}
+	info.Identity = identity
+
 	// Add each drive information
 	for _, id := range d.Bricks {
 		brick, err := NewBrickEntryFromId(tx, id)
@@ -344,10 +534,54 @@ func (d *DeviceEntry) StorageFree(amount uint64) {
 	d.Info.Storage.Used -= amount
 }
 
+// StorageCheck returns true if a brick reserving amount can be
+// placed on the device. Overcommitted devices also refuse bricks once
+// the data in their thin pools crosses the physical usage limit.
 func (d *DeviceEntry) StorageCheck(amount uint64) bool {
+	if d.physicalLimitReached() {
+		return false
+	}
 	return d.Info.Storage.Free > amount
 }
 
+// SetOvercommit applies the overcommit settings of a cluster and the
+// physical usage of the device, if known, to the device.
+func (d *DeviceEntry) SetOvercommit(o api.ClusterOvercommit,
+	physicalUsed uint64, physicalKnown bool) {
+
+	d.overcommit = deviceOvercommit{
+		ratio:         o.OvercommitRatio,
+		physicalLimit: o.PhysicalUsageLimit,
+		physicalUsed:  physicalUsed,
+		physicalKnown: physicalKnown,
+	}
+	if d.overcommit.physicalLimit == 0 {
+		d.overcommit.physicalLimit = api.ClusterDefaultPhysicalUsageLimit
+	}
+}
+
+func (d *DeviceEntry) overcommitted() bool {
+	return d.overcommit.ratio > 1
+}
+
+// physicalUsage returns the percent of the device used by data in
+// its thin pools. The bool is false if it is not known.
+func (d *DeviceEntry) physicalUsage() (float64, bool) {
+	if !d.overcommit.physicalKnown || d.Info.Storage.Total == 0 {
+		return 0, false
+	}
+	return 100 * float64(d.overcommit.physicalUsed) /
+		float64(d.Info.Storage.Total), true
+}
+
+func (d *DeviceEntry) physicalLimitReached() bool {
+	if !d.overcommitted() {
+		return false
+	}
+	p, ok := d.physicalUsage()
+	return ok && p >= d.overcommit.physicalLimit
+}
+
 func (d *DeviceEntry) SetExtentSize(amount uint64) {
 	d.ExtentSize = amount
 }
@@ -362,7 +596,7 @@ func (d *DeviceEntry) NewBrickEntry(amount uint64, snapFactor float64, gid int64
 
 	sn := d.SpaceNeeded(amount, snapFactor)
 
-	logger.Debug("device %v[%v] > required size [%v] ?",
+	opLogger.Debug("device %v[%v] > required size [%v] ?",
 		d.Id(),
 		d.Info.Storage.Free, sn.Total)
 	if !d.StorageCheck(sn.Total) {
@@ -383,10 +617,15 @@ type SpaceNeeded struct {
 }
 
 // SpaceNeeded returns the (estimated) space needed to add a brick
-// of the given size amount and snapFactor to this device.
+// of the given size amount and snapFactor to this device. On
+// overcommitted devices the thin pool is created smaller than the
+// brick by the overcommit ratio.
 func (d *DeviceEntry) SpaceNeeded(amount uint64, snapFactor float64) SpaceNeeded {
 	// Calculate thinpool size
 	tpsize := uint64(float64(amount) * snapFactor)
+	if d.overcommitted() {
+		tpsize = uint64(float64(tpsize) / d.overcommit.ratio)
+	}
 
 	// Align tpsize to extent
 	alignment := tpsize % d.ExtentSize
@@ -405,7 +644,7 @@ func (d *DeviceEntry) SpaceNeeded(amount uint64, snapFactor float64) SpaceNeeded
 
 	// Total required size
 	total := tpsize + metadataSize
-	logger.Debug("expected space needed for amount=%v snapFactor=%v : %v",
+	opLogger.Debug("expected space needed for amount=%v snapFactor=%v : %v",
 		amount, snapFactor, total)
 	return SpaceNeeded{tpsize, metadataSize, total}
 }
@@ -471,15 +710,15 @@ func (d *DeviceEntry) removeBricksFromDevice(db wdb.DB,
 		})
 		if err != nil {
 			if err == errBrickWithEmptyPath {
-				logger.Warning("Skipping brick with empty path, brickID: %v, volumeID: %v, error: %v", brickEntry.Info.Id, brickEntry.Info.VolumeId, err)
+				opLogger.Warning("Skipping brick with empty path, brickID: %v, volumeID: %v, error: %v", brickEntry.Info.Id, brickEntry.Info.VolumeId, err)
 				continue
 			}
 			return err
 		}
-		logger.Info("Replacing brick %v on device %v on node %v", brickEntry.Id(), d.Id(), d.NodeId)
+		opLogger.Info("Replacing brick %v on device %v on node %v", brickEntry.Id(), d.Id(), d.NodeId)
 		err = volumeEntry.replaceBrickInVolume(db, executor, brickEntry.Id())
 		if err != nil {
-			return logger.Err(fmt.Errorf("Failed to remove device, error: %v", err))
+			return opLogger.Err(fmt.Errorf("Failed to remove device, error: %v", err))
 		}
 	}
 	return nil
@@ -505,7 +744,7 @@ func PendingOperationsOnDevice(db wdb.RODB, deviceId string) (pdev bool, e error
 				return err
 			}
 			if b.Info.DeviceId == deviceId {
-				logger.Warning("Device %v used on pending brick %v in operation %v",
+				opLogger.Warning("Device %v used on pending brick %v in operation %v",
 					deviceId, brickId, opId)
 				pdev = true
 				return nil
@@ -516,7 +755,7 @@ func PendingOperationsOnDevice(db wdb.RODB, deviceId string) (pdev bool, e error
 			return err
 		}
 		if _, found := pdr[deviceId]; found {
-			logger.Warning(
+			opLogger.Warning(
 				"Device %v used in another pending device remove operation",
 				deviceId)
 			pdev = true
@@ -583,13 +822,13 @@ func (d *DeviceEntry) DeleteBricksWithEmptyPath(tx *bolt.Tx) error {
 	for _, brick := range bricksToDelete {
 		err := brick.Delete(tx)
 		if err != nil {
-			return logger.LogError("Unable to remove brick %v: %v", brick.Info.Id, err)
+			return opLogger.LogError("Unable to remove brick %v: %v", brick.Info.Id, err)
 		}
 		d.StorageFree(brick.TotalSize())
 		d.BrickDelete(brick.Info.Id)
 		err = d.Save(tx)
 		if err != nil {
-			logger.LogError("Unable to save device %v: %v", d.Info.Id, err)
+			opLogger.LogError("Unable to save device %v: %v", d.Info.Id, err)
 			return err
 		}
 	}
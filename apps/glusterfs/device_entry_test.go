@@ -1196,3 +1196,47 @@ func testDeviceRemoveSizeAccounting(t *testing.T, useArbiter bool) {
 		return nil
 	})
 }
+
+func TestDeviceEntryOvercommit(t *testing.T) {
+	d := NewDeviceEntry()
+	d.Info.Id = utils.GenUUID()
+	d.NodeId = utils.GenUUID()
+	d.StorageSet(100 * GB)
+
+	full := d.SpaceNeeded(30*GB, 1)
+
+	d.SetOvercommit(api.ClusterOvercommit{OvercommitRatio: 3}, 0, false)
+	tests.Assert(t, d.overcommit.physicalLimit ==
+		api.ClusterDefaultPhysicalUsageLimit,
+		"expected default limit, got:", d.overcommit.physicalLimit)
+	sn := d.SpaceNeeded(30*GB, 1)
+	tests.Assert(t, sn.TpSize == 10*GB,
+		"expected sn.TpSize == 10*GB, got:", sn.TpSize)
+	tests.Assert(t, sn.Total < full.Total,
+		"expected less space needed, got:", sn.Total, full.Total)
+
+	// three bricks of the size of the device fit
+	for i := 0; i < 3; i++ {
+		b := d.NewBrickEntry(30*GB, 1, 0, utils.GenUUID())
+		tests.Assert(t, b != nil, "expected brick", i)
+	}
+
+	// the physical usage is only checked when it is known
+	d.SetOvercommit(api.ClusterOvercommit{
+		OvercommitRatio:    3,
+		PhysicalUsageLimit: 50,
+	}, 40*GB, true)
+	tests.Assert(t, d.StorageCheck(GB), "expected storage check to pass")
+	d.SetOvercommit(api.ClusterOvercommit{
+		OvercommitRatio:    3,
+		PhysicalUsageLimit: 50,
+	}, 50*GB, true)
+	tests.Assert(t, !d.StorageCheck(GB), "expected storage check to fail")
+
+	// and not used without overcommit
+	d.SetOvercommit(api.ClusterOvercommit{}, 50*GB, true)
+	tests.Assert(t, d.StorageCheck(GB), "expected storage check to pass")
+	sn = d.SpaceNeeded(30*GB, 1)
+	tests.Assert(t, sn.Total == full.Total,
+		"expected full space needed, got:", sn.Total, full.Total)
+}
@@ -0,0 +1,288 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/heketi/heketi/executors"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+type DeviceHealthStatus struct {
+	DeviceId   string
+	NodeId     string
+	Host       string
+	Name       string
+	LastUpdate time.Time
+
+	// result of the most recent check, nil if the check failed
+	Health *executors.DeviceHealth
+	// error of the most recent check
+	Err error
+}
+
+// DeviceHealthCache periodically checks the volume groups, thin
+// pools and disks of all devices on online nodes and keeps the
+// most recent results.
+type DeviceHealthCache struct {
+	// tunables
+	StartInterval time.Duration
+	CheckInterval time.Duration
+	Expiration    time.Duration
+	// Thin pool data or metadata usage in percent at or above
+	// which a device is flagged
+	ThinPoolWarning float64
+	// Called when the health state of a device changes
+	OnStateChange func(s DeviceHealthStatus,
+		from, to api.DeviceHealthState, warnings []string)
+
+	db      wdb.RODB
+	exec    executors.Executor
+	devices map[string]*DeviceHealthStatus
+	lock    sync.RWMutex
+
+	// to stop the monitor
+	stop chan<- interface{}
+}
+
+func NewDeviceHealthCache(reftime, starttime uint32, db wdb.RODB, e executors.Executor) *DeviceHealthCache {
+	return &DeviceHealthCache{
+		db:              db,
+		exec:            e,
+		devices:         map[string]*DeviceHealthStatus{},
+		StartInterval:   time.Second * time.Duration(starttime),
+		CheckInterval:   time.Second * time.Duration(reftime),
+		Expiration:      time.Hour * 2,
+		ThinPoolWarning: 80,
+	}
+}
+
+// DeviceStatus returns a copy of the most recent health status of
+// the device. The bool is false if the device has not been checked.
+func (dc *DeviceHealthCache) DeviceStatus(deviceId string) (DeviceHealthStatus, bool) {
+	dc.lock.RLock()
+	defer dc.lock.RUnlock()
+	s, ok := dc.devices[deviceId]
+	if !ok {
+		return DeviceHealthStatus{}, false
+	}
+	return *s, true
+}
+
+func (dc *DeviceHealthCache) Refresh() error {
+	healthLogger.Info("Starting Device Health Status refresh")
+	sl, err := dc.toProbe()
+	if err != nil {
+		return err
+	}
+	for _, s := range sl {
+		dc.updateDevice(s)
+	}
+	dc.cleanOld()
+	return nil
+}
+
+func (dc *DeviceHealthCache) updateDevice(s *DeviceHealthStatus) {
+	// probe outside of the lock, checks may take a while
+	health, err := dc.exec.DeviceHealth(s.Host, s.Name, s.DeviceId)
+	s.Health = health
+	s.Err = err
+	s.LastUpdate = healthNow()
+
+	dc.lock.Lock()
+	prev, found := dc.devices[s.DeviceId]
+	dc.devices[s.DeviceId] = s
+
+	// only log when the state changes to avoid flooding the log
+	// on every check
+	prevState := api.DeviceHealthUnknown
+	if found {
+		prevState, _ = dc.flag(prev)
+	}
+	state, warnings := dc.flag(s)
+	status := *s
+	dc.lock.Unlock()
+
+	if state != prevState {
+		healthLogger.Warning("Device %v on node %v changed health from %v to %v: %v",
+			s.DeviceId, s.NodeId, prevState, state, warnings)
+		if dc.OnStateChange != nil {
+			dc.OnStateChange(status, prevState, state, warnings)
+		}
+	}
+}
+
+// flag determines the health state of the device from the status
+// of its most recent check.
+func (dc *DeviceHealthCache) flag(
+	s *DeviceHealthStatus) (api.DeviceHealthState, []string) {
+
+	if s.Err != nil {
+		return api.DeviceHealthUnknown, []string{
+			fmt.Sprintf("check failed: %v", s.Err)}
+	}
+
+	state := api.DeviceHealthOk
+	warnings := []string{}
+	if s.Health.SmartPassed != nil && !*s.Health.SmartPassed {
+		state = api.DeviceHealthFailed
+		warnings = append(warnings, "SMART self-assessment failed")
+	}
+	for _, tp := range s.Health.ThinPools {
+		if tp.DataPercent >= dc.ThinPoolWarning {
+			warnings = append(warnings, fmt.Sprintf(
+				"thin pool %v data usage at %.2f%%", tp.Name, tp.DataPercent))
+		}
+		if tp.MetadataPercent >= dc.ThinPoolWarning {
+			warnings = append(warnings, fmt.Sprintf(
+				"thin pool %v metadata usage at %.2f%%", tp.Name, tp.MetadataPercent))
+		}
+	}
+	if state == api.DeviceHealthOk && len(warnings) > 0 {
+		state = api.DeviceHealthWarning
+	}
+	return state, warnings
+}
+
+// Health returns the api representation of the most recent health
+// status of the device.
+func (dc *DeviceHealthCache) Health(deviceId string) api.DeviceHealth {
+	s, ok := dc.DeviceStatus(deviceId)
+	if !ok {
+		return api.DeviceHealth{State: api.DeviceHealthUnknown}
+	}
+	h := api.DeviceHealth{LastUpdate: s.LastUpdate.Unix()}
+	h.State, h.Warnings = dc.flag(&s)
+	if s.Health != nil {
+		h.TotalExtents = s.Health.TotalExtents
+		h.FreeExtents = s.Health.FreeExtents
+		h.SmartPassed = s.Health.SmartPassed
+		for _, tp := range s.Health.ThinPools {
+			h.ThinPools = append(h.ThinPools, api.ThinPoolUsage{
+				Name:            tp.Name,
+				DataPercent:     tp.DataPercent,
+				MetadataPercent: tp.MetadataPercent,
+				Size:            tp.Size,
+			})
+		}
+	}
+	return h
+}
+
+// PhysicalUsed returns the amount of data in KB written to the thin
+// pools of the device found by its most recent successful check. The
+// bool is false if the usage is not known.
+func (dc *DeviceHealthCache) PhysicalUsed(deviceId string) (uint64, bool) {
+	s, ok := dc.DeviceStatus(deviceId)
+	if !ok || s.Health == nil {
+		return 0, false
+	}
+	var used uint64
+	for _, tp := range s.Health.ThinPools {
+		used += uint64(float64(tp.Size) * tp.DataPercent / 100)
+	}
+	return used, true
+}
+
+func (dc *DeviceHealthCache) cleanOld() {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	// purge any items that are stale
+	cleaned := 0
+	for k, v := range dc.devices {
+		if healthNow().Sub(v.LastUpdate) >= dc.Expiration {
+			delete(dc.devices, k)
+			cleaned++
+		}
+	}
+	healthLogger.Info("Cleaned %v devices from health cache", cleaned)
+}
+
+func (dc *DeviceHealthCache) Monitor() {
+	startTimer := time.NewTimer(dc.StartInterval)
+	ticker := time.NewTicker(dc.CheckInterval)
+	stop := make(chan interface{})
+	dc.stop = stop
+
+	go func() {
+		healthLogger.Info("Started Device Health Cache Monitor")
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				healthLogger.Info("Stopping Device Health Cache Monitor")
+				return
+			case <-startTimer.C:
+				err := dc.Refresh()
+				if err != nil {
+					healthLogger.LogError("Device Heath Cache Monitor: %v", err.Error())
+				}
+			case <-ticker.C:
+				err := dc.Refresh()
+				if err != nil {
+					healthLogger.LogError("Device Heath Cache Monitor: %v", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+func (dc *DeviceHealthCache) Stop() {
+	dc.stop <- true
+}
+
+// toProbe returns the devices to check. Removed devices and the
+// devices of nodes that are not online are skipped.
+func (dc *DeviceHealthCache) toProbe() ([]*DeviceHealthStatus, error) {
+	probeDevices := []*DeviceHealthStatus{}
+	err := dc.db.View(func(tx *bolt.Tx) error {
+		n, err := NodeList(tx)
+		if err != nil {
+			return err
+		}
+		for _, nodeId := range n {
+			if strings.HasPrefix(nodeId, "MANAGE") ||
+				strings.HasPrefix(nodeId, "STORAGE") {
+				continue
+			}
+			node, err := NewNodeEntryFromId(tx, nodeId)
+			if err != nil {
+				return err
+			}
+			if !node.isOnline() {
+				continue
+			}
+			for _, deviceId := range node.Devices {
+				device, err := NewDeviceEntryFromId(tx, deviceId)
+				if err != nil {
+					return err
+				}
+				if device.State == api.EntryStateFailed {
+					continue
+				}
+				probeDevices = append(probeDevices, &DeviceHealthStatus{
+					DeviceId: deviceId,
+					NodeId:   nodeId,
+					Host:     node.ManageHostName(),
+					Name:     device.Info.Name,
+				})
+			}
+		}
+		return nil
+	})
+	return probeDevices, err
+}
@@ -0,0 +1,147 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/tests"
+
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+func TestDeviceHeathCache(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	var devices []string
+	var offlineNode *NodeEntry
+	err = app.db.View(func(tx *bolt.Tx) error {
+		devices, err = DeviceList(tx)
+		if err != nil {
+			return err
+		}
+		nodes, err := NodeList(tx)
+		if err != nil {
+			return err
+		}
+		offlineNode, err = NewNodeEntryFromId(tx, nodes[0])
+		return err
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(devices) == 6, "expected len(devices) == 6, got:", len(devices))
+
+	err = offlineNode.SetState(app.db, app.executor, api.EntryStateOffline)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	skipped := map[string]bool{}
+	for _, id := range offlineNode.Devices {
+		skipped[id] = true
+	}
+
+	var full, failing, broken string
+	for _, id := range devices {
+		if skipped[id] {
+			continue
+		}
+		switch {
+		case full == "":
+			full = id
+		case failing == "":
+			failing = id
+		case broken == "":
+			broken = id
+		}
+	}
+
+	checked := map[string]bool{}
+	app.xo.MockDeviceHealth = func(host, device, vgid string) (*executors.DeviceHealth, error) {
+		checked[vgid] = true
+		passed := vgid != failing
+		h := &executors.DeviceHealth{
+			TotalExtents: 1000,
+			FreeExtents:  500,
+			ThinPools: []executors.ThinPoolUsage{
+				{Name: "tp_a", DataPercent: 10, MetadataPercent: 5},
+			},
+			SmartPassed: &passed,
+		}
+		if vgid == full {
+			h.ThinPools[0].MetadataPercent = 93.5
+		}
+		if vgid == broken {
+			return nil, fmt.Errorf("vgs failed")
+		}
+		return h, nil
+	}
+
+	dc := NewDeviceHealthCache(1, 0, app.db, app.executor)
+	h := dc.Health(full)
+	tests.Assert(t, h.State == api.DeviceHealthUnknown,
+		"expected h.State == api.DeviceHealthUnknown, got:", h.State)
+
+	err = dc.Refresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(checked) == 4, "expected 4 checked devices, got:", checked)
+	for id := range skipped {
+		tests.Assert(t, !checked[id], "expected device of offline node to be skipped")
+	}
+
+	h = dc.Health(full)
+	tests.Assert(t, h.State == api.DeviceHealthWarning,
+		"expected h.State == api.DeviceHealthWarning, got:", h.State)
+	tests.Assert(t, len(h.Warnings) == 1, "expected 1 warning, got:", h.Warnings)
+	tests.Assert(t, len(h.ThinPools) == 1 && h.ThinPools[0].MetadataPercent == 93.5,
+		"expected thin pool usage, got:", h.ThinPools)
+	tests.Assert(t, h.FreeExtents == 500 && h.TotalExtents == 1000,
+		"expected extents, got:", h)
+	tests.Assert(t, h.LastUpdate != 0, "expected h.LastUpdate != 0")
+
+	h = dc.Health(failing)
+	tests.Assert(t, h.State == api.DeviceHealthFailed,
+		"expected h.State == api.DeviceHealthFailed, got:", h.State)
+	tests.Assert(t, h.SmartPassed != nil && !*h.SmartPassed,
+		"expected SMART failure, got:", h.SmartPassed)
+
+	h = dc.Health(broken)
+	tests.Assert(t, h.State == api.DeviceHealthUnknown,
+		"expected h.State == api.DeviceHealthUnknown, got:", h.State)
+	tests.Assert(t, len(h.Warnings) == 1, "expected 1 warning, got:", h.Warnings)
+
+	// raising the threshold clears the thin pool warning
+	dc.ThinPoolWarning = 95
+	h = dc.Health(full)
+	tests.Assert(t, h.State == api.DeviceHealthOk,
+		"expected h.State == api.DeviceHealthOk, got:", h.State)
+
+	// old results are dropped
+	healthNow = func() time.Time {
+		return time.Now().Add(dc.Expiration)
+	}
+	defer func() { healthNow = time.Now }()
+	dc.cleanOld()
+	_, ok := dc.DeviceStatus(full)
+	tests.Assert(t, !ok, "expected status to be dropped")
+}
@@ -0,0 +1,100 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/lpabon/godbc"
+)
+
+// DeviceIdentityEntry records the persistent identifiers of the disk
+// backing a device. It is kept apart from the device entries so that
+// devices without known identifiers take no extra space in the db.
+type DeviceIdentityEntry struct {
+	DeviceId string
+	Info     api.DeviceIdentity
+}
+
+func NewDeviceIdentityEntry() *DeviceIdentityEntry {
+	entry := &DeviceIdentityEntry{}
+	return entry
+}
+
+func NewDeviceIdentityEntryFromInfo(deviceId string,
+	info *executors.DeviceInfo) *DeviceIdentityEntry {
+
+	entry := NewDeviceIdentityEntry()
+	entry.DeviceId = deviceId
+	entry.Info.ById = info.ById
+	entry.Info.Wwn = info.Wwn
+	entry.Info.Serial = info.Serial
+	entry.Info.PvUuid = info.PvUuid
+	return entry
+}
+
+func NewDeviceIdentityEntryFromId(tx *bolt.Tx, id string) (*DeviceIdentityEntry, error) {
+	godbc.Require(tx != nil)
+
+	entry := NewDeviceIdentityEntry()
+	err := EntryLoad(tx, entry, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func DeviceIdentityList(tx *bolt.Tx) ([]string, error) {
+	list := EntryKeys(tx, BOLTDB_BUCKET_DEVICE_IDENTITY)
+	if list == nil {
+		return nil, ErrAccessList
+	}
+	return list, nil
+}
+
+func (di *DeviceIdentityEntry) BucketName() string {
+	return BOLTDB_BUCKET_DEVICE_IDENTITY
+}
+
+func (di *DeviceIdentityEntry) Save(tx *bolt.Tx) error {
+	godbc.Require(tx != nil)
+	godbc.Require(len(di.DeviceId) > 0)
+
+	return EntrySave(tx, di, di.DeviceId)
+}
+
+func (di *DeviceIdentityEntry) Delete(tx *bolt.Tx) error {
+	godbc.Require(tx != nil)
+
+	return EntryDelete(tx, di, di.DeviceId)
+}
+
+func (di *DeviceIdentityEntry) Marshal() ([]byte, error) {
+	var buffer bytes.Buffer
+	enc := gob.NewEncoder(&buffer)
+	err := enc.Encode(*di)
+
+	return buffer.Bytes(), err
+}
+
+func (di *DeviceIdentityEntry) Unmarshal(buffer []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(buffer))
+	err := dec.Decode(di)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
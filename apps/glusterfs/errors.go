@@ -25,4 +25,6 @@ var (
 	ErrKeyExists        = errors.New("Key already exists in the database")
 	ErrNoReplacement    = errors.New("No Replacement was found for resource requested to be removed")
 	ErrCloneBlockVol    = errors.New("Cloning of block hosting volumes is not supported")
+	ErrMigrating        = errors.New("The volume is being migrated to another cluster")
+	ErrGeoReplicated    = errors.New("The volume has geo-replication sessions")
 )
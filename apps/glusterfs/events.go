@@ -0,0 +1,206 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+const (
+	BOLTDB_BUCKET_EVENTS = "EVENTS"
+)
+
+var (
+	// Number of most recent events kept in the db
+	EventBufferSize uint64 = 1000
+
+	// resource names of the entries recorded in events
+	eventResources = map[string]string{
+		BOLTDB_BUCKET_CLUSTER:        "cluster",
+		BOLTDB_BUCKET_NODE:           "node",
+		BOLTDB_BUCKET_DEVICE:         "device",
+		BOLTDB_BUCKET_BRICK:          "brick",
+		BOLTDB_BUCKET_VOLUME:         "volume",
+		BOLTDB_BUCKET_BLOCKVOLUME:    "blockvolume",
+		BOLTDB_BUCKET_GEOREPLICATION: "georeplication",
+	}
+
+	// wakes up the requests waiting for new events
+	eventsChanged = newEventNotifier()
+)
+
+// eventNotifier lets any number of goroutines wait for the next
+// committed event.
+type eventNotifier struct {
+	lock sync.Mutex
+	ch   chan struct{}
+}
+
+func newEventNotifier() *eventNotifier {
+	return &eventNotifier{ch: make(chan struct{})}
+}
+
+// Wait returns a channel closed on the next call to Notify.
+func (n *eventNotifier) Wait() <-chan struct{} {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.ch
+}
+
+func (n *eventNotifier) Notify() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	close(n.ch)
+	n.ch = make(chan struct{})
+}
+
+func eventKey(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
+
+// recordEvent assigns the next sequence number to the event and saves
+// it in the db, dropping the oldest events past EventBufferSize.
+// Nothing is recorded if the db has no events bucket.
+func recordEvent(tx *bolt.Tx, e api.Event) error {
+	b := tx.Bucket([]byte(BOLTDB_BUCKET_EVENTS))
+	if b == nil {
+		return nil
+	}
+
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	e.Seq = seq
+	e.Time = time.Now().Unix()
+	buffer, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	err = b.Put(eventKey(seq), buffer)
+	if err != nil {
+		return err
+	}
+
+	c := b.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.First() {
+		if binary.BigEndian.Uint64(k)+EventBufferSize > seq {
+			break
+		}
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+
+	tx.OnCommit(eventsChanged.Notify)
+	return nil
+}
+
+// recordEntryEvent records a change to the entry if events are
+// kept for entries of its kind.
+func recordEntryEvent(tx *bolt.Tx, entry DbEntry, key string,
+	t api.EventType) error {
+
+	resource, ok := eventResources[entry.BucketName()]
+	if !ok {
+		return nil
+	}
+	return recordEvent(tx, api.Event{
+		Type:     t,
+		Resource: resource,
+		Id:       key,
+	})
+}
+
+// recordOperationEvent records a change of the state of the
+// operation. Errors are only logged as the operation itself is
+// not affected.
+func recordOperationEvent(db wdb.DB, o Operation,
+	t api.EventType, state string) {
+
+	e := api.Event{
+		Type:      t,
+		Resource:  "operation",
+		Id:        operationId(o),
+		Operation: o.Label(),
+		State:     state,
+	}
+	err := db.Update(func(tx *bolt.Tx) error {
+		return recordEvent(tx, e)
+	})
+	if err != nil {
+		dbLogger.Warning("Unable to record event for %v: %v", e.Operation, err)
+	}
+}
+
+// recordStateEvent records a change of the state of the entry made
+// outside of a request, such as by a health monitor. Errors are only
+// logged as the change is already saved.
+func recordStateEvent(db wdb.DB, resource, id string, state api.EntryState) {
+	e := api.Event{
+		Type:     api.EventUpdate,
+		Resource: resource,
+		Id:       id,
+		State:    string(state),
+	}
+	err := db.Update(func(tx *bolt.Tx) error {
+		return recordEvent(tx, e)
+	})
+	if err != nil {
+		dbLogger.Warning("Unable to record state event for %v %v: %v",
+			resource, id, err)
+	}
+}
+
+// EventsSince returns the events recorded after the event with
+// sequence number since. The response is marked truncated if some of
+// these events were already dropped from the db.
+func EventsSince(tx *bolt.Tx, since uint64) (*api.EventListResponse, error) {
+	events := &api.EventListResponse{
+		Events: []api.Event{},
+	}
+
+	// a db without the events bucket has not recorded any event
+	b := tx.Bucket([]byte(BOLTDB_BUCKET_EVENTS))
+	if b == nil {
+		events.Truncated = since > 0
+		return events, nil
+	}
+
+	c := b.Cursor()
+	if k, _ := c.Last(); k != nil {
+		events.LastSeq = binary.BigEndian.Uint64(k)
+	}
+	if k, _ := c.First(); k != nil && binary.BigEndian.Uint64(k) > since+1 {
+		events.Truncated = true
+	}
+	// the db does not know about this event, it may have been
+	// restored from a backup
+	if since > events.LastSeq {
+		events.Truncated = true
+	}
+	for k, v := c.Seek(eventKey(since + 1)); k != nil; k, v = c.Next() {
+		var e api.Event
+		if err := json.Unmarshal(v, &e); err != nil {
+			return nil, err
+		}
+		events.Events = append(events.Events, e)
+	}
+	return events, nil
+}
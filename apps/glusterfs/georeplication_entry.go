@@ -0,0 +1,334 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/executors"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+	"github.com/lpabon/godbc"
+)
+
+// GeoReplicationEntry is a geo-replication session from a volume to
+// another volume managed by heketi, possibly in another cluster.
+type GeoReplicationEntry struct {
+	Info api.GeoReplicationSessionInfo
+}
+
+// states a session may be in before each action
+var geoReplicationActionStates = map[api.GeoReplicationAction][]api.GeoReplicationState{
+	api.GeoReplicationStart:  {api.GeoReplicationCreated, api.GeoReplicationStopped},
+	api.GeoReplicationStop:   {api.GeoReplicationStarted, api.GeoReplicationPaused},
+	api.GeoReplicationPause:  {api.GeoReplicationStarted},
+	api.GeoReplicationResume: {api.GeoReplicationPaused},
+}
+
+// state of a session after each action
+var geoReplicationActionResults = map[api.GeoReplicationAction]api.GeoReplicationState{
+	api.GeoReplicationStart:  api.GeoReplicationStarted,
+	api.GeoReplicationStop:   api.GeoReplicationStopped,
+	api.GeoReplicationPause:  api.GeoReplicationPaused,
+	api.GeoReplicationResume: api.GeoReplicationStarted,
+}
+
+func NewGeoReplicationEntry() *GeoReplicationEntry {
+	entry := &GeoReplicationEntry{}
+	return entry
+}
+
+// NewGeoReplicationEntryFromVolumes returns a new session replicating
+// the master volume to the slave volume.
+func NewGeoReplicationEntryFromVolumes(master, slave *VolumeEntry) *GeoReplicationEntry {
+	entry := NewGeoReplicationEntry()
+	entry.Info.Id = utils.GenUUID()
+	entry.Info.MasterVolumeId = master.Info.Id
+	entry.Info.MasterVolume = master.Info.Name
+	entry.Info.MasterCluster = master.Info.Cluster
+	entry.Info.SlaveVolumeId = slave.Info.Id
+	entry.Info.SlaveVolume = slave.Info.Name
+	entry.Info.SlaveCluster = slave.Info.Cluster
+	entry.Info.SlaveHost = slave.Info.Mount.GlusterFS.Hosts[0]
+	entry.Info.State = api.GeoReplicationCreated
+	return entry
+}
+
+func NewGeoReplicationEntryFromId(tx *bolt.Tx, id string) (*GeoReplicationEntry, error) {
+	godbc.Require(tx != nil)
+
+	entry := NewGeoReplicationEntry()
+	err := EntryLoad(tx, entry, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func GeoReplicationList(tx *bolt.Tx) ([]string, error) {
+	list := EntryKeys(tx, BOLTDB_BUCKET_GEOREPLICATION)
+	if list == nil {
+		return nil, ErrAccessList
+	}
+	return list, nil
+}
+
+// volumeGeoReplications returns the sessions the volume is the master
+// or the slave of.
+func volumeGeoReplications(tx *bolt.Tx, volumeId string) (
+	[]*GeoReplicationEntry, error) {
+
+	ids, err := GeoReplicationList(tx)
+	if err != nil {
+		return nil, err
+	}
+	sessions := []*GeoReplicationEntry{}
+	for _, id := range ids {
+		entry, err := NewGeoReplicationEntryFromId(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Info.MasterVolumeId == volumeId ||
+			entry.Info.SlaveVolumeId == volumeId {
+			sessions = append(sessions, entry)
+		}
+	}
+	return sessions, nil
+}
+
+// checkNoGeoReplication returns ErrGeoReplicated if the volume is the
+// master or the slave of a session.
+func checkNoGeoReplication(tx *bolt.Tx, volumeId string) error {
+	sessions, err := volumeGeoReplications(tx, volumeId)
+	if err != nil {
+		return err
+	}
+	if len(sessions) > 0 {
+		return ErrGeoReplicated
+	}
+	return nil
+}
+
+// checkGeoReplicationVolumes returns an error if the files of the
+// master volume can not be replicated to the slave volume.
+func checkGeoReplicationVolumes(master, slave *VolumeEntry) error {
+	if master.Info.Id == slave.Info.Id {
+		return fmt.Errorf("master and slave volumes must be different")
+	}
+	if master.Info.Block || slave.Info.Block {
+		return fmt.Errorf("block hosting volumes can not be geo-replicated")
+	}
+	if master.Pending.Id != "" || slave.Pending.Id != "" {
+		return fmt.Errorf("volume has a pending operation")
+	}
+	return nil
+}
+
+// checkGeoReplicationSlave returns ErrConflict if the volume is already
+// the slave of a session, gluster allows a single master per slave.
+func checkGeoReplicationSlave(tx *bolt.Tx, volumeId string) error {
+	sessions, err := volumeGeoReplications(tx, volumeId)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s.Info.SlaveVolumeId == volumeId {
+			return ErrConflict
+		}
+	}
+	return nil
+}
+
+func (g *GeoReplicationEntry) BucketName() string {
+	return BOLTDB_BUCKET_GEOREPLICATION
+}
+
+func (g *GeoReplicationEntry) Save(tx *bolt.Tx) error {
+	godbc.Require(tx != nil)
+	godbc.Require(len(g.Info.Id) > 0)
+
+	return EntrySave(tx, g, g.Info.Id)
+}
+
+func (g *GeoReplicationEntry) Delete(tx *bolt.Tx) error {
+	godbc.Require(tx != nil)
+
+	return EntryDelete(tx, g, g.Info.Id)
+}
+
+func (g *GeoReplicationEntry) Marshal() ([]byte, error) {
+	var buffer bytes.Buffer
+	enc := gob.NewEncoder(&buffer)
+	err := enc.Encode(*g)
+
+	return buffer.Bytes(), err
+}
+
+func (g *GeoReplicationEntry) Unmarshal(buffer []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(buffer))
+	err := dec.Decode(g)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CheckAction returns an error if the action can not be applied to the
+// session in the state heketi last set it to.
+func (g *GeoReplicationEntry) CheckAction(action api.GeoReplicationAction) error {
+	for _, s := range geoReplicationActionStates[action] {
+		if g.Info.State == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unable to %v geo-replication session %v in state %v",
+		action, g.Info.Id, g.Info.State)
+}
+
+func (g *GeoReplicationEntry) request(force bool) *executors.GeoReplicationRequest {
+	return &executors.GeoReplicationRequest{
+		MasterVolume: g.Info.MasterVolume,
+		SlaveHost:    g.Info.SlaveHost,
+		SlaveVolume:  g.Info.SlaveVolume,
+		Force:        force,
+	}
+}
+
+// Create distributes the keys of the master cluster to the nodes of
+// the slave cluster and creates the session on gluster. The session is
+// started if start is set.
+func (g *GeoReplicationEntry) Create(db wdb.DB,
+	executor executors.Executor, start bool) error {
+
+	masterHost, err := GetVerifiedManageHostname(db, executor,
+		g.Info.MasterCluster)
+	if err != nil {
+		return err
+	}
+	slaveHost, err := GetVerifiedManageHostname(db, executor,
+		g.Info.SlaveCluster)
+	if err != nil {
+		return err
+	}
+
+	secret, err := executor.GeoReplicationSecret(masterHost)
+	if err != nil {
+		return err
+	}
+	err = executor.GeoReplicationAddSecret(slaveHost, g.request(false), secret)
+	if err != nil {
+		return err
+	}
+	err = executor.GeoReplicationCreate(masterHost, g.request(false))
+	if err != nil {
+		return err
+	}
+
+	if start {
+		err = executor.GeoReplicationAction(masterHost, g.request(false),
+			string(api.GeoReplicationStart))
+		if err != nil {
+			if e := executor.GeoReplicationDestroy(masterHost,
+				g.request(true)); e != nil {
+				opLogger.LogError("Unable to delete geo-replication session %v: %v",
+					g.Info.Id, e)
+			}
+			return err
+		}
+		g.Info.State = api.GeoReplicationStarted
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return g.Save(tx)
+	})
+}
+
+// Action starts, stops, pauses or resumes the session on gluster and
+// records the state the session is in.
+func (g *GeoReplicationEntry) Action(db wdb.DB,
+	executor executors.Executor,
+	action api.GeoReplicationAction,
+	force bool) error {
+
+	host, err := GetVerifiedManageHostname(db, executor, g.Info.MasterCluster)
+	if err != nil {
+		return err
+	}
+	err = executor.GeoReplicationAction(host, g.request(force), string(action))
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		entry, err := NewGeoReplicationEntryFromId(tx, g.Info.Id)
+		if err != nil {
+			return err
+		}
+		entry.Info.State = geoReplicationActionResults[action]
+		g.Info.State = entry.Info.State
+		return entry.Save(tx)
+	})
+}
+
+// Destroy stops and deletes the session on gluster and removes it
+// from the db. The files already copied stay on the slave volume.
+func (g *GeoReplicationEntry) Destroy(db wdb.DB,
+	executor executors.Executor) error {
+
+	host, err := GetVerifiedManageHostname(db, executor, g.Info.MasterCluster)
+	if err != nil {
+		return err
+	}
+	err = executor.GeoReplicationDestroy(host, g.request(true))
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return g.Delete(tx)
+	})
+}
+
+// Status returns the state of the workers of the session as reported
+// by gluster.
+func (g *GeoReplicationEntry) Status(db wdb.RODB,
+	executor executors.Executor) (*api.GeoReplicationStatusResponse, error) {
+
+	host, err := GetVerifiedManageHostname(db, executor, g.Info.MasterCluster)
+	if err != nil {
+		return nil, err
+	}
+	gs, err := executor.GeoReplicationStatus(host, g.request(false))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &api.GeoReplicationStatusResponse{
+		GeoReplicationSessionInfo: g.Info,
+		Workers:                   []api.GeoReplicationWorkerStatus{},
+	}
+	for _, p := range gs.Pairs {
+		status.Workers = append(status.Workers, api.GeoReplicationWorkerStatus{
+			MasterNode:  p.MasterNode,
+			MasterBrick: p.MasterBrick,
+			SlaveNode:   p.SlaveNode,
+			Status:      p.Status,
+			CrawlStatus: p.CrawlStatus,
+			LastSynced:  p.LastSynced,
+		})
+	}
+	return status, nil
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/heketi/heketi/executors"
 	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 )
 
 var (
@@ -29,6 +30,8 @@ type NodeHealthStatus struct {
 	Host       string
 	Up         bool
 	LastUpdate time.Time
+	// number of consecutive failed checks
+	Failures int
 }
 
 type NodeHealthCache struct {
@@ -37,11 +40,25 @@ type NodeHealthCache struct {
 	CheckInterval time.Duration
 	Expiration    time.Duration
 
+	// Number of consecutive failed checks after which an online
+	// node is marked unreachable. Only used if node states are
+	// enabled.
+	UnreachableAfter int
+	// Called after the cache changed the state of a node
+	OnStateChange func(nodeId string, from, to api.EntryState)
+	// Called when a node passes a check after failing the previous
+	// one, or fails a check after passing the previous one or on
+	// its first check
+	OnHealthChange func(nodeId string, up bool)
+
 	db    wdb.RODB
 	exec  executors.Executor
 	nodes map[string]*NodeHealthStatus
 	lock  sync.RWMutex
 
+	// db used to change node states, nil if disabled
+	statedb wdb.DB
+
 	// to stop the monitor
 	stop chan<- interface{}
 }
@@ -57,6 +74,14 @@ func NewNodeHealthCache(reftime, starttime uint32, db wdb.RODB, e executors.Exec
 	}
 }
 
+// EnableNodeStates makes the cache move online nodes to the
+// unreachable state after failedChecks consecutive failed checks
+// and move unreachable nodes back online once a check passes.
+func (hc *NodeHealthCache) EnableNodeStates(db wdb.DB, failedChecks int) {
+	hc.statedb = db
+	hc.UnreachableAfter = failedChecks
+}
+
 func (hc *NodeHealthCache) Status() map[string]bool {
 	hc.lock.RLock()
 	defer hc.lock.RUnlock()
@@ -67,28 +92,92 @@ func (hc *NodeHealthCache) Status() map[string]bool {
 	return healthy
 }
 
+// NodeStatus returns a copy of the most recent health status of
+// the node. The bool is false if the node has not been checked.
+func (hc *NodeHealthCache) NodeStatus(nodeId string) (NodeHealthStatus, bool) {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+	s, ok := hc.nodes[nodeId]
+	if !ok {
+		return NodeHealthStatus{}, false
+	}
+	return *s, true
+}
+
 func (hc *NodeHealthCache) Refresh() error {
-	logger.Info("Starting Node Health Status refresh")
+	healthLogger.Info("Starting Node Health Status refresh")
 	sl, err := hc.toProbe()
 	if err != nil {
 		return err
 	}
 	for _, s := range sl {
-		hc.updateNode(s)
+		status := hc.updateNode(s)
+		if hc.statedb != nil {
+			if err := hc.updateNodeState(status); err != nil {
+				healthLogger.LogError("Unable to update state of node %v: %v",
+					status.NodeId, err)
+			}
+		}
 	}
 	hc.cleanOld()
 	return nil
 }
 
-func (hc *NodeHealthCache) updateNode(s *NodeHealthStatus) {
+func (hc *NodeHealthCache) updateNode(s *NodeHealthStatus) NodeHealthStatus {
 	hc.lock.Lock()
-	defer hc.lock.Unlock()
-	if prev, found := hc.nodes[s.NodeId]; found {
+	prev, found := hc.nodes[s.NodeId]
+	if found {
 		s = prev
 	} else {
 		hc.nodes[s.NodeId] = s
 	}
+	wasUp := s.Up
 	s.update(hc.exec)
+	status := *s
+	hc.lock.Unlock()
+
+	changed := (found && wasUp != status.Up) || (!found && !status.Up)
+	if changed && hc.OnHealthChange != nil {
+		hc.OnHealthChange(status.NodeId, status.Up)
+	}
+	return status
+}
+
+// updateNodeState moves an online node that failed enough
+// consecutive checks to the unreachable state and an unreachable
+// node that passed a check back online. The state is read and
+// written in one transaction so that nodes an administrator set
+// offline or failed are never changed.
+func (hc *NodeHealthCache) updateNodeState(s NodeHealthStatus) error {
+	var from, to api.EntryState
+	err := hc.statedb.Update(func(tx *bolt.Tx) error {
+		node, err := NewNodeEntryFromId(tx, s.NodeId)
+		if err == ErrNotFound {
+			// node was deleted since it was probed
+			return nil
+		} else if err != nil {
+			return err
+		}
+		switch {
+		case node.State == api.EntryStateOnline &&
+			!s.Up && s.Failures >= hc.UnreachableAfter:
+			to = api.EntryStateUnreachable
+		case node.State == api.EntryStateUnreachable && s.Up:
+			to = api.EntryStateOnline
+		default:
+			return nil
+		}
+		from = node.State
+		node.State = to
+		return node.Save(tx)
+	})
+	if err != nil || to == "" {
+		return err
+	}
+	if hc.OnStateChange != nil {
+		hc.OnStateChange(s.NodeId, from, to)
+	}
+	return nil
 }
 
 func (hc *NodeHealthCache) cleanOld() {
@@ -102,7 +191,7 @@ func (hc *NodeHealthCache) cleanOld() {
 			cleaned++
 		}
 	}
-	logger.Info("Cleaned %v nodes from health cache", cleaned)
+	healthLogger.Info("Cleaned %v nodes from health cache", cleaned)
 }
 
 func (hc *NodeHealthCache) Monitor() {
@@ -112,22 +201,22 @@ func (hc *NodeHealthCache) Monitor() {
 	hc.stop = stop
 
 	go func() {
-		logger.Info("Started Node Health Cache Monitor")
+		healthLogger.Info("Started Node Health Cache Monitor")
 		defer ticker.Stop()
 		for {
 			select {
 			case <-stop:
-				logger.Info("Stopping Node Health Cache Monitor")
+				healthLogger.Info("Stopping Node Health Cache Monitor")
 				return
 			case <-startTimer.C:
 				err := hc.Refresh()
 				if err != nil {
-					logger.LogError("Node Heath Cache Monitor: %v", err.Error())
+					healthLogger.LogError("Node Heath Cache Monitor: %v", err.Error())
 				}
 			case <-ticker.C:
 				err := hc.Refresh()
 				if err != nil {
-					logger.LogError("Node Heath Cache Monitor: %v", err.Error())
+					healthLogger.LogError("Node Heath Cache Monitor: %v", err.Error())
 				}
 			}
 		}
@@ -154,8 +243,10 @@ func (hc *NodeHealthCache) toProbe() ([]*NodeHealthStatus, error) {
 			if err != nil {
 				return err
 			}
-			// Ignore if the node is not online
-			if !node.isOnline() {
+			// Ignore if the node is not online. Unreachable
+			// nodes are checked so they can come back online.
+			if !node.isOnline() &&
+				node.State != api.EntryStateUnreachable {
 				continue
 			}
 			nhs := &NodeHealthStatus{
@@ -174,7 +265,12 @@ func (s *NodeHealthStatus) update(e executors.Executor) {
 	err := e.GlusterdCheck(s.Host)
 	s.Up = (err == nil)
 	s.LastUpdate = healthNow()
-	logger.Info("Periodic health check status: node %v up=%v",
+	if s.Up {
+		s.Failures = 0
+	} else {
+		s.Failures++
+	}
+	healthLogger.Info("Periodic health check status: node %v up=%v",
 		s.NodeId, s.Up)
 }
 
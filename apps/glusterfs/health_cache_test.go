@@ -341,3 +341,164 @@ func TestNodeHeathCacheExpireNodes(t *testing.T) {
 		tests.Assert(t, v)
 	}
 }
+
+func TestNodeHeathCacheNodeStates(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	var nodes []*NodeEntry
+	err = app.db.View(func(tx *bolt.Tx) error {
+		ids, err := NodeList(tx)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			n, err := NewNodeEntryFromId(tx, id)
+			if err != nil {
+				return err
+			}
+			nodes = append(nodes, n)
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(nodes) == 3, "expected len(nodes) == 3, got:", len(nodes))
+
+	nodeState := func(id string) api.EntryState {
+		var s api.EntryState
+		err := app.db.View(func(tx *bolt.Tx) error {
+			n, err := NewNodeEntryFromId(tx, id)
+			if err != nil {
+				return err
+			}
+			s = n.State
+			return nil
+		})
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		return s
+	}
+
+	// the first node is down, the second one is down and was set
+	// offline by the admin
+	down := map[string]bool{
+		nodes[0].ManageHostName(): true,
+		nodes[1].ManageHostName(): true,
+	}
+	app.xo.MockGlusterdCheck = func(host string) error {
+		if down[host] {
+			return fmt.Errorf("glusterd is down")
+		}
+		return nil
+	}
+	err = nodes[1].SetState(app.db, app.executor, api.EntryStateOffline)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	changes := 0
+	hc := NewNodeHealthCache(1, 0, app.db, app.executor)
+	hc.EnableNodeStates(app.db, 2)
+	hc.OnStateChange = func(nodeId string, from, to api.EntryState) {
+		changes++
+	}
+
+	// one failed check is within the grace period
+	err = hc.Refresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, nodeState(nodes[0].Info.Id) == api.EntryStateOnline)
+	tests.Assert(t, changes == 0, "expected changes == 0, got:", changes)
+
+	err = hc.Refresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, nodeState(nodes[0].Info.Id) == api.EntryStateUnreachable,
+		"expected node unreachable, got:", nodeState(nodes[0].Info.Id))
+	tests.Assert(t, nodeState(nodes[1].Info.Id) == api.EntryStateOffline,
+		"expected node offline, got:", nodeState(nodes[1].Info.Id))
+	tests.Assert(t, nodeState(nodes[2].Info.Id) == api.EntryStateOnline,
+		"expected node online, got:", nodeState(nodes[2].Info.Id))
+	tests.Assert(t, changes == 1, "expected changes == 1, got:", changes)
+
+	// placement skips the unreachable node
+	v := createSampleReplicaVolumeEntry(100, 2)
+	err = v.Create(app.db, app.executor)
+	tests.Assert(t, err == ErrNoSpace, "expected err == ErrNoSpace, got:", err)
+
+	// unreachable nodes are checked and come back online, the
+	// offline node stays offline
+	down = map[string]bool{}
+	err = hc.Refresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, nodeState(nodes[0].Info.Id) == api.EntryStateOnline,
+		"expected node online, got:", nodeState(nodes[0].Info.Id))
+	tests.Assert(t, nodeState(nodes[1].Info.Id) == api.EntryStateOffline,
+		"expected node offline, got:", nodeState(nodes[1].Info.Id))
+	tests.Assert(t, changes == 2, "expected changes == 2, got:", changes)
+}
+
+func TestNodeHeathCacheHealthChange(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app (I'm being lazy here. An app is not strictly
+	// needed but it is convenient.
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		2,    // nodes_per_cluster
+		1,    // devices_per_node,
+		6*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	down := map[string]bool{}
+	app.xo.MockGlusterdCheck = func(host string) error {
+		if down[host] {
+			return fmt.Errorf("glusterd down on %v", host)
+		}
+		return nil
+	}
+	changes := map[string][]bool{}
+	hc := NewNodeHealthCache(1, 0, app.db, app.executor)
+	hc.OnHealthChange = func(nodeId string, up bool) {
+		changes[nodeId] = append(changes[nodeId], up)
+	}
+
+	// healthy nodes are not reported on their first check
+	err = hc.Refresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(changes) == 0, changes)
+
+	var nodeId string
+	for id := range hc.Status() {
+		nodeId = id
+	}
+	s, ok := hc.NodeStatus(nodeId)
+	tests.Assert(t, ok)
+	down[s.Host] = true
+
+	// only the change is reported, not each failed check
+	for i := 0; i < 2; i++ {
+		err = hc.Refresh()
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	}
+	tests.Assert(t, len(changes) == 1, changes)
+	tests.Assert(t, len(changes[nodeId]) == 1, changes)
+	tests.Assert(t, changes[nodeId][0] == false)
+
+	down[s.Host] = false
+	err = hc.Refresh()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(changes[nodeId]) == 2, changes)
+	tests.Assert(t, changes[nodeId][1] == true)
+}
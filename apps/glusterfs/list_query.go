@@ -0,0 +1,140 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// listQuery holds the filters and paging of a list request
+type listQuery struct {
+	api.ListQuery
+	tags TagFilter
+}
+
+// newListQuery returns the query given by the parameters of a list
+// request, see api.ListQuery.
+func newListQuery(r *http.Request) (*listQuery, error) {
+	v := r.URL.Query()
+	q := &listQuery{}
+	q.Name = v.Get("name")
+	q.NamePrefix = v.Get("name_prefix")
+	q.Cluster = v.Get("cluster")
+	q.Continue = v.Get("continue")
+
+	q.Durability = api.DurabilityType(v.Get("durability"))
+	switch q.Durability {
+	case "", api.DurabilityReplicate, api.DurabilityDistributeOnly,
+		api.DurabilityEC:
+	default:
+		return nil, fmt.Errorf("unknown durability type %v", q.Durability)
+	}
+
+	if s := v.Get("block"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("block must be true or false")
+		}
+		q.Block = &b
+	}
+	if s := v.Get("expand"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("expand must be true or false")
+		}
+		q.Expand = b
+	}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("limit must be a positive number")
+		}
+		q.Limit = n
+	}
+
+	var err error
+	q.tags, err = NewTagFilterFromRequest(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag filter: %v", err)
+	}
+	return q, nil
+}
+
+// filtered returns true if the entries listed must be read to find
+// the ones matching the query.
+func (q *listQuery) filtered() bool {
+	return q.Name != "" || q.NamePrefix != "" || q.Cluster != "" ||
+		q.Durability != "" || q.Block != nil || len(q.tags) > 0
+}
+
+func (q *listQuery) matchName(name string) bool {
+	if q.Name != "" && name != q.Name {
+		return false
+	}
+	return strings.HasPrefix(name, q.NamePrefix)
+}
+
+func (q *listQuery) matchVolume(v *VolumeEntry) bool {
+	return q.matchName(v.Info.Name) &&
+		(q.Cluster == "" || v.Info.Cluster == q.Cluster) &&
+		(q.Durability == "" || v.Info.Durability.Type == q.Durability) &&
+		(q.Block == nil || v.Info.Block == *q.Block) &&
+		q.tags.Matches(v)
+}
+
+func (q *listQuery) matchBlockVolume(bv *BlockVolumeEntry) bool {
+	return q.matchName(bv.Info.Name) &&
+		(q.Cluster == "" || bv.Info.Cluster == q.Cluster) &&
+		q.tags.Matches(bv)
+}
+
+func (q *listQuery) matchCluster(c *ClusterEntry) bool {
+	return (q.Block == nil || c.Info.Block == *q.Block) &&
+		q.tags.Matches(c)
+}
+
+// page returns, in order, the ids matching the query which follow
+// its continuation token, at most limit of them. If more ids match
+// the token continuing the list is returned with them. The token is
+// the last id returned so that pages are not changed by the ids
+// added or removed before them.
+func (q *listQuery) page(ids []string,
+	match func(id string) (bool, error)) ([]string, string, error) {
+
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+
+	page := []string{}
+	for _, id := range sorted {
+		if q.Continue != "" && id <= q.Continue {
+			continue
+		}
+		if q.filtered() {
+			ok, err := match(id)
+			if err != nil {
+				return nil, "", err
+			}
+			if !ok {
+				continue
+			}
+		}
+		if q.Limit != 0 && len(page) == q.Limit {
+			return page, page[len(page)-1], nil
+		}
+		page = append(page, id)
+	}
+	return page, "", nil
+}
@@ -0,0 +1,283 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	operationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "heketi_operation_duration_seconds",
+			Help: "Time taken to run operations",
+			// from one second up to about half an hour
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"operation"})
+	operationFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "heketi_operation_failures_total",
+			Help: "Number of operations which failed",
+		},
+		[]string{"operation"})
+	httpRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "heketi_http_requests_total",
+			Help: "Number of HTTP requests handled",
+		},
+		[]string{"route", "method", "code"})
+)
+
+var (
+	metricUp = prometheus.NewDesc(
+		"heketi_up",
+		"Whether heketi was able to read its database",
+		nil, nil)
+	metricClusterCount = prometheus.NewDesc(
+		"heketi_cluster_count",
+		"Number of clusters",
+		nil, nil)
+	metricNodeCount = prometheus.NewDesc(
+		"heketi_nodes_count",
+		"Number of nodes in the cluster",
+		[]string{"cluster"}, nil)
+	metricVolumeCount = prometheus.NewDesc(
+		"heketi_volumes_count",
+		"Number of volumes in the cluster",
+		[]string{"cluster"}, nil)
+	metricBlockVolumeCount = prometheus.NewDesc(
+		"heketi_block_volumes_count",
+		"Number of block volumes in the cluster",
+		[]string{"cluster"}, nil)
+	metricDeviceCount = prometheus.NewDesc(
+		"heketi_device_count",
+		"Number of devices on the node",
+		[]string{"cluster", "hostname"}, nil)
+	metricDeviceSize = prometheus.NewDesc(
+		"heketi_device_size_bytes",
+		"Total size of the device",
+		[]string{"cluster", "hostname", "device"}, nil)
+	metricDeviceFree = prometheus.NewDesc(
+		"heketi_device_free_bytes",
+		"Free space on the device",
+		[]string{"cluster", "hostname", "device"}, nil)
+	metricDeviceUsed = prometheus.NewDesc(
+		"heketi_device_used_bytes",
+		"Used space on the device",
+		[]string{"cluster", "hostname", "device"}, nil)
+	metricNodeUp = prometheus.NewDesc(
+		"heketi_node_up",
+		"Whether the node passed its last health check",
+		[]string{"cluster", "hostname"}, nil)
+	metricPendingOperations = prometheus.NewDesc(
+		"heketi_pending_operations",
+		"Number of pending operations",
+		[]string{"type"}, nil)
+)
+
+// operations reported by the pending operations metric even if
+// none of them are pending
+var metricOperationTypes = []PendingOperationType{
+	OperationCreateVolume,
+	OperationDeleteVolume,
+	OperationExpandVolume,
+	OperationCreateBlockVolume,
+	OperationDeleteBlockVolume,
+	OperationRemoveDevice,
+	OperationCloneVolume,
+	OperationConvertVolume,
+	OperationMigrateVolume,
+}
+
+func init() {
+	prometheus.MustRegister(operationDuration)
+	prometheus.MustRegister(operationFailures)
+	prometheus.MustRegister(httpRequests)
+}
+
+// observeOperation records the duration of the operation and
+// whether it failed.
+func observeOperation(label string, start time.Time, err error) {
+	operationDuration.WithLabelValues(label).Observe(
+		time.Since(start).Seconds())
+	if err != nil {
+		operationFailures.WithLabelValues(label).Inc()
+	}
+}
+
+// instrumentRoute counts the requests handled by the route.
+func instrumentRoute(name string, h http.Handler) http.Handler {
+	return promhttp.InstrumentHandlerCounter(
+		httpRequests.MustCurryWith(prometheus.Labels{"route": name}), h)
+}
+
+type deviceMetrics struct {
+	name  string
+	total uint64
+	free  uint64
+	used  uint64
+}
+
+type nodeMetrics struct {
+	id       string
+	hostname string
+	devices  []deviceMetrics
+}
+
+type clusterMetrics struct {
+	id           string
+	volumes      int
+	blockVolumes int
+	nodes        []nodeMetrics
+}
+
+// metricsCollector reports the state of the topology and the
+// health of the nodes of an app each time it is scraped.
+type metricsCollector struct {
+	app *App
+}
+
+func (m *metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metricUp
+	ch <- metricClusterCount
+	ch <- metricNodeCount
+	ch <- metricVolumeCount
+	ch <- metricBlockVolumeCount
+	ch <- metricDeviceCount
+	ch <- metricDeviceSize
+	ch <- metricDeviceFree
+	ch <- metricDeviceUsed
+	ch <- metricNodeUp
+	ch <- metricPendingOperations
+}
+
+func (m *metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	var clusters []clusterMetrics
+	pending := map[PendingOperationType]int{}
+	err := m.app.db.View(func(tx *bolt.Tx) error {
+		clusterIds, err := ClusterList(tx)
+		if err != nil {
+			return err
+		}
+		for _, clusterId := range clusterIds {
+			cluster, err := NewClusterEntryFromId(tx, clusterId)
+			if err != nil {
+				return err
+			}
+			cm := clusterMetrics{
+				id:           clusterId,
+				volumes:      len(cluster.Info.Volumes),
+				blockVolumes: len(cluster.Info.BlockVolumes),
+			}
+			for _, nodeId := range cluster.Info.Nodes {
+				node, err := NewNodeEntryFromId(tx, nodeId)
+				if err != nil {
+					return err
+				}
+				nm := nodeMetrics{
+					id:       nodeId,
+					hostname: node.ManageHostName(),
+				}
+				for _, deviceId := range node.Devices {
+					device, err := NewDeviceEntryFromId(tx, deviceId)
+					if err != nil {
+						return err
+					}
+					nm.devices = append(nm.devices, deviceMetrics{
+						name:  device.Info.Name,
+						total: device.Info.Storage.Total,
+						free:  device.Info.Storage.Free,
+						used:  device.Info.Storage.Used,
+					})
+				}
+				cm.nodes = append(cm.nodes, nm)
+			}
+			clusters = append(clusters, cm)
+		}
+
+		opIds, err := PendingOperationList(tx)
+		if err != nil {
+			return err
+		}
+		for _, opId := range opIds {
+			op, err := NewPendingOperationEntryFromId(tx, opId)
+			if err != nil {
+				return err
+			}
+			pending[op.Type]++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogError("Unable to collect metrics: %v", err)
+		ch <- prometheus.MustNewConstMetric(metricUp,
+			prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(metricUp, prometheus.GaugeValue, 1)
+
+	ch <- prometheus.MustNewConstMetric(metricClusterCount,
+		prometheus.GaugeValue, float64(len(clusters)))
+	for _, c := range clusters {
+		ch <- prometheus.MustNewConstMetric(metricNodeCount,
+			prometheus.GaugeValue, float64(len(c.nodes)), c.id)
+		ch <- prometheus.MustNewConstMetric(metricVolumeCount,
+			prometheus.GaugeValue, float64(c.volumes), c.id)
+		ch <- prometheus.MustNewConstMetric(metricBlockVolumeCount,
+			prometheus.GaugeValue, float64(c.blockVolumes), c.id)
+		for _, n := range c.nodes {
+			ch <- prometheus.MustNewConstMetric(metricDeviceCount,
+				prometheus.GaugeValue, float64(len(n.devices)),
+				c.id, n.hostname)
+			for _, d := range n.devices {
+				// device storage is tracked in KiB
+				ch <- prometheus.MustNewConstMetric(metricDeviceSize,
+					prometheus.GaugeValue, float64(d.total*1024),
+					c.id, n.hostname, d.name)
+				ch <- prometheus.MustNewConstMetric(metricDeviceFree,
+					prometheus.GaugeValue, float64(d.free*1024),
+					c.id, n.hostname, d.name)
+				ch <- prometheus.MustNewConstMetric(metricDeviceUsed,
+					prometheus.GaugeValue, float64(d.used*1024),
+					c.id, n.hostname, d.name)
+			}
+			if m.app.nhealth == nil {
+				continue
+			}
+			if s, ok := m.app.nhealth.NodeStatus(n.id); ok {
+				up := 0.0
+				if s.Up {
+					up = 1.0
+				}
+				ch <- prometheus.MustNewConstMetric(metricNodeUp,
+					prometheus.GaugeValue, up, c.id, n.hostname)
+			}
+		}
+	}
+
+	for _, t := range metricOperationTypes {
+		ch <- prometheus.MustNewConstMetric(metricPendingOperations,
+			prometheus.GaugeValue, float64(pending[t]), t.Name())
+	}
+}
+
+// MetricsHandler returns a handler serving the metrics of the app
+// and of the heketi process in the Prometheus exposition format.
+func (a *App) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(
+		prometheus.Gatherers{prometheus.DefaultGatherer, a.metrics},
+		promhttp.HandlerOpts{})
+}
@@ -0,0 +1,134 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/gorilla/mux"
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+// getMetrics returns the value of each series scraped from the
+// server keyed by the name and labels of the series.
+func getMetrics(t *testing.T, url string) map[string]float64 {
+	r, err := http.Get(url + "/metrics")
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	defer r.Body.Close()
+	tests.Assert(t, r.StatusCode == http.StatusOK)
+	body, err := ioutil.ReadAll(r.Body)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	m := map[string]float64{}
+	for _, line := range strings.Split(string(body), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndex(line, " ")
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		m[line[:i]] = v
+	}
+	return m
+}
+
+func TestMetrics(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+	router.Methods("GET").Path("/metrics").Handler(app.MetricsHandler())
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	var clusterId string
+	err = app.db.View(func(tx *bolt.Tx) error {
+		clusters, err := ClusterList(tx)
+		if err != nil {
+			return err
+		}
+		clusterId = clusters[0]
+		return nil
+	})
+	tests.Assert(t, err == nil)
+
+	m := getMetrics(t, ts.URL)
+	tests.Assert(t, m["heketi_up"] == 1, m)
+	tests.Assert(t, m["heketi_cluster_count"] == 1, m)
+	tests.Assert(t, m[`heketi_nodes_count{cluster="`+clusterId+`"}`] == 3, m)
+	tests.Assert(t, m[`heketi_volumes_count{cluster="`+clusterId+`"}`] == 0, m)
+	v, ok := m[`heketi_pending_operations{type="create_volume"}`]
+	tests.Assert(t, ok && v == 0, m)
+	devices := 0
+	for k, v := range m {
+		if strings.HasPrefix(k, "heketi_device_size_bytes{") {
+			tests.Assert(t, v == float64(1*TB*1024), k, v)
+			devices++
+		}
+		// health monitor is not running in the tests
+		tests.Assert(t, !strings.HasPrefix(k, "heketi_node_up{"), k)
+	}
+	tests.Assert(t, devices == 6, "expected devices == 6, got:", devices)
+
+	// create a volume through the api
+	c := client.NewClientNoAuth(ts.URL)
+	req := &api.VolumeCreateRequest{}
+	req.Size = 10
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	// a volume larger than the cluster fails to be created
+	req.Size = 10 * 1024
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err != nil)
+
+	m = getMetrics(t, ts.URL)
+	tests.Assert(t, m[`heketi_volumes_count{cluster="`+clusterId+`"}`] == 1, m)
+	tests.Assert(t,
+		m[`heketi_operation_duration_seconds_count{operation="Create Volume"}`] >= 2, m)
+	tests.Assert(t,
+		m[`heketi_operation_failures_total{operation="Create Volume"}`] >= 1, m)
+	tests.Assert(t,
+		m[`heketi_http_requests_total{code="202",method="post",route="VolumeCreate"}`] >= 1, m)
+
+	// used space of the bricks is reported for the devices
+	var used float64
+	for k, v := range m {
+		if strings.HasPrefix(k, "heketi_device_used_bytes{") {
+			used += v
+		}
+	}
+	tests.Assert(t, used >= float64(3*10*GB*1024), "used:", used)
+}
@@ -106,7 +106,7 @@ func GetVerifiedManageHostname(db wdb.RODB, e executors.Executor, clusterId stri
 		}
 		err = e.GlusterdCheck(newNode.ManageHostName())
 		if err != nil {
-			logger.Info("Glusterd not running in %v", newNode.ManageHostName())
+			opLogger.Info("Glusterd not running in %v", newNode.ManageHostName())
 			continue
 		}
 		node = newNode
@@ -170,7 +170,7 @@ func (n *NodeEntry) Register(tx *bolt.Tx) error {
 				// the registration
 				return nil
 			} else if err != nil {
-				return logger.Err(err)
+				return opLogger.Err(err)
 			}
 
 			// Return that we found a conflict
@@ -194,7 +194,7 @@ func (n *NodeEntry) Register(tx *bolt.Tx) error {
 				// the registration
 				return nil
 			} else if err != nil {
-				return logger.Err(err)
+				return opLogger.Err(err)
 			}
 
 			// Return that we found a conflict
@@ -273,7 +273,7 @@ func (n *NodeEntry) Delete(tx *bolt.Tx) error {
 
 	// Check if the nodes still has drives
 	if !n.IsDeleteOk() {
-		logger.Warning(n.ConflictString())
+		opLogger.Warning(n.ConflictString())
 		return ErrConflict
 	}
 
@@ -355,7 +355,7 @@ func (n *NodeEntry) SetState(db wdb.DB, e executors.Executor,
 				err = d.Remove(db, e)
 				if err != nil {
 					if err == ErrNoReplacement {
-						return logger.LogError("Unable to remove node [%v] as no device was found to replace device [%v]", n.Info.Id, d.Id())
+						return opLogger.LogError("Unable to remove node [%v] as no device was found to replace device [%v]", n.Info.Id, d.Id())
 					}
 					return err
 				}
@@ -377,6 +377,23 @@ func (n *NodeEntry) SetState(db wdb.DB, e executors.Executor,
 		default:
 			return fmt.Errorf("Unknown state type: %v", s)
 		}
+
+	// Node was found unreachable by the health monitor
+	case api.EntryStateUnreachable:
+		switch s {
+		case api.EntryStateOnline, api.EntryStateOffline:
+			err := db.Update(func(tx *bolt.Tx) error {
+				n.State = s
+				return n.Save(tx)
+			})
+			if err != nil {
+				return err
+			}
+		case api.EntryStateFailed:
+			return fmt.Errorf("Node must be offline before remove operation is performed, node:%v", n.Info.Id)
+		default:
+			return fmt.Errorf("Unknown state type: %v", s)
+		}
 	}
 	return nil
 }
@@ -393,6 +410,8 @@ func (n *NodeEntry) NewInfoReponse(tx *bolt.Tx) (*api.NodeInfoResponse, error) {
 	info.State = n.State
 	info.DevicesInfo = make([]api.DeviceInfoResponse, 0)
 	info.Tags = copyTags(n.Info.Tags)
+	info.FailureDomain = n.FailureDomain()
+	info.Health = api.NodeHealth{State: api.NodeHealthUnknown}
 
 	// Add each drive information
 	for _, deviceid := range n.Devices {
@@ -485,3 +504,8 @@ func (n *NodeEntry) SetTags(t map[string]string) error {
 	n.Info.Tags = t
 	return nil
 }
+
+// FailureDomain returns the region and rack the node was labeled with.
+func (n *NodeEntry) FailureDomain() api.FailureDomain {
+	return api.NewFailureDomainFromTags(n.AllTags())
+}
@@ -12,9 +12,12 @@ package glusterfs
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/heketi/heketi/executors"
 	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 
 	"github.com/boltdb/bolt"
 )
@@ -103,6 +106,32 @@ func (om *OperationManager) Id() string {
 	return om.op.Id
 }
 
+// operationId returns the id of the pending operation entry of
+// the operation if it has one.
+func operationId(o Operation) string {
+	if om, ok := o.(interface {
+		Id() string
+	}); ok {
+		return om.Id()
+	}
+	return ""
+}
+
+// operationDb returns the db the operation works on, or nil if the
+// operation does not keep one.
+func operationDb(o Operation) wdb.DB {
+	if om, ok := o.(interface {
+		dbConn() wdb.DB
+	}); ok {
+		return om.dbConn()
+	}
+	return nil
+}
+
+func (om *OperationManager) dbConn() wdb.DB {
+	return om.db
+}
+
 // VolumeCreateOperation implements the operation functions used to
 // create a new volume.
 type VolumeCreateOperation struct {
@@ -169,12 +198,12 @@ func (vc *VolumeCreateOperation) Build() error {
 func (vc *VolumeCreateOperation) Exec(executor executors.Executor) error {
 	brick_entries, err := bricksFromOp(vc.db, vc.op, vc.vol.Info.Gid)
 	if err != nil {
-		logger.LogError("Failed to get bricks from op: %v", err)
+		opLogger.LogError("Failed to get bricks from op: %v", err)
 		return err
 	}
 	err = vc.vol.createVolumeExec(vc.db, executor, brick_entries)
 	if err != nil {
-		logger.LogError("Error executing create volume: %v", err)
+		opLogger.LogError("Error executing create volume: %v", err)
 		return OperationRetryError{err}
 	}
 	return nil
@@ -185,7 +214,7 @@ func (vc *VolumeCreateOperation) Finalize() error {
 	return vc.db.Update(func(tx *bolt.Tx) error {
 		brick_entries, err := bricksFromOp(wdb.WrapTx(tx), vc.op, vc.vol.Info.Gid)
 		if err != nil {
-			logger.LogError("Failed to get bricks from op: %v", err)
+			opLogger.LogError("Failed to get bricks from op: %v", err)
 			return err
 		}
 		for _, brick := range brick_entries {
@@ -211,12 +240,12 @@ func (vc *VolumeCreateOperation) Rollback(executor executors.Executor) error {
 	// TODO make this into one transaction too
 	brick_entries, err := bricksFromOp(vc.db, vc.op, vc.vol.Info.Gid)
 	if err != nil {
-		logger.LogError("Failed to get bricks from op: %v", err)
+		opLogger.LogError("Failed to get bricks from op: %v", err)
 		return err
 	}
 	err = vc.vol.cleanupCreateVolume(vc.db, executor, brick_entries)
 	if err != nil {
-		logger.LogError("Error on create volume rollback: %v", err)
+		opLogger.LogError("Error on create volume rollback: %v", err)
 		return err
 	}
 	err = vc.db.Update(func(tx *bolt.Tx) error {
@@ -288,14 +317,25 @@ func (ve *VolumeExpandOperation) Build() error {
 func (ve *VolumeExpandOperation) Exec(executor executors.Executor) error {
 	brick_entries, err := bricksFromOp(ve.db, ve.op, ve.vol.Info.Gid)
 	if err != nil {
-		logger.LogError("Failed to get bricks from op: %v", err)
+		opLogger.LogError("Failed to get bricks from op: %v", err)
 		return err
 	}
 	err = ve.vol.expandVolumeExec(ve.db, executor, brick_entries)
 	if err != nil {
-		logger.LogError("Error executing expand volume: %v", err)
+		opLogger.LogError("Error executing expand volume: %v", err)
+		return err
 	}
-	return err
+	// the bricks are part of the volume now, they are kept even if
+	// the limit can not be raised
+	if ve.vol.Info.RootQuota {
+		err = ve.vol.raiseRootQuota(ve.db, executor,
+			ve.vol.Info.Size+ve.ExpandSize)
+		if err != nil {
+			opLogger.LogError("Unable to raise the quota of volume %v: %v",
+				ve.vol.Info.Id, err)
+		}
+	}
+	return nil
 }
 
 // Rollback cancels the volume expansion and remove pending brick entries
@@ -304,13 +344,13 @@ func (ve *VolumeExpandOperation) Rollback(executor executors.Executor) error {
 	// TODO make this into one transaction too
 	brick_entries, err := bricksFromOp(ve.db, ve.op, ve.vol.Info.Gid)
 	if err != nil {
-		logger.LogError("Failed to get bricks from op: %v", err)
+		opLogger.LogError("Failed to get bricks from op: %v", err)
 		return err
 	}
 	err = ve.vol.cleanupExpandVolume(
 		ve.db, executor, brick_entries, ve.vol.Info.Size)
 	if err != nil {
-		logger.LogError("Error on create volume rollback: %v", err)
+		opLogger.LogError("Error on create volume rollback: %v", err)
 		return err
 	}
 	err = ve.db.Update(func(tx *bolt.Tx) error {
@@ -325,12 +365,12 @@ func (ve *VolumeExpandOperation) Finalize() error {
 	return ve.db.Update(func(tx *bolt.Tx) error {
 		brick_entries, err := bricksFromOp(wdb.WrapTx(tx), ve.op, ve.vol.Info.Gid)
 		if err != nil {
-			logger.LogError("Failed to get bricks from op: %v", err)
+			opLogger.LogError("Failed to get bricks from op: %v", err)
 			return err
 		}
 		sizeDelta, err := expandSizeFromOp(ve.op)
 		if err != nil {
-			logger.LogError("Failed to get expansion size from op: %v", err)
+			opLogger.LogError("Failed to get expansion size from op: %v", err)
 			return err
 		}
 
@@ -351,6 +391,622 @@ func (ve *VolumeExpandOperation) Finalize() error {
 	})
 }
 
+// VolumeConvertOperation implements the operation functions used to
+// change the durability of an existing replica volume.
+type VolumeConvertOperation struct {
+	OperationManager
+	noRetriesOperation
+	vol       *VolumeEntry
+	reclaimed map[string]bool // gets set in Exec()
+
+	// modification values
+	Arbiter bool
+}
+
+// NewVolumeConvertOperation creates a new VolumeConvertOperation
+// populated with the given volume entry and db connection. The volume
+// is converted to replica 3, with an arbiter brick if arbiter is set.
+func NewVolumeConvertOperation(
+	vol *VolumeEntry, db wdb.DB, arbiter bool) *VolumeConvertOperation {
+
+	return &VolumeConvertOperation{
+		OperationManager: OperationManager{
+			db: db,
+			op: NewPendingOperationEntry(NEW_ID),
+		},
+		vol:     vol,
+		Arbiter: arbiter,
+	}
+}
+
+func (vc *VolumeConvertOperation) Label() string {
+	return "Convert Volume"
+}
+
+func (vc *VolumeConvertOperation) ResourceUrl() string {
+	return fmt.Sprintf("/volumes/%v", vc.vol.Info.Id)
+}
+
+// Build checks that the volume can be converted and records the
+// pending operation. The new bricks can only be placed once the order
+// of the bricks is read from gluster in Exec.
+func (vc *VolumeConvertOperation) Build() error {
+	return vc.db.Update(func(tx *bolt.Tx) error {
+		v, err := NewVolumeEntryFromId(tx, vc.vol.Info.Id)
+		if err != nil {
+			return err
+		}
+		vc.vol = v
+		if err := vc.vol.checkConvert(vc.Arbiter); err != nil {
+			return err
+		}
+		vc.op.RecordConvertVolume(vc.vol)
+		return vc.op.Save(tx)
+	})
+}
+
+// Exec places and creates the new bricks and changes the replica
+// count of the volume on the storage systems.
+func (vc *VolumeConvertOperation) Exec(executor executors.Executor) error {
+	var err error
+	vc.reclaimed, err = vc.vol.convertVolumeExec(
+		vc.db, executor, vc.op, vc.Arbiter)
+	if err != nil {
+		opLogger.LogError("Error executing convert volume: %v", err)
+	}
+	return err
+}
+
+// Rollback removes the new bricks and leaves the volume with the
+// bricks it had. Once the replaced bricks have been taken out of the
+// volume the db no longer matches gluster either way, so the operation
+// is left pending for the admin to resolve.
+func (vc *VolumeConvertOperation) Rollback(executor executors.Executor) error {
+	if vc.op.ConvertBricksRemoved() {
+		opLogger.LogError("Action Required: volume %v has been left with "+
+			"replica %v on gluster, add the bricks of pending operation %v "+
+			"to the volume", vc.vol.Info.Name,
+			vc.vol.Info.Durability.Replicate.Replica-1, vc.op.Id)
+		return fmt.Errorf("can not roll back convert of volume %v: "+
+			"bricks have already been removed from the volume",
+			vc.vol.Info.Name)
+	}
+
+	added, _, err := convertBricksFromOp(vc.db, vc.op, vc.vol.Info.Gid)
+	if err != nil {
+		opLogger.LogError("Failed to get bricks from op: %v", err)
+		return err
+	}
+	DestroyBricks(vc.db, executor, added)
+
+	return vc.db.Update(func(tx *bolt.Tx) error {
+		added, removed, err := convertBricksFromOp(
+			wdb.WrapTx(tx), vc.op, vc.vol.Info.Gid)
+		if err != nil {
+			return err
+		}
+		for _, brick := range added {
+			device, err := NewDeviceEntryFromId(tx, brick.Info.DeviceId)
+			if err != nil {
+				return err
+			}
+			device.StorageFree(brick.TotalSize())
+			if e := device.Save(tx); e != nil {
+				return e
+			}
+			if e := vc.vol.removeBrickFromDb(tx, brick); e != nil {
+				return e
+			}
+		}
+		for _, brick := range removed {
+			vc.op.FinalizeBrick(brick)
+			if e := brick.Save(tx); e != nil {
+				return e
+			}
+		}
+		if e := vc.vol.Save(tx); e != nil {
+			return e
+		}
+		return vc.op.Delete(tx)
+	})
+}
+
+// Finalize marks the new bricks as no longer pending, removes the
+// replaced bricks and updates the durability of the volume entry.
+func (vc *VolumeConvertOperation) Finalize() error {
+	return vc.db.Update(func(tx *bolt.Tx) error {
+		added, removed, err := convertBricksFromOp(
+			wdb.WrapTx(tx), vc.op, vc.vol.Info.Gid)
+		if err != nil {
+			opLogger.LogError("Failed to get bricks from op: %v", err)
+			return err
+		}
+
+		for _, brick := range added {
+			vc.op.FinalizeBrick(brick)
+			if e := brick.Save(tx); e != nil {
+				return e
+			}
+		}
+		for _, brick := range removed {
+			if vc.reclaimed[brick.Info.DeviceId] {
+				device, err := NewDeviceEntryFromId(tx, brick.Info.DeviceId)
+				if err != nil {
+					return err
+				}
+				device.StorageFree(brick.TotalSize())
+				if e := device.Save(tx); e != nil {
+					return e
+				}
+			}
+			if e := vc.vol.removeBrickFromDb(tx, brick); e != nil {
+				return e
+			}
+		}
+
+		vc.vol.setConverted(vc.Arbiter)
+		if e := vc.vol.Save(tx); e != nil {
+			return e
+		}
+		return vc.op.Delete(tx)
+	})
+}
+
+// VolumeMigrateOperation implements the operation functions used to
+// copy a volume to another cluster. The operation stops once the data
+// is copied, the volume is only moved to the other cluster by the
+// VolumeMigrateCutoverOperation. The pending operation entry is kept
+// between the two and records the state of the migration so that it
+// can be resumed.
+type VolumeMigrateOperation struct {
+	OperationManager
+	noRetriesOperation
+	vol       *VolumeEntry
+	target    *VolumeEntry
+	clusterId string
+	resumed   bool
+}
+
+// NewVolumeMigrateOperation returns a new VolumeMigrateOperation to
+// migrate the volume to the cluster. If the volume is already being
+// migrated to the cluster the migration is resumed.
+func NewVolumeMigrateOperation(
+	vol *VolumeEntry, db wdb.DB, clusterId string) *VolumeMigrateOperation {
+
+	return &VolumeMigrateOperation{
+		OperationManager: OperationManager{
+			db: db,
+			op: NewPendingOperationEntry(NEW_ID),
+		},
+		vol:       vol,
+		clusterId: clusterId,
+	}
+}
+
+func (vm *VolumeMigrateOperation) Label() string {
+	return "Migrate Volume"
+}
+
+func (vm *VolumeMigrateOperation) ResourceUrl() string {
+	return fmt.Sprintf("/volumes/%v/migration", vm.vol.Info.Id)
+}
+
+// Build allocates the volume and bricks on the target cluster (tagged
+// as pending) in the db, or loads them if the migration is resumed.
+func (vm *VolumeMigrateOperation) Build() error {
+	if err := claimMigration(vm.vol.Info.Id); err != nil {
+		return err
+	}
+	err := vm.db.Update(func(tx *bolt.Tx) error {
+		v, err := NewVolumeEntryFromId(tx, vm.vol.Info.Id)
+		if err != nil {
+			return err
+		}
+		vm.vol = v
+
+		op, err := volumeMigrationOp(tx, v.Info.Id)
+		if err != nil {
+			return err
+		}
+		if op != nil {
+			state, targetId, err := migrationFromOp(op)
+			if err != nil {
+				return err
+			}
+			target, err := NewVolumeEntryFromId(tx, targetId)
+			if err != nil {
+				return err
+			}
+			if target.Info.Cluster != vm.clusterId {
+				return fmt.Errorf("volume is being migrated to cluster %v",
+					target.Info.Cluster)
+			}
+			if migrationCutOver(op) {
+				return fmt.Errorf("migration of volume %v is %v, "+
+					"confirm the cutover to complete it", v.Info.Id, state)
+			}
+			vm.op = op
+			vm.target = target
+			vm.resumed = true
+			return nil
+		}
+
+		if err := v.checkMigrate(vm.clusterId); err != nil {
+			return err
+		}
+		vm.target = NewVolumeEntryForMigration(v, vm.clusterId)
+		brick_entries, err := vm.target.createVolumeComponents(
+			wdb.WrapTx(tx))
+		if err != nil {
+			return err
+		}
+		for _, brick := range brick_entries {
+			vm.op.RecordAddBrick(brick)
+			if e := brick.Save(tx); e != nil {
+				return e
+			}
+		}
+		vm.op.RecordMigrateVolume(vm.vol, vm.target)
+		if e := vm.target.Save(tx); e != nil {
+			return e
+		}
+		return vm.op.Save(tx)
+	})
+	if err != nil {
+		releaseMigration(vm.vol.Info.Id)
+	}
+	return err
+}
+
+// Exec creates the volume on the target cluster and copies the data
+// of the volume to it. The state of the migration is saved after each
+// step.
+func (vm *VolumeMigrateOperation) Exec(executor executors.Executor) error {
+	state, _, err := migrationFromOp(vm.op)
+	if err != nil {
+		return err
+	}
+
+	if state == api.MigrationCreating {
+		brick_entries, err := bricksFromOp(vm.db, vm.op, vm.target.Info.Gid)
+		if err != nil {
+			opLogger.LogError("Failed to get bricks from op: %v", err)
+			return err
+		}
+		if vm.resumed {
+			// the server may have stopped with the volume or some
+			// of its bricks created
+			err = vm.target.destroyMigrationTarget(vm.db, executor,
+				brick_entries)
+			if err != nil {
+				return err
+			}
+		}
+		err = vm.target.createVolumeExec(vm.db, executor, brick_entries)
+		if err != nil {
+			opLogger.LogError("Error creating volume on cluster %v: %v",
+				vm.target.Info.Cluster, err)
+			return err
+		}
+		state = api.MigrationCopying
+		if err := saveMigrationState(vm.db, vm.op, state); err != nil {
+			return err
+		}
+	}
+
+	if state == api.MigrationCopying {
+		err = vm.vol.syncMigration(vm.db, executor, vm.target)
+		if err != nil {
+			opLogger.LogError("Error copying volume %v to cluster %v: %v",
+				vm.vol.Info.Name, vm.target.Info.Cluster, err)
+			return err
+		}
+		return saveMigrationState(vm.db, vm.op, api.MigrationReady)
+	}
+	return nil
+}
+
+// Rollback removes the volume and bricks from the target cluster if
+// they could not be created. Once the volume is created the migration
+// is kept so that it can be resumed or cancelled.
+func (vm *VolumeMigrateOperation) Rollback(executor executors.Executor) error {
+	defer releaseMigration(vm.vol.Info.Id)
+
+	state, _, err := migrationFromOp(vm.op)
+	if err != nil {
+		return err
+	}
+	if state != api.MigrationCreating {
+		return nil
+	}
+	brick_entries, err := bricksFromOp(vm.db, vm.op, vm.target.Info.Gid)
+	if err != nil {
+		opLogger.LogError("Failed to get bricks from op: %v", err)
+		return err
+	}
+	err = vm.target.destroyMigrationTarget(vm.db, executor, brick_entries)
+	if err != nil {
+		opLogger.LogError("Error on migrate volume rollback: %v", err)
+		return err
+	}
+	return vm.db.Update(func(tx *bolt.Tx) error {
+		err := vm.target.removeMigrationTarget(tx, brick_entries)
+		if err != nil {
+			return err
+		}
+		return vm.op.Delete(tx)
+	})
+}
+
+// Finalize leaves the migration waiting for the cutover. The pending
+// operation entry is kept.
+func (vm *VolumeMigrateOperation) Finalize() error {
+	releaseMigration(vm.vol.Info.Id)
+	return nil
+}
+
+// VolumeMigrateCutoverOperation implements the operation functions
+// used to complete the migration of a volume once the caller confirms
+// it. The changes made to the volume since it was copied are copied
+// again, the volume is deleted from its cluster and the volume entry
+// takes over the volume created on the target cluster.
+type VolumeMigrateCutoverOperation struct {
+	OperationManager
+	noRetriesOperation
+	vol       *VolumeEntry
+	target    *VolumeEntry
+	reclaimed map[string]bool // gets set in Exec()
+}
+
+func NewVolumeMigrateCutoverOperation(
+	vol *VolumeEntry, db wdb.DB) *VolumeMigrateCutoverOperation {
+
+	return &VolumeMigrateCutoverOperation{
+		OperationManager: OperationManager{
+			db: db,
+			op: NewPendingOperationEntry(NEW_ID),
+		},
+		vol: vol,
+	}
+}
+
+func (vc *VolumeMigrateCutoverOperation) Label() string {
+	return "Cut Over Volume Migration"
+}
+
+func (vc *VolumeMigrateCutoverOperation) ResourceUrl() string {
+	return fmt.Sprintf("/volumes/%v", vc.vol.Info.Id)
+}
+
+// Build loads the migration of the volume and records the start of
+// the cutover. A cutover that did not complete is resumed.
+func (vc *VolumeMigrateCutoverOperation) Build() error {
+	if err := claimMigration(vc.vol.Info.Id); err != nil {
+		return err
+	}
+	err := vc.db.Update(func(tx *bolt.Tx) error {
+		v, err := NewVolumeEntryFromId(tx, vc.vol.Info.Id)
+		if err != nil {
+			return err
+		}
+		vc.vol = v
+
+		op, err := volumeMigrationOp(tx, v.Info.Id)
+		if err != nil {
+			return err
+		}
+		if op == nil {
+			return ErrNotFound
+		}
+		state, targetId, err := migrationFromOp(op)
+		if err != nil {
+			return err
+		}
+		if state == api.MigrationCreating || state == api.MigrationCopying {
+			return fmt.Errorf("migration of volume %v is %v, "+
+				"it can only be cut over once ready", v.Info.Id, state)
+		}
+		vc.target, err = NewVolumeEntryFromId(tx, targetId)
+		if err != nil {
+			return err
+		}
+		vc.op = op
+		if state == api.MigrationReady {
+			vc.op.SetMigrationState(api.MigrationCutover)
+			return vc.op.Save(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		releaseMigration(vc.vol.Info.Id)
+	}
+	return err
+}
+
+// Exec makes the volume read-only, copies the changes made to it since
+// the last copy and deletes the volume and its bricks from the source
+// cluster.
+func (vc *VolumeMigrateCutoverOperation) Exec(executor executors.Executor) error {
+	state, _, err := migrationFromOp(vc.op)
+	if err != nil {
+		return err
+	}
+
+	if state == api.MigrationCutover {
+		err = vc.vol.setMigrationReadOnly(vc.db, executor, true)
+		if err != nil {
+			opLogger.LogError("Error making volume %v read-only: %v",
+				vc.vol.Info.Name, err)
+			return err
+		}
+		err = vc.vol.syncMigration(vc.db, executor, vc.target)
+		if err != nil {
+			opLogger.LogError("Error copying volume %v to cluster %v: %v",
+				vc.vol.Info.Name, vc.target.Info.Cluster, err)
+			return err
+		}
+		state = api.MigrationRetiring
+		if err := saveMigrationState(vc.db, vc.op, state); err != nil {
+			return err
+		}
+	}
+
+	brick_entries, err := vc.vol.deleteVolumeComponents(vc.db)
+	if err != nil {
+		return err
+	}
+	sshhost, err := vc.vol.manageHostFromBricks(vc.db, brick_entries)
+	if err != nil {
+		return err
+	}
+	vc.reclaimed, err = vc.vol.deleteVolumeExec(vc.db, executor,
+		brick_entries, sshhost)
+	if err != nil && !strings.Contains(err.Error(), "does not exist") {
+		opLogger.LogError("Error deleting volume %v from cluster %v: %v",
+			vc.vol.Info.Name, vc.vol.Info.Cluster, err)
+		return err
+	}
+	return nil
+}
+
+// Rollback lets the migration be cut over again. If the data could
+// not be copied the volume is left on its cluster and made writable.
+func (vc *VolumeMigrateCutoverOperation) Rollback(executor executors.Executor) error {
+	defer releaseMigration(vc.vol.Info.Id)
+
+	state, _, err := migrationFromOp(vc.op)
+	if err != nil {
+		return err
+	}
+	if state != api.MigrationCutover {
+		return nil
+	}
+	err = vc.vol.setMigrationReadOnly(vc.db, executor, false)
+	if err != nil {
+		opLogger.LogError("Action Required: volume %v may have been left "+
+			"read-only: %v", vc.vol.Info.Name, err)
+	}
+	return saveMigrationState(vc.db, vc.op, api.MigrationReady)
+}
+
+// Finalize moves the volume entry to the target cluster and removes
+// the entries of the volume created for the migration.
+func (vc *VolumeMigrateCutoverOperation) Finalize() error {
+	defer releaseMigration(vc.vol.Info.Id)
+
+	return vc.db.Update(func(tx *bolt.Tx) error {
+		err := vc.vol.saveMigratedVolume(tx, vc.op, vc.target, vc.reclaimed)
+		if err != nil {
+			return err
+		}
+		return vc.op.Delete(tx)
+	})
+}
+
+// VolumeMigrateCancelOperation implements the operation functions used
+// to cancel the migration of a volume before it is cut over. The volume
+// created on the target cluster is deleted.
+type VolumeMigrateCancelOperation struct {
+	OperationManager
+	noRetriesOperation
+	vol    *VolumeEntry
+	target *VolumeEntry
+}
+
+func NewVolumeMigrateCancelOperation(
+	vol *VolumeEntry, db wdb.DB) *VolumeMigrateCancelOperation {
+
+	return &VolumeMigrateCancelOperation{
+		OperationManager: OperationManager{
+			db: db,
+			op: NewPendingOperationEntry(NEW_ID),
+		},
+		vol: vol,
+	}
+}
+
+func (vc *VolumeMigrateCancelOperation) Label() string {
+	return "Cancel Volume Migration"
+}
+
+func (vc *VolumeMigrateCancelOperation) ResourceUrl() string {
+	return ""
+}
+
+// Build loads the migration of the volume.
+func (vc *VolumeMigrateCancelOperation) Build() error {
+	if err := claimMigration(vc.vol.Info.Id); err != nil {
+		return err
+	}
+	err := vc.db.View(func(tx *bolt.Tx) error {
+		op, err := volumeMigrationOp(tx, vc.vol.Info.Id)
+		if err != nil {
+			return err
+		}
+		if op == nil {
+			return ErrNotFound
+		}
+		if migrationCutOver(op) {
+			return fmt.Errorf("migration of volume %v is being cut over",
+				vc.vol.Info.Id)
+		}
+		_, targetId, err := migrationFromOp(op)
+		if err != nil {
+			return err
+		}
+		vc.target, err = NewVolumeEntryFromId(tx, targetId)
+		if err != nil {
+			return err
+		}
+		vc.op = op
+		return nil
+	})
+	if err != nil {
+		releaseMigration(vc.vol.Info.Id)
+	}
+	return err
+}
+
+// Exec deletes the volume and bricks created on the target cluster.
+func (vc *VolumeMigrateCancelOperation) Exec(executor executors.Executor) error {
+	brick_entries, err := bricksFromOp(vc.db, vc.op, vc.target.Info.Gid)
+	if err != nil {
+		opLogger.LogError("Failed to get bricks from op: %v", err)
+		return err
+	}
+	err = vc.target.destroyMigrationTarget(vc.db, executor, brick_entries)
+	if err != nil {
+		opLogger.LogError("Error cancelling volume migration: %v", err)
+	}
+	return err
+}
+
+// Rollback leaves the migration as it was.
+func (vc *VolumeMigrateCancelOperation) Rollback(executor executors.Executor) error {
+	releaseMigration(vc.vol.Info.Id)
+	return nil
+}
+
+// Finalize removes the entries of the volume created on the target
+// cluster and the migration from the db.
+func (vc *VolumeMigrateCancelOperation) Finalize() error {
+	defer releaseMigration(vc.vol.Info.Id)
+
+	return vc.db.Update(func(tx *bolt.Tx) error {
+		brick_entries, err := bricksFromOp(wdb.WrapTx(tx), vc.op,
+			vc.target.Info.Gid)
+		if err != nil {
+			opLogger.LogError("Failed to get bricks from op: %v", err)
+			return err
+		}
+		err = vc.target.removeMigrationTarget(tx, brick_entries)
+		if err != nil {
+			return err
+		}
+		return vc.op.Delete(tx)
+	})
+}
+
 // VolumeDeleteOperation implements the operation functions used to
 // delete an existing volume.
 type VolumeDeleteOperation struct {
@@ -407,7 +1063,7 @@ func (vdel *VolumeDeleteOperation) Build() error {
 func (vdel *VolumeDeleteOperation) Exec(executor executors.Executor) error {
 	brick_entries, err := bricksFromOp(vdel.db, vdel.op, vdel.vol.Info.Gid)
 	if err != nil {
-		logger.LogError("Failed to get bricks from op: %v", err)
+		opLogger.LogError("Failed to get bricks from op: %v", err)
 		return err
 	}
 	sshhost, err := vdel.vol.manageHostFromBricks(vdel.db, brick_entries)
@@ -416,7 +1072,7 @@ func (vdel *VolumeDeleteOperation) Exec(executor executors.Executor) error {
 	}
 	vdel.reclaimed, err = vdel.vol.deleteVolumeExec(vdel.db, executor, brick_entries, sshhost)
 	if err != nil {
-		logger.LogError("Error executing delete volume: %v", err)
+		opLogger.LogError("Error executing delete volume: %v", err)
 	}
 	return err
 }
@@ -429,7 +1085,7 @@ func (vdel *VolumeDeleteOperation) Rollback(executor executors.Executor) error {
 		txdb := wdb.WrapTx(tx)
 		brick_entries, err := bricksFromOp(txdb, vdel.op, vdel.vol.Info.Gid)
 		if err != nil {
-			logger.LogError("Failed to get bricks from op: %v", err)
+			opLogger.LogError("Failed to get bricks from op: %v", err)
 			return err
 		}
 
@@ -457,7 +1113,7 @@ func (vdel *VolumeDeleteOperation) Finalize() error {
 
 		brick_entries, err := bricksFromOp(txdb, vdel.op, vdel.vol.Info.Gid)
 		if err != nil {
-			logger.LogError("Failed to get bricks from op: %v", err)
+			opLogger.LogError("Failed to get bricks from op: %v", err)
 			return err
 		}
 
@@ -474,12 +1130,12 @@ func (vdel *VolumeDeleteOperation) Finalize() error {
 
 				device, err := NewDeviceEntryFromId(tx, dev_id)
 				if err != nil {
-					logger.Err(err)
+					opLogger.Err(err)
 					return err
 				}
 
 				// Deallocate space on device
-				device.StorageFree(device.SpaceNeeded(b.Info.Size, float64(vdel.vol.Info.Snapshot.Factor)).Total)
+				device.StorageFree(b.TotalSize())
 				device.Save(tx)
 			}
 		}
@@ -704,6 +1360,9 @@ func (bvc *BlockVolumeCreateOperation) Build() error {
 			if err != nil {
 				return err
 			}
+			// the bricks of the new hosting volume must satisfy
+			// the placement selector of the block volume
+			vol.Info.Selector = bvc.bvol.Info.Selector
 			brick_entries, err := vol.createVolumeComponents(txdb)
 			if err != nil {
 				return err
@@ -746,7 +1405,7 @@ func (bvc *BlockVolumeCreateOperation) volAndBricks(db wdb.RODB) (
 	vol = nil
 	volume_entries, err := volumesFromOp(db, bvc.op)
 	if err != nil {
-		logger.LogError("Failed to get volumes from op: %v", err)
+		opLogger.LogError("Failed to get volumes from op: %v", err)
 		return
 	}
 	// try to get gid now even though we haven't done any sanity checks
@@ -757,21 +1416,21 @@ func (bvc *BlockVolumeCreateOperation) volAndBricks(db wdb.RODB) (
 	}
 	brick_entries, err = bricksFromOp(db, bvc.op, brickGid)
 	if err != nil {
-		logger.LogError("Failed to get bricks from op: %v", err)
+		opLogger.LogError("Failed to get bricks from op: %v", err)
 		return
 	}
 
 	if len(volume_entries) > 1 {
-		err = logger.LogError("Unexpected number of new volume entries (%v)",
+		err = opLogger.LogError("Unexpected number of new volume entries (%v)",
 			len(volume_entries))
 		return
 	}
 	if len(volume_entries) > 0 && len(brick_entries) == 0 {
-		err = logger.LogError("Cannot create a new block hosting volume without bricks")
+		err = opLogger.LogError("Cannot create a new block hosting volume without bricks")
 		return
 	}
 	if len(volume_entries) == 0 && len(brick_entries) > 0 {
-		err = logger.LogError("Cannot create bricks without a hosting volume")
+		err = opLogger.LogError("Cannot create bricks without a hosting volume")
 		return
 	}
 
@@ -791,7 +1450,7 @@ func (bvc *BlockVolumeCreateOperation) Exec(executor executors.Executor) error {
 	if vol != nil {
 		err = vol.createVolumeExec(bvc.db, executor, brick_entries)
 		if err != nil {
-			logger.LogError("Error executing create volume: %v", err)
+			opLogger.LogError("Error executing create volume: %v", err)
 			return err
 		}
 	}
@@ -801,7 +1460,7 @@ func (bvc *BlockVolumeCreateOperation) Exec(executor executors.Executor) error {
 	// resumeable if we ever add resume support to normal volume create.
 	err = bvc.bvol.createBlockVolume(bvc.db, executor, bvc.bvol.Info.BlockHostingVolume)
 	if err != nil {
-		logger.LogError("Error executing create block volume: %v", err)
+		opLogger.LogError("Error executing create block volume: %v", err)
 	}
 	return err
 }
@@ -860,7 +1519,7 @@ func (bvc *BlockVolumeCreateOperation) Rollback(executor executors.Executor) err
 	if vol != nil {
 		err = vol.cleanupCreateVolume(bvc.db, executor, brick_entries)
 		if err != nil {
-			logger.LogError("Error on create volume rollback: %v", err)
+			opLogger.LogError("Error on create volume rollback: %v", err)
 			return err
 		}
 	}
@@ -942,7 +1601,7 @@ func (vdel *BlockVolumeDeleteOperation) Finalize() error {
 	return vdel.db.Update(func(tx *bolt.Tx) error {
 		txdb := wdb.WrapTx(tx)
 		if e := vdel.bvol.removeComponents(txdb); e != nil {
-			logger.LogError("Failed to remove block volume from db")
+			opLogger.LogError("Failed to remove block volume from db")
 			return e
 		}
 
@@ -1004,7 +1663,7 @@ func (dro *DeviceRemoveOperation) Build() error {
 		if p, err := PendingOperationsOnDevice(txdb, d.Info.Id); err != nil {
 			return err
 		} else if p {
-			logger.LogError("Found operations still pending on device."+
+			opLogger.LogError("Found operations still pending on device."+
 				" Can not remove device %v at this time.",
 				d.Info.Id)
 			return ErrConflict
@@ -1087,7 +1746,7 @@ func bricksFromOp(db wdb.RODB,
 			if a.Change == OpAddBrick || a.Change == OpDeleteBrick {
 				brick, err := NewBrickEntryFromId(tx, a.Id)
 				if err != nil {
-					logger.LogError("failed to find brick with id: %v", a.Id)
+					opLogger.LogError("failed to find brick with id: %v", a.Id)
 					return err
 				}
 				// this next line is a bit of an unfortunate hack because
@@ -1148,33 +1807,47 @@ func AsyncHttpOperation(app *App,
 	op Operation) error {
 
 	label := op.Label()
+	start := time.Now()
+	executor := app.requestExecutor(r)
+	oplog := executorLogger(executor)
 	if err := op.Build(); err != nil {
-		logger.LogError("%v Build Failed: %v", label, err)
+		oplog.LogError("%v Build Failed: %v", label, err)
+		observeOperation(label, start, err)
 		return err
 	}
 
-	app.asyncManager.AsyncHttpRedirectFunc(w, r, func() (string, error) {
-		logger.Info("Started async operation: %v", label)
-		if err := op.Exec(app.executor); err != nil {
+	recordOperationEvent(app.db, op, api.EventCreate, "started")
+	app.asyncManager.AsyncHttpRedirectFunc(w, r, func() (url string, err error) {
+		defer func() {
+			observeOperation(label, start, err)
+			state := "succeeded"
+			if err != nil {
+				state = "failed"
+			}
+			recordOperationEvent(app.db, op, api.EventDelete, state)
+			notifyOperation(app.webhooks, op, err)
+		}()
+		oplog.Info("Started async operation: %v", label)
+		if err := op.Exec(executor); err != nil {
 			if _, ok := err.(OperationRetryError); ok && op.MaxRetries() > 0 {
-				logger.Warning("%v Exec requested retry", label)
-				err := retryOperation(op, app.executor)
+				oplog.Warning("%v Exec requested retry", label)
+				err := retryOperation(op, executor)
 				if err != nil {
 					return "", err
 				}
 				return op.ResourceUrl(), nil
 			}
-			if rerr := op.Rollback(app.executor); rerr != nil {
-				logger.LogError("%v Rollback error: %v", label, rerr)
+			if rerr := op.Rollback(executor); rerr != nil {
+				oplog.LogError("%v Rollback error: %v", label, rerr)
 			}
-			logger.LogError("%v Failed: %v", label, err)
+			oplog.LogError("%v Failed: %v", label, err)
 			return "", err
 		}
 		if err := op.Finalize(); err != nil {
-			logger.LogError("%v Finalize failed: %v", label, err)
+			oplog.LogError("%v Finalize failed: %v", label, err)
 			return "", err
 		}
-		logger.Info("%v succeeded", label)
+		oplog.Info("%v succeeded", label)
 		return op.ResourceUrl(), nil
 	})
 	return nil
@@ -1182,32 +1855,51 @@ func AsyncHttpOperation(app *App,
 
 // RunOperation performs all steps of an Operation and returns
 // an error if any of those steps fail. This function is meant to
-// make it easy to run an operation outside of the rest endpoints
-// and should only be used in test code.
+// make it easy to run an operation outside of the rest endpoints.
+// Like AsyncHttpOperation it records the events of the operation
+// and notifies the webhooks of the active app.
 func RunOperation(o Operation,
 	executor executors.Executor) (err error) {
 
 	label := o.Label()
+	start := time.Now()
+	oplog := executorLogger(executor)
+	db := operationDb(o)
+	started := false
 	defer func() {
+		observeOperation(label, start, err)
 		if err != nil {
-			logger.LogError("Error in %v: %v", label, err)
+			oplog.LogError("Error in %v: %v", label, err)
 		}
+		if started {
+			state := "succeeded"
+			if err != nil {
+				state = "failed"
+			}
+			recordOperationEvent(db, o, api.EventDelete, state)
+		}
+		// unlike in a request nobody is told of a failed build
+		notifyOperation(currentWebhookNotifier, o, err)
 	}()
 
-	logger.Info("Running %v", o.Label())
+	oplog.Info("Running %v", o.Label())
 	if err := o.Build(); err != nil {
-		logger.LogError("%v Build Failed: %v", label, err)
+		oplog.LogError("%v Build Failed: %v", label, err)
 		return err
 	}
+	if db != nil {
+		recordOperationEvent(db, o, api.EventCreate, "started")
+		started = true
+	}
 	if err := o.Exec(executor); err != nil {
 		if _, ok := err.(OperationRetryError); ok && o.MaxRetries() > 0 {
-			logger.Warning("%v Exec requested retry", label)
+			oplog.Warning("%v Exec requested retry", label)
 			return retryOperation(o, executor)
 		}
 		if rerr := o.Rollback(executor); rerr != nil {
-			logger.LogError("%v Rollback error: %v", label, rerr)
+			oplog.LogError("%v Rollback error: %v", label, rerr)
 		}
-		logger.LogError("%v Failed: %v", label, err)
+		oplog.LogError("%v Failed: %v", label, err)
 		return err
 	}
 	if err := o.Finalize(); err != nil {
@@ -1221,16 +1913,17 @@ func retryOperation(o Operation,
 
 	label := o.Label()
 	max := o.MaxRetries()
+	oplog := executorLogger(executor)
 	for i := 0; i < max; i++ {
-		logger.Info("Retry %v (%v)", label, i+1)
+		oplog.Info("Retry %v (%v)", label, i+1)
 		if e := o.Rollback(executor); e != nil {
 			// when retrying rollback must succeed cleanly or it
 			// is not safe to retry
-			logger.LogError("%v Rollback error: %v", label, e)
+			oplog.LogError("%v Rollback error: %v", label, e)
 			return e
 		}
 		if e := o.Build(); e != nil {
-			logger.LogError("%v Build Failed: %v", label, e)
+			oplog.LogError("%v Build Failed: %v", label, e)
 			return e
 		}
 		err = o.Exec(executor)
@@ -1238,13 +1931,13 @@ func retryOperation(o Operation,
 			// exec succeeded. Finalize it and we're outta here.
 			return o.Finalize()
 		}
-		logger.LogError("%v Failed: %v", label, err)
+		oplog.LogError("%v Failed: %v", label, err)
 		if _, ok := err.(OperationRetryError); !ok {
 			break
 		}
 	}
 	if e := o.Rollback(executor); e != nil {
-		logger.LogError("%v Rollback error: %v", label, e)
+		oplog.LogError("%v Rollback error: %v", label, e)
 	}
 	// if we exceeded our retries, pull the "real" error out
 	// of the retry error so we return that
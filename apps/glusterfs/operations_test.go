@@ -758,6 +758,139 @@ func TestVolumeExpandOperation(t *testing.T) {
 	})
 }
 
+func TestVolumeConvertOperationRollback(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	req := &api.VolumeCreateRequest{}
+	req.Size = 100
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+	vol := NewVolumeEntryFromRequest(req)
+	err = vol.Create(app.db, app.executor)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	freeSpace := func() uint64 {
+		var free uint64
+		app.db.View(func(tx *bolt.Tx) error {
+			dl, e := DeviceList(tx)
+			tests.Assert(t, e == nil, "expected e == nil, got", e)
+			for _, id := range dl {
+				d, e := NewDeviceEntryFromId(tx, id)
+				tests.Assert(t, e == nil, "expected e == nil, got", e)
+				free += d.Info.Storage.Free
+			}
+			return nil
+		})
+		return free
+	}
+	free := freeSpace()
+
+	app.xo.MockVolumeInfo = func(host string, volume string) (*executors.Volume, error) {
+		return mockVolumeInfoFromDb(app.db, volume)
+	}
+	app.xo.MockVolumeConvert = func(host string, vcr *executors.VolumeConvertRequest) (*executors.Volume, error) {
+		return nil, fmt.Errorf("remove-brick failed")
+	}
+
+	vc := NewVolumeConvertOperation(vol, app.db, true)
+	err = RunOperation(vc, app.executor)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// the volume keeps its bricks and durability
+	app.db.View(func(tx *bolt.Tx) error {
+		bl, e := BrickList(tx)
+		tests.Assert(t, e == nil, "expected e == nil, got", e)
+		tests.Assert(t, len(bl) == 3, "expected len(bl) == 3, got:", len(bl))
+		for _, id := range bl {
+			b, e := NewBrickEntryFromId(tx, id)
+			tests.Assert(t, e == nil, "expected e == nil, got", e)
+			tests.Assert(t, b.Pending.Id == "",
+				"expected brick not pending, got:", b.Pending.Id)
+		}
+		po, e := PendingOperationList(tx)
+		tests.Assert(t, e == nil, "expected e == nil, got", e)
+		tests.Assert(t, len(po) == 0, "expected len(po) == 0, got:", len(po))
+		v, e := NewVolumeEntryFromId(tx, vol.Info.Id)
+		tests.Assert(t, e == nil, "expected e == nil, got", e)
+		tests.Assert(t, len(v.Bricks) == 3,
+			"expected len(v.Bricks) == 3, got:", len(v.Bricks))
+		tests.Assert(t, !v.HasArbiterOption(), "expected no arbiter option")
+		return nil
+	})
+	tests.Assert(t, freeSpace() == free,
+		"expected free space", free, "got:", freeSpace())
+}
+func TestVolumeConvertOperationAddBrickFails(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	req := &api.VolumeCreateRequest{}
+	req.Size = 100
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+	vol := NewVolumeEntryFromRequest(req)
+	err = vol.Create(app.db, app.executor)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	app.xo.MockVolumeInfo = func(host string, volume string) (*executors.Volume, error) {
+		return mockVolumeInfoFromDb(app.db, volume)
+	}
+	app.xo.MockVolumeConvert = func(host string, vcr *executors.VolumeConvertRequest) (*executors.Volume, error) {
+		if len(vcr.RemoveBricks) > 0 {
+			return &executors.Volume{}, nil
+		}
+		return nil, fmt.Errorf("add-brick failed")
+	}
+
+	vc := NewVolumeConvertOperation(vol, app.db, true)
+	err = RunOperation(vc, app.executor)
+	tests.Assert(t, err != nil, "expected err != nil")
+
+	// the bricks were already removed on gluster, the operation is
+	// left pending instead of restoring the old bricks in the db
+	app.db.View(func(tx *bolt.Tx) error {
+		po, e := PendingOperationList(tx)
+		tests.Assert(t, e == nil, "expected e == nil, got", e)
+		tests.Assert(t, len(po) == 1, "expected len(po) == 1, got:", len(po))
+		op, e := NewPendingOperationEntryFromId(tx, po[0])
+		tests.Assert(t, e == nil, "expected e == nil, got", e)
+		tests.Assert(t, op.ConvertBricksRemoved(),
+			"expected op to record the removed bricks")
+		bl, e := BrickList(tx)
+		tests.Assert(t, e == nil, "expected e == nil, got", e)
+		tests.Assert(t, len(bl) == 4, "expected len(bl) == 4, got:", len(bl))
+		v, e := NewVolumeEntryFromId(tx, vol.Info.Id)
+		tests.Assert(t, e == nil, "expected e == nil, got", e)
+		tests.Assert(t, !v.HasArbiterOption(), "expected no arbiter option")
+		return nil
+	})
+}
+
 func TestBlockVolumeCreateOperation(t *testing.T) {
 	tmpfile := tests.Tempfile()
 	defer os.Remove(tmpfile)
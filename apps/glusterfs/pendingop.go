@@ -11,6 +11,8 @@ package glusterfs
 
 import (
 	"fmt"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 )
 
 // The pendingop.go file defines the basic structures needed to track
@@ -36,8 +38,35 @@ const (
 	OperationDeleteBlockVolume
 	OperationRemoveDevice
 	OperationCloneVolume
+	OperationConvertVolume
+	OperationMigrateVolume
 )
 
+// Name returns a short name for the operation type.
+func (t PendingOperationType) Name() string {
+	switch t {
+	case OperationCreateVolume:
+		return "create_volume"
+	case OperationDeleteVolume:
+		return "delete_volume"
+	case OperationExpandVolume:
+		return "expand_volume"
+	case OperationCreateBlockVolume:
+		return "create_block_volume"
+	case OperationDeleteBlockVolume:
+		return "delete_block_volume"
+	case OperationRemoveDevice:
+		return "remove_device"
+	case OperationCloneVolume:
+		return "clone_volume"
+	case OperationConvertVolume:
+		return "convert_volume"
+	case OperationMigrateVolume:
+		return "migrate_volume"
+	}
+	return "unknown"
+}
+
 // PendingChangeType identifies what kind of lower-level new item or change
 // is being made to the system as part of a higher-level pending operation.
 type PendingChangeType int
@@ -55,8 +84,14 @@ const (
 	OpCloneVolume
 	OpSnapshotVolume
 	OpAddVolumeClone
+	OpConvertVolume
+	OpMigrateVolume
 )
 
+// convertBricksRemoved is the delta of an OpConvertVolume action once
+// the replaced bricks have been removed from the volume.
+const convertBricksRemoved = "bricks-removed"
+
 // PendingOperationAction tracks individual changes to entries within the
 // heketi db. It consists of a required change type and (heketi uuid) id,
 // as well as an optional delta object for extra metadata.
@@ -91,3 +126,27 @@ func (a PendingOperationAction) ExpandSize() (int, error) {
 	}
 	return 0, fmt.Errorf("Action delta for ExpandSize is missing/invalid")
 }
+
+// ConvertBricksRemoved returns true if the volume convert the
+// PendingOperationAction tracks has already taken the replaced bricks
+// out of the volume on gluster.
+func (a PendingOperationAction) ConvertBricksRemoved() bool {
+	if a.Change == OpConvertVolume {
+		if v, ok := a.Delta.(string); ok {
+			return v == convertBricksRemoved
+		}
+	}
+	return false
+}
+
+// MigrationState extracts the state of a volume migration from the
+// PendingOperationAction if the change type is correct. If the type
+// is not correct error will be non-nil.
+func (a PendingOperationAction) MigrationState() (api.MigrationState, error) {
+	if a.Change == OpMigrateVolume {
+		if v, ok := a.Delta.(string); ok {
+			return api.MigrationState(v), nil
+		}
+	}
+	return "", fmt.Errorf("Action delta for MigrationState is missing/invalid")
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/boltdb/bolt"
 	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 	"github.com/lpabon/godbc"
 )
@@ -47,7 +48,8 @@ func PendingOperationList(tx *bolt.Tx) ([]string, error) {
 }
 
 // HasPendingOperations returns true if the db contains one or more pending
-// operation entries. If the db cannot be read the function panics.
+// operation entries. Volume migrations are not counted as they can be
+// resumed. If the db cannot be read the function panics.
 func HasPendingOperations(db wdb.RODB) bool {
 	var pending bool
 	if err := db.View(func(tx *bolt.Tx) error {
@@ -55,7 +57,15 @@ func HasPendingOperations(db wdb.RODB) bool {
 		if err != nil {
 			return err
 		}
-		pending = (len(l) > 0)
+		for _, id := range l {
+			op, err := NewPendingOperationEntryFromId(tx, id)
+			if err != nil {
+				return err
+			}
+			if op.Type != OperationMigrateVolume {
+				pending = true
+			}
+		}
 		return nil
 	}); err != nil {
 		panic(err)
@@ -217,6 +227,58 @@ func (p *PendingOperationEntry) RecordExpandVolume(v *VolumeEntry, sizeGB int) {
 	p.Type = OperationExpandVolume
 }
 
+// RecordConvertVolume adds tracking metadata for a volume whose
+// durability is being changed to the PendingOperationEntry.
+func (p *PendingOperationEntry) RecordConvertVolume(v *VolumeEntry) {
+	p.recordChange(OpConvertVolume, v.Info.Id)
+	p.Type = OperationConvertVolume
+}
+
+// SetConvertBricksRemoved records that the volume convert has taken
+// the replaced bricks out of the volume on gluster.
+func (p *PendingOperationEntry) SetConvertBricksRemoved() {
+	for i, a := range p.Actions {
+		if a.Change == OpConvertVolume {
+			p.Actions[i].Delta = convertBricksRemoved
+		}
+	}
+}
+
+// ConvertBricksRemoved returns true if the volume convert has taken
+// the replaced bricks out of the volume on gluster.
+func (p *PendingOperationEntry) ConvertBricksRemoved() bool {
+	for _, a := range p.Actions {
+		if a.ConvertBricksRemoved() {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordMigrateVolume adds tracking metadata for a volume being
+// migrated to the volume created for it on another cluster.
+func (p *PendingOperationEntry) RecordMigrateVolume(src, target *VolumeEntry) {
+	godbc.Require(p.Id != "")
+	p.Actions = append(p.Actions,
+		PendingOperationAction{
+			Change: OpMigrateVolume,
+			Id:     src.Info.Id,
+			Delta:  string(api.MigrationCreating),
+		})
+	p.Type = OperationMigrateVolume
+	p.recordChange(OpAddVolume, target.Info.Id)
+	target.Pending.Id = p.Id
+}
+
+// SetMigrationState records the step the volume migration has reached.
+func (p *PendingOperationEntry) SetMigrationState(s api.MigrationState) {
+	for i, a := range p.Actions {
+		if a.Change == OpMigrateVolume {
+			p.Actions[i].Delta = string(s)
+		}
+	}
+}
+
 // RecordDeleteVolume adds tracking metadata for a to-be-deleted volume
 // to the PendingOperationEntry and BrickEntry.
 func (p *PendingOperationEntry) RecordDeleteVolume(v *VolumeEntry) {
@@ -9,6 +9,12 @@
 
 package glusterfs
 
+import (
+	"fmt"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
 type DeviceAndNode struct {
 	Device *DeviceEntry
 	Node   *NodeEntry
@@ -52,6 +58,38 @@ type PlacementOpts interface {
 	SetCount() int
 	// AverageFileSize returns the average file size for the volume
 	AverageFileSize() uint64
+	// AllocationStrategy returns the strategy used to order the
+	// devices, the default strategy is used if it is empty
+	AllocationStrategy() api.AllocationStrategy
+}
+
+// deviceRejecter can be implemented by PlacementOpts to be told
+// why the placer did not use a device for a brick.
+type deviceRejecter interface {
+	RejectDevice(d *DeviceEntry, reason string)
+}
+
+func rejectDevice(opts PlacementOpts, d *DeviceEntry,
+	format string, v ...interface{}) {
+
+	if r, ok := opts.(deviceRejecter); ok {
+		r.RejectDevice(d, fmt.Sprintf(format, v...))
+	}
+}
+
+// rejectNoSpace records why a brick did not fit on a device
+func rejectNoSpace(opts PlacementOpts, d *DeviceEntry,
+	brickSize uint64, snapFactor float64) {
+
+	if d.physicalLimitReached() {
+		p, _ := d.physicalUsage()
+		rejectDevice(opts, d, rejectPhysicalUsage,
+			p, d.overcommit.physicalLimit)
+		return
+	}
+	rejectDevice(opts, d, rejectTooSmall,
+		d.SpaceNeeded(brickSize, snapFactor).Total,
+		d.Info.Storage.Free)
 }
 
 // DeviceFilter functions can be defined by the caller of a
@@ -33,6 +33,9 @@ type ArbiterBrickPlacer struct {
 	// dep. injection & unit testing
 	canHostArbiter func(*DeviceEntry, DeviceSource) bool
 	canHostData    func(*DeviceEntry, DeviceSource) bool
+	// spread the bricks of a set across distinct regions and
+	// racks before distinct nodes, like the FailureDomainBrickPlacer
+	failureDomains bool
 }
 
 // Arbiter opts supports passing arbiter specific options
@@ -95,7 +98,7 @@ func (bp *ArbiterBrickPlacer) PlaceAll(
 
 	numBrickSets := opts.SetCount()
 	for sn := 0; sn < numBrickSets; sn++ {
-		logger.Info("Allocating brick set #%v", sn)
+		allocLogger.Info("Allocating brick set #%v", sn)
 		bs, ds, err := bp.newSets(
 			dsrc,
 			opts,
@@ -131,7 +134,7 @@ func (bp *ArbiterBrickPlacer) Replace(
 			"brick replace index out of bounds (got %v, set size %v)",
 			index, bs.SetSize)
 	}
-	logger.Info("Replace brick in brick set %v with index %v",
+	allocLogger.Info("Replace brick in brick set %v with index %v",
 		bs, index)
 
 	// we return a brick allocation for symmetry with PlaceAll
@@ -143,7 +146,7 @@ func (bp *ArbiterBrickPlacer) Replace(
 	wbs := r.BrickSets[0]
 	wds := r.DeviceSets[0]
 
-	dscan, err := bp.Scanner(dsrc)
+	dscan, err := bp.Scanner(dsrc, opts)
 	if err != nil {
 		return r, err
 	}
@@ -179,7 +182,7 @@ func (bp *ArbiterBrickPlacer) newSets(
 	ssize := opts.SetSize()
 	bs := NewSparseBrickSet(ssize)
 	ds := NewSparseDeviceSet(ssize)
-	dscan, err := bp.Scanner(dsrc)
+	dscan, err := bp.Scanner(dsrc, opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -215,33 +218,105 @@ func (bp *ArbiterBrickPlacer) placeBrickInSet(
 	ds *DeviceSet,
 	index int) error {
 
-	logger.Info("Placing brick in brick set at position %v", index)
-	for deviceId := range dscan.Scan(index) {
+	allocLogger.Info("Placing brick in brick set at position %v", index)
+	used, err := bp.usedFailureDomains(dsrc, bs, index)
+	if err != nil {
+		return err
+	}
 
+	// devices in a failure domain already used by the set are
+	// deferred, by the widest level they are free at, until no
+	// device in an unused domain is left. Devices not tried are
+	// handed back to the scanner for the next brick.
+	var deferred [failureDomainLevels][]string
+	defer func() {
+		for _, ids := range deferred {
+			dscan.PassOver(index, ids)
+		}
+	}()
+	tryDevice := func(deviceId string, level int) (bool, error) {
 		device, err := dsrc.Device(deviceId)
 		if err != nil {
-			return err
+			return false, err
+		}
+		if bp.failureDomains {
+			node, err := dsrc.Node(device.NodeId)
+			if err != nil {
+				return false, err
+			}
+			if free := used.widestFree(nodeFailureDomainKey(node)); free > level {
+				rejectDevice(opts.o, device, rejectSameDomain,
+					failureDomainLevelNames[free-1])
+				deferred[free] = append(deferred[free], deviceId)
+				return false, nil
+			}
 		}
 
 		err = bp.tryPlaceBrickOnDevice(
 			opts, pred, bs, ds, index, device)
 		switch err {
 		case tryPlaceAgain:
-			continue
+			return false, nil
 		case nil:
-			logger.Debug("Placed brick at index %v on device %v",
+			allocLogger.Debug("Placed brick at index %v on device %v",
 				index, deviceId)
-			return nil
+			return true, nil
 		default:
+			return false, err
+		}
+	}
+
+	for {
+		deviceId, ok := dscan.Next(index)
+		if !ok {
+			break
+		}
+		placed, err := tryDevice(deviceId, failureDomainRegion)
+		if placed || err != nil {
 			return err
 		}
 	}
+	for level := failureDomainRack; level < failureDomainLevels; level++ {
+		pending := deferred[level]
+		deferred[level] = nil
+		for i, deviceId := range pending {
+			placed, err := tryDevice(deviceId, level)
+			if placed || err != nil {
+				deferred[level] = pending[i+1:]
+				return err
+			}
+		}
+	}
 
 	// we exhausted all possible devices for this brick
-	logger.Debug("Can not find any device for brick (index=%v)", index)
+	allocLogger.Debug("Can not find any device for brick (index=%v)", index)
 	return ErrNoSpace
 }
 
+// usedFailureDomains returns the failure domains used by the bricks
+// of the set other than the one at the given index.
+func (bp *ArbiterBrickPlacer) usedFailureDomains(
+	dsrc DeviceSource,
+	bs *BrickSet,
+	index int) (failureDomainsInUse, error) {
+
+	used := newFailureDomainsInUse()
+	if !bp.failureDomains {
+		return used, nil
+	}
+	for i, b := range bs.Bricks {
+		if i == index || b == nil {
+			continue
+		}
+		node, err := dsrc.Node(b.Info.NodeId)
+		if err != nil {
+			return used, err
+		}
+		used.add(nodeFailureDomainKey(node))
+	}
+	return used, nil
+}
+
 // tryPlaceBrickOnDevice attempts to place a brick on the given device.
 // If placement is successful the brick and device sets are updated,
 // and the error is nil.
@@ -254,7 +329,7 @@ func (bp *ArbiterBrickPlacer) tryPlaceBrickOnDevice(
 	index int,
 	device *DeviceEntry) error {
 
-	logger.Debug("Trying to place brick on device %v (node %v)",
+	allocLogger.Debug("Trying to place brick on device %v (node %v)",
 		device.Info.Id, device.NodeId)
 
 	for i, b := range bs.Bricks {
@@ -272,14 +347,16 @@ func (bp *ArbiterBrickPlacer) tryPlaceBrickOnDevice(
 		if b.Info.NodeId == device.NodeId {
 			// this node is used by an existing brick in the set
 			// we can not use this device
-			logger.Debug("Node %v already in use by brick set (device %v)",
+			allocLogger.Debug("Node %v already in use by brick set (device %v)",
 				device.NodeId, device.Info.Id)
+			rejectDevice(opts.o, device, rejectSameNode)
 			return tryPlaceAgain
 		}
 	}
 
 	if pred != nil && !pred(bs, device) {
-		logger.Debug("Device %v rejected by predicate function", device.Info.Id)
+		allocLogger.Debug("Device %v rejected by predicate function", device.Info.Id)
+		rejectDevice(opts.o, device, rejectFiltered)
 		return tryPlaceAgain
 	}
 
@@ -287,14 +364,15 @@ func (bp *ArbiterBrickPlacer) tryPlaceBrickOnDevice(
 	origBrickSize, snapFactor := opts.o.BrickSizes()
 	brickSize := opts.brickSize
 	if brickSize != origBrickSize {
-		logger.Info("Placing brick with discounted size: %v", brickSize)
+		allocLogger.Info("Placing brick with discounted size: %v", brickSize)
 	}
 	brick := device.NewBrickEntry(brickSize, snapFactor,
 		opts.o.BrickGid(), opts.o.BrickOwner())
 	if brick == nil {
-		logger.Debug(
+		allocLogger.Debug(
 			"Unable to place a brick of size %v & factor %v on device %v",
 			brickSize, snapFactor, device.Info.Id)
+		rejectNoSpace(opts.o, device, brickSize, snapFactor)
 		return tryPlaceAgain
 	}
 
@@ -311,14 +389,18 @@ type arbiterDeviceScanner struct {
 	arbiterDone chan struct{}
 	dataDevs    <-chan string
 	dataDone    chan struct{}
+	// devices passed over by a brick of the set, offered again
+	// to the next brick placed from the same pool
+	arbiterLeft []string
+	dataLeft    []string
 }
 
 // Scanner returns a pointer to an arbiterDeviceScanner helper object.
 // This object can be used to range over the devices that a brick
 // may be placed on. The .Close method must be called to release
 // resources associated with this object.
-func (bp *ArbiterBrickPlacer) Scanner(dsrc DeviceSource) (
-	*arbiterDeviceScanner, error) {
+func (bp *ArbiterBrickPlacer) Scanner(dsrc DeviceSource,
+	opts PlacementOpts) (*arbiterDeviceScanner, error) {
 
 	dataRing := NewSimpleAllocatorRing()
 	arbiterRing := NewSimpleAllocatorRing()
@@ -337,17 +419,24 @@ func (bp *ArbiterBrickPlacer) Scanner(dsrc DeviceSource) (
 		// blocks may be true.
 		if bp.canHostArbiter(dan.Device, dsrc) {
 			arbiterRing.Add(sd)
+		} else {
+			rejectDevice(opts, dan.Device, rejectNoArbiter)
 		}
 		if bp.canHostData(dan.Device, dsrc) {
 			dataRing.Add(sd)
+		} else {
+			rejectDevice(opts, dan.Device, rejectNoData)
 		}
 	}
 
 	id := utils.GenUUID()
+	strategy := allocationStrategy(opts)
 	dataDevs, dataDone := make(chan string), make(chan struct{})
-	generateDevices(dataRing.GetDeviceList(id), dataDevs, dataDone)
+	generateDevices(sortDevices(dataRing.GetDeviceList(id), dnl, strategy),
+		dataDevs, dataDone)
 	arbiterDevs, arbiterDone := make(chan string), make(chan struct{})
-	generateDevices(arbiterRing.GetDeviceList(id), arbiterDevs, arbiterDone)
+	generateDevices(sortDevices(arbiterRing.GetDeviceList(id), dnl, strategy),
+		arbiterDevs, arbiterDone)
 	return &arbiterDeviceScanner{
 		arbiterDevs: arbiterDevs,
 		arbiterDone: arbiterDone,
@@ -375,6 +464,33 @@ func (dscan *arbiterDeviceScanner) Scan(index int) <-chan string {
 	return dscan.dataDevs
 }
 
+// Next returns the next eligible device for a brick in a brick set
+// with the position specified by index. Devices handed back with
+// PassOver are returned before the rest of the pool.
+func (dscan *arbiterDeviceScanner) Next(index int) (string, bool) {
+	left := &dscan.dataLeft
+	if index == arbiter_index {
+		left = &dscan.arbiterLeft
+	}
+	if len(*left) > 0 {
+		deviceId := (*left)[0]
+		*left = (*left)[1:]
+		return deviceId, true
+	}
+	deviceId, ok := <-dscan.Scan(index)
+	return deviceId, ok
+}
+
+// PassOver hands devices back to the pool used for the position
+// specified by index.
+func (dscan *arbiterDeviceScanner) PassOver(index int, ids []string) {
+	if index == arbiter_index {
+		dscan.arbiterLeft = append(dscan.arbiterLeft, ids...)
+	} else {
+		dscan.dataLeft = append(dscan.dataLeft, ids...)
+	}
+}
+
 func discountBrickSize(dataBrickSize, averageFileSize uint64) (brickSize uint64,
 	err error) {
 
@@ -387,7 +503,7 @@ func discountBrickSize(dataBrickSize, averageFileSize uint64) (brickSize uint64,
 	brickSize = dataBrickSize / averageFileSize
 
 	if brickSize < 16*MB {
-		logger.Info("Increasing calculated arbiter brickSize (%vKiB) "+
+		allocLogger.Info("Increasing calculated arbiter brickSize (%vKiB) "+
 			"to 16MiB, the minimum XFS filsystem size with 4KiB "+
 			"blocks.", brickSize)
 		brickSize = 16 * MB
@@ -399,7 +515,7 @@ func discountBrickSize(dataBrickSize, averageFileSize uint64) (brickSize uint64,
 func deviceHasArbiterTag(d *DeviceEntry, dsrc DeviceSource, v ...string) bool {
 	n, err := dsrc.Node(d.NodeId)
 	if err != nil {
-		logger.LogError("failed to fetch node (%v) for arbiter tag: %v",
+		allocLogger.LogError("failed to fetch node (%v) for arbiter tag: %v",
 			d.NodeId, err)
 		return false
 	}
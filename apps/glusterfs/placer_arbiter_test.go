@@ -14,6 +14,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/tests"
 )
 
@@ -109,6 +110,7 @@ type TestPlacementOpts struct {
 	setSize         int
 	setCount        int
 	averageFileSize uint64
+	allocation      api.AllocationStrategy
 }
 
 func (tpo *TestPlacementOpts) BrickSizes() (uint64, float64) {
@@ -131,6 +133,10 @@ func (tpo *TestPlacementOpts) SetCount() int {
 	return tpo.setCount
 }
 
+func (tpo *TestPlacementOpts) AllocationStrategy() api.AllocationStrategy {
+	return tpo.allocation
+}
+
 func (tpo *TestPlacementOpts) AverageFileSize() uint64 {
 	return tpo.averageFileSize
 }
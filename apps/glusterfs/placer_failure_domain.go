@@ -0,0 +1,285 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+	"github.com/lpabon/godbc"
+)
+
+// failure domain levels, from the widest to the narrowest
+const (
+	failureDomainRegion = iota
+	failureDomainRack
+	failureDomainHost
+	failureDomainLevels
+)
+
+var failureDomainLevelNames = []string{"region", "rack", "host"}
+
+// failureDomainKey identifies the domain a node belongs to at
+// each level. Racks are only unique within their region.
+type failureDomainKey [failureDomainLevels]string
+
+func nodeFailureDomainKey(n *NodeEntry) failureDomainKey {
+	fd := n.FailureDomain()
+	return failureDomainKey{
+		fd.Region,
+		fd.Region + "/" + fd.Rack,
+		n.Info.Id,
+	}
+}
+
+// hasFailureDomains returns true if any node of the device source is
+// labeled with a region or a rack. Errors are left for the placer
+// to report.
+func hasFailureDomains(dsrc DeviceSource) bool {
+	dnl, err := dsrc.Devices()
+	if err != nil {
+		return false
+	}
+	for _, dan := range dnl {
+		if dan.Node.FailureDomain() != (api.FailureDomain{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// failureDomainsInUse records the failure domains used by the
+// bricks of a set at each level.
+type failureDomainsInUse [failureDomainLevels]map[string]bool
+
+func newFailureDomainsInUse() failureDomainsInUse {
+	var used failureDomainsInUse
+	for level := range used {
+		used[level] = map[string]bool{}
+	}
+	return used
+}
+
+// add marks the domains of the given key as used at every level.
+func (used failureDomainsInUse) add(key failureDomainKey) {
+	for level := range used {
+		used[level][key[level]] = true
+	}
+}
+
+// widestFree returns the widest level at which the given domain is
+// not used yet. Same node conflicts are left to the caller.
+func (used failureDomainsInUse) widestFree(key failureDomainKey) int {
+	for level := 0; level < failureDomainHost; level++ {
+		if !used[level][key[level]] {
+			return level
+		}
+	}
+	return failureDomainHost
+}
+
+// FailureDomainBrickPlacer places the bricks of each brick set
+// in different failure domains. Nodes are labeled with their
+// region and rack using the well-known failure domain tags and
+// the bricks of a set are spread across distinct regions if
+// possible, falling back to distinct racks and finally to
+// distinct nodes. Nodes without labels share a single region and
+// rack, so on an unlabeled cluster this places bricks exactly like
+// the StandardBrickPlacer.
+type FailureDomainBrickPlacer struct{}
+
+func NewFailureDomainBrickPlacer() *FailureDomainBrickPlacer {
+	return &FailureDomainBrickPlacer{}
+}
+
+func (bp *FailureDomainBrickPlacer) PlaceAll(
+	dsrc DeviceSource,
+	opts PlacementOpts,
+	pred DeviceFilter) (
+	*BrickAllocation, error) {
+
+	r := &BrickAllocation{
+		BrickSets:  []*BrickSet{},
+		DeviceSets: []*DeviceSet{},
+	}
+
+	numBrickSets := opts.SetCount()
+	ssize := opts.SetSize()
+	for sn := 0; sn < numBrickSets; sn++ {
+		allocLogger.Info("Allocating brick set #%v", sn)
+
+		// Generate an id for the brick, this is used as a
+		// random index into the ring
+		brickId := utils.GenUUID()
+
+		devices, err := placementDevices(dsrc, opts, brickId)
+		if err != nil {
+			return r, err
+		}
+
+		bs := NewBrickSet(ssize)
+		ds := NewDeviceSet(ssize)
+		for i := 0; i < ssize; i++ {
+			brick, device, err := bp.findDeviceAndBrick(
+				dsrc, opts, pred, devices, bs)
+			if err != nil {
+				return r, err
+			}
+
+			// If the first in the set, then reset the id
+			if i == 0 {
+				brick.SetId(brickId)
+			}
+
+			bs.Add(brick)
+			ds.Add(device)
+			device.BrickAdd(brick.Id())
+		}
+		r.BrickSets = append(r.BrickSets, bs)
+		r.DeviceSets = append(r.DeviceSets, ds)
+	}
+
+	return r, nil
+}
+
+func (bp *FailureDomainBrickPlacer) Replace(
+	dsrc DeviceSource,
+	opts PlacementOpts,
+	pred DeviceFilter,
+	bs *BrickSet,
+	index int) (
+	*BrickAllocation, error) {
+
+	if index < 0 || index >= bs.SetSize {
+		return nil, fmt.Errorf(
+			"brick replace index out of bounds (got %v, set size %v)",
+			index, bs.SetSize)
+	}
+	allocLogger.Info("Replace brick in brick set %v with index %v",
+		bs, index)
+
+	r := &BrickAllocation{
+		BrickSets:  []*BrickSet{NewBrickSet(bs.SetSize)},
+		DeviceSets: []*DeviceSet{NewDeviceSet(bs.SetSize)},
+	}
+
+	brickId := utils.GenUUID()
+	devices, err := placementDevices(dsrc, opts, brickId)
+	if err != nil {
+		return r, err
+	}
+
+	// the new brick must be placed away from the remaining bricks
+	// of the set
+	others := NewBrickSet(bs.SetSize)
+	for i, b := range bs.Bricks {
+		if i != index {
+			others.Add(b)
+		}
+	}
+	newBrickEntry, newDeviceEntry, err := bp.findDeviceAndBrick(
+		dsrc, opts, pred, devices, others)
+	if err != nil {
+		return r, err
+	}
+	newBrickEntry.SetId(brickId)
+
+	newBricks := make([]*BrickEntry, bs.SetSize)
+	newDevices := make([]*DeviceEntry, bs.SetSize)
+	for i := 0; i < bs.SetSize; i++ {
+		if i == index {
+			newBricks[i] = newBrickEntry
+			newDevices[i] = newDeviceEntry
+		} else {
+			newBricks[i] = bs.Bricks[i]
+			d, err := dsrc.Device(bs.Bricks[i].Info.DeviceId)
+			if err != nil {
+				return r, err
+			}
+			newDevices[i] = d
+		}
+	}
+	r.BrickSets[0].Bricks = newBricks
+	r.DeviceSets[0].Devices = newDevices
+
+	godbc.Require(r.BrickSets[0].Full())
+	godbc.Require(r.DeviceSets[0].Full())
+	return r, nil
+}
+
+// findDeviceAndBrick places a brick on a device whose failure domains
+// are not yet used by the bricks in the set. The widest level is
+// tried first and narrower levels are only used when no device in a
+// distinct domain of the wider level can hold the brick.
+func (bp *FailureDomainBrickPlacer) findDeviceAndBrick(
+	dsrc DeviceSource,
+	opts PlacementOpts,
+	pred DeviceFilter,
+	devices SimpleDevices,
+	bs *BrickSet) (*BrickEntry, *DeviceEntry, error) {
+
+	domains := map[string]failureDomainKey{}
+	domainOf := func(nodeId string) (failureDomainKey, error) {
+		if key, ok := domains[nodeId]; ok {
+			return key, nil
+		}
+		node, err := dsrc.Node(nodeId)
+		if err != nil {
+			return failureDomainKey{}, err
+		}
+		domains[nodeId] = nodeFailureDomainKey(node)
+		return domains[nodeId], nil
+	}
+
+	used := newFailureDomainsInUse()
+	for _, b := range bs.Bricks {
+		key, err := domainOf(b.Info.NodeId)
+		if err != nil {
+			return nil, nil, err
+		}
+		used.add(key)
+	}
+
+	for level := 0; level < failureDomainLevels; level++ {
+		for _, d := range devices {
+			key, err := domainOf(d.nodeId)
+			if err != nil {
+				return nil, nil, err
+			}
+			device, err := dsrc.Device(d.deviceId)
+			if err != nil {
+				return nil, nil, err
+			}
+			if used[level][key[level]] {
+				if level == failureDomainHost {
+					rejectDevice(opts, device, rejectSameNode)
+				} else {
+					rejectDevice(opts, device, rejectSameDomain,
+						failureDomainLevelNames[level])
+				}
+				continue
+			}
+			brick := tryAllocateBrickOnDevice(opts, pred, device, bs)
+			if brick == nil {
+				continue
+			}
+			return brick, device, nil
+		}
+		if len(bs.Bricks) > 0 && level+1 < failureDomainLevels {
+			allocLogger.Debug("Unable to place brick in a distinct %v, trying %v",
+				failureDomainLevelNames[level],
+				failureDomainLevelNames[level+1])
+		}
+	}
+
+	// No devices found
+	return nil, nil, ErrNoSpace
+}
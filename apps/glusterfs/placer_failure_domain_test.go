@@ -0,0 +1,284 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/heketi/tests"
+)
+
+func addFailureDomainNode(dsrc *TestDeviceSource,
+	nodeId, region, rack string, devices int) {
+
+	addDev := dsrc.MultiAdd(nodeId)
+	tags := map[string]string{}
+	if region != "" {
+		tags[TAG_FAILURE_DOMAIN_REGION] = region
+	}
+	if rack != "" {
+		tags[TAG_FAILURE_DOMAIN_RACK] = rack
+	}
+	dsrc.nodes[nodeId].SetTags(tags)
+	for i := 0; i < devices; i++ {
+		addDev(fmt.Sprintf("%v-d%v", nodeId, i),
+			fmt.Sprintf("/dev/x%v", i), 100*GB)
+	}
+}
+
+func failureDomainsOfSet(t *testing.T,
+	dsrc *TestDeviceSource, bs *BrickSet) (regions, racks map[string]bool) {
+
+	regions = map[string]bool{}
+	racks = map[string]bool{}
+	nodes := map[string]bool{}
+	for _, b := range bs.Bricks {
+		n, err := dsrc.Node(b.Info.NodeId)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, !nodes[n.Info.Id],
+			"expected distinct nodes, got duplicate:", n.Info.Id)
+		nodes[n.Info.Id] = true
+		key := nodeFailureDomainKey(n)
+		regions[key[failureDomainRegion]] = true
+		racks[key[failureDomainRack]] = true
+	}
+	return
+}
+
+func TestFailureDomainPlacerRegions(t *testing.T) {
+	dsrc := NewTestDeviceSource()
+	for _, region := range []string{"east", "west", "north"} {
+		for _, rack := range []string{"r1", "r2"} {
+			addFailureDomainNode(dsrc, region+"-"+rack, region, rack, 2)
+		}
+	}
+
+	opts := &TestPlacementOpts{
+		brickSize:       10 * GB,
+		brickSnapFactor: 1,
+		setSize:         3,
+		setCount:        4,
+	}
+
+	bp := NewFailureDomainBrickPlacer()
+	ba, err := bp.PlaceAll(dsrc, opts, nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(ba.BrickSets) == 4,
+		"expected len(ba.BrickSets) == 4, got:", len(ba.BrickSets))
+
+	for _, bs := range ba.BrickSets {
+		tests.Assert(t, bs.Full(), "expected brick set to be full")
+		regions, _ := failureDomainsOfSet(t, dsrc, bs)
+		tests.Assert(t, len(regions) == 3,
+			"expected bricks in 3 regions, got:", regions)
+	}
+}
+
+func TestFailureDomainPlacerFallback(t *testing.T) {
+	dsrc := NewTestDeviceSource()
+	addFailureDomainNode(dsrc, "east-r1-a", "east", "r1", 1)
+	addFailureDomainNode(dsrc, "east-r1-b", "east", "r1", 1)
+	addFailureDomainNode(dsrc, "east-r2-a", "east", "r2", 1)
+	addFailureDomainNode(dsrc, "west-r1-a", "west", "r1", 1)
+
+	opts := &TestPlacementOpts{
+		brickSize:       10 * GB,
+		brickSnapFactor: 1,
+		setSize:         3,
+		setCount:        5,
+	}
+
+	// only two regions exist, the third brick must go to a
+	// different rack than the other brick in its region
+	bp := NewFailureDomainBrickPlacer()
+	ba, err := bp.PlaceAll(dsrc, opts, nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	for _, bs := range ba.BrickSets {
+		regions, racks := failureDomainsOfSet(t, dsrc, bs)
+		tests.Assert(t, len(regions) == 2,
+			"expected bricks in 2 regions, got:", regions)
+		tests.Assert(t, len(racks) == 3,
+			"expected bricks in 3 racks, got:", racks)
+	}
+
+	// with four bricks per set one rack has to hold two bricks
+	// on different nodes
+	opts.setSize = 4
+	opts.setCount = 1
+	ba, err = bp.PlaceAll(dsrc, opts, nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	regions, racks := failureDomainsOfSet(t, dsrc, ba.BrickSets[0])
+	tests.Assert(t, len(regions) == 2,
+		"expected bricks in 2 regions, got:", regions)
+	tests.Assert(t, len(racks) == 3,
+		"expected bricks in 3 racks, got:", racks)
+
+	// no more nodes than bricks are available
+	opts.setSize = 5
+	ba, err = bp.PlaceAll(dsrc, opts, nil)
+	tests.Assert(t, err == ErrNoSpace, "expected err == ErrNoSpace, got:", err)
+}
+
+func TestFailureDomainPlacerUnlabeled(t *testing.T) {
+	dsrc := NewTestDeviceSource()
+	for i := 0; i < 3; i++ {
+		addFailureDomainNode(dsrc, fmt.Sprintf("node%v", i), "", "", 2)
+	}
+
+	opts := &TestPlacementOpts{
+		brickSize:       10 * GB,
+		brickSnapFactor: 1,
+		setSize:         3,
+		setCount:        2,
+	}
+
+	bp := NewFailureDomainBrickPlacer()
+	ba, err := bp.PlaceAll(dsrc, opts, nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	for _, bs := range ba.BrickSets {
+		tests.Assert(t, bs.Full(), "expected brick set to be full")
+		failureDomainsOfSet(t, dsrc, bs)
+	}
+}
+
+func TestFailureDomainPlacerReplace(t *testing.T) {
+	dsrc := NewTestDeviceSource()
+	addFailureDomainNode(dsrc, "east-a", "east", "r1", 1)
+	addFailureDomainNode(dsrc, "east-b", "east", "r2", 1)
+	addFailureDomainNode(dsrc, "west-a", "west", "r1", 1)
+	addFailureDomainNode(dsrc, "west-b", "west", "r2", 1)
+	addFailureDomainNode(dsrc, "north-a", "north", "r1", 1)
+
+	opts := &TestPlacementOpts{
+		brickSize:       10 * GB,
+		brickSnapFactor: 1,
+		setSize:         3,
+		setCount:        1,
+	}
+
+	bp := NewFailureDomainBrickPlacer()
+	ba, err := bp.PlaceAll(dsrc, opts, nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	bs := ba.BrickSets[0]
+	regions, _ := failureDomainsOfSet(t, dsrc, bs)
+	tests.Assert(t, len(regions) == 3,
+		"expected bricks in 3 regions, got:", regions)
+
+	// replacing any brick must keep the set in three regions
+	for index := 0; index < 3; index++ {
+		ba, err := bp.Replace(dsrc, opts, nil, bs, index)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		nbs := ba.BrickSets[0]
+		tests.Assert(t, nbs.Full(), "expected brick set to be full")
+		regions, _ := failureDomainsOfSet(t, dsrc, nbs)
+		tests.Assert(t, len(regions) == 3,
+			"expected bricks in 3 regions, got:", regions)
+	}
+
+	_, err = bp.Replace(dsrc, opts, nil, bs, 3)
+	tests.Assert(t, err != nil, "expected err != nil")
+}
+
+func TestPlacerForVolumeFailureDomains(t *testing.T) {
+	v := NewVolumeEntry()
+	av := NewVolumeEntry()
+	av.GlusterVolumeOptions = []string{HEKETI_ARBITER_KEY + " true"}
+
+	dsrc := NewTestDeviceSource()
+	for i := 0; i < 3; i++ {
+		addFailureDomainNode(dsrc, fmt.Sprintf("node%v", i), "", "", 1)
+	}
+	_, ok := PlacerForVolume(v, dsrc).(*StandardBrickPlacer)
+	tests.Assert(t, ok, "expected standard placer on an unlabeled cluster")
+	abp, ok := PlacerForVolume(av, dsrc).(*ArbiterBrickPlacer)
+	tests.Assert(t, ok, "expected arbiter placer for arbiter volume")
+	tests.Assert(t, !abp.failureDomains,
+		"expected arbiter placer to ignore failure domains")
+
+	addFailureDomainNode(dsrc, "east-a", "east", "", 1)
+	_, ok = PlacerForVolume(v, dsrc).(*FailureDomainBrickPlacer)
+	tests.Assert(t, ok, "expected failure domain placer on a labeled cluster")
+	abp, ok = PlacerForVolume(av, dsrc).(*ArbiterBrickPlacer)
+	tests.Assert(t, ok, "expected arbiter placer for arbiter volume")
+	tests.Assert(t, abp.failureDomains,
+		"expected arbiter placer to use failure domains")
+}
+
+func TestArbiterPlacerFailureDomains(t *testing.T) {
+	dsrc := NewTestDeviceSource()
+	for _, region := range []string{"east", "west", "north"} {
+		for _, rack := range []string{"r1", "r2"} {
+			for _, n := range []string{"a", "b"} {
+				addFailureDomainNode(dsrc,
+					region+"-"+rack+"-"+n, region, rack, 1)
+			}
+		}
+	}
+
+	opts := &TestPlacementOpts{
+		brickSize:       10 * GB,
+		brickSnapFactor: 1,
+		setSize:         3,
+		setCount:        4,
+		averageFileSize: 64 * KB,
+	}
+
+	bp := NewArbiterBrickPlacer()
+	bp.failureDomains = true
+	ba, err := bp.PlaceAll(dsrc, opts, nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(ba.BrickSets) == 4,
+		"expected len(ba.BrickSets) == 4, got:", len(ba.BrickSets))
+	for _, bs := range ba.BrickSets {
+		tests.Assert(t, bs.Full(), "expected brick set to be full")
+		regions, _ := failureDomainsOfSet(t, dsrc, bs)
+		tests.Assert(t, len(regions) == 3,
+			"expected bricks in 3 regions, got:", regions)
+	}
+
+	// replacing the arbiter brick must keep the set in three regions
+	bs := ba.BrickSets[0]
+	ba, err = bp.Replace(dsrc, opts, nil, bs, arbiter_index)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	regions, _ := failureDomainsOfSet(t, dsrc, ba.BrickSets[0])
+	tests.Assert(t, len(regions) == 3,
+		"expected bricks in 3 regions, got:", regions)
+}
+
+func TestArbiterPlacerFailureDomainsFallback(t *testing.T) {
+	dsrc := NewTestDeviceSource()
+	addFailureDomainNode(dsrc, "east-r1-a", "east", "r1", 1)
+	addFailureDomainNode(dsrc, "east-r1-b", "east", "r1", 1)
+	addFailureDomainNode(dsrc, "east-r2-a", "east", "r2", 1)
+	addFailureDomainNode(dsrc, "west-r1-a", "west", "r1", 1)
+
+	opts := &TestPlacementOpts{
+		brickSize:       10 * GB,
+		brickSnapFactor: 1,
+		setSize:         3,
+		setCount:        5,
+		averageFileSize: 64 * KB,
+	}
+
+	// only two regions exist, the third brick must go to a
+	// different rack than the other brick in its region
+	bp := NewArbiterBrickPlacer()
+	bp.failureDomains = true
+	ba, err := bp.PlaceAll(dsrc, opts, nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	for _, bs := range ba.BrickSets {
+		regions, racks := failureDomainsOfSet(t, dsrc, bs)
+		tests.Assert(t, len(regions) == 2,
+			"expected bricks in 2 regions, got:", regions)
+		tests.Assert(t, len(racks) == 3,
+			"expected bricks in 3 racks, got:", racks)
+	}
+}
@@ -0,0 +1,112 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// selectorMatchesTags returns true if the tags satisfy all the
+// terms of the placement selector.
+func selectorMatchesTags(s *api.PlacementSelector,
+	tags map[string]string) bool {
+
+	for k, v := range s.MatchTags {
+		if tv, ok := tags[k]; !ok || tv != v {
+			return false
+		}
+	}
+	for _, e := range s.MatchExpressions {
+		tv, ok := tags[e.Key]
+		found := false
+		if ok {
+			for _, v := range e.Values {
+				if v == tv {
+					found = true
+					break
+				}
+			}
+		}
+		switch e.Operator {
+		case api.PlacementSelectorIn:
+			if !found {
+				return false
+			}
+		case api.PlacementSelectorNotIn:
+			if found {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// selectorMatchesDevice returns true if the tags of the device,
+// merged with the tags of the node the device is on, satisfy the
+// placement selector.
+func selectorMatchesDevice(s *api.PlacementSelector,
+	d *DeviceEntry, n *NodeEntry) bool {
+
+	return selectorMatchesTags(s, MergeTags(n, d))
+}
+
+// SelectorDeviceFilter returns a device filter that only accepts
+// devices matching the placement selector. If a non-nil pred is
+// given the device must also be accepted by pred.
+func SelectorDeviceFilter(s *api.PlacementSelector,
+	dsrc DeviceSource, pred DeviceFilter) DeviceFilter {
+
+	if s == nil {
+		return pred
+	}
+	return func(bs *BrickSet, d *DeviceEntry) bool {
+		if pred != nil && !pred(bs, d) {
+			return false
+		}
+		n, err := dsrc.Node(d.NodeId)
+		if err != nil {
+			allocLogger.LogError("failed to fetch node (%v) for selector: %v",
+				d.NodeId, err)
+			return false
+		}
+		return selectorMatchesDevice(s, d, n)
+	}
+}
+
+// checkSelectorSatisfiable returns an error if no device in any of
+// the given clusters matches the placement selector. A selector
+// that matches some devices may still fail placement later if the
+// matching devices lack space or are on too few nodes.
+func checkSelectorSatisfiable(tx *bolt.Tx,
+	s *api.PlacementSelector, clusters []string) error {
+
+	if s == nil {
+		return nil
+	}
+	for _, clusterId := range clusters {
+		dnl, err := NewClusterDeviceSource(tx, clusterId).Devices()
+		if err != nil {
+			return err
+		}
+		for _, dan := range dnl {
+			if selectorMatchesDevice(s, dan.Device, dan.Node) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf(
+		"No online devices in clusters %v match the placement selector [%v]",
+		clusters, s)
+}
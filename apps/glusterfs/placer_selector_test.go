@@ -0,0 +1,293 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+func TestSelectorMatchesTags(t *testing.T) {
+	tags := map[string]string{
+		"disktype": "ssd",
+		"tenant":   "a",
+	}
+
+	ps := &api.PlacementSelector{}
+	tests.Assert(t, selectorMatchesTags(ps, tags),
+		"expected empty selector to match")
+
+	ps.MatchTags = map[string]string{"disktype": "ssd"}
+	tests.Assert(t, selectorMatchesTags(ps, tags),
+		"expected disktype=ssd to match")
+	ps.MatchTags = map[string]string{"disktype": "hdd"}
+	tests.Assert(t, !selectorMatchesTags(ps, tags),
+		"expected disktype=hdd not to match")
+	ps.MatchTags = map[string]string{"rack": ""}
+	tests.Assert(t, !selectorMatchesTags(ps, tags),
+		"expected missing tag not to match")
+
+	ps.MatchTags = nil
+	ps.MatchExpressions = []api.PlacementSelectorExpression{
+		{Key: "tenant", Operator: api.PlacementSelectorIn, Values: []string{"a", "b"}},
+	}
+	tests.Assert(t, selectorMatchesTags(ps, tags),
+		"expected tenant in (a,b) to match")
+	ps.MatchExpressions[0].Operator = api.PlacementSelectorNotIn
+	tests.Assert(t, !selectorMatchesTags(ps, tags),
+		"expected tenant notin (a,b) not to match")
+
+	ps.MatchExpressions[0].Key = "owner"
+	tests.Assert(t, selectorMatchesTags(ps, tags),
+		"expected owner notin (a,b) to match missing tag")
+	ps.MatchExpressions[0].Operator = api.PlacementSelectorIn
+	tests.Assert(t, !selectorMatchesTags(ps, tags),
+		"expected owner in (a,b) not to match missing tag")
+}
+
+func TestPlacementSelectorValidate(t *testing.T) {
+	ps := api.PlacementSelector{
+		MatchTags: map[string]string{"disktype": "ssd"},
+		MatchExpressions: []api.PlacementSelectorExpression{
+			{Key: "tenant", Operator: api.PlacementSelectorNotIn, Values: []string{"a"}},
+		},
+	}
+	tests.Assert(t, ps.Validate() == nil)
+
+	ps.MatchExpressions[0].Operator = "has"
+	tests.Assert(t, ps.Validate() != nil)
+
+	ps.MatchExpressions[0].Operator = api.PlacementSelectorIn
+	ps.MatchExpressions[0].Values = nil
+	tests.Assert(t, ps.Validate() != nil)
+
+	ps.MatchExpressions[0].Values = []string{"a"}
+	ps.MatchExpressions[0].Key = "bad key"
+	tests.Assert(t, ps.Validate() != nil)
+
+	ps.MatchExpressions = nil
+	ps.MatchTags = map[string]string{"": "x"}
+	tests.Assert(t, ps.Validate() != nil)
+}
+
+// tagSampleDevices sets the given tags on all the devices of the
+// first n nodes of the sample topology and returns the ids of the
+// tagged devices.
+func tagSampleDevices(t *testing.T, app *App,
+	n int, tags map[string]string) map[string]bool {
+
+	tagged := map[string]bool{}
+	err := app.db.Update(func(tx *bolt.Tx) error {
+		nodes, err := NodeList(tx)
+		if err != nil {
+			return err
+		}
+		for _, nodeId := range nodes[:n] {
+			node, err := NewNodeEntryFromId(tx, nodeId)
+			if err != nil {
+				return err
+			}
+			for _, deviceId := range node.Devices {
+				device, err := NewDeviceEntryFromId(tx, deviceId)
+				if err != nil {
+					return err
+				}
+				device.SetTags(copyTags(tags))
+				if err := device.Save(tx); err != nil {
+					return err
+				}
+				tagged[deviceId] = true
+			}
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	return tagged
+}
+
+func TestVolumeCreateWithSelector(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		6,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	ssd := tagSampleDevices(t, app, 3, map[string]string{"disktype": "ssd"})
+
+	v := createSampleReplicaVolumeEntry(100, 3)
+	v.Info.Selector = &api.PlacementSelector{
+		MatchTags: map[string]string{"disktype": "ssd"},
+	}
+	err = v.Create(app.db, app.executor)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	err = app.db.View(func(tx *bolt.Tx) error {
+		entry, err := NewVolumeEntryFromId(tx, v.Info.Id)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, entry.Info.Selector != nil)
+		for _, brickId := range entry.Bricks {
+			brick, err := NewBrickEntryFromId(tx, brickId)
+			tests.Assert(t, err == nil, "expected err == nil, got:", err)
+			tests.Assert(t, ssd[brick.Info.DeviceId],
+				"expected brick on ssd device, got:", brick.Info.DeviceId)
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil)
+
+	// expanding the volume keeps using the selected devices
+	err = v.Expand(app.db, app.executor, 100)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	err = app.db.View(func(tx *bolt.Tx) error {
+		for _, brickId := range v.Bricks {
+			brick, err := NewBrickEntryFromId(tx, brickId)
+			tests.Assert(t, err == nil, "expected err == nil, got:", err)
+			tests.Assert(t, ssd[brick.Info.DeviceId],
+				"expected brick on ssd device, got:", brick.Info.DeviceId)
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil)
+
+	// excluding the ssd devices places bricks elsewhere
+	v = createSampleReplicaVolumeEntry(100, 3)
+	v.Info.Selector = &api.PlacementSelector{
+		MatchExpressions: []api.PlacementSelectorExpression{
+			{Key: "disktype", Operator: api.PlacementSelectorNotIn, Values: []string{"ssd"}},
+		},
+	}
+	err = v.Create(app.db, app.executor)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	err = app.db.View(func(tx *bolt.Tx) error {
+		for _, brickId := range v.Bricks {
+			brick, err := NewBrickEntryFromId(tx, brickId)
+			tests.Assert(t, err == nil, "expected err == nil, got:", err)
+			tests.Assert(t, !ssd[brick.Info.DeviceId],
+				"expected brick not on ssd device, got:", brick.Info.DeviceId)
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil)
+}
+
+func TestVolumeCreateWithSelectorUnsatisfiable(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		6,    // nodes_per_cluster
+		2,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	tagSampleDevices(t, app, 2, map[string]string{"disktype": "ssd"})
+
+	// no device matches at all
+	v := createSampleReplicaVolumeEntry(100, 3)
+	v.Info.Selector = &api.PlacementSelector{
+		MatchTags: map[string]string{"disktype": "nvme"},
+	}
+	err = v.Create(app.db, app.executor)
+	tests.Assert(t, err != nil, "expected err != nil")
+	tests.Assert(t, strings.Contains(err.Error(), "placement selector"),
+		"expected placement selector error, got:", err)
+
+	// devices match but only on two nodes, too few for replica 3
+	v = createSampleReplicaVolumeEntry(100, 3)
+	v.Info.Selector = &api.PlacementSelector{
+		MatchTags: map[string]string{"disktype": "ssd"},
+	}
+	err = v.Create(app.db, app.executor)
+	tests.Assert(t, err == ErrNoSpace, "expected err == ErrNoSpace, got:", err)
+}
+
+func TestBlockVolumeCreateWithSelector(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		6,    // nodes_per_cluster
+		1,    // devices_per_node,
+		2*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	ssd := tagSampleDevices(t, app, 3, map[string]string{"disktype": "ssd"})
+
+	// the first hosting volume is placed away from the ssd devices
+	bv := createSampleBlockVolumeEntry(100)
+	bv.Info.Selector = &api.PlacementSelector{
+		MatchExpressions: []api.PlacementSelectorExpression{
+			{Key: "disktype", Operator: api.PlacementSelectorNotIn, Values: []string{"ssd"}},
+		},
+	}
+	err = bv.Create(app.db, app.executor)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	firstHost := bv.Info.BlockHostingVolume
+
+	// the ssd block volume needs a hosting volume of its own
+	bv = createSampleBlockVolumeEntry(100)
+	bv.Info.Selector = &api.PlacementSelector{
+		MatchTags: map[string]string{"disktype": "ssd"},
+	}
+	err = bv.Create(app.db, app.executor)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, bv.Info.BlockHostingVolume != firstHost,
+		"expected a new block hosting volume")
+
+	err = app.db.View(func(tx *bolt.Tx) error {
+		vol, err := NewVolumeEntryFromId(tx, bv.Info.BlockHostingVolume)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		for _, brickId := range vol.Bricks {
+			brick, err := NewBrickEntryFromId(tx, brickId)
+			tests.Assert(t, err == nil, "expected err == nil, got:", err)
+			tests.Assert(t, ssd[brick.Info.DeviceId],
+				"expected brick on ssd device, got:", brick.Info.DeviceId)
+		}
+		return nil
+	})
+	tests.Assert(t, err == nil)
+
+	// another ssd block volume reuses the ssd hosting volume
+	ssdHost := bv.Info.BlockHostingVolume
+	bv = createSampleBlockVolumeEntry(100)
+	bv.Info.Selector = &api.PlacementSelector{
+		MatchTags: map[string]string{"disktype": "ssd"},
+	}
+	err = bv.Create(app.db, app.executor)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, bv.Info.BlockHostingVolume == ssdHost,
+		"expected ssd hosting volume to be reused")
+}
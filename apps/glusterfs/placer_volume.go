@@ -9,9 +9,18 @@
 
 package glusterfs
 
-func PlacerForVolume(v *VolumeEntry) BrickPlacer {
+// PlacerForVolume returns the brick placer for the volume. Bricks are
+// only spread across failure domains when nodes of the cluster are
+// labeled with them.
+func PlacerForVolume(v *VolumeEntry, dsrc DeviceSource) BrickPlacer {
+	failureDomains := hasFailureDomains(dsrc)
 	if v.HasArbiterOption() {
-		return NewArbiterBrickPlacer()
+		bp := NewArbiterBrickPlacer()
+		bp.failureDomains = failureDomains
+		return bp
+	}
+	if failureDomains {
+		return NewFailureDomainBrickPlacer()
 	}
 	return NewStandardBrickPlacer()
 }
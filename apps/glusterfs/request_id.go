@@ -0,0 +1,72 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+const (
+	// Header used by clients to set the id of a request, and by the
+	// server to return it
+	RequestIdHeader = "X-Request-ID"
+)
+
+var (
+	// ids sent by clients are only used if they are not too long and
+	// are safe to log
+	validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+)
+
+type requestIdKey struct{}
+
+// withRequestId tags the request with the id set by the client in the
+// X-Request-ID header, or a new id if there is none, and returns the
+// id in the same header of the response.
+func withRequestId(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIdHeader)
+		if !validRequestId.MatchString(id) {
+			id = utils.GenUUID()
+		}
+		w.Header().Set(RequestIdHeader, id)
+		apiLogger.With("request_id", id).Info("%v %v", r.Method, r.URL.Path)
+
+		ctx := context.WithValue(r.Context(), requestIdKey{}, id)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestId returns the id of the request, empty if the request did
+// not go through withRequestId.
+func requestId(r *http.Request) string {
+	id, _ := r.Context().Value(requestIdKey{}).(string)
+	return id
+}
+
+// executorLogger returns the logger of the operations adding the id
+// of the request the executor runs commands for to the messages.
+func executorLogger(executor executors.Executor) *utils.Logger {
+	if id := executors.RequestId(executor); id != "" {
+		return opLogger.With("request_id", id)
+	}
+	return opLogger
+}
+
+// requestExecutor returns the executor of the app running commands on
+// behalf of the request.
+func (a *App) requestExecutor(r *http.Request) executors.Executor {
+	return executors.WithRequestId(a.executor, requestId(r))
+}
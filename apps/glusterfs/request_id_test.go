@@ -0,0 +1,125 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/tests"
+)
+
+func TestRequestId(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	// a new id is generated for each request
+	r, err := http.Get(ts.URL + "/clusters")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, r.StatusCode == http.StatusOK)
+	id1 := r.Header.Get(RequestIdHeader)
+	tests.Assert(t, id1 != "")
+	r, err = http.Get(ts.URL + "/clusters")
+	tests.Assert(t, err == nil)
+	id2 := r.Header.Get(RequestIdHeader)
+	tests.Assert(t, id2 != "" && id2 != id1, id1, id2)
+
+	// the id of the client is returned with the async response
+	request := []byte(`{
+		"size" : 10,
+		"durability": {"type": "replicate", "replicate": {"replica": 3}}
+	}`)
+	req, err := http.NewRequest("POST", ts.URL+"/volumes",
+		bytes.NewBuffer(request))
+	tests.Assert(t, err == nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RequestIdHeader, "client-request.1")
+	r, err = http.DefaultClient.Do(req)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, r.StatusCode == http.StatusAccepted, r.StatusCode)
+	tests.Assert(t, r.Header.Get(RequestIdHeader) == "client-request.1",
+		r.Header.Get(RequestIdHeader))
+	location, err := r.Location()
+	tests.Assert(t, err == nil)
+
+	// Query queue until finished
+	for {
+		r, err = http.Get(location.String())
+		tests.Assert(t, err == nil)
+		tests.Assert(t, r.StatusCode == http.StatusOK)
+		if r.Header.Get("X-Pending") == "true" {
+			time.Sleep(time.Millisecond * 10)
+			continue
+		}
+		break
+	}
+
+	// ids which are not safe to log are replaced
+	req, err = http.NewRequest("GET", ts.URL+"/clusters", nil)
+	tests.Assert(t, err == nil)
+	req.Header.Set(RequestIdHeader, "bad id\"")
+	r, err = http.DefaultClient.Do(req)
+	tests.Assert(t, err == nil)
+	id := r.Header.Get(RequestIdHeader)
+	tests.Assert(t, id != "" && id != "bad id\"", id)
+}
+
+func TestRequestExecutor(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+
+	var executor executors.Executor
+	h := withRequestId(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			executor = app.requestExecutor(r)
+		}))
+
+	req := httptest.NewRequest("GET", "/clusters", nil)
+	req.Header.Set(RequestIdHeader, "abc")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	tests.Assert(t, executors.RequestId(executor) == "abc")
+
+	// commands still run through the executor of the app
+	_, err := executor.VolumeInfo("host", "vol")
+	tests.Assert(t, err == nil, err)
+
+	// without a request id the executor of the app is used
+	req = httptest.NewRequest("GET", "/clusters", nil)
+	executor = app.requestExecutor(req)
+	tests.Assert(t, executor == app.executor)
+	tests.Assert(t, executors.RequestId(executor) == "")
+}
@@ -10,12 +10,18 @@
 package glusterfs
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/heketi/heketi/pkg/glusterfs/api"
 )
 
 // Well-known tags
 const (
-	TAG_ARBITER string = "arbiter"
+	TAG_ARBITER               string = "arbiter"
+	TAG_FAILURE_DOMAIN_REGION        = api.FailureDomainRegionTag
+	TAG_FAILURE_DOMAIN_RACK          = api.FailureDomainRackTag
+	TAG_ALLOCATION_WEIGHT            = api.AllocationWeightTag
 )
 
 // Well-known tag values
@@ -101,3 +107,46 @@ func ArbiterTag(t map[string]string) string {
 		return TAG_VAL_ARBITER_SUPPORTED
 	}
 }
+
+type tagMatch struct {
+	name     string
+	value    string
+	anyValue bool
+}
+
+// TagFilter selects taggable objects in list requests.
+type TagFilter []tagMatch
+
+// NewTagFilterFromRequest returns the filter given by the "tag" query
+// parameters of a request. Each parameter is either a tag name, which
+// the object must have with any value, or a name:value pair.
+func NewTagFilterFromRequest(r *http.Request) (TagFilter, error) {
+	f := TagFilter{}
+	names := map[string]string{}
+	for _, param := range r.URL.Query()["tag"] {
+		parts := strings.SplitN(param, ":", 2)
+		m := tagMatch{name: parts[0], anyValue: len(parts) == 1}
+		if !m.anyValue {
+			m.value = parts[1]
+		}
+		names[m.name] = m.value
+		f = append(f, m)
+	}
+	if err := api.ValidateTags(names); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Matches returns true if the tags of the object satisfy all
+// the conditions of the filter.
+func (f TagFilter) Matches(t Taggable) bool {
+	tags := t.AllTags()
+	for _, m := range f {
+		v, ok := tags[m.name]
+		if !ok || (!m.anyValue && v != m.value) {
+			return false
+		}
+	}
+	return true
+}
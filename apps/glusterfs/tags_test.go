@@ -10,6 +10,7 @@
 package glusterfs
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/heketi/tests"
@@ -215,3 +216,38 @@ func TestArbiterTag(t *testing.T) {
 	tests.Assert(t, a == TAG_VAL_ARBITER_SUPPORTED,
 		"expected a == TAG_VAL_ARBITER_SUPPORTED, got", a)
 }
+
+func TestTagFilter(t *testing.T) {
+	tt := &testTaggable{T: map[string]string{
+		"owner":       "team-a",
+		"cost-center": "",
+	}}
+
+	r, err := http.NewRequest("GET", "/volumes", nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	f, err := NewTagFilterFromRequest(r)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(f) == 0, "expected empty filter, got:", f)
+	tests.Assert(t, f.Matches(tt), "expected empty filter to match")
+
+	match := func(query string) bool {
+		r, err := http.NewRequest("GET", "/volumes?"+query, nil)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		f, err := NewTagFilterFromRequest(r)
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		return f.Matches(tt)
+	}
+	tests.Assert(t, match("tag=owner"))
+	tests.Assert(t, match("tag=owner:team-a"))
+	tests.Assert(t, !match("tag=owner:team-b"))
+	tests.Assert(t, match("tag=cost-center"))
+	tests.Assert(t, match("tag=cost-center:"))
+	tests.Assert(t, !match("tag=rack"))
+	tests.Assert(t, match("tag=owner:team-a&tag=cost-center"))
+	tests.Assert(t, !match("tag=owner:team-a&tag=rack"))
+
+	r, err = http.NewRequest("GET", "/volumes?tag=bad%20name", nil)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	_, err = NewTagFilterFromRequest(r)
+	tests.Assert(t, err != nil, "expected err != nil")
+}
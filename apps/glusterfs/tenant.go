@@ -0,0 +1,210 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/middleware"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// DefaultTenantId is the id of the tenant config whose limits are
+// shared by all the tenants which are not listed in the config.
+const DefaultTenantId = "*"
+
+// TenantConfig sets the limits of a tenant. The tenant of a request
+// is the issuer of its JWT token, or the value of the tenant claim
+// if one is set in the jwt settings.
+type TenantConfig struct {
+	Id string `json:"id"`
+	api.TenantLimits
+}
+
+// requestTenant returns the tenant saved in the request by the JWT
+// middleware, or an empty string if authentication is not enabled.
+func requestTenant(r *http.Request) string {
+	return middleware.Tenant(r)
+}
+
+// tenantConfig returns the limits configured for the tenant, or nil
+// if the tenant has no limits. Tenants which are not listed get the
+// default tenant config, if one is set, so that tenants minted with
+// the tenant claim can not escape the limits.
+func (a *App) tenantConfig(id string) *TenantConfig {
+	if id == "" {
+		return nil
+	}
+	var def *TenantConfig
+	for i := range a.conf.Tenants {
+		switch a.conf.Tenants[i].Id {
+		case id:
+			return &a.conf.Tenants[i]
+		case DefaultTenantId:
+			def = &a.conf.Tenants[i]
+		}
+	}
+	return def
+}
+
+// tenantMembers returns a function telling if the volumes of a
+// tenant count against the limits of the given config. The default
+// tenant config counts the volumes of all the tenants not listed.
+func (a *App) tenantMembers(t *TenantConfig) func(string) bool {
+	if t.Id != DefaultTenantId {
+		return func(id string) bool {
+			return id == t.Id
+		}
+	}
+	listed := map[string]bool{}
+	for _, c := range a.conf.Tenants {
+		listed[c.Id] = true
+	}
+	return func(id string) bool {
+		return id != "" && !listed[id]
+	}
+}
+
+// tenantUsage returns the capacity provisioned for the tenants the
+// member function accepts. Volumes and expansions still being created
+// are counted so that concurrent requests can not go over the limits
+// together.
+func tenantUsage(tx *bolt.Tx,
+	member func(string) bool) (*api.TenantUsage, error) {
+	usage := &api.TenantUsage{}
+	owned := map[string]bool{}
+
+	volumes, err := VolumeList(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, volumeId := range volumes {
+		v, err := NewVolumeEntryFromId(tx, volumeId)
+		if err != nil {
+			return nil, err
+		}
+		if !member(v.Info.Tenant) {
+			continue
+		}
+		owned[volumeId] = true
+		usage.Size += v.Info.Size
+		usage.Volumes++
+	}
+
+	blockvolumes, err := BlockVolumeList(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, blockvolumeId := range blockvolumes {
+		bv, err := NewBlockVolumeEntryFromId(tx, blockvolumeId)
+		if err != nil {
+			return nil, err
+		}
+		if !member(bv.Info.Tenant) {
+			continue
+		}
+		usage.Size += bv.Info.Size
+		usage.BlockVolumes++
+	}
+
+	ops, err := PendingOperationList(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, opId := range ops {
+		op, err := NewPendingOperationEntryFromId(tx, opId)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range op.Actions {
+			if a.Change != OpExpandVolume || !owned[a.Id] {
+				continue
+			}
+			size, err := a.ExpandSize()
+			if err != nil {
+				return nil, err
+			}
+			usage.Size += size
+		}
+	}
+
+	return usage, nil
+}
+
+// exceeded returns the reason provisioning size GiB more in count
+// more volumes is refused, or an empty string if it is within the
+// limits of the tenant.
+func (t *TenantConfig) exceeded(usage *api.TenantUsage,
+	size, count int) string {
+
+	name := "tenant " + t.Id
+	if t.Id == DefaultTenantId {
+		name = "tenants without limits of their own"
+	}
+	if t.MaxSize != 0 && usage.Size+size > t.MaxSize {
+		return fmt.Sprintf("%v would use %v GiB of the limit of %v GiB",
+			name, usage.Size+size, t.MaxSize)
+	}
+	if t.MaxVolumes != 0 &&
+		usage.Volumes+usage.BlockVolumes+count > t.MaxVolumes {
+		return fmt.Sprintf("%v would have %v volumes, the limit is %v",
+			name, usage.Volumes+usage.BlockVolumes+count, t.MaxVolumes)
+	}
+	return ""
+}
+
+// clusters returns the clusters a volume of the tenant may be placed
+// on, given the clusters requested.
+func (t *TenantConfig) clusters(requested []string) ([]string, error) {
+	if len(t.Clusters) == 0 {
+		return requested, nil
+	}
+	if len(requested) == 0 {
+		return append([]string{}, t.Clusters...), nil
+	}
+	allowed := map[string]bool{}
+	for _, id := range t.Clusters {
+		allowed[id] = true
+	}
+	for _, id := range requested {
+		if !allowed[id] {
+			return nil, fmt.Errorf("tenant %v is not allowed to use cluster %v",
+				t.Id, id)
+		}
+	}
+	return requested, nil
+}
+
+// checkTenantLimits writes an error to the response and returns false
+// if provisioning size GiB more in count more volumes would take the
+// tenant over its limits. The caller must hold the tenant lock until
+// the volume or expansion allowed is saved in the db.
+func (a *App) checkTenantLimits(w http.ResponseWriter,
+	t *TenantConfig, size, count int) bool {
+
+	var usage *api.TenantUsage
+	err := a.db.View(func(tx *bolt.Tx) error {
+		var err error
+		usage, err = tenantUsage(tx, a.tenantMembers(t))
+		return err
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if reason := t.exceeded(usage, size, count); reason != "" {
+		http.Error(w, reason, http.StatusForbidden)
+		apiLogger.LogError("%v", reason)
+		return false
+	}
+	return true
+}
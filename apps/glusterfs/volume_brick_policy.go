@@ -0,0 +1,92 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// validateBrickPolicy checks that the brick policy of a volume stays
+// within the brick limits of the server.
+func validateBrickPolicy(bp *api.BrickPolicy, d VolumeDurability) error {
+	if bp == nil {
+		return nil
+	}
+	if bp.MinBrickSize != 0 && uint64(bp.MinBrickSize)*GB < BrickMinSize {
+		return fmt.Errorf("min_brick_size must be at least %v GB",
+			BrickMinSize/GB)
+	}
+	if uint64(bp.MinBrickSize)*GB > BrickMaxSize {
+		return fmt.Errorf("min_brick_size must be at most %v GB",
+			BrickMaxSize/GB)
+	}
+	if bp.MaxBrickSize != 0 && uint64(bp.MaxBrickSize)*GB < BrickMinSize {
+		return fmt.Errorf("max_brick_size must be at least %v GB",
+			BrickMinSize/GB)
+	}
+	if uint64(bp.MaxBrickSize)*GB > BrickMaxSize {
+		return fmt.Errorf("max_brick_size must be at most %v GB",
+			BrickMaxSize/GB)
+	}
+	if bp.MaxBrickSets*d.BricksInSet() > BrickMaxNum {
+		return fmt.Errorf("max_brick_sets must be at most %v",
+			BrickMaxNum/d.BricksInSet())
+	}
+	return nil
+}
+
+// brickSizeGenerator returns the brick sizes to try for the given
+// size, in the same way as the BrickSizeGenerator of the durability,
+// but skipping the sizes the brick policy of the volume does not
+// allow. The brick sets the volume already has count towards the
+// maximum number of sets so that expansions keep the brick shape.
+func (v *VolumeEntry) brickSizeGenerator(size uint64) func() (int, uint64, error) {
+	gen := v.Durability.BrickSizeGenerator(size)
+	bp := v.Info.BrickPolicy
+	if bp == nil {
+		return gen
+	}
+
+	existingSets := len(v.Bricks) / v.Durability.BricksInSet()
+	return func() (int, uint64, error) {
+		for {
+			sets, brickSize, err := gen()
+			if err != nil {
+				return 0, 0, err
+			}
+			if bp.MaxBrickSize != 0 &&
+				brickSize > uint64(bp.MaxBrickSize)*GB {
+				continue
+			}
+			if brickSize < uint64(bp.MinBrickSize)*GB {
+				return 0, 0, ErrMinimumBrickSize
+			}
+			if bp.MaxBrickSets != 0 &&
+				existingSets+sets > bp.MaxBrickSets {
+				return 0, 0, ErrMaxBricks
+			}
+			return sets, brickSize, nil
+		}
+	}
+}
+
+// minVolumeSize returns the smallest size of the volume, taking the
+// minimum brick size of its brick policy into account.
+func (v *VolumeEntry) minVolumeSize() uint64 {
+	min := v.Durability.MinVolumeSize()
+	bp := v.Info.BrickPolicy
+	if bp != nil && uint64(bp.MinBrickSize)*GB > BrickMinSize {
+		// the minimum volume size is a multiple of the brick size
+		min = min / BrickMinSize * uint64(bp.MinBrickSize) * GB
+	}
+	return min
+}
@@ -0,0 +1,107 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"testing"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+func newBrickPolicyVolume(bp *api.BrickPolicy) *VolumeEntry {
+	req := &api.VolumeCreateRequest{}
+	req.Size = 400
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+	req.BrickPolicy = bp
+	return NewVolumeEntryFromRequest(req)
+}
+
+func TestVolumeBrickSizeGeneratorNoPolicy(t *testing.T) {
+	v := newBrickPolicyVolume(nil)
+
+	gen := v.brickSizeGenerator(400 * GB)
+	sets, brickSize, err := gen()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, sets == 1, "expected sets == 1, got:", sets)
+	tests.Assert(t, brickSize == 400*GB,
+		"expected brickSize == 400*GB, got:", brickSize)
+	tests.Assert(t, v.minVolumeSize() == BrickMinSize,
+		"expected v.minVolumeSize() == BrickMinSize, got:", v.minVolumeSize())
+}
+
+func TestVolumeBrickSizeGeneratorPolicy(t *testing.T) {
+	v := newBrickPolicyVolume(&api.BrickPolicy{
+		MinBrickSize: 60,
+		MaxBrickSize: 100,
+	})
+
+	// larger bricks are skipped
+	gen := v.brickSizeGenerator(400 * GB)
+	sets, brickSize, err := gen()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, sets == 4, "expected sets == 4, got:", sets)
+	tests.Assert(t, brickSize == 100*GB,
+		"expected brickSize == 100*GB, got:", brickSize)
+
+	// smaller bricks are refused
+	_, _, err = gen()
+	tests.Assert(t, err == ErrMinimumBrickSize,
+		"expected err == ErrMinimumBrickSize, got:", err)
+
+	tests.Assert(t, v.minVolumeSize() == 60*GB,
+		"expected v.minVolumeSize() == 60*GB, got:", v.minVolumeSize())
+}
+
+func TestVolumeBrickSizeGeneratorMaxSets(t *testing.T) {
+	v := newBrickPolicyVolume(&api.BrickPolicy{
+		MaxBrickSets: 4,
+	})
+	v.Bricks = []string{"b1", "b2", "b3"}
+
+	// the existing brick set counts against the limit
+	gen := v.brickSizeGenerator(400 * GB)
+	for _, expected := range []int{1, 2} {
+		sets, _, err := gen()
+		tests.Assert(t, err == nil, "expected err == nil, got:", err)
+		tests.Assert(t, sets == expected,
+			"expected sets ==", expected, "got:", sets)
+	}
+	_, _, err := gen()
+	tests.Assert(t, err == ErrMaxBricks,
+		"expected err == ErrMaxBricks, got:", err)
+}
+
+func TestValidateBrickPolicy(t *testing.T) {
+	d := NewVolumeReplicaDurability(&api.ReplicaDurability{Replica: 3})
+
+	err := validateBrickPolicy(nil, d)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	err = validateBrickPolicy(&api.BrickPolicy{
+		MinBrickSize: 10,
+		MaxBrickSize: 100,
+		MaxBrickSets: 1,
+	}, d)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	err = validateBrickPolicy(&api.BrickPolicy{
+		MaxBrickSize: int(BrickMaxSize/GB) + 1,
+	}, d)
+	tests.Assert(t, err != nil, "expected err != nil")
+	err = validateBrickPolicy(&api.BrickPolicy{
+		MinBrickSize: int(BrickMaxSize/GB) + 1,
+	}, d)
+	tests.Assert(t, err != nil, "expected err != nil")
+	err = validateBrickPolicy(&api.BrickPolicy{
+		MaxBrickSets: BrickMaxNum/3 + 1,
+	}, d)
+	tests.Assert(t, err != nil, "expected err != nil")
+}
@@ -92,6 +92,11 @@ func NewVolumeEntryFromRequest(req *api.VolumeCreateRequest) *VolumeEntry {
 	vol.Info.Snapshot = req.Snapshot
 	vol.Info.Size = req.Size
 	vol.Info.Block = req.Block
+	vol.Info.Selector = req.Selector
+	vol.Info.Allocation = req.Allocation
+	vol.Info.BrickPolicy = req.BrickPolicy
+	vol.Info.RootQuota = req.RootQuota
+	vol.Info.Tags = copyTags(req.Tags)
 
 	if vol.Info.Block {
 		vol.Info.BlockInfo.FreeSize = req.Size
@@ -104,20 +109,20 @@ func NewVolumeEntryFromRequest(req *api.VolumeCreateRequest) *VolumeEntry {
 	switch {
 
 	case durability == api.DurabilityReplicate:
-		logger.Debug("[%v] Replica %v",
+		opLogger.Debug("[%v] Replica %v",
 			vol.Info.Id,
 			vol.Info.Durability.Replicate.Replica)
 		vol.Durability = NewVolumeReplicaDurability(&vol.Info.Durability.Replicate)
 
 	case durability == api.DurabilityEC:
-		logger.Debug("[%v] EC %v + %v ",
+		opLogger.Debug("[%v] EC %v + %v ",
 			vol.Info.Id,
 			vol.Info.Durability.Disperse.Data,
 			vol.Info.Durability.Disperse.Redundancy)
 		vol.Durability = NewVolumeDisperseDurability(&vol.Info.Durability.Disperse)
 
 	case durability == api.DurabilityDistributeOnly || durability == "":
-		logger.Debug("[%v] Distributed", vol.Info.Id)
+		opLogger.Debug("[%v] Distributed", vol.Info.Id)
 		vol.Durability = NewNoneDurability()
 
 	default:
@@ -219,6 +224,11 @@ func (v *VolumeEntry) NewInfoResponse(tx *bolt.Tx) (*api.VolumeInfoResponse, err
 	info.GlusterVolumeOptions = v.GlusterVolumeOptions
 	info.Block = v.Info.Block
 	info.BlockInfo = v.Info.BlockInfo
+	info.Selector = v.Info.Selector
+	info.BrickPolicy = v.Info.BrickPolicy
+	info.RootQuota = v.Info.RootQuota
+	info.Tenant = v.Info.Tenant
+	info.Tags = copyTags(v.Info.Tags)
 
 	for _, brickid := range v.BricksIds() {
 		brick, err := NewBrickEntryFromId(tx, brickid)
@@ -280,7 +290,7 @@ func (v *VolumeEntry) GetAverageFileSize() uint64 {
 		if len(r) == 2 && r[0] == HEKETI_AVERAGE_FILE_SIZE_KEY {
 			if v, e := strconv.ParseUint(r[1], 10, 64); e == nil {
 				if v == 0 {
-					logger.LogError("Average File Size cannot be zero, using default file size %v", averageFileSize)
+					opLogger.LogError("Average File Size cannot be zero, using default file size %v", averageFileSize)
 					return averageFileSize
 				}
 				return v
@@ -319,17 +329,17 @@ func (v *VolumeEntry) tryAllocateBricks(
 
 		if err == nil {
 			v.Info.Cluster = cluster
-			logger.Debug("Volume to be created on cluster %v", cluster)
+			opLogger.Debug("Volume to be created on cluster %v", cluster)
 			break
 		} else if err == ErrNoSpace ||
 			err == ErrMaxBricks ||
 			err == ErrMinimumBrickSize {
-			logger.Debug("Cluster %v can not accommodate volume "+
+			opLogger.Debug("Cluster %v can not accommodate volume "+
 				"(%v), trying next cluster", cluster, err)
 			continue
 		} else {
 			// A genuine error occurred - bail out
-			logger.LogError("Error calling v.allocBricksInCluster: %v", err)
+			opLogger.LogError("Error calling v.allocBricksInCluster: %v", err)
 			return
 		}
 	}
@@ -350,13 +360,13 @@ func (v *VolumeEntry) cleanupCreateVolume(db wdb.DB,
 			// we asked gluster to delete a volume that already does not exist
 			return false, nil
 		default:
-			logger.Warning("failed to delete volume %v via %v: %v",
+			opLogger.Warning("failed to delete volume %v via %v: %v",
 				v.Info.Id, h, err)
 			return true, err
 		}
 	})
 	if err != nil {
-		logger.LogError("failed to delete volume in cleanup: %v", err)
+		opLogger.LogError("failed to delete volume in cleanup: %v", err)
 		return fmt.Errorf("failed to clean up volume: %v", v.Info.Id)
 	}
 
@@ -421,10 +431,17 @@ func (v *VolumeEntry) createVolumeComponents(db wdb.DB) (
 		return brick_entries, err
 	}
 	if len(possibleClusters) == 0 {
-		logger.LogError("No clusters eligible to satisfy create volume request")
+		opLogger.LogError("No clusters eligible to satisfy create volume request")
 		return brick_entries, ErrNoSpace
 	}
-	logger.Debug("Using the following clusters: %+v", possibleClusters)
+	opLogger.Debug("Using the following clusters: %+v", possibleClusters)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		return checkSelectorSatisfiable(tx, v.Info.Selector, possibleClusters)
+	})
+	if err != nil {
+		return brick_entries, err
+	}
 
 	return v.saveCreateVolume(db, possibleClusters)
 }
@@ -491,14 +508,14 @@ func (v *VolumeEntry) deleteVolumeExec(db wdb.RODB,
 	// Determine if we can destroy the volume
 	err := executor.VolumeDestroyCheck(sshhost, v.Info.Name)
 	if err != nil {
-		logger.Err(err)
+		opLogger.Err(err)
 		return nil, err
 	}
 
 	// Determine if the bricks can be destroyed
 	err = v.checkBricksCanBeDestroyed(db, executor, brick_entries)
 	if err != nil {
-		logger.Err(err)
+		opLogger.Err(err)
 		return nil, err
 	}
 
@@ -506,14 +523,14 @@ func (v *VolumeEntry) deleteVolumeExec(db wdb.RODB,
 	// Stop volume
 	err = executor.VolumeDestroy(sshhost, v.Info.Name)
 	if err != nil {
-		logger.LogError("Unable to delete volume: %v", err)
+		opLogger.LogError("Unable to delete volume: %v", err)
 		return nil, err
 	}
 
 	// Destroy bricks
 	space_reclaimed, err := DestroyBricks(db, executor, brick_entries)
 	if err != nil {
-		logger.LogError("Unable to delete bricks: %v", err)
+		opLogger.LogError("Unable to delete bricks: %v", err)
 		return nil, err
 	}
 
@@ -528,7 +545,7 @@ func (v *VolumeEntry) saveDeleteVolume(db wdb.DB,
 		for _, brick := range brick_entries {
 			err := v.removeBrickFromDb(tx, brick)
 			if err != nil {
-				logger.Err(err)
+				opLogger.Err(err)
 				// Everything is destroyed anyways, just keep deleting the others
 				// Do not return here
 			}
@@ -537,14 +554,14 @@ func (v *VolumeEntry) saveDeleteVolume(db wdb.DB,
 		// Remove volume from cluster
 		cluster, err := NewClusterEntryFromId(tx, v.Info.Cluster)
 		if err != nil {
-			logger.Err(err)
+			opLogger.Err(err)
 			// Do not return here.. keep going
 		}
 		cluster.VolumeDelete(v.Info.Id)
 
 		err = cluster.Save(tx)
 		if err != nil {
-			logger.Err(err)
+			opLogger.Err(err)
 			// Do not return here.. keep going
 		}
 
@@ -579,7 +596,7 @@ func (v *VolumeEntry) deleteVolumeComponents(
 		for _, id := range v.BricksIds() {
 			brick, err := NewBrickEntryFromId(tx, id)
 			if err != nil {
-				logger.LogError("Brick %v not found in db: %v", id, err)
+				opLogger.LogError("Brick %v not found in db: %v", id, err)
 				return err
 			}
 			brick_entries = append(brick_entries, brick)
@@ -590,7 +607,7 @@ func (v *VolumeEntry) deleteVolumeComponents(
 }
 
 func (v *VolumeEntry) Destroy(db wdb.DB, executor executors.Executor) error {
-	logger.Info("Destroying volume %v", v.Info.Id)
+	opLogger.Info("Destroying volume %v", v.Info.Id)
 
 	return RunOperation(
 		NewVolumeDeleteOperation(v, db),
@@ -633,7 +650,7 @@ func (v *VolumeEntry) cleanupExpandVolume(db wdb.DB,
 	brick_entries []*BrickEntry,
 	origSize int) (e error) {
 
-	logger.Debug("Error detected, cleaning up")
+	opLogger.Debug("Error detected, cleaning up")
 	DestroyBricks(db, executor, brick_entries)
 
 	// Remove from db
@@ -709,7 +726,7 @@ func (v *VolumeEntry) checkBricksCanBeDestroyed(db wdb.RODB,
 	// any of errored, it would be cought here
 	err := sg.Result()
 	if err != nil {
-		logger.Err(err)
+		opLogger.Err(err)
 	}
 	return err
 }
@@ -775,7 +792,7 @@ func eligibleClusters(db wdb.RODB, req ClusterReq,
 			case !req.Block && c.Info.File:
 			case !(c.Info.Block || c.Info.File):
 				// possibly bad cluster config
-				logger.Info("Cluster %v lacks both block and file flags",
+				opLogger.Info("Cluster %v lacks both block and file flags",
 					clusterId)
 				continue
 			default:
@@ -787,7 +804,7 @@ func eligibleClusters(db wdb.RODB, req ClusterReq,
 					return err
 				}
 				if found {
-					logger.LogError("Name %v already in use in cluster %v",
+					opLogger.LogError("Name %v already in use in cluster %v",
 						req.Name, clusterId)
 					continue
 				}
@@ -826,7 +843,7 @@ func (v *VolumeEntry) runOnHost(db wdb.RODB,
 		return nil
 	})
 	if err != nil {
-		logger.LogError("runOnHost failed to get hosts: %v", err)
+		opLogger.LogError("runOnHost failed to get hosts: %v", err)
 		return err
 	}
 
@@ -835,11 +852,11 @@ func (v *VolumeEntry) runOnHost(db wdb.RODB,
 		if up, found := nodeUp[nodeId]; found && !up {
 			// if the node is in the cache and we know it was not
 			// recently healthy, skip it
-			logger.Debug("skipping node. %v (%v) is presumed unhealthy",
+			opLogger.Debug("skipping node. %v (%v) is presumed unhealthy",
 				nodeId, host)
 			continue
 		}
-		logger.Debug("running function on node %v (%v)", nodeId, host)
+		opLogger.Debug("running function on node %v (%v)", nodeId, host)
 		tryNext, err := cb(host)
 		if !tryNext {
 			return err
@@ -904,7 +921,7 @@ func updateCloneBrickPaths(bricks []*BrickEntry,
 				origPath)
 		}
 		brick := bricks[bidx]
-		logger.Debug("Updating brick %v with new path %v (had %v)",
+		opLogger.Debug("Updating brick %v with new path %v (had %v)",
 			brick.Id(), clonePath, origPath)
 		brick.Info.Path = clonePath
 	}
@@ -952,3 +969,15 @@ func (v *VolumeEntry) cloneVolumeRequest(db wdb.RODB, clonename string) (*execut
 
 	return vcr, sshhost, nil
 }
+
+func (v *VolumeEntry) AllTags() map[string]string {
+	if v.Info.Tags == nil {
+		return map[string]string{}
+	}
+	return v.Info.Tags
+}
+
+func (v *VolumeEntry) SetTags(t map[string]string) error {
+	v.Info.Tags = t
+	return nil
+}
@@ -27,37 +27,37 @@ func (v *VolumeEntry) allocBricksInCluster(db wdb.DB,
 	// Setup a brick size generator
 	// Note: subsequent calls to gen need to return decreasing
 	//       brick sizes in order for the following code to work!
-	gen := v.Durability.BrickSizeGenerator(size)
+	gen := v.brickSizeGenerator(size)
 
 	// Try decreasing possible brick sizes until space is found
 	for {
 		// Determine next possible brick size
 		sets, brick_size, err := gen()
 		if err != nil {
-			logger.Err(err)
+			allocLogger.Err(err)
 			return nil, err
 		}
 
 		num_bricks := sets * v.Durability.BricksInSet()
 
-		logger.Debug("brick_size = %v", brick_size)
-		logger.Debug("sets = %v", sets)
-		logger.Debug("num_bricks = %v", num_bricks)
+		allocLogger.Debug("brick_size = %v", brick_size)
+		allocLogger.Debug("sets = %v", sets)
+		allocLogger.Debug("num_bricks = %v", num_bricks)
 
 		// Check that the volume would not have too many bricks
 		if (num_bricks + len(v.Bricks)) > BrickMaxNum {
-			logger.Debug("Maximum number of bricks reached")
+			allocLogger.Debug("Maximum number of bricks reached")
 			return nil, ErrMaxBricks
 		}
 
 		// Allocate bricks in the cluster
 		brick_entries, err := v.allocBricks(db, cluster, sets, brick_size)
 		if err == ErrNoSpace {
-			logger.Debug("No space, re-trying with smaller brick size")
+			allocLogger.Debug("No space, re-trying with smaller brick size")
 			continue
 		}
 		if err != nil {
-			logger.Err(err)
+			allocLogger.Err(err)
 			return nil, err
 		}
 
@@ -100,7 +100,7 @@ func (v *VolumeEntry) getBrickSetForBrickId(db wdb.DB,
 	// retains the brick order
 	vinfo, err := executor.VolumeInfo(node, v.Info.Name)
 	if err != nil {
-		logger.LogError("Unable to get volume info from gluster node %v for volume %v: %v", node, v.Info.Name, err)
+		allocLogger.LogError("Unable to get volume info from gluster node %v for volume %v: %v", node, v.Info.Name, err)
 		return nil, 0, err
 	}
 
@@ -119,7 +119,7 @@ func (v *VolumeEntry) getBrickSetForBrickId(db wdb.DB,
 		for _, brick := range vinfo.Bricks.BrickList[slicestartindex : slicestartindex+ssize] {
 			brickentry, found := bmap[brick.Name]
 			if !found {
-				logger.LogError("Unable to create brick entry using brick name:%v",
+				allocLogger.LogError("Unable to create brick entry using brick name:%v",
 					brick.Name)
 				return nil, 0, ErrNotFound
 			}
@@ -134,7 +134,7 @@ func (v *VolumeEntry) getBrickSetForBrickId(db wdb.DB,
 		}
 	}
 
-	logger.LogError("Unable to find brick set for brick %v, db is possibly corrupt", oldBrickId)
+	allocLogger.LogError("Unable to find brick set for brick %v, db is possibly corrupt", oldBrickId)
 	return nil, 0, ErrNotFound
 }
 
@@ -277,11 +277,13 @@ func (v *VolumeEntry) allocBrickReplacement(db wdb.DB,
 		}
 
 		var err error
-		placer := PlacerForVolume(v)
+		dsrc := NewClusterDeviceSource(tx, v.Info.Cluster)
+		placer := PlacerForVolume(v, dsrc)
 		r, err = placer.Replace(
-			NewClusterDeviceSource(tx, v.Info.Cluster),
+			dsrc,
 			NewVolumePlacementOpts(v, oldBrickEntry.Info.Size, bs.SetSize),
-			diffDevice, bs, index)
+			SelectorDeviceFilter(v.Info.Selector, dsrc, diffDevice),
+			bs, index)
 		if err == ErrNoSpace {
 			// swap error conditions to better match the intent
 			return ErrNoReplacement
@@ -384,7 +386,7 @@ func (v *VolumeEntry) replaceBrickInVolume(db wdb.DB, executor executors.Executo
 
 	spaceReclaimed, err := oldBrickEntry.Destroy(db, executor)
 	if err != nil {
-		logger.LogError("Error destroying old brick: %v", err)
+		allocLogger.LogError("Error destroying old brick: %v", err)
 	}
 
 	// We must read entries from db again as state on disk might
@@ -432,10 +434,10 @@ func (v *VolumeEntry) replaceBrickInVolume(db wdb.DB, executor executors.Executo
 		return nil
 	})
 	if err != nil {
-		logger.Err(err)
+		allocLogger.Err(err)
 	}
 
-	logger.Info("replaced brick:%v on node:%v at path:%v with brick:%v on node:%v at path:%v",
+	allocLogger.Info("replaced brick:%v on node:%v at path:%v with brick:%v on node:%v at path:%v",
 		oldBrickEntry.Id(), oldBrickEntry.Info.NodeId, oldBrickEntry.Info.Path,
 		newBrickEntry.Id(), newBrickEntry.Info.NodeId, newBrickEntry.Info.Path)
 	return nil
@@ -452,7 +454,7 @@ func (v *VolumeEntry) allocBricks(
 
 		// Check the named return value 'err'
 		if e != nil {
-			logger.Debug("Error detected.  Cleaning up volume %v: Len(%v) ", v.Info.Id, len(brick_entries))
+			allocLogger.Debug("Error detected.  Cleaning up volume %v: Len(%v) ", v.Info.Id, len(brick_entries))
 			db.Update(func(tx *bolt.Tx) error {
 				for _, brick := range brick_entries {
 					v.removeBrickFromDb(tx, brick)
@@ -477,7 +479,7 @@ func (v *VolumeEntry) allocBricks(
 				if err != nil {
 					return err
 				}
-				logger.Debug("Adding brick %v to volume %v", x.Id(), v.Info.Id)
+				allocLogger.Debug("Adding brick %v to volume %v", x.Id(), v.Info.Id)
 				v.BrickAdd(x.Id())
 			}
 		}
@@ -501,21 +503,21 @@ func (v *VolumeEntry) allocBricks(
 func (v *VolumeEntry) removeBrickFromDb(tx *bolt.Tx, brick *BrickEntry) error {
 	err := brick.RemoveFromDevice(tx)
 	if err != nil {
-		logger.Err(err)
+		allocLogger.Err(err)
 		return err
 	}
 
 	// Delete brick entryfrom db
 	err = brick.Delete(tx)
 	if err != nil {
-		logger.Err(err)
+		allocLogger.Err(err)
 		return err
 	}
 
 	// Delete brick from volume db
 	v.BrickDelete(brick.Info.Id)
 	if err != nil {
-		logger.Err(err)
+		allocLogger.Err(err)
 		return err
 	}
 
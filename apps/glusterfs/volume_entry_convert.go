@@ -0,0 +1,267 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/executors"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// checkConvert returns an error if the volume can not be converted
+// to a replica 3 volume, or to an arbiter volume if arbiter is set.
+func (v *VolumeEntry) checkConvert(arbiter bool) error {
+	if v.Info.Durability.Type != api.DurabilityReplicate {
+		return fmt.Errorf("only replicate volumes can be converted, "+
+			"volume has durability type %v", v.Info.Durability.Type)
+	}
+	if v.HasArbiterOption() {
+		return fmt.Errorf("volume is already an arbiter volume")
+	}
+	switch v.Info.Durability.Replicate.Replica {
+	case 2:
+		return nil
+	case 3:
+		if !arbiter {
+			return fmt.Errorf("volume already has replica 3")
+		}
+		return nil
+	}
+	return fmt.Errorf("volumes with replica %v can not be converted",
+		v.Info.Durability.Replicate.Replica)
+}
+
+// setConverted changes the durability of the volume entry to the one
+// it has once converted.
+func (v *VolumeEntry) setConverted(arbiter bool) {
+	v.Info.Durability.Replicate.Replica = 3
+	if arbiter && !v.HasArbiterOption() {
+		v.GlusterVolumeOptions = append(v.GlusterVolumeOptions,
+			HEKETI_ARBITER_KEY+" true")
+	}
+	v.Durability = NewVolumeReplicaDurability(&v.Info.Durability.Replicate)
+}
+
+// convertedEntry returns a copy of the volume entry with the durability
+// the volume has once converted. It is used to place the new bricks.
+func (v *VolumeEntry) convertedEntry(arbiter bool) *VolumeEntry {
+	cv := *v
+	cv.GlusterVolumeOptions = append([]string{}, v.GlusterVolumeOptions...)
+	cv.setConverted(arbiter)
+	return &cv
+}
+
+// brickSets returns the brick sets of the volume in the order gluster
+// keeps them.
+func (v *VolumeEntry) brickSets(db wdb.RODB,
+	executor executors.Executor, node string) ([]*BrickSet, error) {
+
+	vinfo, err := executor.VolumeInfo(node, v.Info.Name)
+	if err != nil {
+		allocLogger.LogError("Unable to get volume info from gluster node %v for volume %v: %v", node, v.Info.Name, err)
+		return nil, err
+	}
+	bmap, err := v.brickNameMap(db)
+	if err != nil {
+		return nil, err
+	}
+
+	ssize := v.Durability.BricksInSet()
+	if len(vinfo.Bricks.BrickList)%ssize != 0 {
+		return nil, fmt.Errorf("volume %v has %v bricks, expected a "+
+			"multiple of %v", v.Info.Name, len(vinfo.Bricks.BrickList), ssize)
+	}
+	sets := []*BrickSet{}
+	for i := 0; i < len(vinfo.Bricks.BrickList); i += ssize {
+		bs := NewBrickSet(ssize)
+		for _, brick := range vinfo.Bricks.BrickList[i : i+ssize] {
+			brickentry, found := bmap[brick.Name]
+			if !found {
+				allocLogger.LogError("Unable to create brick entry using brick name:%v",
+					brick.Name)
+				return nil, ErrNotFound
+			}
+			bs.Add(brickentry)
+		}
+		sets = append(sets, bs)
+	}
+	return sets, nil
+}
+
+// allocConvertBricks places a new third brick in each of the brick
+// sets. For volumes with replica 3 the current third brick of each set
+// is replaced, and recorded in the pending operation to be deleted.
+func (v *VolumeEntry) allocConvertBricks(db wdb.DB,
+	op *PendingOperationEntry,
+	sets []*BrickSet,
+	arbiter bool) error {
+
+	const index = 2
+	cv := v.convertedEntry(arbiter)
+	return db.Update(func(tx *bolt.Tx) error {
+		dsrc := NewClusterDeviceSource(tx, v.Info.Cluster)
+		placer := PlacerForVolume(cv, dsrc)
+		for _, bs := range sets {
+			placeSet := NewBrickSet(cv.Durability.BricksInSet())
+			placeSet.Bricks = bs.Bricks
+			if len(bs.Bricks) > index {
+				old := bs.Bricks[index]
+				op.RecordDeleteBrick(old)
+				if err := old.Save(tx); err != nil {
+					return err
+				}
+			}
+
+			// the replace path places bricks of the size it is given,
+			// arbiter bricks are sized from the data bricks here
+			brickSize := bs.Bricks[0].Info.Size
+			if arbiter {
+				var err error
+				brickSize, err = discountBrickSize(brickSize,
+					cv.GetAverageFileSize())
+				if err != nil {
+					return err
+				}
+			}
+			r, err := placer.Replace(dsrc,
+				NewVolumePlacementOpts(cv, brickSize, 1),
+				SelectorDeviceFilter(v.Info.Selector, dsrc, nil),
+				placeSet, index)
+			if err != nil {
+				return err
+			}
+			brick := r.BrickSets[0].Bricks[index]
+			device := r.DeviceSets[0].Devices[index]
+			device.BrickAdd(brick.Id())
+			if err := device.Save(tx); err != nil {
+				return err
+			}
+			op.RecordAddBrick(brick)
+			if err := brick.Save(tx); err != nil {
+				return err
+			}
+			v.BrickAdd(brick.Id())
+		}
+		if err := v.Save(tx); err != nil {
+			return err
+		}
+		return op.Save(tx)
+	})
+}
+
+// convertVolumeExec creates the new bricks of the volume and changes
+// the replica count on gluster. The bricks taken out of the volume are
+// destroyed, the devices they were freed from are returned.
+func (v *VolumeEntry) convertVolumeExec(db wdb.DB,
+	executor executors.Executor,
+	op *PendingOperationEntry,
+	arbiter bool) (map[string]bool, error) {
+
+	node, err := GetVerifiedManageHostname(db, executor, v.Info.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := v.brickSets(db, executor, node)
+	if err != nil {
+		return nil, err
+	}
+	err = v.allocConvertBricks(db, op, sets, arbiter)
+	if err != nil {
+		return nil, err
+	}
+
+	added, removed, err := convertBricksFromOp(db, op, v.Info.Gid)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateBricks(db, executor, added)
+	if err != nil {
+		return nil, err
+	}
+
+	vcr := &executors.VolumeConvertRequest{
+		Name:    v.Info.Name,
+		Replica: 3,
+		Arbiter: arbiter,
+	}
+	if len(removed) > 0 {
+		vr, _, err := v.createVolumeRequest(db, removed)
+		if err != nil {
+			return nil, err
+		}
+		vcr.RemoveBricks = vr.Bricks
+		_, err = executor.VolumeConvert(node, vcr)
+		if err != nil {
+			return nil, err
+		}
+		vcr.RemoveBricks = nil
+
+		// The volume no longer has the replaced bricks, from here on
+		// the db can not be rolled back to the bricks it had
+		err = db.Update(func(tx *bolt.Tx) error {
+			op.SetConvertBricksRemoved()
+			return op.Save(tx)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	vr, _, err := v.createVolumeRequest(db, added)
+	if err != nil {
+		return nil, err
+	}
+	vcr.AddBricks = vr.Bricks
+	_, err = executor.VolumeConvert(node, vcr)
+	if err != nil {
+		return nil, err
+	}
+
+	// After this point the volume has been converted on gluster and
+	// errors must not roll back the new bricks
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	reclaimed, err := DestroyBricks(db, executor, removed)
+	if err != nil {
+		opLogger.LogError("Error destroying bricks removed from volume %v: %v",
+			v.Info.Name, err)
+	}
+	return reclaimed, nil
+}
+
+// convertBricksFromOp returns the bricks a volume convert operation
+// adds to and removes from the volume.
+func convertBricksFromOp(db wdb.RODB, op *PendingOperationEntry,
+	gid int64) (added, removed []*BrickEntry, err error) {
+
+	deleted := map[string]bool{}
+	for _, a := range op.Actions {
+		if a.Change == OpDeleteBrick {
+			deleted[a.Id] = true
+		}
+	}
+	brick_entries, err := bricksFromOp(db, op, gid)
+	if err != nil {
+		return nil, nil, err
+	}
+	added = []*BrickEntry{}
+	removed = []*BrickEntry{}
+	for _, b := range brick_entries {
+		if deleted[b.Info.Id] {
+			removed = append(removed, b)
+		} else {
+			added = append(added, b)
+		}
+	}
+	return added, removed, nil
+}
@@ -37,6 +37,9 @@ func (v *VolumeEntry) createVolume(db wdb.RODB,
 	if _, err := executor.VolumeCreate(host, vr); err != nil {
 		return err
 	}
+	if v.Info.RootQuota {
+		return v.setRootQuota(executor, host, v.Info.Size)
+	}
 	return nil
 }
 
@@ -106,7 +109,7 @@ func (v *VolumeEntry) createVolumeRequest(db wdb.RODB,
 			return nil
 		})
 		if err != nil {
-			logger.Err(err)
+			opLogger.Err(err)
 			return nil, "", err
 		}
 	}
@@ -0,0 +1,349 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/executors"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+// runningMigrations tracks the volumes whose migration is being run by
+// an operation of this server. The state of a migration is kept in its
+// pending operation so that it can be resumed, this only prevents two
+// requests from running the same migration at once.
+var runningMigrations = struct {
+	sync.Mutex
+	ids map[string]bool
+}{ids: map[string]bool{}}
+
+// claimMigration returns an error if an operation of this server is
+// already running the migration of the volume.
+func claimMigration(volumeId string) error {
+	runningMigrations.Lock()
+	defer runningMigrations.Unlock()
+	if runningMigrations.ids[volumeId] {
+		return fmt.Errorf("migration of volume %v is in progress", volumeId)
+	}
+	runningMigrations.ids[volumeId] = true
+	return nil
+}
+
+func releaseMigration(volumeId string) {
+	runningMigrations.Lock()
+	defer runningMigrations.Unlock()
+	delete(runningMigrations.ids, volumeId)
+}
+
+// volumeMigrationOp returns the pending operation of the migration of
+// the volume, or nil if the volume is not being migrated.
+func volumeMigrationOp(tx *bolt.Tx, volumeId string) (
+	*PendingOperationEntry, error) {
+
+	ids, err := PendingOperationList(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		op, err := NewPendingOperationEntryFromId(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if op.Type != OperationMigrateVolume {
+			continue
+		}
+		for _, a := range op.Actions {
+			if a.Change == OpMigrateVolume && a.Id == volumeId {
+				return op, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// checkNotMigrating returns ErrMigrating if the volume is being
+// migrated.
+func checkNotMigrating(tx *bolt.Tx, volumeId string) error {
+	op, err := volumeMigrationOp(tx, volumeId)
+	if err != nil {
+		return err
+	}
+	if op != nil {
+		return ErrMigrating
+	}
+	return nil
+}
+
+// migrationFromOp returns the state of a volume migration and the id
+// of the volume created on the target cluster.
+func migrationFromOp(op *PendingOperationEntry) (
+	state api.MigrationState, targetId string, err error) {
+
+	for _, a := range op.Actions {
+		switch a.Change {
+		case OpMigrateVolume:
+			state, err = a.MigrationState()
+			if err != nil {
+				return
+			}
+		case OpAddVolume:
+			targetId = a.Id
+		}
+	}
+	if state == "" || targetId == "" {
+		err = fmt.Errorf("pending operation %v is not a volume migration",
+			op.Id)
+	}
+	return
+}
+
+// migrationCutOver returns true if the pending operation is a volume
+// migration whose source volume may already have been deleted.
+func migrationCutOver(op *PendingOperationEntry) bool {
+	if op.Type != OperationMigrateVolume {
+		return false
+	}
+	state, _, err := migrationFromOp(op)
+	return err == nil &&
+		(state == api.MigrationCutover || state == api.MigrationRetiring)
+}
+
+// saveMigrationState records the state the migration has reached in
+// the db so that it is resumed from there.
+func saveMigrationState(db wdb.DB, op *PendingOperationEntry,
+	state api.MigrationState) error {
+
+	return db.Update(func(tx *bolt.Tx) error {
+		op.SetMigrationState(state)
+		return op.Save(tx)
+	})
+}
+
+// NewVolumeMigrationInfo returns the api description of the migration
+// recorded in the pending operation.
+func NewVolumeMigrationInfo(tx *bolt.Tx, op *PendingOperationEntry,
+	volumeId string) (*api.VolumeMigrationInfo, error) {
+
+	state, targetId, err := migrationFromOp(op)
+	if err != nil {
+		return nil, err
+	}
+	target, err := NewVolumeEntryFromId(tx, targetId)
+	if err != nil {
+		return nil, err
+	}
+	return &api.VolumeMigrationInfo{
+		Id:        volumeId,
+		Target:    targetId,
+		ClusterId: target.Info.Cluster,
+		State:     state,
+	}, nil
+}
+
+// checkMigrate returns an error if the volume can not be migrated to
+// the cluster.
+func (v *VolumeEntry) checkMigrate(clusterId string) error {
+	if v.Info.Block {
+		return fmt.Errorf("block hosting volumes can not be migrated")
+	}
+	if v.Info.Cluster == clusterId {
+		return fmt.Errorf("volume is already on cluster %v", clusterId)
+	}
+	if v.Pending.Id != "" {
+		return fmt.Errorf("volume has a pending operation")
+	}
+	return nil
+}
+
+// NewVolumeEntryForMigration returns a new volume entry with the same
+// name and properties as the volume, to be created on the cluster.
+func NewVolumeEntryForMigration(v *VolumeEntry, clusterId string) *VolumeEntry {
+	entry := NewVolumeEntry()
+
+	entry.Info.Id = utils.GenUUID()
+	entry.Info.Name = v.Info.Name
+	entry.Info.Size = v.Info.Size
+	entry.Info.Durability = v.Info.Durability
+	entry.Info.Snapshot = v.Info.Snapshot
+	entry.Info.Gid = v.Info.Gid
+	entry.Info.Selector = v.Info.Selector
+	entry.Info.Allocation = v.Info.Allocation
+	entry.Info.BrickPolicy = v.Info.BrickPolicy
+	entry.Info.RootQuota = v.Info.RootQuota
+	entry.Info.Tags = copyTags(v.Info.Tags)
+	entry.Info.Clusters = []string{clusterId}
+	entry.Durability = v.Durability
+	entry.GlusterVolumeOptions = append([]string{},
+		v.GlusterVolumeOptions...)
+
+	return entry
+}
+
+// syncMigration copies the files of the volume to the volume created
+// for it on the target cluster. The copy is run from a node of the
+// target cluster.
+func (v *VolumeEntry) syncMigration(db wdb.RODB,
+	executor executors.Executor,
+	target *VolumeEntry) error {
+
+	host, err := GetVerifiedManageHostname(db, executor, target.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	return executor.VolumeSync(host, &executors.VolumeSyncRequest{
+		Name:       v.Info.Name,
+		SourceHost: v.Info.Mount.GlusterFS.Hosts[0],
+		TargetHost: target.Info.Mount.GlusterFS.Hosts[0],
+	})
+}
+
+// setMigrationReadOnly makes the volume read-only, or writable again,
+// on the cluster it is being migrated from. The last copy of a
+// migration is made while the volume is read-only so that no writes
+// are lost when it is deleted.
+func (v *VolumeEntry) setMigrationReadOnly(db wdb.RODB,
+	executor executors.Executor,
+	readOnly bool) error {
+
+	host, err := GetVerifiedManageHostname(db, executor, v.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	return executor.VolumeReadOnly(host, v.Info.Name, readOnly)
+}
+
+// destroyMigrationTarget deletes the volume created for a migration
+// and its bricks from the storage systems. Volumes and bricks that do
+// not exist are ignored.
+func (v *VolumeEntry) destroyMigrationTarget(db wdb.RODB,
+	executor executors.Executor,
+	brick_entries []*BrickEntry) error {
+
+	err := v.runOnHost(db, func(h string) (bool, error) {
+		err := executor.VolumeDestroy(h, v.Info.Name)
+		switch {
+		case err == nil:
+			return false, nil
+		case strings.Contains(err.Error(), "does not exist"):
+			return false, nil
+		default:
+			opLogger.Warning("failed to delete volume %v via %v: %v",
+				v.Info.Id, h, err)
+			return true, err
+		}
+	})
+	if err != nil {
+		return err
+	}
+	DestroyBricks(db, executor, brick_entries)
+	return nil
+}
+
+// removeMigrationTarget removes the volume created for a migration
+// and its bricks from the db, and frees the space of the bricks.
+func (v *VolumeEntry) removeMigrationTarget(tx *bolt.Tx,
+	brick_entries []*BrickEntry) error {
+
+	for _, brick := range brick_entries {
+		device, err := NewDeviceEntryFromId(tx, brick.Info.DeviceId)
+		if err != nil {
+			return err
+		}
+		device.StorageFree(brick.TotalSize())
+		if e := device.Save(tx); e != nil {
+			return e
+		}
+		if e := v.removeBrickFromDb(tx, brick); e != nil {
+			return e
+		}
+	}
+	cluster, err := NewClusterEntryFromId(tx, v.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	cluster.VolumeDelete(v.Info.Id)
+	if e := cluster.Save(tx); e != nil {
+		return e
+	}
+	return v.Delete(tx)
+}
+
+// saveMigratedVolume moves the volume to the cluster of the volume
+// created for its migration. The volume keeps its id and takes the
+// bricks and mount information of the target volume, its own bricks
+// are removed from the db.
+func (v *VolumeEntry) saveMigratedVolume(tx *bolt.Tx,
+	op *PendingOperationEntry,
+	target *VolumeEntry,
+	reclaimed map[string]bool) error {
+
+	for _, id := range v.BricksIds() {
+		brick, err := NewBrickEntryFromId(tx, id)
+		if err != nil {
+			return err
+		}
+		if reclaimed[brick.Info.DeviceId] {
+			device, err := NewDeviceEntryFromId(tx, brick.Info.DeviceId)
+			if err != nil {
+				return err
+			}
+			device.StorageFree(brick.TotalSize())
+			if e := device.Save(tx); e != nil {
+				return e
+			}
+		}
+		if e := v.removeBrickFromDb(tx, brick); e != nil {
+			return e
+		}
+	}
+	cluster, err := NewClusterEntryFromId(tx, v.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	cluster.VolumeDelete(v.Info.Id)
+	if e := cluster.Save(tx); e != nil {
+		return e
+	}
+
+	brick_entries, err := bricksFromOp(wdb.WrapTx(tx), op, target.Info.Gid)
+	if err != nil {
+		return err
+	}
+	for _, brick := range brick_entries {
+		op.FinalizeBrick(brick)
+		brick.Info.VolumeId = v.Info.Id
+		if e := brick.Save(tx); e != nil {
+			return e
+		}
+		v.BrickAdd(brick.Info.Id)
+	}
+	cluster, err = NewClusterEntryFromId(tx, target.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	cluster.VolumeDelete(target.Info.Id)
+	cluster.VolumeAdd(v.Info.Id)
+	if e := cluster.Save(tx); e != nil {
+		return e
+	}
+
+	v.Info.Cluster = target.Info.Cluster
+	v.Info.Mount = target.Info.Mount
+	if e := v.Save(tx); e != nil {
+		return e
+	}
+	return target.Delete(tx)
+}
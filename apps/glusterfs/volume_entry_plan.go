@@ -0,0 +1,222 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"github.com/boltdb/bolt"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// reasons devices are not used for a brick
+const (
+	rejectNodeOffline   = "node is offline"
+	rejectUnhealthy     = "node failed its last health check"
+	rejectOffline       = "device is offline"
+	rejectSameNode      = "node already has a brick of the brick set"
+	rejectSameDomain    = "%v already has a brick of the brick set"
+	rejectFiltered      = "excluded by the placement selector"
+	rejectTooSmall      = "not enough free space: %v KB needed, %v KB free"
+	rejectPhysicalUsage = "%.1f%% used by data, the limit is %v%%"
+	rejectNoArbiter     = "arbiter tag does not allow arbiter bricks"
+	rejectNoData        = "arbiter tag does not allow data bricks"
+)
+
+// tracedPlacementOpts records why the placer rejected devices
+type tracedPlacementOpts struct {
+	PlacementOpts
+	rejected map[string]api.DeviceRejection
+	order    []string
+}
+
+func newTracedPlacementOpts(opts PlacementOpts) *tracedPlacementOpts {
+	return &tracedPlacementOpts{
+		PlacementOpts: opts,
+		rejected:      map[string]api.DeviceRejection{},
+	}
+}
+
+// RejectDevice records the reason a device was not used. Only the
+// last reason is kept as devices are tried again for later bricks.
+func (t *tracedPlacementOpts) RejectDevice(d *DeviceEntry, reason string) {
+	if _, ok := t.rejected[d.Info.Id]; !ok {
+		t.order = append(t.order, d.Info.Id)
+	}
+	t.rejected[d.Info.Id] = api.DeviceRejection{
+		Device: d.Info.Id,
+		Node:   d.NodeId,
+		Reason: reason,
+	}
+}
+
+func (t *tracedPlacementOpts) Rejections() []api.DeviceRejection {
+	r := []api.DeviceRejection{}
+	for _, id := range t.order {
+		r = append(r, t.rejected[id])
+	}
+	return r
+}
+
+// rejectUnavailableDevices records the devices of the cluster that
+// the ClusterDeviceSource does not offer to the placer.
+func rejectUnavailableDevices(tx *bolt.Tx, clusterId string,
+	opts PlacementOpts) error {
+
+	cluster, err := NewClusterEntryFromId(tx, clusterId)
+	if err != nil {
+		return err
+	}
+	nodeUp := currentNodeHealthStatus()
+	for _, nodeId := range cluster.Info.Nodes {
+		node, err := NewNodeEntryFromId(tx, nodeId)
+		if err != nil {
+			return err
+		}
+		_, healthChecked := nodeUp[nodeId]
+		for _, deviceId := range node.Devices {
+			device, err := NewDeviceEntryFromId(tx, deviceId)
+			if err != nil {
+				return err
+			}
+			switch {
+			case !node.isOnline():
+				rejectDevice(opts, device, rejectNodeOffline)
+			case healthChecked && !nodeUp[nodeId]:
+				rejectDevice(opts, device, rejectUnhealthy)
+			case !device.isOnline():
+				rejectDevice(opts, device, rejectOffline)
+			}
+		}
+	}
+	return nil
+}
+
+// simulateAllocBricks places the bricks of the volume in the cluster
+// trying the same brick sizes as allocBricksInCluster, but the bricks
+// and devices are only changed in memory. The options of the last
+// placement tried are returned with the reasons devices were rejected.
+func (v *VolumeEntry) simulateAllocBricks(tx *bolt.Tx, clusterId string) (
+	*BrickAllocation, *tracedPlacementOpts, error) {
+
+	var opts *tracedPlacementOpts
+	gen := v.brickSizeGenerator(uint64(v.Info.Size) * GB)
+	for {
+		sets, brickSize, err := gen()
+		if err != nil {
+			return nil, opts, err
+		}
+
+		if sets*v.Durability.BricksInSet()+len(v.Bricks) > BrickMaxNum {
+			return nil, opts, ErrMaxBricks
+		}
+
+		// a new device source is used for every attempt as the
+		// placer reserves space on the devices it caches
+		dsrc := NewClusterDeviceSource(tx, clusterId)
+		opts = newTracedPlacementOpts(
+			NewVolumePlacementOpts(v, brickSize, sets))
+		err = rejectUnavailableDevices(tx, clusterId, opts)
+		if err != nil {
+			return nil, opts, err
+		}
+		r, err := PlacerForVolume(v, dsrc).PlaceAll(dsrc, opts,
+			SelectorDeviceFilter(v.Info.Selector, dsrc, nil))
+		if err == ErrNoSpace {
+			continue
+		} else if err != nil {
+			return nil, opts, err
+		}
+		return r, opts, nil
+	}
+}
+
+// Plan finds the cluster and the devices the bricks of the volume
+// would be placed on if it was created now, without creating the
+// volume. If the volume can not be placed the reasons are reported
+// for each cluster tried.
+func (v *VolumeEntry) Plan(db wdb.RODB) (*api.VolumePlanResponse, error) {
+	plan := &api.VolumePlanResponse{}
+
+	var possibleClusters []string
+	err := db.View(func(tx *bolt.Tx) error {
+		var err error
+		if len(v.Info.Clusters) == 0 {
+			possibleClusters, err = ClusterList(tx)
+		} else {
+			possibleClusters = v.Info.Clusters
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cr := ClusterReq{v.Info.Block, v.Info.Name}
+	eligible, err := eligibleClusters(db, cr, possibleClusters)
+	if err != nil {
+		return nil, err
+	}
+	isEligible := map[string]bool{}
+	for _, clusterId := range eligible {
+		isEligible[clusterId] = true
+	}
+	for _, clusterId := range possibleClusters {
+		if !isEligible[clusterId] {
+			plan.Rejected = append(plan.Rejected, api.ClusterRejection{
+				Id: clusterId,
+				Error: "cluster does not allow this type of volume " +
+					"or the name is already in use",
+			})
+		}
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		for _, clusterId := range eligible {
+			r, opts, err := v.simulateAllocBricks(tx, clusterId)
+			if err == nil {
+				plan.Feasible = true
+				plan.Cluster = clusterId
+				for _, bs := range r.BrickSets {
+					sp := api.BrickSetPlan{Bricks: []api.BrickPlan{}}
+					for _, b := range bs.Bricks {
+						sp.Bricks = append(sp.Bricks, api.BrickPlan{
+							Device: b.Info.DeviceId,
+							Node:   b.Info.NodeId,
+							Size:   b.Info.Size,
+						})
+					}
+					plan.BrickSets = append(plan.BrickSets, sp)
+				}
+				return nil
+			}
+			if err != ErrNoSpace &&
+				err != ErrMaxBricks &&
+				err != ErrMinimumBrickSize {
+				return err
+			}
+
+			rejection := api.ClusterRejection{
+				Id:    clusterId,
+				Error: err.Error(),
+			}
+			if opts != nil {
+				rejection.BrickSize, _ = opts.BrickSizes()
+				rejection.Devices = opts.Rejections()
+			}
+			plan.Rejected = append(plan.Rejected, rejection)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,190 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/executors"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+const (
+	// Part of the size of a volume its root quota allows to be used,
+	// in percent. The rest is left to the file system metadata so
+	// that the bricks do not fill up.
+	rootQuotaPercent = 95
+
+	quotaRootPath   = "/"
+	quotaOptionName = "features.quota"
+)
+
+// rootQuotaLimit returns the root quota of a volume of the size, in
+// bytes.
+func rootQuotaLimit(sizeGB int) uint64 {
+	return uint64(sizeGB) * GB * 1024 * rootQuotaPercent / 100
+}
+
+// setRootQuota enables quotas on the volume and limits its root
+// directory to the root quota of a volume of the size.
+func (v *VolumeEntry) setRootQuota(executor executors.Executor,
+	host string, sizeGB int) error {
+
+	err := executor.VolumeQuotaEnable(host, v.Info.Name)
+	if err != nil {
+		return err
+	}
+	return executor.VolumeQuotaSetLimit(host, v.Info.Name,
+		&executors.QuotaLimitRequest{
+			Path:      quotaRootPath,
+			HardLimit: rootQuotaLimit(sizeGB),
+		})
+}
+
+// raiseRootQuota sets the root quota of the volume to that of its size
+// once expanded.
+func (v *VolumeEntry) raiseRootQuota(db wdb.RODB,
+	executor executors.Executor, sizeGB int) error {
+
+	host, err := GetVerifiedManageHostname(db, executor, v.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	return v.setRootQuota(executor, host, sizeGB)
+}
+
+// clearRootQuota records that the limit of the root directory is no
+// longer kept in line with the size of the volume.
+func (v *VolumeEntry) clearRootQuota(db wdb.DB) error {
+	if !v.Info.RootQuota {
+		return nil
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		entry, err := NewVolumeEntryFromId(tx, v.Info.Id)
+		if err != nil {
+			return err
+		}
+		entry.Info.RootQuota = false
+		v.Info.RootQuota = false
+		return entry.Save(tx)
+	})
+}
+
+// Quota returns the limits set on the volume and their usage.
+func (v *VolumeEntry) Quota(db wdb.RODB,
+	executor executors.Executor) (*api.VolumeQuotaResponse, error) {
+
+	quota := &api.VolumeQuotaResponse{
+		Id:     v.Info.Id,
+		Limits: []api.VolumeQuotaLimitInfo{},
+	}
+
+	host, err := GetVerifiedManageHostname(db, executor, v.Info.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	vinfo, err := executor.VolumeInfo(host, v.Info.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range vinfo.Options.OptionList {
+		if o.Name == quotaOptionName && o.Value == "on" {
+			quota.Enabled = true
+		}
+	}
+	if !quota.Enabled {
+		return quota, nil
+	}
+
+	limits, err := executor.VolumeQuotaList(host, v.Info.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range limits {
+		quota.Limits = append(quota.Limits, api.VolumeQuotaLimitInfo{
+			Path:              l.Path,
+			HardLimit:         l.HardLimit,
+			SoftLimitPercent:  l.SoftLimitPercent,
+			Used:              l.Used,
+			Available:         l.Available,
+			SoftLimitExceeded: l.SoftLimitExceeded,
+			HardLimitExceeded: l.HardLimitExceeded,
+		})
+	}
+	return quota, nil
+}
+
+// QuotaEnable enables or disables the quotas of the volume. Disabling
+// quotas removes the limits set on the volume, including its root
+// quota.
+func (v *VolumeEntry) QuotaEnable(db wdb.DB,
+	executor executors.Executor, enable bool) error {
+
+	host, err := GetVerifiedManageHostname(db, executor, v.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	if enable {
+		return executor.VolumeQuotaEnable(host, v.Info.Name)
+	}
+	err = executor.VolumeQuotaDisable(host, v.Info.Name)
+	if err != nil {
+		return err
+	}
+	return v.clearRootQuota(db)
+}
+
+// QuotaSetLimit sets the limit of a directory of the volume, enabling
+// quotas if needed. A limit set on the root directory replaces the
+// root quota, which is then no longer raised when the volume is
+// expanded.
+func (v *VolumeEntry) QuotaSetLimit(db wdb.DB,
+	executor executors.Executor, req *api.VolumeQuotaLimitRequest) error {
+
+	host, err := GetVerifiedManageHostname(db, executor, v.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	err = executor.VolumeQuotaEnable(host, v.Info.Name)
+	if err != nil {
+		return err
+	}
+	err = executor.VolumeQuotaSetLimit(host, v.Info.Name,
+		&executors.QuotaLimitRequest{
+			Path:             req.Path,
+			HardLimit:        req.HardLimit,
+			SoftLimitPercent: req.SoftLimitPercent,
+		})
+	if err != nil {
+		return err
+	}
+	if req.Path == quotaRootPath {
+		return v.clearRootQuota(db)
+	}
+	return nil
+}
+
+// QuotaRemoveLimit removes the limit of a directory of the volume.
+func (v *VolumeEntry) QuotaRemoveLimit(db wdb.DB,
+	executor executors.Executor, path string) error {
+
+	host, err := GetVerifiedManageHostname(db, executor, v.Info.Cluster)
+	if err != nil {
+		return err
+	}
+	err = executor.VolumeQuotaRemoveLimit(host, v.Info.Name, path)
+	if err != nil {
+		return err
+	}
+	if path == quotaRootPath {
+		return v.clearRootQuota(db)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"strconv"
+
+	"github.com/boltdb/bolt"
+	"github.com/heketi/heketi/executors"
+	wdb "github.com/heketi/heketi/pkg/db"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+)
+
+// Status queries gluster for the status and pending heals of the
+// bricks of the volume.
+func (v *VolumeEntry) Status(db wdb.RODB,
+	executor executors.Executor) (*api.VolumeStatusResponse, error) {
+
+	status := &api.VolumeStatusResponse{
+		Id:      v.Info.Id,
+		Name:    v.Info.Name,
+		Cluster: v.Info.Cluster,
+		Bricks:  []api.BrickStatus{},
+	}
+
+	names := map[string]int{}
+	err := db.View(func(tx *bolt.Tx) error {
+		for _, brickId := range v.BricksIds() {
+			brick, err := NewBrickEntryFromId(tx, brickId)
+			if err != nil {
+				return err
+			}
+			node, err := NewNodeEntryFromId(tx, brick.Info.NodeId)
+			if err != nil {
+				return err
+			}
+			bs := api.BrickStatus{
+				Id:       brick.Info.Id,
+				NodeId:   brick.Info.NodeId,
+				DeviceId: brick.Info.DeviceId,
+				Host:     node.StorageHostName(),
+				Path:     brick.Info.Path,
+			}
+			names[bs.Host+":"+bs.Path] = len(status.Bricks)
+			status.Bricks = append(status.Bricks, bs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := GetVerifiedManageHostname(db, executor, v.Info.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	vs, err := executor.VolumeStatus(host, v.Info.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range vs.Nodes {
+		// daemons are listed with the bricks, skip them
+		i, ok := names[n.Hostname+":"+n.Path]
+		if !ok {
+			continue
+		}
+		bs := &status.Bricks[i]
+		bs.Online = n.Status == 1
+		if bs.Online {
+			bs.Pid = n.Pid
+			bs.Port, _ = strconv.Atoi(n.Port)
+		}
+	}
+
+	// Only replicated and dispersed volumes heal
+	healed := v.Info.Durability.Type == api.DurabilityReplicate ||
+		v.Info.Durability.Type == api.DurabilityEC
+	if healed {
+		healinfo, err := executor.HealInfo(host, v.Info.Name)
+		if err != nil {
+			opLogger.Warning("Unable to get heal info of volume %v: %v",
+				v.Info.Id, err)
+			// without heal info the volume can not be assumed
+			// to be healthy
+			status.Degraded = true
+		} else {
+			for _, h := range healinfo.Bricks.BrickList {
+				i, ok := names[h.Name]
+				if !ok {
+					continue
+				}
+				// bricks that are down report "-"
+				entries, err := strconv.ParseUint(h.NumberOfEntries, 10, 64)
+				if err != nil {
+					continue
+				}
+				status.Bricks[i].PendingHeals = &entries
+			}
+		}
+	}
+
+	for _, bs := range status.Bricks {
+		if !bs.Online ||
+			(bs.PendingHeals != nil && *bs.PendingHeals > 0) {
+			status.Degraded = true
+		}
+	}
+
+	return status, nil
+}
+
+// ClusterStatus returns the status of all volumes in the cluster
+// which are degraded or whose status can not be determined.
+func ClusterStatus(db wdb.RODB, executor executors.Executor,
+	clusterId string) (*api.ClusterStatusResponse, error) {
+
+	var volumes []*VolumeEntry
+	err := db.View(func(tx *bolt.Tx) error {
+		cluster, err := NewClusterEntryFromId(tx, clusterId)
+		if err != nil {
+			return err
+		}
+		for _, volumeId := range cluster.Info.Volumes {
+			volume, err := NewVolumeEntryFromId(tx, volumeId)
+			if err != nil {
+				return err
+			}
+			if !volume.Visible() {
+				continue
+			}
+			volumes = append(volumes, volume)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &api.ClusterStatusResponse{
+		Id:              clusterId,
+		VolumeCount:     len(volumes),
+		DegradedVolumes: []api.VolumeStatusResponse{},
+	}
+	for _, volume := range volumes {
+		vs, err := volume.Status(db, executor)
+		if err != nil {
+			vs = &api.VolumeStatusResponse{
+				Id:       volume.Info.Id,
+				Name:     volume.Info.Name,
+				Cluster:  clusterId,
+				Degraded: true,
+				Bricks:   []api.BrickStatus{},
+				Error:    err.Error(),
+			}
+		}
+		if vs.Degraded {
+			status.DegradedVolumes = append(status.DegradedVolumes, *vs)
+		}
+	}
+	return status, nil
+}
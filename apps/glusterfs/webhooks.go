@@ -0,0 +1,272 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+const (
+	// Notifications queued per webhook before new ones are dropped
+	webhookQueueSize = 100
+	// Failures kept for the API
+	webhookMaxFailures = 100
+)
+
+var (
+	// Delay before the first retry, doubled for each further retry
+	webhookRetryDelay = time.Second
+	// Longest delay between retries
+	webhookMaxRetryDelay = 5 * time.Minute
+	// Time allowed for a webhook to respond
+	webhookTimeout = 10 * time.Second
+)
+
+type WebhookConfig struct {
+	Url string `json:"url"`
+	// Used to sign the notifications, unsigned if empty
+	Secret string `json:"secret"`
+	// Kinds of notifications sent, all if empty
+	Events []api.WebhookEvent `json:"events"`
+	// Number of attempts to deliver a notification, 5 if not set
+	Retries int `json:"retries"`
+}
+
+type webhook struct {
+	config    WebhookConfig
+	queue     chan api.WebhookNotification
+	delivered uint64
+	failed    uint64
+}
+
+func (h *webhook) wants(event api.WebhookEvent) bool {
+	if len(h.config.Events) == 0 {
+		return true
+	}
+	for _, e := range h.config.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookNotifier posts notifications to the configured webhooks.
+// Each webhook has a queue of notifications delivered in order,
+// a notification is retried with an increasing delay until it is
+// delivered or the retries of the webhook are exhausted.
+type WebhookNotifier struct {
+	hooks    []*webhook
+	failures []api.WebhookFailure
+	client   *http.Client
+	lock     sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func NewWebhookNotifier(configs []WebhookConfig) *WebhookNotifier {
+	wn := &WebhookNotifier{
+		hooks:    []*webhook{},
+		failures: []api.WebhookFailure{},
+		client:   &http.Client{Timeout: webhookTimeout},
+		stop:     make(chan struct{}),
+	}
+	for _, c := range configs {
+		if c.Retries <= 0 {
+			c.Retries = 5
+		}
+		wn.hooks = append(wn.hooks, &webhook{
+			config: c,
+			queue:  make(chan api.WebhookNotification, webhookQueueSize),
+		})
+	}
+	return wn
+}
+
+// Start starts delivering the notifications to the webhooks.
+func (wn *WebhookNotifier) Start() {
+	for _, h := range wn.hooks {
+		wn.wg.Add(1)
+		go func(h *webhook) {
+			defer wn.wg.Done()
+			for {
+				select {
+				case n := <-h.queue:
+					wn.deliver(h, n)
+				case <-wn.stop:
+					return
+				}
+			}
+		}(h)
+	}
+}
+
+// Stop stops delivering notifications. Queued notifications are
+// dropped. Stopping a notifier more than once has no effect.
+func (wn *WebhookNotifier) Stop() {
+	wn.stopOnce.Do(func() {
+		close(wn.stop)
+	})
+	wn.wg.Wait()
+}
+
+// Notify queues the notification for the webhooks which want it.
+func (wn *WebhookNotifier) Notify(event api.WebhookEvent,
+	resource, resourceId, message string) {
+
+	n := api.WebhookNotification{
+		Id:         utils.GenUUID(),
+		Event:      event,
+		Time:       time.Now().Unix(),
+		Resource:   resource,
+		ResourceId: resourceId,
+		Message:    message,
+	}
+	for _, h := range wn.hooks {
+		if !h.wants(event) {
+			continue
+		}
+		select {
+		case h.queue <- n:
+		default:
+			wn.failed(h, n, 0, errors.New("queue is full"))
+		}
+	}
+}
+
+func (wn *WebhookNotifier) deliver(h *webhook, n api.WebhookNotification) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		wn.failed(h, n, 0, err)
+		return
+	}
+
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		err = wn.post(h, n, body)
+		if err == nil {
+			wn.lock.Lock()
+			h.delivered++
+			wn.lock.Unlock()
+			return
+		}
+		logger.Warning("Unable to deliver notification %v to %v: %v",
+			n.Id, h.config.Url, err)
+		if attempt >= h.config.Retries {
+			wn.failed(h, n, attempt, err)
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-wn.stop:
+			return
+		}
+		delay *= 2
+		if delay > webhookMaxRetryDelay {
+			delay = webhookMaxRetryDelay
+		}
+	}
+}
+
+func (wn *WebhookNotifier) post(h *webhook,
+	n api.WebhookNotification, body []byte) error {
+
+	req, err := http.NewRequest("POST", h.config.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Heketi-Event", string(n.Event))
+	req.Header.Set("X-Heketi-Delivery", n.Id)
+	if h.config.Secret != "" {
+		req.Header.Set("X-Heketi-Signature",
+			"sha256="+WebhookSignature(h.config.Secret, body))
+	}
+
+	r, err := wn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %v", r.Status)
+	}
+	return nil
+}
+
+func (wn *WebhookNotifier) failed(h *webhook,
+	n api.WebhookNotification, attempts int, err error) {
+
+	logger.LogError("Failed to deliver notification %v to %v: %v",
+		n.Id, h.config.Url, err)
+
+	wn.lock.Lock()
+	defer wn.lock.Unlock()
+	h.failed++
+	wn.failures = append(wn.failures, api.WebhookFailure{
+		Url:          h.config.Url,
+		Notification: n,
+		Attempts:     attempts,
+		Error:        err.Error(),
+		Time:         time.Now().Unix(),
+	})
+	if len(wn.failures) > webhookMaxFailures {
+		wn.failures = wn.failures[len(wn.failures)-webhookMaxFailures:]
+	}
+}
+
+// Status returns the delivery counts of the webhooks and the most
+// recent failures.
+func (wn *WebhookNotifier) Status() *api.WebhookListResponse {
+	wn.lock.Lock()
+	defer wn.lock.Unlock()
+	status := &api.WebhookListResponse{
+		Webhooks: []api.WebhookInfo{},
+		Failures: append([]api.WebhookFailure{}, wn.failures...),
+	}
+	for _, h := range wn.hooks {
+		events := h.config.Events
+		if events == nil {
+			events = []api.WebhookEvent{}
+		}
+		status.Webhooks = append(status.Webhooks, api.WebhookInfo{
+			Url:       h.config.Url,
+			Events:    events,
+			Queued:    len(h.queue),
+			Delivered: h.delivered,
+			Failed:    h.failed,
+		})
+	}
+	return status
+}
+
+// WebhookSignature returns the hex encoded HMAC-SHA256 of the body
+// using the secret as key. Receivers compare it to the value of the
+// X-Heketi-Signature header following "sha256=".
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,279 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package glusterfs
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/tests"
+)
+
+// testWebhookReceiver stands in for a service receiving
+// notifications. It fails the first failures requests.
+type testWebhookReceiver struct {
+	lock          sync.Mutex
+	failures      int
+	requests      int
+	notifications []api.WebhookNotification
+	signatures    []string
+	bodies        [][]byte
+}
+
+func (tr *testWebhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.requests++
+	if tr.requests <= tr.failures {
+		http.Error(w, "not now", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var n api.WebhookNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Header.Get("X-Heketi-Event") != string(n.Event) {
+		http.Error(w, "event mismatch", http.StatusBadRequest)
+		return
+	}
+	tr.notifications = append(tr.notifications, n)
+	tr.signatures = append(tr.signatures, r.Header.Get("X-Heketi-Signature"))
+	tr.bodies = append(tr.bodies, body)
+}
+
+func (tr *testWebhookReceiver) received() []api.WebhookNotification {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	return append([]api.WebhookNotification{}, tr.notifications...)
+}
+
+// waitWebhooks waits until the webhooks delivered or failed to
+// deliver count notifications.
+func waitWebhooks(t *testing.T, wn *WebhookNotifier, count uint64) *api.WebhookListResponse {
+	for i := 0; i < 500; i++ {
+		status := wn.Status()
+		var done uint64
+		for _, h := range status.Webhooks {
+			done += h.Delivered + h.Failed
+		}
+		if done >= count {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %v notifications", count)
+	return nil
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	defer tests.Patch(&webhookRetryDelay, time.Millisecond).Restore()
+
+	all := &testWebhookReceiver{failures: 2}
+	allServer := httptest.NewServer(all)
+	defer allServer.Close()
+	deletes := &testWebhookReceiver{}
+	deletesServer := httptest.NewServer(deletes)
+	defer deletesServer.Close()
+	broken := &testWebhookReceiver{failures: 100}
+	brokenServer := httptest.NewServer(broken)
+	defer brokenServer.Close()
+
+	wn := NewWebhookNotifier([]WebhookConfig{
+		WebhookConfig{
+			Url:    allServer.URL,
+			Secret: "s3cret",
+		},
+		WebhookConfig{
+			Url:    deletesServer.URL,
+			Events: []api.WebhookEvent{api.WebhookVolumeDeleted},
+		},
+		WebhookConfig{
+			Url:     brokenServer.URL,
+			Retries: 3,
+			Events:  []api.WebhookEvent{api.WebhookVolumeCreated},
+		},
+	})
+	wn.Start()
+	defer wn.Stop()
+
+	wn.Notify(api.WebhookVolumeCreated, "volume", "abc", "")
+	wn.Notify(api.WebhookVolumeDeleted, "volume", "abc", "")
+	status := waitWebhooks(t, wn, 4)
+
+	// retried until delivered
+	n := all.received()
+	tests.Assert(t, len(n) == 2, n)
+	tests.Assert(t, n[0].Event == api.WebhookVolumeCreated)
+	tests.Assert(t, n[0].ResourceId == "abc")
+	tests.Assert(t, n[1].Event == api.WebhookVolumeDeleted)
+	tests.Assert(t, n[0].Id != n[1].Id)
+	tests.Assert(t, all.requests == 4, all.requests)
+	for i, sig := range all.signatures {
+		tests.Assert(t,
+			sig == "sha256="+WebhookSignature("s3cret", all.bodies[i]), sig)
+	}
+
+	// only the selected events are sent
+	n = deletes.received()
+	tests.Assert(t, len(n) == 1, n)
+	tests.Assert(t, n[0].Event == api.WebhookVolumeDeleted)
+	tests.Assert(t, deletes.signatures[0] == "", deletes.signatures)
+
+	// failures are kept after the retries
+	tests.Assert(t, broken.requests == 3, broken.requests)
+	tests.Assert(t, len(status.Webhooks) == 3)
+	tests.Assert(t, status.Webhooks[0].Delivered == 2)
+	tests.Assert(t, status.Webhooks[2].Failed == 1)
+	tests.Assert(t, len(status.Failures) == 1, status.Failures)
+	f := status.Failures[0]
+	tests.Assert(t, f.Url == brokenServer.URL)
+	tests.Assert(t, f.Attempts == 3)
+	tests.Assert(t, f.Notification.Event == api.WebhookVolumeCreated)
+	tests.Assert(t, f.Error != "")
+}
+
+func TestWebhookNotifierStopTwice(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	// closing the app stops its notifier, a second close must not
+	// close the stop channel again
+	app := NewTestApp(tmpfile)
+	tests.Assert(t, app.webhooks != nil)
+	app.Close()
+	app.Close()
+}
+
+func TestWebhooksApp(t *testing.T) {
+	tmpfile := tests.Tempfile()
+	defer os.Remove(tmpfile)
+
+	receiver := &testWebhookReceiver{}
+	rs := httptest.NewServer(receiver)
+	defer rs.Close()
+
+	// Create the app
+	app := NewTestApp(tmpfile)
+	defer app.Close()
+	app.webhooks.Stop()
+	app.webhooks = NewWebhookNotifier([]WebhookConfig{
+		WebhookConfig{Url: rs.URL},
+	})
+	app.webhooks.Start()
+	currentWebhookNotifier = app.webhooks
+	router := mux.NewRouter()
+	app.SetRoutes(router)
+
+	// Setup the server
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	err := setupSampleDbWithTopology(app,
+		1,    // clusters
+		3,    // nodes_per_cluster
+		1,    // devices_per_node,
+		1*TB, // disksize)
+	)
+	tests.Assert(t, err == nil)
+
+	c := client.NewClientNoAuth(ts.URL)
+	tests.Assert(t, c != nil)
+
+	req := &api.VolumeCreateRequest{}
+	req.Size = 10
+	req.Durability.Type = api.DurabilityReplicate
+	req.Durability.Replicate.Replica = 3
+	volume, err := c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	err = c.VolumeDelete(volume.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+
+	app.xo.MockVolumeCreate = func(host string, volume *executors.VolumeRequest) (*executors.Volume, error) {
+		return nil, errors.New("volume create failed")
+	}
+	_, err = c.VolumeCreate(req)
+	tests.Assert(t, err != nil)
+
+	waitWebhooks(t, app.webhooks, 3)
+	n := receiver.received()
+	tests.Assert(t, len(n) == 3, n)
+	tests.Assert(t, n[0].Event == api.WebhookVolumeCreated)
+	tests.Assert(t, n[0].ResourceId == volume.Id)
+	tests.Assert(t, n[1].Event == api.WebhookVolumeDeleted)
+	tests.Assert(t, n[1].ResourceId == volume.Id)
+	tests.Assert(t, n[2].Event == api.WebhookOperationFailed)
+	tests.Assert(t, n[2].Resource == "operation")
+	tests.Assert(t, n[2].Message != "")
+
+	// health changes are sent
+	app.nodeHealthChanged("abc", true)
+	app.nodeHealthChanged("abc", false)
+	app.deviceHealthChanged(DeviceHealthStatus{DeviceId: "def"},
+		api.DeviceHealthOk, api.DeviceHealthWarning, []string{"full"})
+	app.deviceHealthChanged(DeviceHealthStatus{DeviceId: "def"},
+		api.DeviceHealthWarning, api.DeviceHealthFailed, []string{"gone"})
+	waitWebhooks(t, app.webhooks, 5)
+	n = receiver.received()
+	tests.Assert(t, len(n) == 5, n)
+	tests.Assert(t, n[3].Event == api.WebhookNodeUnhealthy)
+	tests.Assert(t, n[3].ResourceId == "abc")
+	tests.Assert(t, n[4].Event == api.WebhookDeviceFailed)
+	tests.Assert(t, n[4].ResourceId == "def")
+
+	// failed device removals and devices set failed are sent
+	app.xo.MockVolumeCreate = func(host string, volume *executors.VolumeRequest) (*executors.Volume, error) {
+		return &executors.Volume{}, nil
+	}
+	volume, err = c.VolumeCreate(req)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	deviceId := volume.Bricks[0].DeviceId
+	err = c.DeviceState(deviceId, &api.StateRequest{State: api.EntryStateOffline})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	err = c.DeviceState(deviceId, &api.StateRequest{State: api.EntryStateFailed})
+	tests.Assert(t, err != nil, "expected err != nil")
+	err = c.VolumeDelete(volume.Id)
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	err = c.DeviceState(deviceId, &api.StateRequest{State: api.EntryStateFailed})
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	waitWebhooks(t, app.webhooks, 9)
+	n = receiver.received()
+	tests.Assert(t, len(n) == 9, n)
+	tests.Assert(t, n[6].Event == api.WebhookOperationFailed, n[6])
+	tests.Assert(t, strings.Contains(n[6].Message, "Remove Device"), n[6])
+	tests.Assert(t, n[7].Event == api.WebhookVolumeDeleted, n[7])
+	tests.Assert(t, n[8].Event == api.WebhookDeviceFailed, n[8])
+	tests.Assert(t, n[8].ResourceId == deviceId, n[8])
+
+	webhooks, err := c.Webhooks()
+	tests.Assert(t, err == nil, "expected err == nil, got:", err)
+	tests.Assert(t, len(webhooks.Webhooks) == 1)
+	tests.Assert(t, webhooks.Webhooks[0].Url == rs.URL)
+	tests.Assert(t, webhooks.Webhooks[0].Delivered == 9)
+	tests.Assert(t, len(webhooks.Failures) == 0)
+}
@@ -71,7 +71,13 @@ func (c *Client) BlockVolumeCreate(request *api.BlockVolumeCreateRequest) (
 }
 
 func (c *Client) BlockVolumeList() (*api.BlockVolumeListResponse, error) {
-	req, err := http.NewRequest("GET", c.host+"/blockvolumes", nil)
+	return c.BlockVolumeListQuery(nil)
+}
+
+// BlockVolumeListQuery returns the block volumes matching the query,
+// see VolumeListQuery.
+func (c *Client) BlockVolumeListQuery(q *api.ListQuery) (*api.BlockVolumeListResponse, error) {
+	req, err := http.NewRequest("GET", c.listUrl("/blockvolumes", q), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -158,3 +164,35 @@ func (c *Client) BlockVolumeDelete(id string) error {
 
 	return nil
 }
+
+func (c *Client) BlockVolumeSetTags(id string, request *api.TagsChangeRequest) error {
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST",
+		c.host+"/blockvolumes/"+id+"/tags",
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return utils.GetErrorFromResponse(r)
+	}
+	return nil
+}
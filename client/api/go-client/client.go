@@ -19,6 +19,7 @@ import (
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
 	"github.com/heketi/heketi/pkg/utils"
 )
 
@@ -141,6 +142,17 @@ func (c *Client) waitForResponseWithTimer(r *http.Response,
 
 }
 
+// listUrl returns the url of a list request filtered and paged by
+// the query, which may be nil
+func (c *Client) listUrl(path string, q *api.ListQuery) string {
+	if q != nil {
+		if params := q.Values().Encode(); params != "" {
+			return c.host + path + "?" + params
+		}
+	}
+	return c.host + path
+}
+
 // Create JSON Web Token
 func (c *Client) setToken(r *http.Request) error {
 
@@ -15,6 +15,7 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/heketi/heketi/pkg/glusterfs/api"
@@ -96,6 +97,41 @@ func (c *Client) ClusterSetFlags(id string, request *api.ClusterSetFlagsRequest)
 	return nil
 }
 
+func (c *Client) ClusterSetOvercommit(id string,
+	request *api.ClusterSetOvercommitRequest) error {
+
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	// Create a request
+	req, err := http.NewRequest("POST", c.host+"/clusters/"+id+"/overcommit",
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return utils.GetErrorFromResponse(r)
+	}
+
+	return nil
+}
+
 func (c *Client) ClusterInfo(id string) (*api.ClusterInfoResponse, error) {
 
 	// Create request
@@ -131,9 +167,15 @@ func (c *Client) ClusterInfo(id string) (*api.ClusterInfoResponse, error) {
 }
 
 func (c *Client) ClusterList() (*api.ClusterListResponse, error) {
+	return c.ClusterListQuery(nil)
+}
+
+// ClusterListQuery returns the clusters matching the query, see
+// VolumeListQuery.
+func (c *Client) ClusterListQuery(q *api.ListQuery) (*api.ClusterListResponse, error) {
 
 	// Create request
-	req, err := http.NewRequest("GET", c.host+"/clusters", nil)
+	req, err := http.NewRequest("GET", c.listUrl("/clusters", q), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -190,3 +232,112 @@ func (c *Client) ClusterDelete(id string) error {
 
 	return nil
 }
+
+func (c *Client) ClusterSetTags(id string, request *api.TagsChangeRequest) error {
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST",
+		c.host+"/clusters/"+id+"/tags",
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return utils.GetErrorFromResponse(r)
+	}
+	return nil
+}
+
+func (c *Client) ClusterStatus(id string) (*api.ClusterStatusResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/clusters/"+id+"/status", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get status
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var status api.ClusterStatusResponse
+	err = utils.GetJsonFromResponse(r, &status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// ClusterCapacity returns the free space of the cluster and the size
+// of the largest volumes which can be created in it. If snapFactor is
+// greater than 1 the sizes are for volumes with snapshots enabled.
+func (c *Client) ClusterCapacity(id string,
+	snapFactor float32) (*api.ClusterCapacityResponse, error) {
+
+	url := c.host + "/clusters/" + id + "/capacity"
+	if snapFactor > 1 {
+		url += fmt.Sprintf("?snapshot_factor=%v", snapFactor)
+	}
+
+	// Create request
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get capacity
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var capacity api.ClusterCapacityResponse
+	err = utils.GetJsonFromResponse(r, &capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &capacity, nil
+}
@@ -99,9 +99,24 @@ func (c *Client) DeviceInfo(id string) (*api.DeviceInfoResponse, error) {
 }
 
 func (c *Client) DeviceDelete(id string) error {
+	return c.deviceDelete(id, false)
+}
+
+// DeviceDeleteForce deletes the device even if the disk behind it can
+// not be read to verify that it is the disk which was added.
+func (c *Client) DeviceDeleteForce(id string) error {
+	return c.deviceDelete(id, true)
+}
+
+func (c *Client) deviceDelete(id string, force bool) error {
+
+	url := c.host + "/devices/" + id
+	if force {
+		url += "?force=true"
+	}
 
 	// Create a request
-	req, err := http.NewRequest("DELETE", c.host+"/devices/"+id, nil)
+	req, err := http.NewRequest("DELETE", url, nil)
 	if err != nil {
 		return err
 	}
@@ -247,3 +262,90 @@ func (c *Client) DeviceSetTags(id string, request *api.TagsChangeRequest) error
 	}
 	return nil
 }
+
+func (c *Client) NodeDiscoverDevices(id string) (*api.DeviceDiscoverResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/nodes/"+id+"/discover-devices", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var devices api.DeviceDiscoverResponse
+	err = utils.GetJsonFromResponse(r, &devices)
+	if err != nil {
+		return nil, err
+	}
+
+	return &devices, nil
+}
+
+func (c *Client) NodeAddDiscoveredDevices(id string,
+	request *api.DeviceDiscoverAddRequest) (*api.NodeInfoResponse, error) {
+
+	// Marshal request to JSON
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a request
+	req, err := http.NewRequest("POST",
+		c.host+"/nodes/"+id+"/discover-devices",
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusAccepted {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Wait for response
+	r, err = c.waitForResponseWithTimer(r, time.Millisecond*250)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var node api.NodeInfoResponse
+	err = utils.GetJsonFromResponse(r, &node)
+	if err != nil {
+		return nil, err
+	}
+
+	return &node, nil
+}
@@ -0,0 +1,93 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), as published by the Free Software Foundation,
+// or under the Apache License, Version 2.0 <LICENSE-APACHE2 or
+// http://www.apache.org/licenses/LICENSE-2.0>.
+//
+// You may not use this file except in compliance with those terms.
+//
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+var (
+	// ErrEventsTruncated is returned by Watch when some of the events
+	// to watch are no longer kept by the server. The state of the
+	// server should be fetched again, for example from the topology,
+	// before watching again from the last sequence number.
+	ErrEventsTruncated = errors.New("events are no longer available")
+
+	// Time the server waits for new events in each Watch request
+	WatchWait = 30 * time.Second
+)
+
+// Events returns the events recorded after the event with sequence
+// number since. If there are no such events the server waits up to
+// wait for new events before responding.
+func (c *Client) Events(since uint64, wait time.Duration) (*api.EventListResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", fmt.Sprintf("%v/events?since=%v&wait=%v",
+		c.host, since, int(wait.Seconds())), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get events
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var events api.EventListResponse
+	err = utils.GetJsonFromResponse(r, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	return &events, nil
+}
+
+// Watch calls fn for each event recorded after the event with sequence
+// number since, in order, until fn returns false or an error occurs.
+// ErrEventsTruncated is returned if some events were missed.
+func (c *Client) Watch(since uint64, fn func(api.Event) bool) error {
+	for {
+		events, err := c.Events(since, WatchWait)
+		if err != nil {
+			return err
+		}
+		if events.Truncated {
+			return ErrEventsTruncated
+		}
+		for _, e := range events.Events {
+			if !fn(e) {
+				return nil
+			}
+			since = e.Seq
+		}
+	}
+}
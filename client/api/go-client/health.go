@@ -0,0 +1,168 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), as published by the Free Software Foundation,
+// or under the Apache License, Version 2.0 <LICENSE-APACHE2 or
+// http://www.apache.org/licenses/LICENSE-2.0>.
+//
+// You may not use this file except in compliance with those terms.
+//
+
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+func (c *Client) NodeHealth(id string) (*api.NodeHealthResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/nodes/"+id+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var health api.NodeHealthResponse
+	err = utils.GetJsonFromResponse(r, &health)
+	if err != nil {
+		return nil, err
+	}
+
+	return &health, nil
+}
+
+func (c *Client) DeviceHealth(id string) (*api.DeviceHealthResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/devices/"+id+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var health api.DeviceHealthResponse
+	err = utils.GetJsonFromResponse(r, &health)
+	if err != nil {
+		return nil, err
+	}
+
+	return &health, nil
+}
+
+func (c *Client) Health() (*api.HealthResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var health api.HealthResponse
+	err = utils.GetJsonFromResponse(r, &health)
+	if err != nil {
+		return nil, err
+	}
+
+	return &health, nil
+}
+
+// HealthRefresh makes the server check the health of all nodes
+// immediately and returns the updated results.
+func (c *Client) HealthRefresh() (*api.HealthResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("POST", c.host+"/health/refresh", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusAccepted {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Wait for response
+	r, err = c.waitForResponseWithTimer(r, time.Millisecond*250)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var health api.HealthResponse
+	err = utils.GetJsonFromResponse(r, &health)
+	if err != nil {
+		return nil, err
+	}
+
+	return &health, nil
+}
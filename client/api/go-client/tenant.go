@@ -0,0 +1,51 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), as published by the Free Software Foundation,
+// or under the Apache License, Version 2.0 <LICENSE-APACHE2 or
+// http://www.apache.org/licenses/LICENSE-2.0>.
+//
+// You may not use this file except in compliance with those terms.
+//
+
+package client
+
+import (
+	"net/http"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+// TenantInfo returns the limits of the tenant and the capacity
+// provisioned for it.
+func (c *Client) TenantInfo(id string) (*api.TenantInfoResponse, error) {
+	req, err := http.NewRequest("GET", c.host+"/tenants/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	var tenant api.TenantInfoResponse
+	err = utils.GetJsonFromResponse(r, &tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
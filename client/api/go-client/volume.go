@@ -16,6 +16,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/heketi/heketi/pkg/glusterfs/api"
@@ -75,6 +76,53 @@ func (c *Client) VolumeCreate(request *api.VolumeCreateRequest) (
 	return &volume, nil
 
 }
+
+// VolumePlan returns where the bricks of a volume created from
+// request would be placed, without creating the volume.
+func (c *Client) VolumePlan(request *api.VolumeCreateRequest) (
+	*api.VolumePlanResponse, error) {
+
+	// Marshal request to JSON
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a request
+	req, err := http.NewRequest("POST",
+		c.host+"/volumes/plan",
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var plan api.VolumePlanResponse
+	err = utils.GetJsonFromResponse(r, &plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
 func (c *Client) VolumeExpand(id string, request *api.VolumeExpandRequest) (
 	*api.VolumeInfoResponse, error) {
 
@@ -129,10 +177,248 @@ func (c *Client) VolumeExpand(id string, request *api.VolumeExpandRequest) (
 
 }
 
+func (c *Client) VolumeConvert(id string, request *api.VolumeConvertRequest) (
+	*api.VolumeInfoResponse, error) {
+
+	// Marshal request to JSON
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a request
+	req, err := http.NewRequest("POST",
+		c.host+"/volumes/"+id+"/convert",
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusAccepted {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Wait for response
+	r, err = c.waitForResponseWithTimer(r, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var volume api.VolumeInfoResponse
+	err = utils.GetJsonFromResponse(r, &volume)
+	if err != nil {
+		return nil, err
+	}
+
+	return &volume, nil
+}
+
+// VolumeMigrate copies the volume to another cluster. The returned
+// migration is ready to be cut over with VolumeMigrateCutover.
+// Migrating a volume that is already being migrated to the cluster
+// resumes the migration.
+func (c *Client) VolumeMigrate(id string, request *api.VolumeMigrateRequest) (
+	*api.VolumeMigrationInfo, error) {
+
+	// Marshal request to JSON
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a request
+	req, err := http.NewRequest("POST",
+		c.host+"/volumes/"+id+"/migrate",
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusAccepted {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Wait for response
+	r, err = c.waitForResponseWithTimer(r, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var migration api.VolumeMigrationInfo
+	err = utils.GetJsonFromResponse(r, &migration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migration, nil
+}
+
+// VolumeMigration returns the state of the migration of the volume.
+func (c *Client) VolumeMigration(id string) (*api.VolumeMigrationInfo, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/volumes/"+id+"/migration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var migration api.VolumeMigrationInfo
+	err = utils.GetJsonFromResponse(r, &migration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migration, nil
+}
+
+// VolumeMigrateCutover completes the migration of the volume. The
+// volume is deleted from its cluster and replaced by its copy.
+func (c *Client) VolumeMigrateCutover(id string) (*api.VolumeInfoResponse, error) {
+
+	// Create a request
+	req, err := http.NewRequest("POST",
+		c.host+"/volumes/"+id+"/migrate/cutover",
+		bytes.NewBuffer([]byte("{}")))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusAccepted {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Wait for response
+	r, err = c.waitForResponseWithTimer(r, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var volume api.VolumeInfoResponse
+	err = utils.GetJsonFromResponse(r, &volume)
+	if err != nil {
+		return nil, err
+	}
+
+	return &volume, nil
+}
+
+// VolumeMigrateCancel cancels the migration of the volume and deletes
+// its copy.
+func (c *Client) VolumeMigrateCancel(id string) error {
+
+	// Create a request
+	req, err := http.NewRequest("DELETE", c.host+"/volumes/"+id+"/migration", nil)
+	if err != nil {
+		return err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusAccepted {
+		return utils.GetErrorFromResponse(r)
+	}
+
+	// Wait for response
+	r, err = c.waitForResponseWithTimer(r, time.Second)
+	if err != nil {
+		return err
+	}
+	if r.StatusCode != http.StatusNoContent {
+		return utils.GetErrorFromResponse(r)
+	}
+
+	return nil
+}
+
 func (c *Client) VolumeList() (*api.VolumeListResponse, error) {
+	return c.VolumeListQuery(nil)
+}
+
+// VolumeListQuery returns the volumes matching the query, with their
+// info if the query is expanded. A query with a limit returns a page
+// of the list, the next page is requested with the returned token.
+func (c *Client) VolumeListQuery(q *api.ListQuery) (*api.VolumeListResponse, error) {
 
 	// Create request
-	req, err := http.NewRequest("GET", c.host+"/volumes", nil)
+	req, err := http.NewRequest("GET", c.listUrl("/volumes", q), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -281,3 +567,207 @@ func (c *Client) VolumeClone(id string, request *api.VolumeCloneRequest) (*api.V
 
 	return &volume, nil
 }
+
+func (c *Client) VolumeSetTags(id string, request *api.TagsChangeRequest) error {
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST",
+		c.host+"/volumes/"+id+"/tags",
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return utils.GetErrorFromResponse(r)
+	}
+	return nil
+}
+
+func (c *Client) VolumeStatus(id string) (*api.VolumeStatusResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/volumes/"+id+"/status", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get status
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var status api.VolumeStatusResponse
+	err = utils.GetJsonFromResponse(r, &status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func (c *Client) VolumeQuota(id string) (*api.VolumeQuotaResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/volumes/"+id+"/quota", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get quota
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var quota api.VolumeQuotaResponse
+	err = utils.GetJsonFromResponse(r, &quota)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// volumeQuotaPost sends a request changing the quotas of a volume and
+// returns the quotas once changed.
+func (c *Client) volumeQuotaPost(id string, path string, request interface{}) (
+	*api.VolumeQuotaResponse, error) {
+
+	// Marshal request to JSON
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a request
+	req, err := http.NewRequest("POST",
+		c.host+"/volumes/"+id+path,
+		bytes.NewBuffer(buffer))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusAccepted {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Wait for response
+	r, err = c.waitForResponseWithTimer(r, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var quota api.VolumeQuotaResponse
+	err = utils.GetJsonFromResponse(r, &quota)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+func (c *Client) VolumeQuotaEnable(id string, request *api.VolumeQuotaRequest) (
+	*api.VolumeQuotaResponse, error) {
+
+	return c.volumeQuotaPost(id, "/quota", request)
+}
+
+func (c *Client) VolumeQuotaSetLimit(id string, request *api.VolumeQuotaLimitRequest) (
+	*api.VolumeQuotaResponse, error) {
+
+	return c.volumeQuotaPost(id, "/quota/limits", request)
+}
+
+func (c *Client) VolumeQuotaRemoveLimit(id string, path string) error {
+
+	// Create a request
+	req, err := http.NewRequest("DELETE",
+		c.host+"/volumes/"+id+"/quota/limits?path="+url.QueryEscape(path), nil)
+	if err != nil {
+		return err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	r, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusAccepted {
+		return utils.GetErrorFromResponse(r)
+	}
+
+	// Wait for response
+	r, err = c.waitForResponseWithTimer(r, time.Second)
+	if err != nil {
+		return err
+	}
+	if r.StatusCode != http.StatusNoContent {
+		return utils.GetErrorFromResponse(r)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), as published by the Free Software Foundation,
+// or under the Apache License, Version 2.0 <LICENSE-APACHE2 or
+// http://www.apache.org/licenses/LICENSE-2.0>.
+//
+// You may not use this file except in compliance with those terms.
+//
+
+package client
+
+import (
+	"net/http"
+
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/heketi/heketi/pkg/utils"
+)
+
+// Webhooks returns the delivery counts of the configured webhooks
+// and the notifications which could not be delivered.
+func (c *Client) Webhooks() (*api.WebhookListResponse, error) {
+
+	// Create request
+	req, err := http.NewRequest("GET", c.host+"/webhooks", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set token
+	err = c.setToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get info
+	r, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, utils.GetErrorFromResponse(r)
+	}
+
+	// Read JSON response
+	var webhooks api.WebhookListResponse
+	err = utils.GetJsonFromResponse(r, &webhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhooks, nil
+}
@@ -27,6 +27,8 @@ var (
 	bv_auth     bool
 	bv_clusters string
 	bv_ha       int
+	bv_selector string
+	bv_tags     string
 )
 
 func init() {
@@ -50,10 +52,21 @@ func init() {
 			"\n\ton any of the configured clusters which have the available space."+
 			"\n\tProviding a set of clusters will ensure Heketi allocates storage"+
 			"\n\tfor this volume only in the clusters specified.")
+	blockVolumeCreateCommand.Flags().StringVar(&bv_selector, "selector", "",
+		selectorUsage)
+	blockVolumeCreateCommand.Flags().StringVar(&bv_tags, "tags", "", tagsUsage)
 	blockVolumeCreateCommand.SilenceUsage = true
 	blockVolumeDeleteCommand.SilenceUsage = true
 	blockVolumeInfoCommand.SilenceUsage = true
 	blockVolumeListCommand.SilenceUsage = true
+	blockVolumeCommand.AddCommand(blockVolumeSetTagsCommand)
+	blockVolumeCommand.AddCommand(blockVolumeRmTagsCommand)
+	blockVolumeSetTagsCommand.Flags().BoolP("exact", "e", false,
+		"Set the object to this exact set of tags. Overwrites existing tags.")
+	blockVolumeRmTagsCommand.Flags().Bool("all", false,
+		"Remove all tags.")
+	blockVolumeSetTagsCommand.SilenceUsage = true
+	blockVolumeRmTagsCommand.SilenceUsage = true
 }
 
 var blockVolumeCommand = &cobra.Command{
@@ -93,6 +106,17 @@ var blockVolumeCreateCommand = &cobra.Command{
 			req.Clusters = strings.Split(bv_clusters, ",")
 		}
 
+		selector, err := parsePlacementSelector(bv_selector)
+		if err != nil {
+			return err
+		}
+		req.Selector = selector
+
+		req.Tags, err = parseTags(bv_tags)
+		if err != nil {
+			return err
+		}
+
 		if bv_volname != "" {
 			req.Name = bv_volname
 		}
@@ -229,3 +253,28 @@ var blockVolumeListCommand = &cobra.Command{
 		return nil
 	},
 }
+
+var blockVolumeSetTagsCommand = &cobra.Command{
+	Use:     "settags [blockvolume_id] tag1:value1 tag2:value2...",
+	Short:   "Sets tags on a block volume",
+	Long:    "Sets user-controlled metadata tags on a block volume",
+	Example: "  $ heketi-cli blockvolume settags 886a86a868711bef83001 owner:team-a",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		return setTagsCommand(cmd, heketi.BlockVolumeSetTags)
+	},
+}
+
+var blockVolumeRmTagsCommand = &cobra.Command{
+	Use:     "rmtags [blockvolume_id] tag1:value1 tag2:value2...",
+	Aliases: []string{"deltags", "removetags"},
+	Short:   "Removes tags from a block volume",
+	Long:    "Removes user-controlled metadata tags on a block volume",
+	Example: "  $ heketi-cli blockvolume rmtags 886a86a868711bef83001 owner",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		return rmTagsCommand(cmd, heketi.BlockVolumeSetTags)
+	},
+}
@@ -26,6 +26,10 @@ var (
 	cl_file      bool
 	cl_block_str string
 	cl_file_str  string
+	cl_tags      string
+	cl_snap      float64
+	cl_ratio     float64
+	cl_physical  float64
 )
 
 func init() {
@@ -46,6 +50,7 @@ func init() {
 			"\n\tregular file volumes on the cluster to be created."+
 			"\n\tThis is enabled by default. Use '--file=false' to"+
 			"\n\tdisable creation of file volumes on this cluster.")
+	clusterCreateCommand.Flags().StringVar(&cl_tags, "tags", "", tagsUsage)
 
 	clusterSetFlagsCommand.Flags().StringVar(&cl_block_str, "block", "",
 		"\n\tOptional: Allow the user to control the possibility of creating"+
@@ -63,6 +68,33 @@ func init() {
 	clusterInfoCommand.SilenceUsage = true
 	clusterListCommand.SilenceUsage = true
 	clusterSetFlagsCommand.SilenceUsage = true
+	clusterCommand.AddCommand(clusterSetTagsCommand)
+	clusterCommand.AddCommand(clusterRmTagsCommand)
+	clusterSetTagsCommand.Flags().BoolP("exact", "e", false,
+		"Set the object to this exact set of tags. Overwrites existing tags.")
+	clusterRmTagsCommand.Flags().Bool("all", false,
+		"Remove all tags.")
+	clusterSetTagsCommand.SilenceUsage = true
+	clusterRmTagsCommand.SilenceUsage = true
+	clusterCommand.AddCommand(clusterStatusCommand)
+	clusterStatusCommand.SilenceUsage = true
+	clusterCommand.AddCommand(clusterCapacityCommand)
+	clusterCapacityCommand.Flags().Float64Var(&cl_snap, "snapshot-factor", 1.0,
+		"\n\tOptional: Amount of storage to allocate for snapshot support."+
+			"\n\tMust be greater than 1.0.  For example if a 10TiB volume"+
+			"\n\trequires 5TiB of snapshot storage, then snapshot-factor"+
+			"\n\twould be set to 1.5.  If the value is set to 1, then"+
+			"\n\tthe sizes are for volumes without snapshots.")
+	clusterCapacityCommand.SilenceUsage = true
+	clusterCommand.AddCommand(clusterOvercommitCommand)
+	clusterOvercommitCommand.Flags().Float64Var(&cl_ratio, "ratio", 0,
+		"\n\tRatio by which the bricks may exceed the size of the devices."+
+			"\n\tThe thin pools of new bricks are created smaller than the"+
+			"\n\tbricks by this ratio.  Use 0 or 1 to disable overcommit.")
+	clusterOvercommitCommand.Flags().Float64Var(&cl_physical, "physical-limit", 0,
+		"\n\tOptional: Percent of a device used by data at or above which"+
+			"\n\tno new bricks are placed on it.  Default is 90.")
+	clusterOvercommitCommand.SilenceUsage = true
 }
 
 var clusterCommand = &cobra.Command{
@@ -88,6 +120,11 @@ var clusterCreateCommand = &cobra.Command{
 		req := &api.ClusterCreateRequest{}
 		req.File = cl_file
 		req.Block = cl_block
+		tags, err := parseTags(cl_tags)
+		if err != nil {
+			return err
+		}
+		req.Tags = tags
 
 		// Create a client to talk to Heketi
 		heketi := client.NewClient(options.Url, options.User, options.Key)
@@ -199,6 +236,127 @@ var clusterDeleteCommand = &cobra.Command{
 	},
 }
 
+var clusterStatusCommand = &cobra.Command{
+	Use:     "status [cluster_id]",
+	Short:   "Lists degraded volumes in the cluster",
+	Long:    "Lists degraded volumes in the cluster",
+	Example: "  $ heketi-cli cluster status 886a86a868711bef83001",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Cluster id missing")
+		}
+
+		//set clusterId
+		clusterId := cmd.Flags().Arg(0)
+
+		// Create a client to talk to Heketi
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		status, err := heketi.ClusterStatus(clusterId)
+		if err != nil {
+			return err
+		}
+
+		// Check if JSON should be printed
+		if options.Json {
+			data, err := json.Marshal(status)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			fmt.Fprintf(stdout, "Cluster id: %v\n", status.Id)
+			fmt.Fprintf(stdout, "Volumes: %v\n", status.VolumeCount)
+			fmt.Fprintf(stdout, "Degraded volumes: %v\n", len(status.DegradedVolumes))
+			for _, v := range status.DegradedVolumes {
+				fmt.Fprintf(stdout, "Id:%v    Name:%v", v.Id, v.Name)
+				if v.Error != "" {
+					fmt.Fprintf(stdout, "    Error:%v", v.Error)
+				}
+				fmt.Fprintf(stdout, "\n")
+			}
+		}
+
+		return nil
+	},
+}
+
+var clusterOvercommitCommand = &cobra.Command{
+	Use:   "overcommit [cluster_id]",
+	Short: "Set the thin provisioning overcommit of a cluster",
+	Long:  "Set the thin provisioning overcommit of a cluster",
+	Example: `  * Allow the bricks to use three times the size of the devices:
+      $ heketi-cli cluster overcommit --ratio=3 886a86a868711bef83001
+
+  * Disable overcommit:
+      $ heketi-cli cluster overcommit --ratio=0 886a86a868711bef83001
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Cluster id missing")
+		}
+
+		clusterId := cmd.Flags().Arg(0)
+
+		req := &api.ClusterSetOvercommitRequest{}
+		req.OvercommitRatio = cl_ratio
+		req.PhysicalUsageLimit = cl_physical
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		return heketi.ClusterSetOvercommit(clusterId, req)
+	},
+}
+
+var clusterCapacityCommand = &cobra.Command{
+	Use:     "capacity [cluster_id]",
+	Short:   "Reports the free space and largest volumes of the cluster",
+	Long:    "Reports the free space and largest volumes of the cluster",
+	Example: "  $ heketi-cli cluster capacity 886a86a868711bef83001",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Cluster id missing")
+		}
+
+		//set clusterId
+		clusterId := cmd.Flags().Arg(0)
+
+		// Create a client to talk to Heketi
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		capacity, err := heketi.ClusterCapacity(clusterId, float32(cl_snap))
+		if err != nil {
+			return err
+		}
+
+		// Check if JSON should be printed
+		if options.Json {
+			data, err := json.Marshal(capacity)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			fmt.Fprintf(stdout, "Cluster id: %v\n"+
+				"Size (GiB): %v\n"+
+				"Used (GiB): %v\n"+
+				"Free (GiB): %v\n",
+				capacity.Id,
+				capacity.Storage.Total/(1024*1024),
+				capacity.Storage.Used/(1024*1024),
+				capacity.Storage.Free/(1024*1024))
+			fmt.Fprintf(stdout, "Largest volumes (GiB):\n")
+			for _, v := range capacity.Volumes {
+				fmt.Fprintf(stdout, "  %v: %v\n", v.Name, v.MaxSize)
+			}
+		}
+
+		return nil
+	},
+}
+
 var clusterInfoCommand = &cobra.Command{
 	Use:     "info [cluster_id]",
 	Short:   "Retrieves information about cluster",
@@ -234,6 +392,20 @@ var clusterInfoCommand = &cobra.Command{
 			fmt.Fprintf(stdout, "\nVolumes:\n%v", strings.Join(info.Volumes, "\n"))
 			fmt.Fprintf(stdout, "\nBlock: %v\n", info.Block)
 			fmt.Fprintf(stdout, "\nFile: %v\n", info.File)
+			if info.OvercommitRatio > 1 {
+				limit := info.PhysicalUsageLimit
+				if limit == 0 {
+					limit = api.ClusterDefaultPhysicalUsageLimit
+				}
+				fmt.Fprintf(stdout, "Overcommit ratio: %v\n", info.OvercommitRatio)
+				fmt.Fprintf(stdout, "Physical usage limit: %v%%\n", limit)
+			}
+			if len(info.Tags) != 0 {
+				fmt.Fprintf(stdout, "Tags:\n")
+				for k, v := range info.Tags {
+					fmt.Fprintf(stdout, "  %v: %v\n", k, v)
+				}
+			}
 		}
 
 		return nil
@@ -287,3 +459,28 @@ var clusterListCommand = &cobra.Command{
 		return nil
 	},
 }
+
+var clusterSetTagsCommand = &cobra.Command{
+	Use:     "settags [cluster_id] tag1:value1 tag2:value2...",
+	Short:   "Sets tags on a cluster",
+	Long:    "Sets user-controlled metadata tags on a cluster",
+	Example: "  $ heketi-cli cluster settags 886a86a868711bef83001 owner:team-a",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		return setTagsCommand(cmd, heketi.ClusterSetTags)
+	},
+}
+
+var clusterRmTagsCommand = &cobra.Command{
+	Use:     "rmtags [cluster_id] tag1:value1 tag2:value2...",
+	Aliases: []string{"deltags", "removetags"},
+	Short:   "Removes tags from a cluster",
+	Long:    "Removes user-controlled metadata tags on a cluster",
+	Example: "  $ heketi-cli cluster rmtags 886a86a868711bef83001 owner",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		return rmTagsCommand(cmd, heketi.ClusterSetTags)
+	},
+}
@@ -13,6 +13,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/heketi/heketi/client/api/go-client"
 	"github.com/heketi/heketi/pkg/glusterfs/api"
@@ -34,16 +35,25 @@ func init() {
 	deviceCommand.AddCommand(deviceResyncCommand)
 	deviceCommand.AddCommand(deviceSetTagsCommand)
 	deviceCommand.AddCommand(deviceRmTagsCommand)
+	deviceCommand.AddCommand(deviceDiscoverCommand)
 	deviceAddCommand.Flags().StringVar(&device, "name", "",
 		"Name of device to add")
 	deviceAddCommand.Flags().StringVar(&nodeId, "node", "",
 		"Id of the node which has this device")
 	deviceAddCommand.Flags().Bool("destroy-existing-data", false,
 		"[DANGEROUS] Destroy any existing data on the device.")
+	deviceDiscoverCommand.Flags().String("node", "",
+		"Id of the node to search for unused devices")
+	deviceDiscoverCommand.Flags().Bool("add-all", false,
+		"Add all the discovered devices to the node.")
+	deviceDiscoverCommand.Flags().Bool("destroy-existing-data", false,
+		"[DANGEROUS] Destroy any existing data on the added devices.")
 	deviceSetTagsCommand.Flags().BoolP("exact", "e", false,
 		"Set the object to this exact set of tags. Overwrites existing tags.")
 	deviceRmTagsCommand.Flags().Bool("all", false,
 		"Remove all tags.")
+	deviceDeleteCommand.Flags().Bool("force", false,
+		"[DANGEROUS] Delete the device even if its disk can not be verified.")
 	deviceAddCommand.SilenceUsage = true
 	deviceDeleteCommand.SilenceUsage = true
 	deviceRemoveCommand.SilenceUsage = true
@@ -51,6 +61,7 @@ func init() {
 	deviceResyncCommand.SilenceUsage = true
 	deviceSetTagsCommand.SilenceUsage = true
 	deviceRmTagsCommand.SilenceUsage = true
+	deviceDiscoverCommand.SilenceUsage = true
 }
 
 var deviceCommand = &cobra.Command{
@@ -120,7 +131,12 @@ var deviceDeleteCommand = &cobra.Command{
 		heketi := client.NewClient(options.Url, options.User, options.Key)
 
 		//set url
-		err := heketi.DeviceDelete(deviceId)
+		var err error
+		if force, _ := cmd.Flags().GetBool("force"); force {
+			err = heketi.DeviceDeleteForce(deviceId)
+		} else {
+			err = heketi.DeviceDelete(deviceId)
+		}
 		if err == nil {
 			fmt.Fprintf(stdout, "Device %v deleted\n", deviceId)
 		}
@@ -215,6 +231,9 @@ var deviceInfoCommand = &cobra.Command{
 					fmt.Fprintf(stdout, "  %v: %v\n", k, v)
 				}
 			}
+			if info.Health != nil {
+				printDeviceHealth(info.Health)
+			}
 
 			fmt.Fprintf(stdout, "Bricks:\n")
 			for _, d := range info.Bricks {
@@ -347,3 +366,96 @@ var deviceRmTagsCommand = &cobra.Command{
 		return rmTagsCommand(cmd, heketi.DeviceSetTags)
 	},
 }
+
+var deviceDiscoverCommand = &cobra.Command{
+	Use:   "discover",
+	Short: "Lists unused devices of a node",
+	Long: "Lists the block devices of a node which carry no partitions, " +
+		"filesystems or LVM metadata and optionally adds all of them",
+	Example: `  $ heketi-cli device discover \
+      --node=3e098cb4407d7109806bb196d9e8f095
+  $ heketi-cli device discover --add-all \
+      --node=3e098cb4407d7109806bb196d9e8f095`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		node, err := cmd.Flags().GetString("node")
+		if err != nil {
+			return err
+		}
+		if node == "" {
+			return errors.New("Missing node id")
+		}
+		addAll, err := cmd.Flags().GetBool("add-all")
+		if err != nil {
+			return err
+		}
+		destroyData, err := cmd.Flags().GetBool("destroy-existing-data")
+		if err != nil {
+			return err
+		}
+
+		// Create a client
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		if addAll {
+			req := &api.DeviceDiscoverAddRequest{}
+			req.DestroyData = destroyData
+			info, err := heketi.NodeAddDiscoveredDevices(node, req)
+			if err != nil {
+				return err
+			}
+			if options.Json {
+				data, err := json.Marshal(info)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(stdout, string(data))
+			} else {
+				fmt.Fprintf(stdout, "Devices added successfully\n")
+			}
+			return nil
+		}
+
+		devices, err := heketi.NodeDiscoverDevices(node)
+		if err != nil {
+			return err
+		}
+		if options.Json {
+			data, err := json.Marshal(devices)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			for _, d := range devices.Devices {
+				fmt.Fprintf(stdout, "Name:%-20v Size (GiB):%-8v Model:%-20v Serial:%v\n",
+					d.Name,
+					d.Size/(1024*1024),
+					d.Model,
+					d.Serial)
+			}
+		}
+		return nil
+	},
+}
+
+func printDeviceHealth(h *api.DeviceHealth) {
+	fmt.Fprintf(stdout, "Health: %v\n", h.State)
+	if h.LastUpdate != 0 {
+		fmt.Fprintf(stdout, "Last Health Check: %v\n",
+			time.Unix(h.LastUpdate, 0).Format(time.RFC3339))
+	}
+	if h.TotalExtents != 0 {
+		fmt.Fprintf(stdout, "Free Extents: %v of %v\n",
+			h.FreeExtents, h.TotalExtents)
+	}
+	if h.SmartPassed != nil {
+		fmt.Fprintf(stdout, "SMART Passed: %v\n", *h.SmartPassed)
+	}
+	for _, tp := range h.ThinPools {
+		fmt.Fprintf(stdout, "Thin Pool: %v Data: %.2f%% Metadata: %.2f%%\n",
+			tp.Name, tp.DataPercent, tp.MetadataPercent)
+	}
+	for _, w := range h.Warnings {
+		fmt.Fprintf(stdout, "Warning: %v\n", w)
+	}
+}
@@ -0,0 +1,301 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package cmds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/heketi/heketi/pkg/glusterfs/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	geoRepMaster string
+	geoRepSlave  string
+	geoRepStart  bool
+	geoRepForce  bool
+)
+
+func init() {
+	RootCmd.AddCommand(geoReplicationCommand)
+	geoReplicationCommand.AddCommand(geoReplicationCreateCommand)
+	geoReplicationCommand.AddCommand(geoReplicationListCommand)
+	geoReplicationCommand.AddCommand(geoReplicationInfoCommand)
+	geoReplicationCommand.AddCommand(geoReplicationStatusCommand)
+	geoReplicationCommand.AddCommand(geoReplicationDeleteCommand)
+
+	geoReplicationCreateCommand.Flags().StringVar(&geoRepMaster, "master", "",
+		"\n\tId of the volume whose files are replicated")
+	geoReplicationCreateCommand.Flags().StringVar(&geoRepSlave, "slave", "",
+		"\n\tId of the volume the files are replicated to")
+	geoReplicationCreateCommand.Flags().BoolVar(&geoRepStart, "start", false,
+		"\n\tOptional: Start the session once it is created")
+	geoReplicationCreateCommand.SilenceUsage = true
+	geoReplicationListCommand.SilenceUsage = true
+	geoReplicationInfoCommand.SilenceUsage = true
+	geoReplicationStatusCommand.SilenceUsage = true
+	geoReplicationDeleteCommand.SilenceUsage = true
+
+	for _, action := range []api.GeoReplicationAction{
+		api.GeoReplicationStart,
+		api.GeoReplicationStop,
+		api.GeoReplicationPause,
+		api.GeoReplicationResume,
+	} {
+		cmd := newGeoReplicationActionCommand(action)
+		cmd.Flags().BoolVar(&geoRepForce, "force", false,
+			"\n\tOptional: Force the action on gluster, regardless of the"+
+				"\n\tstate recorded by Heketi")
+		cmd.SilenceUsage = true
+		geoReplicationCommand.AddCommand(cmd)
+	}
+}
+
+var geoReplicationCommand = &cobra.Command{
+	Use:   "georeplication",
+	Short: "Heketi Geo-Replication Management",
+	Long:  "Heketi Geo-Replication Management",
+}
+
+func printGeoReplication(s *api.GeoReplicationSessionInfo) {
+	fmt.Fprintf(stdout, "Id: %v\n"+
+		"Master Volume: %v (%v)\n"+
+		"Master Cluster: %v\n"+
+		"Slave Volume: %v (%v)\n"+
+		"Slave Cluster: %v\n"+
+		"Slave Host: %v\n"+
+		"State: %v\n",
+		s.Id,
+		s.MasterVolume, s.MasterVolumeId,
+		s.MasterCluster,
+		s.SlaveVolume, s.SlaveVolumeId,
+		s.SlaveCluster,
+		s.SlaveHost,
+		s.State)
+}
+
+var geoReplicationCreateCommand = &cobra.Command{
+	Use:   "create",
+	Short: "Create a geo-replication session",
+	Long: "Create a session replicating the files of a volume to another" +
+		" volume, which may be in another cluster",
+	Example: `  * Replicate a volume to another volume and start the session
+      $ heketi-cli georeplication create --master=886a86a868711bef83001 \
+        --slave=5d4a1d8b2e1b34fd2a5c --start
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if geoRepMaster == "" {
+			return errors.New("Missing master volume id")
+		}
+		if geoRepSlave == "" {
+			return errors.New("Missing slave volume id")
+		}
+
+		req := &api.GeoReplicationCreateRequest{
+			MasterVolumeId: geoRepMaster,
+			SlaveVolumeId:  geoRepSlave,
+			Start:          geoRepStart,
+		}
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		session, err := heketi.GeoReplicationCreate(req)
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(session)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			printGeoReplication(session)
+		}
+		return nil
+	},
+}
+
+var geoReplicationListCommand = &cobra.Command{
+	Use:     "list",
+	Short:   "Lists the geo-replication sessions managed by Heketi",
+	Long:    "Lists the geo-replication sessions managed by Heketi",
+	Example: "  $ heketi-cli georeplication list",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		list, err := heketi.GeoReplicationList()
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(list)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			for _, id := range list.Sessions {
+				session, err := heketi.GeoReplicationInfo(id)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(stdout, "Id:%-35v Master:%-35v Slave:%-35v State:%v\n",
+					id,
+					session.MasterVolumeId,
+					session.SlaveVolumeId,
+					session.State)
+			}
+		}
+		return nil
+	},
+}
+
+var geoReplicationInfoCommand = &cobra.Command{
+	Use:     "info",
+	Short:   "Retreives information about the geo-replication session",
+	Long:    "Retreives information about the geo-replication session",
+	Example: "  $ heketi-cli georeplication info 2f8e9b0a6f1c4f5e8d1c",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Session id missing")
+		}
+		id := cmd.Flags().Arg(0)
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		session, err := heketi.GeoReplicationInfo(id)
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(session)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			printGeoReplication(session)
+		}
+		return nil
+	},
+}
+
+var geoReplicationStatusCommand = &cobra.Command{
+	Use:     "status",
+	Short:   "Shows the state of the workers of the geo-replication session",
+	Long:    "Shows the state of the workers of the geo-replication session as reported by gluster",
+	Example: "  $ heketi-cli georeplication status 2f8e9b0a6f1c4f5e8d1c",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Session id missing")
+		}
+		id := cmd.Flags().Arg(0)
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		status, err := heketi.GeoReplicationStatus(id)
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(status)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			printGeoReplication(&status.GeoReplicationSessionInfo)
+			fmt.Fprintf(stdout, "Workers:\n")
+			for _, w := range status.Workers {
+				fmt.Fprintf(stdout, "\tBrick: %v:%v\n"+
+					"\tSlave Node: %v\n"+
+					"\tStatus: %v\n"+
+					"\tCrawl Status: %v\n"+
+					"\tLast Synced: %v\n\n",
+					w.MasterNode, w.MasterBrick,
+					w.SlaveNode,
+					w.Status,
+					w.CrawlStatus,
+					w.LastSynced)
+			}
+		}
+		return nil
+	},
+}
+
+// newGeoReplicationActionCommand returns the command applying the
+// action to a session.
+func newGeoReplicationActionCommand(action api.GeoReplicationAction) *cobra.Command {
+	return &cobra.Command{
+		Use:   string(action),
+		Short: fmt.Sprintf("Applies %v to the geo-replication session", action),
+		Long:  fmt.Sprintf("Applies %v to the geo-replication session", action),
+		Example: fmt.Sprintf("  $ heketi-cli georeplication %v 2f8e9b0a6f1c4f5e8d1c",
+			action),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s := cmd.Flags().Args()
+			if len(s) < 1 {
+				return errors.New("Session id missing")
+			}
+			id := cmd.Flags().Arg(0)
+
+			heketi := client.NewClient(options.Url, options.User, options.Key)
+			session, err := heketi.GeoReplicationAction(id,
+				&api.GeoReplicationActionRequest{
+					Action: action,
+					Force:  geoRepForce,
+				})
+			if err != nil {
+				return err
+			}
+
+			if options.Json {
+				data, err := json.Marshal(session)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(stdout, string(data))
+			} else {
+				printGeoReplication(session)
+			}
+			return nil
+		},
+	}
+}
+
+var geoReplicationDeleteCommand = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes the geo-replication session",
+	Long: "Stops and deletes the geo-replication session. The files already" +
+		" replicated are kept on the slave volume",
+	Example: "  $ heketi-cli georeplication delete 2f8e9b0a6f1c4f5e8d1c",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Session id missing")
+		}
+		id := cmd.Flags().Arg(0)
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		err := heketi.GeoReplicationDelete(id)
+		if err == nil {
+			fmt.Fprintf(stdout, "Geo-replication session %v deleted\n", id)
+		}
+		return err
+	},
+}
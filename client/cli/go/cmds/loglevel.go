@@ -10,7 +10,10 @@
 package cmds
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -18,12 +21,38 @@ import (
 	"github.com/heketi/heketi/pkg/glusterfs/api"
 )
 
+var (
+	logFilterVolumes string
+	logFilterNodes   string
+)
+
 func init() {
 	RootCmd.AddCommand(logLevelCommand)
 	logLevelCommand.AddCommand(logLevelGetCommand)
 	logLevelGetCommand.SilenceUsage = true
 	logLevelCommand.AddCommand(logLevelSetCommand)
 	logLevelSetCommand.SilenceUsage = true
+	logLevelCommand.AddCommand(logLevelListCommand)
+	logLevelListCommand.SilenceUsage = true
+	logLevelCommand.AddCommand(logLevelFilterCommand)
+	logLevelFilterCommand.SilenceUsage = true
+	logLevelFilterCommand.Flags().StringVar(&logFilterVolumes, "volumes", "",
+		"\n\tOptional: Comma separated list of volume ids whose info and"+
+			"\n\tdebug messages are logged")
+	logLevelFilterCommand.Flags().StringVar(&logFilterNodes, "nodes", "",
+		"\n\tOptional: Comma separated list of node ids whose info and"+
+			"\n\tdebug messages are logged")
+}
+
+// splitIds returns the ids of the comma separated list.
+func splitIds(list string) []string {
+	ids := []string{}
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
 var logLevelCommand = &cobra.Command{
@@ -33,35 +62,105 @@ var logLevelCommand = &cobra.Command{
 }
 
 var logLevelGetCommand = &cobra.Command{
-	Use:     "get",
-	Short:   "Get Heketi server Log Level",
-	Long:    "Get Heketi server Log Level",
-	Example: `  $ heketi-cli loglevel get`,
+	Use:   "get [logger]",
+	Short: "Get Heketi server Log Level",
+	Long:  "Get Heketi server Log Level of the logger, glusterfs if not given",
+	Example: `  * Get the level of the server
+      $ heketi-cli loglevel get
+
+  * Get the level of the operations
+      $ heketi-cli loglevel get operations`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("too many arguments")
+		}
+		name := "glusterfs"
+		if len(args) == 1 {
+			name = args[0]
+		}
 		heketi := client.NewClient(options.Url, options.User, options.Key)
 		llinfo, err := heketi.LogLevelGet()
-		if err == nil {
-			fmt.Fprintf(stdout, "%s\n", llinfo.LogLevel["glusterfs"])
+		if err != nil {
+			return err
 		}
-		return err
+		level, ok := llinfo.LogLevel[name]
+		if !ok {
+			return fmt.Errorf("unknown logger: %v", name)
+		}
+		fmt.Fprintf(stdout, "%s\n", level)
+		return nil
+	},
+}
+
+var logLevelListCommand = &cobra.Command{
+	Use:     "list",
+	Short:   "List the Log Levels of the Heketi server loggers",
+	Long:    "List the Log Levels of the Heketi server loggers and the log filter",
+	Example: `  $ heketi-cli loglevel list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		llinfo, err := heketi.LogLevelGet()
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(llinfo)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+			return nil
+		}
+
+		names := []string{}
+		for name := range llinfo.LogLevel {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(stdout, "%v: %v\n", name, llinfo.LogLevel[name])
+		}
+		if llinfo.Filter != nil {
+			if len(llinfo.Filter.Volumes) > 0 {
+				fmt.Fprintf(stdout, "Filter volumes: %v\n",
+					strings.Join(llinfo.Filter.Volumes, ","))
+			}
+			if len(llinfo.Filter.Nodes) > 0 {
+				fmt.Fprintf(stdout, "Filter nodes: %v\n",
+					strings.Join(llinfo.Filter.Nodes, ","))
+			}
+		}
+		return nil
 	},
 }
 
 var logLevelSetCommand = &cobra.Command{
-	Use:     "set",
-	Short:   "Set Heketi server Log Level",
-	Long:    "Set Heketi server Log Level",
-	Example: `  $ heketi-cli loglevel set debug`,
+	Use:   "set <level> [logger...]",
+	Short: "Set Heketi server Log Level",
+	Long: "Set Heketi server Log Level of the loggers, glusterfs if none given." +
+		"\nThe parts of the server log at the level of glusterfs until" +
+		"\ntheir own level is set.",
+	Example: `  * Set the level of the server
+      $ heketi-cli loglevel set debug
+
+  * Set the level of the operations and of the commands they run
+      $ heketi-cli loglevel set debug operations cmdexec`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return fmt.Errorf("missing log-level argument")
 		}
-		if len(args) > 1 {
-			return fmt.Errorf("too many arguments")
+		names := args[1:]
+		if len(names) == 0 {
+			names = []string{"glusterfs"}
+		}
+		levels := map[string]string{}
+		for _, name := range names {
+			levels[name] = args[0]
 		}
 		heketi := client.NewClient(options.Url, options.User, options.Key)
 		err := heketi.LogLevelSet(&api.LogLevelInfo{
-			LogLevel: map[string]string{"glusterfs": args[0]},
+			LogLevel: levels,
 		})
 		if err == nil {
 			fmt.Fprintf(stdout, "Server log level updated\n")
@@ -69,3 +168,39 @@ var logLevelSetCommand = &cobra.Command{
 		return err
 	},
 }
+
+var logLevelFilterCommand = &cobra.Command{
+	Use:   "filter",
+	Short: "Filter Heketi server log messages",
+	Long: "Only log the info and debug messages of the Heketi server about" +
+		"\nthe volumes and nodes. Without volumes and nodes the filter is" +
+		"\nremoved.",
+	Example: `  * Only log info and debug messages about a node
+      $ heketi-cli loglevel filter --nodes=3e098cb4407d7109806bb196d9e8f095
+
+  * Log all messages again
+      $ heketi-cli loglevel filter`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return fmt.Errorf("too many arguments")
+		}
+		filter := &api.LogFilter{
+			Volumes: splitIds(logFilterVolumes),
+			Nodes:   splitIds(logFilterNodes),
+		}
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		err := heketi.LogLevelSet(&api.LogLevelInfo{
+			LogLevel: map[string]string{},
+			Filter:   filter,
+		})
+		if err != nil {
+			return err
+		}
+		if len(filter.Volumes) == 0 && len(filter.Nodes) == 0 {
+			fmt.Fprintf(stdout, "Server log filter removed\n")
+		} else {
+			fmt.Fprintf(stdout, "Server log filter updated\n")
+		}
+		return nil
+	},
+}
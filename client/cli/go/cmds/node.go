@@ -13,6 +13,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/heketi/heketi/client/api/go-client"
 	"github.com/heketi/heketi/pkg/glusterfs/api"
@@ -24,6 +25,8 @@ var (
 	managmentHostNames string
 	storageHostNames   string
 	clusterId          string
+	region             string
+	rack               string
 )
 
 func init() {
@@ -37,20 +40,26 @@ func init() {
 	nodeCommand.AddCommand(nodeRemoveCommand)
 	nodeCommand.AddCommand(nodeSetTagsCommand)
 	nodeCommand.AddCommand(nodeRmTagsCommand)
+	nodeCommand.AddCommand(nodeHealthCommand)
 	nodeAddCommand.Flags().IntVar(&zone, "zone", 0, "The zone in which the node should reside")
 	nodeAddCommand.Flags().StringVar(&clusterId, "cluster", "", "The cluster in which the node should reside")
 	nodeAddCommand.Flags().StringVar(&managmentHostNames, "management-host-name", "", "Management host name")
 	nodeAddCommand.Flags().StringVar(&storageHostNames, "storage-host-name", "", "Storage host name")
+	nodeAddCommand.Flags().StringVar(&region, "region", "", "The region failure domain of the node")
+	nodeAddCommand.Flags().StringVar(&rack, "rack", "", "The rack failure domain of the node within its region")
 	nodeSetTagsCommand.Flags().BoolP("exact", "e", false,
 		"Set the object to this exact set of tags. Overwrites existing tags.")
 	nodeRmTagsCommand.Flags().Bool("all", false,
 		"Remove all tags.")
+	nodeHealthCommand.Flags().Bool("refresh", false,
+		"Check the health of all nodes now instead of showing the last results.")
 	nodeAddCommand.SilenceUsage = true
 	nodeDeleteCommand.SilenceUsage = true
 	nodeInfoCommand.SilenceUsage = true
 	nodeListCommand.SilenceUsage = true
 	nodeRemoveCommand.SilenceUsage = true
 	nodeSetTagsCommand.SilenceUsage = true
+	nodeHealthCommand.SilenceUsage = true
 }
 
 var nodeCommand = &cobra.Command{
@@ -68,6 +77,14 @@ var nodeAddCommand = &cobra.Command{
       --cluster=3e098cb4407d7109806bb196d9e8f095 \
       --management-host-name=node1-manage.gluster.lab.com \
       --storage-host-name=node1-storage.gluster.lab.com
+
+  $ heketi-cli node add \
+      --zone=3 \
+      --region=us-east \
+      --rack=r12 \
+      --cluster=3e098cb4407d7109806bb196d9e8f095 \
+      --management-host-name=node1-manage.gluster.lab.com \
+      --storage-host-name=node1-storage.gluster.lab.com
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check arguments
@@ -90,6 +107,10 @@ var nodeAddCommand = &cobra.Command{
 		req.Hostnames.Manage = []string{managmentHostNames}
 		req.Hostnames.Storage = []string{storageHostNames}
 		req.Zone = zone
+		if region != "" || rack != "" {
+			req.Tags = map[string]string{}
+			api.FailureDomain{Region: region, Rack: rack}.SetTags(req.Tags)
+		}
 
 		// Create a client
 		heketi := client.NewClient(options.Url, options.User, options.Key)
@@ -232,16 +253,30 @@ var nodeListCommand = &cobra.Command{
 			return err
 		}
 
+		health, err := heketi.Health()
+		if err != nil {
+			return err
+		}
+		nodeHealth := map[string]api.NodeHealth{}
+		for _, h := range health.Nodes {
+			nodeHealth[h.NodeId] = h.NodeHealth
+		}
+
 		for _, clusterid := range clusters.Clusters {
 			clusterinfo, err := heketi.ClusterInfo(clusterid)
 			if err != nil {
 				return err
 			}
 			for _, nodeid := range clusterinfo.Nodes {
+				h, ok := nodeHealth[nodeid]
+				if !ok {
+					h.State = api.NodeHealthUnknown
+				}
 				fmt.Fprintf(stdout,
-					"Id:%v\tCluster:%v\n",
+					"Id:%v\tCluster:%v\tHealth:%v\n",
 					nodeid,
-					clusterid)
+					clusterid,
+					h.State)
 			}
 		}
 
@@ -296,6 +331,13 @@ var nodeInfoCommand = &cobra.Command{
 				info.Zone,
 				info.Hostnames.Manage[0],
 				info.Hostnames.Storage[0])
+			if info.FailureDomain.Region != "" {
+				fmt.Fprintf(stdout, "Region: %v\n", info.FailureDomain.Region)
+			}
+			if info.FailureDomain.Rack != "" {
+				fmt.Fprintf(stdout, "Rack: %v\n", info.FailureDomain.Rack)
+			}
+			fmt.Fprintf(stdout, "Health: %v\n", nodeHealthString(info.Health))
 			if len(info.Tags) != 0 {
 				fmt.Fprintf(stdout, "Tags:\n")
 				for k, v := range info.Tags {
@@ -380,3 +422,86 @@ var nodeRmTagsCommand = &cobra.Command{
 		return rmTagsCommand(cmd, heketi.NodeSetTags)
 	},
 }
+
+var nodeHealthCommand = &cobra.Command{
+	Use:   "health [node_id]",
+	Short: "Shows the health of the nodes as seen by the server",
+	Long: "Shows the result of the most recent glusterd check of the server's\n" +
+		"node health monitor for the given node, or for all nodes if no node\n" +
+		"id is given.",
+	Example: `  * Show the health of all nodes
+      $ heketi-cli node health
+
+  * Show the health of one node
+      $ heketi-cli node health 886a86a868711bef83001
+
+  * Check all nodes now and show the results
+      $ heketi-cli node health --refresh`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		refresh, err := cmd.Flags().GetBool("refresh")
+		if err != nil {
+			return err
+		}
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		var health *api.HealthResponse
+		if refresh {
+			health, err = heketi.HealthRefresh()
+		} else {
+			health, err = heketi.Health()
+		}
+		if err != nil {
+			return err
+		}
+
+		nodes := health.Nodes
+		if len(cmd.Flags().Args()) > 0 {
+			nodeId := cmd.Flags().Arg(0)
+			h, err := heketi.NodeHealth(nodeId)
+			if err != nil {
+				return err
+			}
+			nodes = []api.NodeHealthResponse{*h}
+		}
+
+		if options.Json {
+			var data []byte
+			if len(cmd.Flags().Args()) > 0 {
+				data, err = json.Marshal(nodes[0])
+			} else {
+				data, err = json.Marshal(health)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+			return nil
+		}
+
+		if !health.MonitorEnabled {
+			fmt.Fprintf(stdout, "Node health monitor is not enabled\n")
+		}
+		for _, h := range nodes {
+			fmt.Fprintf(stdout, "Id:%v\tCluster:%v\tHostname:%v\tHealth:%v\n",
+				h.NodeId,
+				h.ClusterId,
+				h.Hostname,
+				nodeHealthString(h.NodeHealth))
+		}
+		return nil
+	},
+}
+
+// nodeHealthString formats the node health with the time of the
+// most recent check, if any.
+func nodeHealthString(h api.NodeHealth) string {
+	if h.State == "" {
+		h.State = api.NodeHealthUnknown
+	}
+	if h.LastUpdate == 0 {
+		return string(h.State)
+	}
+	return fmt.Sprintf("%v (checked %v)", h.State,
+		time.Unix(h.LastUpdate, 0).Format(time.RFC3339))
+}
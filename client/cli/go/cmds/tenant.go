@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package cmds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	client "github.com/heketi/heketi/client/api/go-client"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(tenantCommand)
+	tenantCommand.AddCommand(tenantInfoCommand)
+	tenantInfoCommand.SilenceUsage = true
+}
+
+var tenantCommand = &cobra.Command{
+	Use:   "tenant",
+	Short: "Heketi Tenant Limits",
+	Long:  "Show the limits of the tenants and the capacity provisioned for them",
+}
+
+// limitString prints a tenant limit, 0 meaning no limit
+func limitString(limit int) string {
+	if limit == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%v", limit)
+}
+
+var tenantInfoCommand = &cobra.Command{
+	Use:     "info",
+	Short:   "Retreives the limits and usage of a tenant",
+	Long:    "Retreives the limits and usage of a tenant",
+	Example: "  $ heketi-cli tenant info team-a",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Tenant id missing")
+		}
+		id := cmd.Flags().Arg(0)
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		tenant, err := heketi.TenantInfo(id)
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(tenant)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+			return nil
+		}
+
+		fmt.Fprintf(stdout, "Tenant: %v\n"+
+			"Size (GiB): %v of %v\n"+
+			"Volumes: %v\n"+
+			"Block Volumes: %v\n"+
+			"Max Volumes: %v\n",
+			tenant.Id,
+			tenant.Usage.Size, limitString(tenant.Limits.MaxSize),
+			tenant.Usage.Volumes,
+			tenant.Usage.BlockVolumes,
+			limitString(tenant.Limits.MaxVolumes))
+		if len(tenant.Limits.Clusters) > 0 {
+			fmt.Fprintf(stdout, "Clusters: %v\n", tenant.Limits.Clusters)
+		}
+		return nil
+	},
+}
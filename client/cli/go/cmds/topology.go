@@ -39,8 +39,9 @@ type ConfigFileDevice struct {
 	ConfigFileDeviceOptions
 }
 type ConfigFileNode struct {
-	Devices []*ConfigFileDevice `json:"devices"`
-	Node    api.NodeAddRequest  `json:"node"`
+	Devices       []*ConfigFileDevice `json:"devices"`
+	Node          api.NodeAddRequest  `json:"node"`
+	FailureDomain *api.FailureDomain  `json:"failure_domain,omitempty"`
 }
 type ConfigFileCluster struct {
 	Nodes []ConfigFileNode `json:"nodes"`
@@ -229,6 +230,12 @@ var topologyLoadCommand = &cobra.Command{
 					// Create node
 					fmt.Fprintf(stdout, "\tCreating node %v ... ", node.Node.Hostnames.Manage[0])
 					node.Node.ClusterId = clusterInfo.Id
+					if node.FailureDomain != nil {
+						if node.Node.Tags == nil {
+							node.Node.Tags = map[string]string{}
+						}
+						node.FailureDomain.SetTags(node.Node.Tags)
+					}
 					nodeInfo, err = heketi.NodeAdd(&node.Node)
 					if err != nil {
 						fmt.Fprintf(stdout, "Unable to create node: %v\n", err)
@@ -368,6 +375,13 @@ var topologyInfoCommand = &cobra.Command{
 						info.Zone,
 						strings.Join(info.Hostnames.Manage, ", "),
 						strings.Join(info.Hostnames.Storage, ", "))
+					if info.FailureDomain.Region != "" {
+						fmt.Fprintf(stdout, "\tRegion: %v\n", info.FailureDomain.Region)
+					}
+					if info.FailureDomain.Rack != "" {
+						fmt.Fprintf(stdout, "\tRack: %v\n", info.FailureDomain.Rack)
+					}
+					fmt.Fprintf(stdout, "\tHealth: %v\n", nodeHealthString(info.Health))
 					fmt.Fprintf(stdout, "\tDevices:\n")
 
 					// format and print the device info
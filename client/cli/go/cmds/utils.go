@@ -99,3 +99,75 @@ func rmTagsCommand(cmd *cobra.Command,
 
 	return submitTags(id, req)
 }
+
+const selectorUsage = "\n\tOptional: Comma separated list of tag constraints on the devices" +
+	"\n\twhere bricks may be placed. Device tags are combined with the" +
+	"\n\ttags of their node. Each constraint is one of:" +
+	"\n\t  tag:value          the tag must have this value" +
+	"\n\t  tag:value1|value2  the tag must have one of the values" +
+	"\n\t  tag!:value1|value2 the tag must not have any of the values"
+
+// parsePlacementSelector converts the placement selector flag
+// to a placement selector request. An empty string results in no
+// selector.
+func parsePlacementSelector(s string) (*api.PlacementSelector, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	ps := &api.PlacementSelector{}
+	for _, term := range strings.Split(s, ",") {
+		parts := strings.SplitN(term, ":", 2)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf(
+				"expected colon (:) between tag name and values, got: %v",
+				term)
+		}
+		key, values := parts[0], strings.Split(parts[1], "|")
+		switch {
+		case strings.HasSuffix(key, "!"):
+			ps.MatchExpressions = append(ps.MatchExpressions,
+				api.PlacementSelectorExpression{
+					Key:      strings.TrimSuffix(key, "!"),
+					Operator: api.PlacementSelectorNotIn,
+					Values:   values,
+				})
+		case len(values) > 1:
+			ps.MatchExpressions = append(ps.MatchExpressions,
+				api.PlacementSelectorExpression{
+					Key:      key,
+					Operator: api.PlacementSelectorIn,
+					Values:   values,
+				})
+		default:
+			if ps.MatchTags == nil {
+				ps.MatchTags = map[string]string{}
+			}
+			ps.MatchTags[key] = values[0]
+		}
+	}
+	return ps, nil
+}
+
+const tagsUsage = "\n\tOptional: Comma separated list of tag:value pairs to set on" +
+	"\n\tthe new object."
+
+// parseTags converts a comma separated list of tag:value pairs
+// to a tags map. An empty string results in no tags.
+func parseTags(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	tags := map[string]string{}
+	for _, t := range strings.Split(s, ",") {
+		parts := strings.SplitN(t, ":", 2)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf(
+				"expected colon (:) between tag name and value, got: %v",
+				t)
+		}
+		tags[parts[0]] = parts[1]
+	}
+	return tags, nil
+}
@@ -39,8 +39,36 @@ var (
 	kubePv               bool
 	glusterVolumeOptions string
 	block                bool
+	selector             string
+	volumeTags           string
+	volumeDryRun         bool
+	allocation           string
+	minBrickSize         int
+	maxBrickSize         int
+	maxBrickSets         int
+	convertReplica       int
+	convertArbiter       bool
+	migrateCluster       string
+	migrateCutover       bool
+	migrateCancel        bool
+	rootQuota            bool
+	quotaEnable          bool
+	quotaDisable         bool
+	quotaPath            string
+	quotaLimit           int
+	quotaSoftLimit       int
+	quotaRemove          bool
+	listName             string
+	listNamePrefix       string
+	listCluster          string
+	listDurability       string
+	listBlock            bool
+	listTags             string
 )
 
+// number of volumes requested at once by volume list
+const volumeListPageSize = 100
+
 func init() {
 	RootCmd.AddCommand(volumeCommand)
 	volumeCommand.AddCommand(volumeCreateCommand)
@@ -95,16 +123,101 @@ func init() {
 	volumeCreateCommand.Flags().BoolVar(&block, "block", false,
 		"\n\tOptional: Create a block-hosting volume. Intended to host"+
 			"\n\tloopback files to be exported as block devices.")
+	volumeCreateCommand.Flags().StringVar(&selector, "selector", "",
+		selectorUsage)
+	volumeCreateCommand.Flags().StringVar(&volumeTags, "tags", "", tagsUsage)
+	volumeCreateCommand.Flags().StringVar(&allocation, "allocation", "",
+		"\n\tOptional: Order in which devices are tried for the bricks."+
+			"\n\tValues are: simple, least-used, pack, weighted."+
+			"\n\tIf omitted, the server default is used.")
+	volumeCreateCommand.Flags().IntVar(&minBrickSize, "min-brick-size", 0,
+		"\n\tOptional: Smallest size of a brick in GiB. Also applies when"+
+			"\n\tthe volume is expanded.")
+	volumeCreateCommand.Flags().IntVar(&maxBrickSize, "max-brick-size", 0,
+		"\n\tOptional: Largest size of a brick in GiB. Also applies when"+
+			"\n\tthe volume is expanded.")
+	volumeCreateCommand.Flags().IntVar(&maxBrickSets, "max-brick-sets", 0,
+		"\n\tOptional: Largest number of brick sets (distribute count)"+
+			"\n\tof the volume. Use 1 for a volume with a single brick set.")
+	volumeCreateCommand.Flags().BoolVar(&volumeDryRun, "dry-run", false,
+		"\n\tOptional: Only show where the bricks of the volume would be"+
+			"\n\tplaced, or why the volume can not be placed, without"+
+			"\n\tcreating it.")
+	volumeCreateCommand.Flags().BoolVar(&rootQuota, "root-quota", false,
+		"\n\tOptional: Limit the usage of the volume slightly below its"+
+			"\n\tsize with a quota on its root directory. The limit is"+
+			"\n\traised when the volume is expanded.")
+	volumeListCommand.Flags().StringVar(&listName, "name", "",
+		"\n\tOptional: Only list the volume with this name")
+	volumeListCommand.Flags().StringVar(&listNamePrefix, "name-prefix", "",
+		"\n\tOptional: Only list the volumes whose name starts with this prefix")
+	volumeListCommand.Flags().StringVar(&listCluster, "cluster", "",
+		"\n\tOptional: Only list the volumes of this cluster")
+	volumeListCommand.Flags().StringVar(&listDurability, "durability", "",
+		"\n\tOptional: Only list the volumes of this durability type:"+
+			"\n\tnone, replicate or disperse")
+	volumeListCommand.Flags().BoolVar(&listBlock, "block", false,
+		"\n\tOptional: Only list block-hosting volumes if true, or the"+
+			"\n\tother volumes if false")
+	volumeListCommand.Flags().StringVar(&listTags, "tags", "",
+		"\n\tOptional: Comma separated list of tag names, or name:value"+
+			"\n\tpairs, the volumes listed must have")
 	volumeCreateCommand.SilenceUsage = true
 	volumeDeleteCommand.SilenceUsage = true
 	volumeExpandCommand.SilenceUsage = true
 	volumeInfoCommand.SilenceUsage = true
 	volumeListCommand.SilenceUsage = true
+	volumeCommand.AddCommand(volumeSetTagsCommand)
+	volumeCommand.AddCommand(volumeRmTagsCommand)
+	volumeSetTagsCommand.Flags().BoolP("exact", "e", false,
+		"Set the object to this exact set of tags. Overwrites existing tags.")
+	volumeRmTagsCommand.Flags().Bool("all", false,
+		"Remove all tags.")
+	volumeSetTagsCommand.SilenceUsage = true
+	volumeRmTagsCommand.SilenceUsage = true
 
 	volumeCommand.AddCommand(volumeCloneCommand)
 	volumeCloneCommand.Flags().StringVar(&volname, "name", "",
 		"\n\tOptional: Name of the newly cloned volume.")
 	volumeCloneCommand.SilenceUsage = true
+
+	volumeCommand.AddCommand(volumeConvertCommand)
+	volumeConvertCommand.Flags().IntVar(&convertReplica, "replica", 3,
+		"\n\tReplica count the volume is converted to. Only 3 is supported.")
+	volumeConvertCommand.Flags().BoolVar(&convertArbiter, "arbiter", false,
+		"\n\tOptional: Make the third brick of each brick set an arbiter brick.")
+	volumeConvertCommand.SilenceUsage = true
+
+	volumeCommand.AddCommand(volumeMigrateCommand)
+	volumeMigrateCommand.Flags().StringVar(&migrateCluster, "cluster", "",
+		"\n\tId of the cluster to copy the volume to. Starts the migration,"+
+			"\n\tor resumes it if it was interrupted.")
+	volumeMigrateCommand.Flags().BoolVar(&migrateCutover, "cutover", false,
+		"\n\tComplete the migration: copy the last changes, delete the"+
+			"\n\tvolume from its cluster and use the copy in its place.")
+	volumeMigrateCommand.Flags().BoolVar(&migrateCancel, "cancel", false,
+		"\n\tCancel the migration and delete the copy of the volume.")
+	volumeMigrateCommand.SilenceUsage = true
+
+	volumeCommand.AddCommand(volumeStatusCommand)
+	volumeStatusCommand.SilenceUsage = true
+
+	volumeCommand.AddCommand(volumeQuotaCommand)
+	volumeQuotaCommand.Flags().BoolVar(&quotaEnable, "enable", false,
+		"\n\tEnable the directory quotas of the volume.")
+	volumeQuotaCommand.Flags().BoolVar(&quotaDisable, "disable", false,
+		"\n\tDisable the directory quotas of the volume and remove its limits.")
+	volumeQuotaCommand.Flags().StringVar(&quotaPath, "path", "",
+		"\n\tDirectory, from the root of the volume, whose limit is set"+
+			"\n\twith --limit or removed with --remove.")
+	volumeQuotaCommand.Flags().IntVar(&quotaLimit, "limit", 0,
+		"\n\tLimit of the directory in GiB. Quotas are enabled if needed.")
+	volumeQuotaCommand.Flags().IntVar(&quotaSoftLimit, "soft-limit", 0,
+		"\n\tOptional: Usage, in percent of the limit, above which"+
+			"\n\tgluster logs warnings.")
+	volumeQuotaCommand.Flags().BoolVar(&quotaRemove, "remove", false,
+		"\n\tRemove the limit of the directory.")
+	volumeQuotaCommand.SilenceUsage = true
 }
 
 var volumeCommand = &cobra.Command{
@@ -139,6 +252,12 @@ var volumeCreateCommand = &cobra.Command{
 
   * Create a 100GiB distributed volume which supports performance related volume options.
       $ heketi-cli volume create --size=100 --durability=none --gluster-volume-options="performance.rda-cache-limit 10MB","performance.nl-cache-positive-entry no"
+
+  * Create a 100GiB replica 3 volume on SSD devices of nodes not owned by tenant-b:
+      $ heketi-cli volume create --size=100 --selector='disktype:ssd,tenant!:tenant-b'
+
+  * Show where the bricks of a 100GiB replica 3 volume would be placed:
+      $ heketi-cli volume create --size=100 --dry-run
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check volume size
@@ -166,11 +285,33 @@ var volumeCreateCommand = &cobra.Command{
 			req.Clusters = strings.Split(clusters, ",")
 		}
 
+		// Check placement selector
+		s, err := parsePlacementSelector(selector)
+		if err != nil {
+			return err
+		}
+		req.Selector = s
+
+		req.Tags, err = parseTags(volumeTags)
+		if err != nil {
+			return err
+		}
+
 		// Check volume options
 		if glusterVolumeOptions != "" {
 			req.GlusterVolumeOptions = strings.Split(glusterVolumeOptions, ",")
 		}
 
+		req.Allocation = api.AllocationStrategy(allocation)
+
+		if minBrickSize != 0 || maxBrickSize != 0 || maxBrickSets != 0 {
+			req.BrickPolicy = &api.BrickPolicy{
+				MinBrickSize: minBrickSize,
+				MaxBrickSize: maxBrickSize,
+				MaxBrickSets: maxBrickSets,
+			}
+		}
+
 		// Set group id if specified
 		if gid != 0 {
 			req.Gid = gid
@@ -185,9 +326,19 @@ var volumeCreateCommand = &cobra.Command{
 			req.Snapshot.Enable = true
 		}
 
+		req.RootQuota = rootQuota
+
 		// Create a client
 		heketi := client.NewClient(options.Url, options.User, options.Key)
 
+		if volumeDryRun {
+			plan, err := heketi.VolumePlan(req)
+			if err != nil {
+				return err
+			}
+			return printVolumePlan(plan)
+		}
+
 		// Add volume
 		volume, err := heketi.VolumeCreate(req)
 		if err != nil {
@@ -235,6 +386,41 @@ var volumeCreateCommand = &cobra.Command{
 	},
 }
 
+func printVolumePlan(plan *api.VolumePlanResponse) error {
+	if options.Json {
+		data, err := json.Marshal(plan)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, string(data))
+		return nil
+	}
+
+	if plan.Feasible {
+		fmt.Fprintf(stdout, "Volume can be placed on cluster %v\n", plan.Cluster)
+		for i, bs := range plan.BrickSets {
+			fmt.Fprintf(stdout, "Brick set %v:\n", i)
+			for _, b := range bs.Bricks {
+				fmt.Fprintf(stdout, "  Node:%v    Device:%v    Size (GiB):%v\n",
+					b.Node, b.Device, b.Size/(1024*1024))
+			}
+		}
+	} else {
+		fmt.Fprintf(stdout, "Volume can not be placed\n")
+	}
+	for _, c := range plan.Rejected {
+		fmt.Fprintf(stdout, "Cluster %v: %v\n", c.Id, c.Error)
+		if c.BrickSize != 0 {
+			fmt.Fprintf(stdout, "  Brick size (GiB): %v\n", c.BrickSize/(1024*1024))
+		}
+		for _, d := range c.Devices {
+			fmt.Fprintf(stdout, "  Node:%v    Device:%v    %v\n",
+				d.Node, d.Device, d.Reason)
+		}
+	}
+	return nil
+}
+
 var volumeDeleteCommand = &cobra.Command{
 	Use:     "delete",
 	Short:   "Deletes the volume",
@@ -345,40 +531,144 @@ var volumeInfoCommand = &cobra.Command{
 	},
 }
 
-var volumeListCommand = &cobra.Command{
-	Use:     "list",
-	Short:   "Lists the volumes managed by Heketi",
-	Long:    "Lists the volumes managed by Heketi",
-	Example: "  $ heketi-cli volume list",
+var volumeStatusCommand = &cobra.Command{
+	Use:     "status",
+	Short:   "Retrieves brick and heal status of the volume",
+	Long:    "Retrieves brick and heal status of the volume",
+	Example: "  $ heketi-cli volume status 886a86a868711bef83001",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Create a client
+		//ensure proper number of args
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Volume id missing")
+		}
+
+		// Set volume id
+		volumeId := cmd.Flags().Arg(0)
+
+		// Create a client to talk to Heketi
 		heketi := client.NewClient(options.Url, options.User, options.Key)
 
-		// List volumes
-		list, err := heketi.VolumeList()
+		status, err := heketi.VolumeStatus(volumeId)
 		if err != nil {
 			return err
 		}
 
 		if options.Json {
-			data, err := json.Marshal(list)
+			data, err := json.Marshal(status)
 			if err != nil {
 				return err
 			}
 			fmt.Fprintf(stdout, string(data))
 		} else {
-			for _, id := range list.Volumes {
-				volume, err := heketi.VolumeInfo(id)
-				if err != nil {
-					return err
+			fmt.Fprintf(stdout, "Name: %v\n"+
+				"Id: %v\n"+
+				"Cluster Id: %v\n"+
+				"Degraded: %v\n",
+				status.Name,
+				status.Id,
+				status.Cluster,
+				status.Degraded)
+			if status.Error != "" {
+				fmt.Fprintf(stdout, "Error: %v\n", status.Error)
+			}
+			fmt.Fprintf(stdout, "Bricks:\n")
+			for _, b := range status.Bricks {
+				heals := "unknown"
+				if b.PendingHeals != nil {
+					heals = fmt.Sprintf("%v", *b.PendingHeals)
+				}
+				fmt.Fprintf(stdout, "\tId: %v\n"+
+					"\tBrick: %v:%v\n"+
+					"\tOnline: %v\n"+
+					"\tPid: %v\n"+
+					"\tPort: %v\n"+
+					"\tPending Heals: %v\n\n",
+					b.Id,
+					b.Host,
+					b.Path,
+					b.Online,
+					b.Pid,
+					b.Port,
+					heals)
+			}
+		}
+		return nil
+	},
+}
+
+var volumeListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the volumes managed by Heketi",
+	Long:  "Lists the volumes managed by Heketi",
+	Example: `  * List all the volumes
+      $ heketi-cli volume list
+
+  * Find a volume by name
+      $ heketi-cli volume list --name=vol_app1
+
+  * List the replica volumes of a team
+      $ heketi-cli volume list --durability=replicate --tags=owner:team-a`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Create a client
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		q := &api.ListQuery{
+			Name:       listName,
+			NamePrefix: listNamePrefix,
+			Cluster:    listCluster,
+			Durability: api.DurabilityType(listDurability),
+			Tags:       splitIds(listTags),
+			Expand:     true,
+			Limit:      volumeListPageSize,
+		}
+		if cmd.Flags().Changed("block") {
+			q.Block = &listBlock
+		}
+
+		// List volumes with their info, one page at a time
+		list := &api.VolumeListResponse{
+			Volumes: []string{},
+			Info:    []api.VolumeInfoResponse{},
+		}
+		for {
+			page, err := heketi.VolumeListQuery(q)
+			if err != nil {
+				return err
+			}
+			// servers which do not expand lists only return the ids
+			if len(page.Info) != len(page.Volumes) {
+				page.Info = []api.VolumeInfoResponse{}
+				for _, id := range page.Volumes {
+					volume, err := heketi.VolumeInfo(id)
+					if err != nil {
+						return err
+					}
+					page.Info = append(page.Info, *volume)
 				}
+			}
+			list.Volumes = append(list.Volumes, page.Volumes...)
+			list.Info = append(list.Info, page.Info...)
+			if page.Next == "" {
+				break
+			}
+			q.Continue = page.Next
+		}
 
+		if options.Json {
+			data, err := json.Marshal(list)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			for _, volume := range list.Info {
 				blockstr := ""
 				if volume.Block {
 					blockstr = " [block]"
 				}
 				fmt.Fprintf(stdout, "Id:%-35v Cluster:%-35v Name:%v%v\n",
-					id,
+					volume.Id,
 					volume.Cluster,
 					volume.Name,
 					blockstr)
@@ -431,3 +721,258 @@ var volumeCloneCommand = &cobra.Command{
 		return nil
 	},
 }
+
+var volumeConvertCommand = &cobra.Command{
+	Use:   "convert",
+	Short: "Changes the durability of a replica volume",
+	Long:  "Changes the durability of a replica volume",
+	Example: `  * Convert a replica 2 volume to replica 3
+    $ heketi-cli volume convert 886a86a868711bef83001
+
+  * Convert a replica 2 or replica 3 volume to an arbiter volume
+    $ heketi-cli volume convert 886a86a868711bef83001 --arbiter
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		//ensure proper number of args
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Volume id missing")
+		}
+
+		// Set volume id
+		volumeId := cmd.Flags().Arg(0)
+
+		// Create request
+		req := &api.VolumeConvertRequest{
+			Replica: convertReplica,
+			Arbiter: convertArbiter,
+		}
+
+		// Create client
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		// Convert the volume
+		volume, err := heketi.VolumeConvert(volumeId, req)
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(volume)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			fmt.Fprintf(stdout, "%v", volume)
+		}
+		return nil
+	},
+}
+
+var volumeMigrateCommand = &cobra.Command{
+	Use:   "migrate",
+	Short: "Moves a volume to another cluster",
+	Long: "Moves a volume to another cluster. The volume is copied to the" +
+		" cluster and keeps being used until the migration is cut over.",
+	Example: `  * Copy a volume to another cluster
+    $ heketi-cli volume migrate 886a86a868711bef83001 --cluster=3a8b3a6c5c8b1b6a0e5d
+
+  * Show the state of the migration
+    $ heketi-cli volume migrate 886a86a868711bef83001
+
+  * Replace the volume by its copy once it is ready
+    $ heketi-cli volume migrate 886a86a868711bef83001 --cutover
+
+  * Cancel the migration
+    $ heketi-cli volume migrate 886a86a868711bef83001 --cancel
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		//ensure proper number of args
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Volume id missing")
+		}
+
+		// Set volume id
+		volumeId := cmd.Flags().Arg(0)
+
+		actions := 0
+		for _, set := range []bool{migrateCluster != "", migrateCutover, migrateCancel} {
+			if set {
+				actions++
+			}
+		}
+		if actions > 1 {
+			return errors.New("Only one of --cluster, --cutover and --cancel may be given")
+		}
+
+		// Create client
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		var result interface{}
+		var err error
+		switch {
+		case migrateCancel:
+			err = heketi.VolumeMigrateCancel(volumeId)
+			if err == nil {
+				fmt.Fprintf(stdout, "Migration of volume %v cancelled\n", volumeId)
+			}
+			return err
+		case migrateCutover:
+			result, err = heketi.VolumeMigrateCutover(volumeId)
+		case migrateCluster != "":
+			result, err = heketi.VolumeMigrate(volumeId,
+				&api.VolumeMigrateRequest{ClusterId: migrateCluster})
+		default:
+			result, err = heketi.VolumeMigration(volumeId)
+		}
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(result)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else if m, ok := result.(*api.VolumeMigrationInfo); ok {
+			fmt.Fprintf(stdout, "Volume: %v\n"+
+				"Cluster: %v\n"+
+				"State: %v\n",
+				m.Id, m.ClusterId, m.State)
+		} else {
+			fmt.Fprintf(stdout, "%v", result)
+		}
+		return nil
+	},
+}
+
+var volumeSetTagsCommand = &cobra.Command{
+	Use:     "settags [volume_id] tag1:value1 tag2:value2...",
+	Short:   "Sets tags on a volume",
+	Long:    "Sets user-controlled metadata tags on a volume",
+	Example: "  $ heketi-cli volume settags 886a86a868711bef83001 owner:team-a",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		return setTagsCommand(cmd, heketi.VolumeSetTags)
+	},
+}
+
+var volumeRmTagsCommand = &cobra.Command{
+	Use:     "rmtags [volume_id] tag1:value1 tag2:value2...",
+	Aliases: []string{"deltags", "removetags"},
+	Short:   "Removes tags from a volume",
+	Long:    "Removes user-controlled metadata tags on a volume",
+	Example: "  $ heketi-cli volume rmtags 886a86a868711bef83001 owner",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+		return rmTagsCommand(cmd, heketi.VolumeSetTags)
+	},
+}
+
+var volumeQuotaCommand = &cobra.Command{
+	Use:   "quota",
+	Short: "Manages the directory quotas of a volume",
+	Long:  "Shows and changes the directory quotas of a volume",
+	Example: `  * Show the limits of a volume and their usage
+    $ heketi-cli volume quota 886a86a868711bef83001
+
+  * Limit a directory of the volume to 10GiB
+    $ heketi-cli volume quota 886a86a868711bef83001 --path=/team-a --limit=10
+
+  * Remove the limit of a directory
+    $ heketi-cli volume quota 886a86a868711bef83001 --path=/team-a --remove
+
+  * Disable the quotas of the volume
+    $ heketi-cli volume quota 886a86a868711bef83001 --disable
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		//ensure proper number of args
+		s := cmd.Flags().Args()
+		if len(s) < 1 {
+			return errors.New("Volume id missing")
+		}
+
+		// Set volume id
+		volumeId := cmd.Flags().Arg(0)
+
+		actions := 0
+		for _, set := range []bool{quotaEnable, quotaDisable, quotaLimit != 0, quotaRemove} {
+			if set {
+				actions++
+			}
+		}
+		if actions > 1 {
+			return errors.New("Only one of --enable, --disable, --limit and --remove may be given")
+		}
+		if (quotaLimit != 0 || quotaRemove) && quotaPath == "" {
+			return errors.New("Missing directory path")
+		}
+
+		// Create client
+		heketi := client.NewClient(options.Url, options.User, options.Key)
+
+		var quota *api.VolumeQuotaResponse
+		var err error
+		switch {
+		case quotaRemove:
+			err = heketi.VolumeQuotaRemoveLimit(volumeId, quotaPath)
+			if err == nil {
+				fmt.Fprintf(stdout, "Quota limit of %v removed\n", quotaPath)
+			}
+			return err
+		case quotaLimit != 0:
+			quota, err = heketi.VolumeQuotaSetLimit(volumeId,
+				&api.VolumeQuotaLimitRequest{
+					Path:             quotaPath,
+					HardLimit:        uint64(quotaLimit) * 1024 * 1024 * 1024,
+					SoftLimitPercent: quotaSoftLimit,
+				})
+		case quotaEnable || quotaDisable:
+			quota, err = heketi.VolumeQuotaEnable(volumeId,
+				&api.VolumeQuotaRequest{Enable: quotaEnable})
+		default:
+			quota, err = heketi.VolumeQuota(volumeId)
+		}
+		if err != nil {
+			return err
+		}
+
+		if options.Json {
+			data, err := json.Marshal(quota)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, string(data))
+		} else {
+			fmt.Fprintf(stdout, "Volume: %v\n"+
+				"Enabled: %v\n",
+				quota.Id,
+				quota.Enabled)
+			if len(quota.Limits) > 0 {
+				fmt.Fprintf(stdout, "Limits:\n")
+			}
+			for _, l := range quota.Limits {
+				fmt.Fprintf(stdout, "\tPath: %v\n"+
+					"\tHard Limit: %v\n"+
+					"\tSoft Limit: %v%%\n"+
+					"\tUsed: %v\n"+
+					"\tAvailable: %v\n"+
+					"\tSoft Limit Exceeded: %v\n"+
+					"\tHard Limit Exceeded: %v\n\n",
+					l.Path,
+					l.HardLimit,
+					l.SoftLimitPercent,
+					l.Used,
+					l.Available,
+					l.SoftLimitExceeded,
+					l.HardLimitExceeded)
+			}
+		}
+		return nil
+	},
+}
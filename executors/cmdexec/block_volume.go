@@ -63,7 +63,7 @@ func (s *CmdExecutor) BlockVolumeCreate(host string,
 
 	if blockVolumeCreate.Result == "FAIL" {
 		s.BlockVolumeDestroy(host, volume.GlusterVolumeName, volume.Name)
-		logger.LogError("%v", blockVolumeCreate.ErrMsg)
+		s.Logger().LogError("%v", blockVolumeCreate.ErrMsg)
 		return nil, fmt.Errorf("%v", blockVolumeCreate.ErrMsg)
 	}
 
@@ -99,19 +99,19 @@ func (s *CmdExecutor) BlockVolumeDestroy(host string, blockHostingVolumeName str
 	}
 	output, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
 	if err != nil {
-		logger.LogError("Unable to delete volume %v: %v", blockVolumeName, err)
+		s.Logger().LogError("Unable to delete volume %v: %v", blockVolumeName, err)
 		return err
 	}
 
 	var blockVolumeDelete CliOutput
 	err = json.Unmarshal([]byte(output[0]), &blockVolumeDelete)
 	if err != nil {
-		err := logger.LogError("Unable to get the block volume delete info for block volume %v", blockVolumeName)
+		err := s.Logger().LogError("Unable to get the block volume delete info for block volume %v", blockVolumeName)
 		return err
 	}
 
 	if blockVolumeDelete.Result == "FAIL" {
-		err := logger.LogError("%v", blockVolumeDelete.ErrMsg)
+		err := s.Logger().LogError("%v", blockVolumeDelete.ErrMsg)
 		return err
 	}
 
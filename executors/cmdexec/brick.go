@@ -19,6 +19,16 @@ import (
 	"github.com/lpabon/godbc"
 )
 
+const (
+	// LVM metadata profile of the thin pools of overcommitted devices,
+	// the pools are extended by a fifth once they are 80% full
+	thinPoolProfileDir  = "/etc/lvm/profile"
+	thinPoolProfileName = "heketi-thin-autoextend"
+	thinPoolProfile     = "activation { " +
+		"thin_pool_autoextend_threshold = 80 " +
+		"thin_pool_autoextend_percent = 20 }"
+)
+
 func (s *CmdExecutor) BrickCreate(host string,
 	brick *executors.BrickRequest) (*executors.BrickInfo, error) {
 
@@ -26,7 +36,7 @@ func (s *CmdExecutor) BrickCreate(host string,
 	godbc.Require(host != "")
 	godbc.Require(brick.Name != "")
 	godbc.Require(brick.Size > 0)
-	godbc.Require(brick.TpSize >= brick.Size)
+	godbc.Require(brick.TpSize >= brick.Size || brick.Autoextend)
 	godbc.Require(brick.VgId != "")
 	godbc.Require(brick.Path != "")
 	godbc.Require(s.Fstab != "")
@@ -36,6 +46,14 @@ func (s *CmdExecutor) BrickCreate(host string,
 	brickPath := brick.Path
 	mountPath := utils.BrickMountFromPath(brickPath)
 
+	// Thin pools smaller than their brick get a metadata profile so
+	// that dmeventd extends them before they fill up
+	profile := ""
+	if brick.Autoextend {
+		profile = fmt.Sprintf("--metadataprofile %v --monitor y ",
+			thinPoolProfileName)
+	}
+
 	// Create command set to execute on the node
 	devnode := utils.BrickDevNode(brick.VgId, brick.Name)
 	commands := []string{
@@ -44,10 +62,13 @@ func (s *CmdExecutor) BrickCreate(host string,
 		fmt.Sprintf("mkdir -p %v", mountPath),
 
 		// Setup the LV
-		fmt.Sprintf("lvcreate --autobackup=%v --poolmetadatasize %vK --chunksize 256K --size %vK --thin %v/%v --virtualsize %vK --name %v",
+		fmt.Sprintf("lvcreate --autobackup=%v %v--poolmetadatasize %vK --chunksize 256K --size %vK --thin %v/%v --virtualsize %vK --name %v",
 			// backup LVM metadata
 			utils.BoolToYN(s.BackupLVM),
 
+			// Autoextend profile
+			profile,
+
 			// MetadataSize
 			brick.PoolMetadataSize,
 
@@ -82,6 +103,15 @@ func (s *CmdExecutor) BrickCreate(host string,
 		fmt.Sprintf("mkdir %v", brickPath),
 	}
 
+	if brick.Autoextend {
+		commands = append([]string{
+			fmt.Sprintf("mkdir -p %v", thinPoolProfileDir),
+			fmt.Sprintf("printf '%%s\\n' %v > %v/%v.profile",
+				shellQuote(thinPoolProfile), thinPoolProfileDir,
+				thinPoolProfileName),
+		}, commands...)
+	}
+
 	// Only set the GID if the value is other than root(gid 0).
 	// When no gid is set, root is the only one that can write to the volume
 	if 0 != brick.Gid {
@@ -135,7 +165,7 @@ func (s *CmdExecutor) BrickDestroy(host string,
 	}
 	output, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 	if err != nil {
-		logger.Err(err)
+		s.Logger().Err(err)
 	}
 	tp := output[0]
 
@@ -145,7 +175,7 @@ func (s *CmdExecutor) BrickDestroy(host string,
 	}
 	_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 	if err != nil {
-		logger.Err(err)
+		s.Logger().Err(err)
 	}
 
 	// Remove the LV (by device name)
@@ -154,7 +184,7 @@ func (s *CmdExecutor) BrickDestroy(host string,
 	}
 	_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 	if err != nil {
-		logger.Err(err)
+		s.Logger().Err(err)
 	} else {
 		// no space freed when tp sticks around
 		spaceReclaimed = false
@@ -166,7 +196,7 @@ func (s *CmdExecutor) BrickDestroy(host string,
 	}
 	output, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 	if err != nil {
-		logger.Err(err)
+		s.Logger().Err(err)
 		return spaceReclaimed, fmt.Errorf("Unable to determine number of logical volumes in "+
 			"thin pool %v on host %v", tp, host)
 	}
@@ -182,7 +212,7 @@ func (s *CmdExecutor) BrickDestroy(host string,
 		}
 		_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 		if err != nil {
-			logger.Err(err)
+			s.Logger().Err(err)
 		} else {
 			spaceReclaimed = true
 		}
@@ -198,7 +228,7 @@ func (s *CmdExecutor) BrickDestroy(host string,
 		}
 		_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 		if err != nil {
-			logger.Err(err)
+			s.Logger().Err(err)
 		}
 	}
 
@@ -208,7 +238,7 @@ func (s *CmdExecutor) BrickDestroy(host string,
 	}
 	_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 	if err != nil {
-		logger.Err(err)
+		s.Logger().Err(err)
 	}
 
 	return spaceReclaimed, nil
@@ -177,6 +177,51 @@ func TestSshExecBrickCreateWithGid(t *testing.T) {
 
 }
 
+func TestSshExecBrickCreateAutoextend(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+	s.portStr = "100"
+
+	// The thin pool of a brick on an overcommitted device is smaller
+	// than the brick
+	b := &executors.BrickRequest{
+		VgId:             "xvgid",
+		Name:             "id",
+		TpSize:           10,
+		Size:             30,
+		PoolMetadataSize: 5,
+		Path:             utils.BrickPath("xvgid", "id"),
+		Autoextend:       true,
+	}
+
+	var executed []string
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		executed = append(executed, commands...)
+		return nil, nil
+	}
+
+	_, err = s.BrickCreate("myhost", b)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(executed) == 8, executed)
+	tests.Assert(t, executed[0] == "mkdir -p /etc/lvm/profile", executed[0])
+	tests.Assert(t, executed[1] == "printf '%s\\n' 'activation { "+
+		"thin_pool_autoextend_threshold = 80 "+
+		"thin_pool_autoextend_percent = 20 }' > "+
+		"/etc/lvm/profile/heketi-thin-autoextend.profile", executed[1])
+	tests.Assert(t, executed[3] == "lvcreate --autobackup="+
+		utils.BoolToYN(s.BackupLVM)+" "+
+		"--metadataprofile heketi-thin-autoextend --monitor y "+
+		"--poolmetadatasize 5K --chunksize 256K --size 10K "+
+		"--thin vg_xvgid/tp_id --virtualsize 30K --name brick_id",
+		executed[3])
+}
+
 func TestSshExecBrickCreateSudo(t *testing.T) {
 	f := NewCommandFaker()
 	s, err := NewFakeExecutor(f)
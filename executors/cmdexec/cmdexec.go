@@ -11,14 +11,35 @@ package cmdexec
 
 import (
 	"sync"
+	"time"
 
 	"github.com/heketi/heketi/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
 	logger = utils.NewLogger("[cmdexec]", utils.LEVEL_DEBUG)
+
+	commandDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "heketi_executor_command_duration_seconds",
+			Help: "Time taken to run commands on a host",
+		},
+		[]string{"host"})
 )
 
+func init() {
+	prometheus.MustRegister(commandDuration)
+}
+
+// ObserveCommandDuration records the time taken since start to run
+// commands on the host. The remote command transports defer it once
+// the connection to the host is no longer throttled.
+func ObserveCommandDuration(host string, start time.Time) {
+	commandDuration.WithLabelValues(host).Observe(
+		time.Since(start).Seconds())
+}
+
 type RemoteCommandTransport interface {
 	RemoteCommandExecute(host string, commands []string, timeoutMinutes int) ([]string, error)
 	RebalanceOnExpansion() bool
@@ -32,6 +53,36 @@ type CmdExecutor struct {
 	RemoteExecutor RemoteCommandTransport
 	Fstab          string
 	BackupLVM      bool
+
+	// Set for the executors running commands on behalf of a request
+	requestId string
+	// Executor whose connections are throttled
+	throttler *CmdExecutor
+}
+
+// InitRequest sets up c to run commands like s on behalf of the
+// request id through the remote executor. The connections to the
+// hosts are throttled together with those of s.
+func (s *CmdExecutor) InitRequest(c *CmdExecutor,
+	remote RemoteCommandTransport, id string) {
+
+	c.RemoteExecutor = remote
+	c.Fstab = s.Fstab
+	c.BackupLVM = s.BackupLVM
+	c.requestId = id
+	c.throttler = s.throttle()
+}
+
+// RequestId returns the id of the request the commands are run for.
+func (s *CmdExecutor) RequestId() string {
+	return s.requestId
+}
+
+func (s *CmdExecutor) throttle() *CmdExecutor {
+	if s.throttler != nil {
+		return s.throttler
+	}
+	return s
 }
 
 func (s *CmdExecutor) AccessConnection(host string) {
@@ -40,20 +91,22 @@ func (s *CmdExecutor) AccessConnection(host string) {
 		ok bool
 	)
 
-	s.Lock.Lock()
-	if c, ok = s.Throttlemap[host]; !ok {
+	t := s.throttle()
+	t.Lock.Lock()
+	if c, ok = t.Throttlemap[host]; !ok {
 		c = make(chan bool, 1)
-		s.Throttlemap[host] = c
+		t.Throttlemap[host] = c
 	}
-	s.Lock.Unlock()
+	t.Lock.Unlock()
 
 	c <- true
 }
 
 func (s *CmdExecutor) FreeConnection(host string) {
-	s.Lock.Lock()
-	c := s.Throttlemap[host]
-	s.Lock.Unlock()
+	t := s.throttle()
+	t.Lock.Lock()
+	c := t.Throttlemap[host]
+	t.Lock.Unlock()
 
 	<-c
 }
@@ -75,6 +128,16 @@ func (s *CmdExecutor) SetLogLevel(level string) {
 	}
 }
 
+// Loggers returns the logger of the commands run by the executor.
+func (s *CmdExecutor) Loggers() map[string]*utils.Logger {
+	return map[string]*utils.Logger{"cmdexec": logger}
+}
+
+// Logger returns the logger of the executor, which adds the request
+// id to the messages of executors running commands for a request.
 func (s *CmdExecutor) Logger() *utils.Logger {
+	if s.requestId != "" {
+		return logger.With("request_id", s.requestId)
+	}
 	return logger
 }
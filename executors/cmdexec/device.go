@@ -12,6 +12,7 @@ package cmdexec
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -27,6 +28,8 @@ const (
 	VGDISPLAY_FREE_NUMBER_EXTENTS      = 15
 )
 
+var lsblkPairRegexp = regexp.MustCompile(`([A-Z:-]+)="([^"]*)"`)
+
 // Read:
 // https://access.redhat.com/documentation/en-US/Red_Hat_Storage/3.1/html/Administration_Guide/Brick_Configuration.html
 //
@@ -37,7 +40,7 @@ func (s *CmdExecutor) DeviceSetup(host, device, vgid string, destroy bool) (d *e
 	commands := []string{}
 
 	if destroy {
-		logger.Info("Data on device %v (host %v) will be destroyed", device, host)
+		s.Logger().Info("Data on device %v (host %v) will be destroyed", device, host)
 		commands = append(commands, fmt.Sprintf("wipefs --all %v", device))
 	}
 	commands = append(commands, fmt.Sprintf("pvcreate --metadatasize=128M --dataalignment=256K '%v'", device))
@@ -66,6 +69,10 @@ func (s *CmdExecutor) GetDeviceInfo(host, device, vgid string) (d *executors.Dev
 	if err != nil {
 		return nil, err
 	}
+	err = s.getDeviceIdentity(d, host, device)
+	if err != nil {
+		return nil, err
+	}
 	return d, nil
 }
 
@@ -80,7 +87,7 @@ func (s *CmdExecutor) DeviceTeardown(host, device, vgid string) error {
 	// Execute command
 	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 	if err != nil {
-		logger.LogError("Error while deleting device %v with id %v on host %v: %v",
+		s.Logger().LogError("Error while deleting device %v with id %v on host %v: %v",
 			device, vgid, host, err)
 	}
 
@@ -99,7 +106,7 @@ func (s *CmdExecutor) DeviceTeardown(host, device, vgid string) error {
 
 	_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
 	if err != nil {
-		logger.LogError("Error while removing the VG directory")
+		s.Logger().LogError("Error while removing the VG directory")
 		return nil
 	}
 
@@ -144,6 +151,274 @@ func (s *CmdExecutor) getVgSizeFromNode(
 
 	d.Size = free_extents * extent_size
 	d.ExtentSize = extent_size
-	logger.Debug("Size of %v in %v is %v", device, host, d.Size)
+	s.Logger().Debug("Size of %v in %v is %v", device, host, d.Size)
+	return nil
+}
+
+// getDeviceIdentity reads the persistent identifiers of the disk
+// behind the device path. Not all hardware provides all of them, and
+// hosts without udevadm or with an lsblk too old to report the WWN
+// can not read some of them, so identifiers which are not available
+// are left empty. Empty identifiers are not compared.
+func (s *CmdExecutor) getDeviceIdentity(
+	d *executors.DeviceInfo,
+	host, device string) error {
+
+	commands := []string{
+		fmt.Sprintf("pvs --noheadings --options pv_uuid '%v'", device),
+	}
+	b, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
+	if err != nil {
+		return s.Logger().LogError("Unable to get PV UUID of %v on %v: %v",
+			device, host, err)
+	}
+	d.PvUuid = strings.TrimSpace(b[0])
+
+	// Example:
+	// WWN="0x5000c500a1b2c3d4" SERIAL="ZA1B2C3D"
+	commands = []string{
+		fmt.Sprintf("lsblk --nodeps --noheadings --pairs --output WWN,SERIAL '%v'", device),
+	}
+	b, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
+	if err != nil {
+		s.Logger().Warning("Unable to get WWN of %v on %v: %v",
+			device, host, err)
+	} else {
+		for _, m := range lsblkPairRegexp.FindAllStringSubmatch(b[0], -1) {
+			switch m[1] {
+			case "WWN":
+				d.Wwn = strings.TrimSpace(m[2])
+			case "SERIAL":
+				d.Serial = strings.TrimSpace(m[2])
+			}
+		}
+	}
+
+	// Example:
+	// disk/by-id/scsi-35000c500a1b2c3d4 disk/by-id/wwn-0x5000c500a1b2c3d4 disk/by-path/pci-0000:00:10.0-scsi-0:0:1:0
+	commands = []string{
+		fmt.Sprintf("udevadm info --query=symlink --name='%v'", device),
+	}
+	b, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
+	if err != nil {
+		s.Logger().Warning("Unable to get device links of %v on %v: %v",
+			device, host, err)
+	} else {
+		d.ById = byIdLink(strings.Fields(b[0]))
+	}
+
+	s.Logger().Debug("Identity of %v in %v is wwn:%v serial:%v by-id:%v pv:%v",
+		device, host, d.Wwn, d.Serial, d.ById, d.PvUuid)
+	return nil
+}
+
+// byIdLink picks the most stable /dev/disk/by-id link, preferring
+// the ones based on the WWN.
+func byIdLink(links []string) string {
+	byId := ""
+	for _, link := range links {
+		if !strings.HasPrefix(link, "disk/by-id/") {
+			continue
+		}
+		if strings.HasPrefix(link, "disk/by-id/wwn-") {
+			return "/dev/" + link
+		}
+		if byId == "" {
+			byId = "/dev/" + link
+		}
+	}
+	return byId
+}
+
+// DeviceDiscover returns the block devices on the host which look
+// safe to hand to heketi: whole disks that are writable, carry no
+// partitions, no filesystem or raid signatures, and are not already
+// used as LVM physical volumes.
+func (s *CmdExecutor) DeviceDiscover(host string) ([]executors.DiscoveredDevice, error) {
+
+	commands := []string{
+		"lsblk --bytes --pairs --output KNAME,PKNAME,TYPE,SIZE,RO,FSTYPE,MODEL,SERIAL",
+		"pvs --noheadings --options pv_name",
+	}
+
+	b, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDiscoveredDevices(b[0], b[1])
+}
+
+func parseDiscoveredDevices(lsblk, pvs string) ([]executors.DiscoveredDevice, error) {
+	inUse := map[string]bool{}
+	for _, pv := range strings.Fields(pvs) {
+		inUse[pv] = true
+	}
+
+	// Example:
+	// KNAME="sdb" PKNAME="" TYPE="disk" SIZE="107374182400" RO="0" FSTYPE="" MODEL="QEMU HARDDISK" SERIAL="drive-scsi1"
+	var rows []map[string]string
+	for _, line := range strings.Split(lsblk, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		row := map[string]string{}
+		for _, m := range lsblkPairRegexp.FindAllStringSubmatch(line, -1) {
+			row[m[1]] = strings.TrimSpace(m[2])
+		}
+		if _, ok := row["KNAME"]; !ok {
+			return nil, fmt.Errorf("lsblk returned an invalid string: %v", line)
+		}
+		rows = append(rows, row)
+
+		// Any device with children (partitions, device mapper
+		// targets, raid members) is in use
+		if parent := row["PKNAME"]; parent != "" {
+			inUse["/dev/"+parent] = true
+		}
+	}
+
+	devices := []executors.DiscoveredDevice{}
+	for _, row := range rows {
+		name := "/dev/" + row["KNAME"]
+		if row["TYPE"] != "disk" ||
+			row["RO"] != "0" ||
+			row["FSTYPE"] != "" ||
+			inUse[name] {
+			continue
+		}
+
+		size, err := strconv.ParseUint(row["SIZE"], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, executors.DiscoveredDevice{
+			Name:   name,
+			Size:   size / 1024,
+			Model:  row["MODEL"],
+			Serial: row["SERIAL"],
+		})
+	}
+
+	return devices, nil
+}
+
+// DeviceHealth reads the extent usage of the volume group of the
+// device, the usage of its thin pools and, if smartctl is installed
+// on the host, the SMART overall health of the disk.
+func (s *CmdExecutor) DeviceHealth(host, device, vgid string) (*executors.DeviceHealth, error) {
+
+	vg := utils.VgIdToName(vgid)
+	commands := []string{
+		fmt.Sprintf("vgs --noheadings --separator : --options vg_extent_count,vg_free_count %v", vg),
+		fmt.Sprintf("lvs --noheadings --separator : --units k --nosuffix --options lv_name,lv_attr,data_percent,metadata_percent,lv_size %v", vg),
+	}
+	b, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &executors.DeviceHealth{}
+	err = parseVgExtents(h, b[0])
+	if err != nil {
+		return nil, err
+	}
+	h.ThinPools, err = parseThinPools(b[1])
+	if err != nil {
+		return nil, err
+	}
+
+	// smartctl uses its exit status to report failing disks and
+	// may not be installed at all, so it must not fail the check
+	commands = []string{
+		fmt.Sprintf("smartctl -H '%v' || true", device),
+	}
+	b, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
+	if err != nil {
+		s.Logger().Warning("Unable to get SMART status of %v on %v: %v", device, host, err)
+	} else {
+		h.SmartPassed = parseSmartHealth(b[0])
+	}
+
+	return h, nil
+}
+
+func parseVgExtents(h *executors.DeviceHealth, vgs string) error {
+	// Example:
+	//   511996:12800
+	fields := strings.Split(strings.TrimSpace(vgs), ":")
+	if len(fields) != 2 {
+		return fmt.Errorf("vgs returned an invalid string: %v", vgs)
+	}
+	var err error
+	h.TotalExtents, err = strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return err
+	}
+	h.FreeExtents, err = strconv.ParseUint(fields[1], 10, 64)
+	return err
+}
+
+// parseThinPools returns the usage of the thin pools in the lvs
+// output, other logical volumes are skipped.
+func parseThinPools(lvs string) ([]executors.ThinPoolUsage, error) {
+	// Example:
+	//   tp_3d5a6c41b2b2e8c42d1d0dfd44c5cc8d:twi-aotz--:12.50:3.20:2097152.00
+	//   brick_3d5a6c41b2b2e8c42d1d0dfd44c5cc8d:Vwi-aotz--:12.50::2097152.00
+	pools := []executors.ThinPoolUsage{}
+	for _, line := range strings.Split(lvs, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("lvs returned an invalid string: %v", line)
+		}
+		if !strings.HasPrefix(fields[1], "t") {
+			continue
+		}
+		data, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		meta, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, err
+		}
+		pools = append(pools, executors.ThinPoolUsage{
+			Name:            fields[0],
+			DataPercent:     data,
+			MetadataPercent: meta,
+			Size:            uint64(size),
+		})
+	}
+	return pools, nil
+}
+
+// parseSmartHealth returns the overall health reported by smartctl
+// or nil if the output does not contain it.
+func parseSmartHealth(smartctl string) *bool {
+	// Example (ATA):
+	//   SMART overall-health self-assessment test result: PASSED
+	// Example (SCSI):
+	//   SMART Health Status: OK
+	for _, line := range strings.Split(smartctl, "\n") {
+		var result string
+		switch {
+		case strings.HasPrefix(line, "SMART overall-health self-assessment test result:"),
+			strings.HasPrefix(line, "SMART Health Status:"):
+			result = strings.TrimSpace(line[strings.Index(line, ":")+1:])
+		default:
+			continue
+		}
+		passed := result == "PASSED" || result == "OK"
+		return &passed
+	}
 	return nil
 }
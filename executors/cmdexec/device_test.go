@@ -0,0 +1,274 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package cmdexec
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/heketi/tests"
+)
+
+func TestSshExecDeviceDiscover(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	lsblk := strings.Join([]string{
+		`KNAME="sda" PKNAME="" TYPE="disk" SIZE="53687091200" RO="0" FSTYPE="" MODEL="QEMU HARDDISK   " SERIAL="drive-scsi0"`,
+		`KNAME="sda1" PKNAME="sda" TYPE="part" SIZE="1073741824" RO="0" FSTYPE="xfs" MODEL="" SERIAL=""`,
+		`KNAME="sdb" PKNAME="" TYPE="disk" SIZE="107374182400" RO="0" FSTYPE="" MODEL="QEMU HARDDISK   " SERIAL="drive-scsi1"`,
+		`KNAME="sdc" PKNAME="" TYPE="disk" SIZE="107374182400" RO="0" FSTYPE="LVM2_member" MODEL="QEMU HARDDISK   " SERIAL="drive-scsi2"`,
+		`KNAME="sdd" PKNAME="" TYPE="disk" SIZE="107374182400" RO="0" FSTYPE="" MODEL="QEMU HARDDISK   " SERIAL="drive-scsi3"`,
+		`KNAME="sde" PKNAME="" TYPE="disk" SIZE="10737418240" RO="0" FSTYPE="xfs" MODEL="QEMU HARDDISK   " SERIAL="drive-scsi4"`,
+		`KNAME="sr0" PKNAME="" TYPE="rom" SIZE="1073741312" RO="1" FSTYPE="" MODEL="QEMU DVD-ROM    " SERIAL="QM00003"`,
+		`KNAME="vdf" PKNAME="" TYPE="disk" SIZE="2147483648" RO="0" FSTYPE="" MODEL="" SERIAL=""`,
+		``,
+	}, "\n")
+
+	// Mock ssh function
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		tests.Assert(t, len(commands) == 2)
+		tests.Assert(t, strings.HasPrefix(commands[0], "lsblk "), commands)
+		tests.Assert(t, strings.HasPrefix(commands[1], "pvs "), commands)
+
+		return []string{lsblk, "  /dev/sdd\n"}, nil
+	}
+
+	// Call function
+	devices, err := s.DeviceDiscover("host")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(devices) == 2, devices)
+
+	tests.Assert(t, devices[0].Name == "/dev/sdb", devices[0].Name)
+	tests.Assert(t, devices[0].Size == 104857600, devices[0].Size)
+	tests.Assert(t, devices[0].Model == "QEMU HARDDISK", devices[0].Model)
+	tests.Assert(t, devices[0].Serial == "drive-scsi1", devices[0].Serial)
+
+	tests.Assert(t, devices[1].Name == "/dev/vdf", devices[1].Name)
+	tests.Assert(t, devices[1].Size == 2097152, devices[1].Size)
+	tests.Assert(t, devices[1].Model == "", devices[1].Model)
+	tests.Assert(t, devices[1].Serial == "", devices[1].Serial)
+}
+
+func TestSshExecDeviceDiscoverBadOutput(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		return []string{"NAME TYPE SIZE\nsdb disk 100G\n", ""}, nil
+	}
+
+	_, err = s.DeviceDiscover("host")
+	tests.Assert(t, err != nil)
+	tests.Assert(t, strings.Contains(err.Error(), "lsblk"), err)
+}
+
+func TestSshExecGetDeviceInfo(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		tests.Assert(t, len(commands) == 1)
+
+		switch {
+		case strings.HasPrefix(commands[0], "vgdisplay -c vg_xvgid"):
+			return []string{"vg_xvgid:r/w:772:-1:0:0:0:-1:0:1:1:104722432:4096:25567:0:25567:rJ0bIG-3XNc-NoS0-fkKm-batK-dFyX-xbxHym"}, nil
+		case strings.HasPrefix(commands[0], "pvs "):
+			tests.Assert(t, strings.Contains(commands[0], "'/dev/sdb'"), commands)
+			return []string{"  Q1w2E3-r4T5-y6U7-i8O9-p0A1-s2D3-f4G5h6\n"}, nil
+		case strings.HasPrefix(commands[0], "lsblk "):
+			tests.Assert(t, strings.Contains(commands[0], "'/dev/sdb'"), commands)
+			return []string{`WWN="0x5000c500a1b2c3d4" SERIAL="ZA1B2C3D"` + "\n"}, nil
+		case strings.HasPrefix(commands[0], "udevadm "):
+			tests.Assert(t, strings.Contains(commands[0], "'/dev/sdb'"), commands)
+			return []string{"disk/by-id/scsi-35000c500a1b2c3d4 " +
+				"disk/by-id/wwn-0x5000c500a1b2c3d4 " +
+				"disk/by-path/pci-0000:00:10.0-scsi-0:0:1:0\n"}, nil
+		}
+
+		tests.Assert(t, false, "unexpected command", commands)
+		return nil, nil
+	}
+
+	d, err := s.GetDeviceInfo("host", "/dev/sdb", "xvgid")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, d.Size == 25567*4096, d.Size)
+	tests.Assert(t, d.ExtentSize == 4096, d.ExtentSize)
+	tests.Assert(t, d.PvUuid == "Q1w2E3-r4T5-y6U7-i8O9-p0A1-s2D3-f4G5h6", d.PvUuid)
+	tests.Assert(t, d.Wwn == "0x5000c500a1b2c3d4", d.Wwn)
+	tests.Assert(t, d.Serial == "ZA1B2C3D", d.Serial)
+	tests.Assert(t, d.ById == "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4", d.ById)
+}
+
+func TestSshExecGetDeviceInfoNoIdentity(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	// Disks without identifiers are accepted
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		switch {
+		case strings.HasPrefix(commands[0], "vgdisplay"):
+			return []string{"vg_xvgid:r/w:772:-1:0:0:0:-1:0:1:1:104722432:4096:25567:0:25567:rJ0bIG"}, nil
+		case strings.HasPrefix(commands[0], "lsblk "):
+			return []string{`WWN="" SERIAL=""` + "\n"}, nil
+		}
+		return []string{""}, nil
+	}
+
+	d, err := s.GetDeviceInfo("host", "/dev/vdb", "xvgid")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, d.Size == 25567*4096, d.Size)
+	tests.Assert(t, d.PvUuid == "", d.PvUuid)
+	tests.Assert(t, d.Wwn == "", d.Wwn)
+	tests.Assert(t, d.ById == "", d.ById)
+
+	// Hosts without udevadm or with an old lsblk report no WWN,
+	// serial or by-id path
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		switch {
+		case strings.HasPrefix(commands[0], "vgdisplay"):
+			return []string{"vg_xvgid:r/w:772:-1:0:0:0:-1:0:1:1:104722432:4096:25567:0:25567:rJ0bIG"}, nil
+		case strings.HasPrefix(commands[0], "pvs "):
+			return []string{"  Q1w2E3-r4T5-y6U7-i8O9-p0A1-s2D3-f4G5h6\n"}, nil
+		}
+		return nil, fmt.Errorf("command not found")
+	}
+
+	d, err = s.GetDeviceInfo("host", "/dev/vdb", "xvgid")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, d.PvUuid == "Q1w2E3-r4T5-y6U7-i8O9-p0A1-s2D3-f4G5h6", d.PvUuid)
+	tests.Assert(t, d.Wwn == "" && d.Serial == "" && d.ById == "", d)
+}
+
+func TestSshExecDeviceHealth(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	lvs := strings.Join([]string{
+		"  tp_aaaa:twi-aotz--:12.50:3.20:2097152.00",
+		"  brick_aaaa:Vwi-aotz--:12.50::2097152.00",
+		"  tp_bbbb:twi-aotz--:81.00:95.75:1048576.00",
+		"  brick_bbbb:Vwi-aotz--:81.00::1048576.00",
+		"",
+	}, "\n")
+	smart := "smartctl 6.5 2016-05-07 r4318\n" +
+		"=== START OF READ SMART DATA SECTION ===\n" +
+		"SMART overall-health self-assessment test result: FAILED!\n"
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		if strings.HasPrefix(commands[0], "smartctl ") {
+			tests.Assert(t, len(commands) == 1)
+			tests.Assert(t, strings.Contains(commands[0], "'/dev/sdb'"), commands)
+			return []string{smart}, nil
+		}
+		tests.Assert(t, len(commands) == 2)
+		tests.Assert(t, strings.HasPrefix(commands[0], "vgs "), commands)
+		tests.Assert(t, strings.HasSuffix(commands[0], " vg_xxxx"), commands)
+		tests.Assert(t, strings.HasPrefix(commands[1], "lvs "), commands)
+		return []string{"  511996:12800\n", lvs}, nil
+	}
+
+	h, err := s.DeviceHealth("host", "/dev/sdb", "xxxx")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, h.TotalExtents == 511996, h.TotalExtents)
+	tests.Assert(t, h.FreeExtents == 12800, h.FreeExtents)
+	tests.Assert(t, len(h.ThinPools) == 2, h.ThinPools)
+	tests.Assert(t, h.ThinPools[0].Name == "tp_aaaa", h.ThinPools[0])
+	tests.Assert(t, h.ThinPools[0].DataPercent == 12.5, h.ThinPools[0])
+	tests.Assert(t, h.ThinPools[0].Size == 2097152, h.ThinPools[0])
+	tests.Assert(t, h.ThinPools[1].MetadataPercent == 95.75, h.ThinPools[1])
+	tests.Assert(t, h.SmartPassed != nil && !*h.SmartPassed, h.SmartPassed)
+
+	// smartctl not installed
+	smart = "bash: smartctl: command not found\n"
+	h, err = s.DeviceHealth("host", "/dev/sdb", "xxxx")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, h.SmartPassed == nil, h.SmartPassed)
+
+	smart = "SMART Health Status: OK\n"
+	h, err = s.DeviceHealth("host", "/dev/sdb", "xxxx")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, h.SmartPassed != nil && *h.SmartPassed, h.SmartPassed)
+}
+
+func TestSshExecDeviceHealthBadOutput(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		return []string{"  Volume group not found\n", ""}, nil
+	}
+	_, err = s.DeviceHealth("host", "/dev/sdb", "xxxx")
+	tests.Assert(t, err != nil)
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		return []string{"10:10", "  tp_aaaa:twi-aotz--\n"}, nil
+	}
+	_, err = s.DeviceHealth("host", "/dev/sdb", "xxxx")
+	tests.Assert(t, err != nil)
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		return nil, fmt.Errorf("ssh failed")
+	}
+	_, err = s.DeviceHealth("host", "/dev/sdb", "xxxx")
+	tests.Assert(t, err != nil)
+}
@@ -0,0 +1,219 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package cmdexec
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/heketi/heketi/executors"
+	"github.com/lpabon/godbc"
+)
+
+const (
+	// Public keys of the geo-replication workers of a cluster, gluster
+	// paths are relative to /var/lib/glusterd
+	geoRepSecretFile   = "/geo-replication/common_secret.pem.pub"
+	geoRepSecretPrefix = "/var/lib/glusterd"
+)
+
+// geoRepSessionSecretFile returns the file the keys of the master
+// cluster of the session are kept in on the slave cluster. It is the
+// file push-pem uses, so that the keys of other sessions are kept.
+func geoRepSessionSecretFile(gr *executors.GeoReplicationRequest) string {
+	return fmt.Sprintf("/geo-replication/%v_%v_common_secret.pem.pub",
+		gr.MasterVolume, gr.SlaveVolume)
+}
+
+// geoRepSession returns the gluster name of the session
+func geoRepSession(gr *executors.GeoReplicationRequest) string {
+	return fmt.Sprintf("%v %v::%v",
+		gr.MasterVolume, gr.SlaveHost, gr.SlaveVolume)
+}
+
+// shellQuote quotes s for the remote shell
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// GeoReplicationSecret generates the keys of the geo-replication
+// workers on all the nodes of the cluster of the host and returns
+// their public keys.
+func (s *CmdExecutor) GeoReplicationSecret(host string) (string, error) {
+	godbc.Require(host != "")
+
+	commands := []string{
+		"gluster --mode=script system:: execute gsec_create",
+		fmt.Sprintf("cat %v%v", geoRepSecretPrefix, geoRepSecretFile),
+	}
+	output, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
+	if err != nil {
+		return "", fmt.Errorf("Unable to create geo-replication keys: %v", err)
+	}
+	secret := strings.TrimSpace(output[1])
+	if secret == "" {
+		return "", fmt.Errorf("No geo-replication keys found on %v", host)
+	}
+	return secret, nil
+}
+
+// GeoReplicationAddSecret authorizes the public keys of the
+// geo-replication workers of the master cluster of the session on all
+// the nodes of the cluster of the host, which is the slave cluster.
+func (s *CmdExecutor) GeoReplicationAddSecret(host string,
+	gr *executors.GeoReplicationRequest, secret string) error {
+
+	godbc.Require(host != "")
+	godbc.Require(gr != nil)
+	godbc.Require(secret != "")
+
+	file := geoRepSessionSecretFile(gr)
+	commands := []string{
+		fmt.Sprintf("mkdir -p %v/geo-replication", geoRepSecretPrefix),
+		fmt.Sprintf("printf '%%s\\n' %v > %v%v", shellQuote(secret),
+			geoRepSecretPrefix, file),
+		fmt.Sprintf("gluster --mode=script system:: copy file %v", file),
+		fmt.Sprintf("gluster --mode=script system:: execute add_secret_pub root %v %v",
+			gr.MasterVolume, gr.SlaveVolume),
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
+	if err != nil {
+		return fmt.Errorf("Unable to add geo-replication keys: %v", err)
+	}
+	return nil
+}
+
+// GeoReplicationCreate creates the session. The keys of the master
+// cluster must have been added to the slave cluster.
+func (s *CmdExecutor) GeoReplicationCreate(host string,
+	gr *executors.GeoReplicationRequest) error {
+
+	godbc.Require(host != "")
+	godbc.Require(gr != nil)
+
+	cmd := fmt.Sprintf("gluster --mode=script volume geo-replication %v create no-verify",
+		geoRepSession(gr))
+	if gr.Force {
+		cmd += " force"
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, []string{cmd}, 10)
+	if err != nil {
+		return fmt.Errorf("Unable to create geo-replication session %v: %v",
+			geoRepSession(gr), err)
+	}
+	return nil
+}
+
+// GeoReplicationAction starts, stops, pauses or resumes the session.
+func (s *CmdExecutor) GeoReplicationAction(host string,
+	gr *executors.GeoReplicationRequest, action string) error {
+
+	godbc.Require(host != "")
+	godbc.Require(gr != nil)
+	godbc.Require(action != "")
+
+	cmd := fmt.Sprintf("gluster --mode=script volume geo-replication %v %v",
+		geoRepSession(gr), action)
+	if gr.Force {
+		cmd += " force"
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, []string{cmd}, 10)
+	if err != nil {
+		return fmt.Errorf("Unable to %v geo-replication session %v: %v",
+			action, geoRepSession(gr), err)
+	}
+	return nil
+}
+
+func (s *CmdExecutor) GeoReplicationStatus(host string,
+	gr *executors.GeoReplicationRequest) (*executors.GeoReplicationStatus, error) {
+
+	godbc.Require(host != "")
+	godbc.Require(gr != nil)
+
+	type CliOutput struct {
+		OpRet    int    `xml:"opRet"`
+		OpErrno  int    `xml:"opErrno"`
+		OpErrStr string `xml:"opErrstr"`
+		GeoRep   struct {
+			Volumes []struct {
+				Sessions struct {
+					SessionList []struct {
+						Pairs []executors.GeoReplicationPair `xml:"pair"`
+					} `xml:"session"`
+				} `xml:"sessions"`
+			} `xml:"volume"`
+		} `xml:"geoRep"`
+	}
+
+	command := []string{
+		fmt.Sprintf("gluster --mode=script volume geo-replication %v status --xml",
+			geoRepSession(gr)),
+	}
+	output, err := s.RemoteExecutor.RemoteCommandExecute(host, command, 10)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get status of geo-replication session %v: %v",
+			geoRepSession(gr), err)
+	}
+
+	var out CliOutput
+	err = xml.Unmarshal([]byte(output[0]), &out)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse status of geo-replication session %v: %v",
+			geoRepSession(gr), err)
+	}
+	s.Logger().Debug("%+v\n", out)
+	if out.OpRet != 0 {
+		return nil, fmt.Errorf("Unable to get status of geo-replication session %v: %v",
+			geoRepSession(gr), out.OpErrStr)
+	}
+
+	status := &executors.GeoReplicationStatus{
+		Pairs: []executors.GeoReplicationPair{},
+	}
+	for _, v := range out.GeoRep.Volumes {
+		for _, session := range v.Sessions.SessionList {
+			status.Pairs = append(status.Pairs, session.Pairs...)
+		}
+	}
+	return status, nil
+}
+
+// GeoReplicationDestroy stops and deletes the session. The files
+// already replicated are kept on the slave volume.
+func (s *CmdExecutor) GeoReplicationDestroy(host string,
+	gr *executors.GeoReplicationRequest) error {
+
+	godbc.Require(host != "")
+	godbc.Require(gr != nil)
+
+	// The session may not be running
+	commands := []string{
+		fmt.Sprintf("gluster --mode=script volume geo-replication %v stop force",
+			geoRepSession(gr)),
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
+	if err != nil {
+		s.Logger().Warning("Unable to stop geo-replication session %v: %v",
+			geoRepSession(gr), err)
+	}
+
+	commands = []string{
+		fmt.Sprintf("gluster --mode=script volume geo-replication %v delete",
+			geoRepSession(gr)),
+	}
+	_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
+	if err != nil {
+		return fmt.Errorf("Unable to delete geo-replication session %v: %v",
+			geoRepSession(gr), err)
+	}
+	return nil
+}
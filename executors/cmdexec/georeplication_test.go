@@ -0,0 +1,189 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package cmdexec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/tests"
+)
+
+func TestSshExecGeoReplicationSecret(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	var executed []string
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		executed = append(executed, commands...)
+		return []string{"", "ssh-rsa AAAA root@host\n"}, nil
+	}
+
+	secret, err := s.GeoReplicationSecret("host")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, secret == "ssh-rsa AAAA root@host", secret)
+	tests.Assert(t, len(executed) == 2, executed)
+	tests.Assert(t,
+		executed[0] == "gluster --mode=script system:: execute gsec_create",
+		executed)
+	tests.Assert(t,
+		executed[1] == "cat /var/lib/glusterd/geo-replication/common_secret.pem.pub",
+		executed)
+
+	executed = nil
+	gr := &executors.GeoReplicationRequest{
+		MasterVolume: "vol_m",
+		SlaveHost:    "slave",
+		SlaveVolume:  "vol_s",
+	}
+	err = s.GeoReplicationAddSecret("host", gr, "key 'a' root@host")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(executed) == 4, executed)
+	tests.Assert(t, executed[1] == `printf '%s\n' 'key '\''a'\'' root@host' > `+
+		"/var/lib/glusterd/geo-replication/vol_m_vol_s_common_secret.pem.pub",
+		executed)
+	tests.Assert(t, executed[2] == "gluster --mode=script system:: copy file "+
+		"/geo-replication/vol_m_vol_s_common_secret.pem.pub", executed)
+	tests.Assert(t, executed[3] == "gluster --mode=script system:: "+
+		"execute add_secret_pub root vol_m vol_s", executed)
+}
+
+func TestSshExecGeoReplicationSession(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	var executed []string
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		executed = append(executed, commands...)
+		if commands[0] == "gluster --mode=script volume geo-replication "+
+			"vol_a h2::vol_b stop force" {
+			return nil, fmt.Errorf("session is not running")
+		}
+		return []string{""}, nil
+	}
+
+	gr := &executors.GeoReplicationRequest{
+		MasterVolume: "vol_a",
+		SlaveHost:    "h2",
+		SlaveVolume:  "vol_b",
+	}
+	err = s.GeoReplicationCreate("host", gr)
+	tests.Assert(t, err == nil, err)
+	err = s.GeoReplicationAction("host", gr, "pause")
+	tests.Assert(t, err == nil, err)
+	gr.Force = true
+	err = s.GeoReplicationAction("host", gr, "start")
+	tests.Assert(t, err == nil, err)
+	// the session is deleted even if it can not be stopped
+	err = s.GeoReplicationDestroy("host", gr)
+	tests.Assert(t, err == nil, err)
+
+	tests.Assert(t, len(executed) == 5, executed)
+	tests.Assert(t, executed[0] == "gluster --mode=script volume "+
+		"geo-replication vol_a h2::vol_b create no-verify", executed)
+	tests.Assert(t, executed[1] == "gluster --mode=script volume "+
+		"geo-replication vol_a h2::vol_b pause", executed)
+	tests.Assert(t, executed[2] == "gluster --mode=script volume "+
+		"geo-replication vol_a h2::vol_b start force", executed)
+	tests.Assert(t, executed[4] == "gluster --mode=script volume "+
+		"geo-replication vol_a h2::vol_b delete", executed)
+}
+
+func TestSshExecGeoReplicationStatus(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	xml := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cliOutput>
+  <opRet>0</opRet>
+  <opErrno>0</opErrno>
+  <opErrstr/>
+  <geoRep>
+    <volume>
+      <name>vol_a</name>
+      <sessions>
+        <session>
+          <session_slave>5e1c0d8e:ssh://h2::vol_b:8b3f</session_slave>
+          <pair>
+            <master_node>h1</master_node>
+            <master_brick>/var/lib/heketi/mounts/vg_1/brick_1/brick</master_brick>
+            <slave_user>root</slave_user>
+            <slave>ssh://h2::vol_b</slave>
+            <slave_node>h2</slave_node>
+            <status>Active</status>
+            <crawl_status>Changelog Crawl</crawl_status>
+            <entry>0</entry>
+            <data>0</data>
+            <meta>0</meta>
+            <failures>0</failures>
+            <checkpoint_completed>N/A</checkpoint_completed>
+            <master_node_uuid>6f2c</master_node_uuid>
+            <last_synced>2018-05-14 10:23:41</last_synced>
+          </pair>
+          <pair>
+            <master_node>h3</master_node>
+            <master_brick>/var/lib/heketi/mounts/vg_3/brick_3/brick</master_brick>
+            <slave_user>root</slave_user>
+            <slave>ssh://h2::vol_b</slave>
+            <slave_node>h4</slave_node>
+            <status>Passive</status>
+            <crawl_status>N/A</crawl_status>
+            <last_synced>N/A</last_synced>
+          </pair>
+        </session>
+      </sessions>
+    </volume>
+  </geoRep>
+</cliOutput>`
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		tests.Assert(t, len(commands) == 1)
+		tests.Assert(t, commands[0] == "gluster --mode=script volume "+
+			"geo-replication vol_a h2::vol_b status --xml", commands)
+		return []string{xml}, nil
+	}
+
+	status, err := s.GeoReplicationStatus("host",
+		&executors.GeoReplicationRequest{
+			MasterVolume: "vol_a",
+			SlaveHost:    "h2",
+			SlaveVolume:  "vol_b",
+		})
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(status.Pairs) == 2, status.Pairs)
+	tests.Assert(t, status.Pairs[0].MasterNode == "h1")
+	tests.Assert(t, status.Pairs[0].Status == "Active")
+	tests.Assert(t, status.Pairs[0].CrawlStatus == "Changelog Crawl")
+	tests.Assert(t, status.Pairs[0].LastSynced == "2018-05-14 10:23:41")
+	tests.Assert(t, status.Pairs[1].SlaveNode == "h4")
+	tests.Assert(t, status.Pairs[1].Status == "Passive")
+}
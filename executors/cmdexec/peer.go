@@ -21,7 +21,7 @@ func (s *CmdExecutor) PeerProbe(host, newnode string) error {
 	godbc.Require(host != "")
 	godbc.Require(newnode != "")
 
-	logger.Info("Probing: %v -> %v", host, newnode)
+	s.Logger().Info("Probing: %v -> %v", host, newnode)
 	// create the commands
 	commands := []string{
 		fmt.Sprintf("gluster peer probe %v", newnode),
@@ -33,7 +33,7 @@ func (s *CmdExecutor) PeerProbe(host, newnode string) error {
 
 	// Determine if there is a snapshot limit configuration setting
 	if s.RemoteExecutor.SnapShotLimit() > 0 {
-		logger.Info("Setting snapshot limit")
+		s.Logger().Info("Setting snapshot limit")
 		commands = []string{
 			fmt.Sprintf("gluster --mode=script snapshot config snap-max-hard-limit %v",
 				s.RemoteExecutor.SnapShotLimit()),
@@ -52,13 +52,13 @@ func (s *CmdExecutor) PeerDetach(host, detachnode string) error {
 	godbc.Require(detachnode != "")
 
 	// create the commands
-	logger.Info("Detaching node %v", detachnode)
+	s.Logger().Info("Detaching node %v", detachnode)
 	commands := []string{
 		fmt.Sprintf("gluster peer detach %v", detachnode),
 	}
 	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
 	if err != nil {
-		logger.Err(err)
+		s.Logger().Err(err)
 	}
 
 	return nil
@@ -67,13 +67,13 @@ func (s *CmdExecutor) PeerDetach(host, detachnode string) error {
 func (s *CmdExecutor) GlusterdCheck(host string) error {
 	godbc.Require(host != "")
 
-	logger.Info("Check Glusterd service status in node %v", host)
+	s.Logger().Info("Check Glusterd service status in node %v", host)
 	commands := []string{
 		fmt.Sprintf("systemctl status glusterd"),
 	}
 	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
 	if err != nil {
-		logger.Err(err)
+		s.Logger().Err(err)
 		return err
 	}
 
@@ -0,0 +1,165 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package cmdexec
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/heketi/heketi/executors"
+	"github.com/lpabon/godbc"
+)
+
+// VolumeQuotaEnable enables the directory quotas of the volume. It
+// succeeds if they are already enabled.
+func (s *CmdExecutor) VolumeQuotaEnable(host string, volume string) error {
+	godbc.Require(host != "")
+	godbc.Require(volume != "")
+
+	command := []string{
+		fmt.Sprintf("gluster --mode=script volume quota %v enable", volume),
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, command, 10)
+	if err != nil && !strings.Contains(err.Error(), "already enabled") {
+		return fmt.Errorf("Unable to enable quota on volume %v: %v", volume, err)
+	}
+	return nil
+}
+
+// VolumeQuotaDisable disables the directory quotas of the volume, which
+// removes all its limits. It succeeds if they are already disabled.
+func (s *CmdExecutor) VolumeQuotaDisable(host string, volume string) error {
+	godbc.Require(host != "")
+	godbc.Require(volume != "")
+
+	command := []string{
+		fmt.Sprintf("gluster --mode=script volume quota %v disable", volume),
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, command, 10)
+	if err != nil && !strings.Contains(err.Error(), "already disabled") {
+		return fmt.Errorf("Unable to disable quota on volume %v: %v", volume, err)
+	}
+	return nil
+}
+
+func (s *CmdExecutor) VolumeQuotaSetLimit(host string, volume string,
+	ql *executors.QuotaLimitRequest) error {
+
+	godbc.Require(host != "")
+	godbc.Require(volume != "")
+	godbc.Require(ql != nil)
+	godbc.Require(ql.Path != "")
+
+	cmd := fmt.Sprintf("gluster --mode=script volume quota %v limit-usage %v %v",
+		volume, ql.Path, ql.HardLimit)
+	if ql.SoftLimitPercent != 0 {
+		cmd += fmt.Sprintf(" %v%%", ql.SoftLimitPercent)
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, []string{cmd}, 10)
+	if err != nil {
+		return fmt.Errorf("Unable to set quota limit of %v on volume %v: %v",
+			ql.Path, volume, err)
+	}
+	return nil
+}
+
+func (s *CmdExecutor) VolumeQuotaRemoveLimit(host string, volume string,
+	path string) error {
+
+	godbc.Require(host != "")
+	godbc.Require(volume != "")
+	godbc.Require(path != "")
+
+	command := []string{
+		fmt.Sprintf("gluster --mode=script volume quota %v remove %v",
+			volume, path),
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, command, 10)
+	if err != nil {
+		return fmt.Errorf("Unable to remove quota limit of %v on volume %v: %v",
+			path, volume, err)
+	}
+	return nil
+}
+
+// quotaSize parses a size reported by gluster. Sizes of directories
+// which no longer exist are reported as N/A and returned as 0.
+func quotaSize(s string) uint64 {
+	n, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// VolumeQuotaList returns the limits set on the volume with their
+// usage.
+func (s *CmdExecutor) VolumeQuotaList(host string, volume string) (
+	[]executors.QuotaLimit, error) {
+
+	godbc.Require(host != "")
+	godbc.Require(volume != "")
+
+	type CliOutput struct {
+		OpRet    int    `xml:"opRet"`
+		OpErrno  int    `xml:"opErrno"`
+		OpErrStr string `xml:"opErrstr"`
+		VolQuota struct {
+			Limits []struct {
+				Path             string `xml:"path"`
+				HardLimit        string `xml:"hard_limit"`
+				SoftLimitPercent string `xml:"soft_limit_percent"`
+				UsedSpace        string `xml:"used_space"`
+				AvailSpace       string `xml:"avail_space"`
+				SlExceeded       string `xml:"sl_exceeded"`
+				HlExceeded       string `xml:"hl_exceeded"`
+			} `xml:"limit"`
+		} `xml:"volQuota"`
+	}
+
+	command := []string{
+		fmt.Sprintf("gluster --mode=script volume quota %v list --xml", volume),
+	}
+	output, err := s.RemoteExecutor.RemoteCommandExecute(host, command, 10)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list quota limits of volume %v: %v",
+			volume, err)
+	}
+
+	var out CliOutput
+	err = xml.Unmarshal([]byte(output[0]), &out)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse quota limits of volume %v: %v",
+			volume, err)
+	}
+	s.Logger().Debug("%+v\n", out)
+	if out.OpRet != 0 {
+		return nil, fmt.Errorf("Unable to list quota limits of volume %v: %v",
+			volume, out.OpErrStr)
+	}
+
+	limits := []executors.QuotaLimit{}
+	for _, l := range out.VolQuota.Limits {
+		percent, _ := strconv.Atoi(strings.TrimSuffix(
+			strings.TrimSpace(l.SoftLimitPercent), "%"))
+		limits = append(limits, executors.QuotaLimit{
+			Path:              l.Path,
+			HardLimit:         quotaSize(l.HardLimit),
+			SoftLimitPercent:  percent,
+			Used:              quotaSize(l.UsedSpace),
+			Available:         quotaSize(l.AvailSpace),
+			SoftLimitExceeded: l.SlExceeded == "Yes",
+			HardLimitExceeded: l.HlExceeded == "Yes",
+		})
+	}
+	return limits, nil
+}
@@ -0,0 +1,131 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package cmdexec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/tests"
+)
+
+func TestSshExecVolumeQuota(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	var executed []string
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		executed = append(executed, commands...)
+		if commands[0] == "gluster --mode=script volume quota vol_a enable" {
+			return nil, fmt.Errorf("quota command failed : Quota is already enabled")
+		}
+		return []string{""}, nil
+	}
+
+	// enabling quota twice is not an error
+	err = s.VolumeQuotaEnable("host", "vol_a")
+	tests.Assert(t, err == nil, err)
+	err = s.VolumeQuotaSetLimit("host", "vol_a", &executors.QuotaLimitRequest{
+		Path:      "/",
+		HardLimit: 1024,
+	})
+	tests.Assert(t, err == nil, err)
+	err = s.VolumeQuotaSetLimit("host", "vol_a", &executors.QuotaLimitRequest{
+		Path:             "/team-a",
+		HardLimit:        2048,
+		SoftLimitPercent: 70,
+	})
+	tests.Assert(t, err == nil, err)
+	err = s.VolumeQuotaRemoveLimit("host", "vol_a", "/team-a")
+	tests.Assert(t, err == nil, err)
+	err = s.VolumeQuotaDisable("host", "vol_a")
+	tests.Assert(t, err == nil, err)
+
+	tests.Assert(t, len(executed) == 5, executed)
+	tests.Assert(t, executed[1] ==
+		"gluster --mode=script volume quota vol_a limit-usage / 1024", executed)
+	tests.Assert(t, executed[2] ==
+		"gluster --mode=script volume quota vol_a limit-usage /team-a 2048 70%",
+		executed)
+	tests.Assert(t, executed[3] ==
+		"gluster --mode=script volume quota vol_a remove /team-a", executed)
+	tests.Assert(t, executed[4] ==
+		"gluster --mode=script volume quota vol_a disable", executed)
+}
+
+func TestSshExecVolumeQuotaList(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	xml := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cliOutput>
+  <opRet>0</opRet>
+  <opErrno>0</opErrno>
+  <opErrstr/>
+  <volQuota>
+    <limit>
+      <path>/</path>
+      <hard_limit>10737418240</hard_limit>
+      <soft_limit_percent>80%</soft_limit_percent>
+      <soft_limit_value>8589934592</soft_limit_value>
+      <used_space>9663676416</used_space>
+      <avail_space>1073741824</avail_space>
+      <sl_exceeded>Yes</sl_exceeded>
+      <hl_exceeded>No</hl_exceeded>
+    </limit>
+    <limit>
+      <path>/gone</path>
+      <hard_limit>N/A</hard_limit>
+      <soft_limit_percent>N/A</soft_limit_percent>
+      <soft_limit_value>N/A</soft_limit_value>
+      <used_space>N/A</used_space>
+      <avail_space>N/A</avail_space>
+      <sl_exceeded>N/A</sl_exceeded>
+      <hl_exceeded>N/A</hl_exceeded>
+    </limit>
+  </volQuota>
+</cliOutput>`
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		tests.Assert(t, len(commands) == 1)
+		tests.Assert(t, commands[0] ==
+			"gluster --mode=script volume quota vol_a list --xml", commands)
+		return []string{xml}, nil
+	}
+
+	limits, err := s.VolumeQuotaList("host", "vol_a")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(limits) == 2, limits)
+	tests.Assert(t, limits[0].Path == "/")
+	tests.Assert(t, limits[0].HardLimit == 10737418240)
+	tests.Assert(t, limits[0].SoftLimitPercent == 80)
+	tests.Assert(t, limits[0].Used == 9663676416)
+	tests.Assert(t, limits[0].Available == 1073741824)
+	tests.Assert(t, limits[0].SoftLimitExceeded)
+	tests.Assert(t, !limits[0].HardLimitExceeded)
+	tests.Assert(t, limits[1].Path == "/gone")
+	tests.Assert(t, limits[1].HardLimit == 0)
+	tests.Assert(t, !limits[1].SoftLimitExceeded)
+}
@@ -42,7 +42,7 @@ func (s *CmdExecutor) snapshotActivate(host string, snapshot string) error {
 	if err != nil {
 		return fmt.Errorf("Unable to parse output from activate snapshot %v: %v", snapshot, err)
 	}
-	logger.Debug("%+v\n", snapActivate)
+	s.Logger().Debug("%+v\n", snapActivate)
 	if snapActivate.OpRet != 0 {
 		return fmt.Errorf("Failed to activate snapshot %v: %v", snapshot, snapActivate.OpErrStr)
 	}
@@ -75,7 +75,7 @@ func (s *CmdExecutor) snapshotDeactivate(host string, snapshot string) error {
 	if err != nil {
 		return fmt.Errorf("Unable to parse output from deactivate snapshot %v: %v", snapshot, err)
 	}
-	logger.Debug("%+v\n", snapDeactivate)
+	s.Logger().Debug("%+v\n", snapDeactivate)
 	if snapDeactivate.OpRet != 0 {
 		return fmt.Errorf("Failed to deactivate snapshot %v: %v", snapshot, snapDeactivate.OpErrStr)
 	}
@@ -117,7 +117,7 @@ func (s *CmdExecutor) SnapshotCloneVolume(host string, vcr *executors.SnapshotCl
 	if err != nil {
 		return nil, fmt.Errorf("Unable to parse output from clone snapshot %v: %v", vcr.Snapshot, err)
 	}
-	logger.Debug("%+v\n", cliOutput)
+	s.Logger().Debug("%+v\n", cliOutput)
 	if cliOutput.OpRet != 0 {
 		return nil, fmt.Errorf("Failed to clone snapshot %v to volume %v: %v", vcr.Snapshot, vcr.Volume, cliOutput.OpErrStr)
 	}
@@ -166,7 +166,7 @@ func (s *CmdExecutor) SnapshotDestroy(host string, snapshot string) error {
 	if err != nil {
 		return fmt.Errorf("Unable to parse output from delete snapshot %v: %v", snapshot, err)
 	}
-	logger.Debug("%+v\n", snapDelete)
+	s.Logger().Debug("%+v\n", snapDelete)
 	if snapDelete.OpRet != 0 {
 		return fmt.Errorf("Failed to delete snapshot %v: %v", snapshot, snapDelete.OpErrStr)
 	}
@@ -18,6 +18,14 @@ import (
 	"github.com/lpabon/godbc"
 )
 
+const (
+	// Directory the volumes are mounted under while they are copied
+	volumeSyncDir = "/var/lib/heketi/sync"
+	// Copying the files of a volume can take much longer than any
+	// other command, the timeout is in minutes
+	volumeSyncTimeout = 24 * 60
+)
+
 func (s *CmdExecutor) VolumeCreate(host string,
 	volume *executors.VolumeRequest) (*executors.Volume, error) {
 
@@ -34,11 +42,11 @@ func (s *CmdExecutor) VolumeCreate(host string,
 	)
 	switch volume.Type {
 	case executors.DurabilityNone:
-		logger.Info("Creating volume %v with no durability", volume.Name)
+		s.Logger().Info("Creating volume %v with no durability", volume.Name)
 		inSet = 1
 		maxPerSet = 15
 	case executors.DurabilityReplica:
-		logger.Info("Creating volume %v replica %v", volume.Name, volume.Replica)
+		s.Logger().Info("Creating volume %v replica %v", volume.Name, volume.Replica)
 		cmd += fmt.Sprintf("replica %v ", volume.Replica)
 		if volume.Arbiter {
 			cmd += "arbiter 1 "
@@ -46,7 +54,7 @@ func (s *CmdExecutor) VolumeCreate(host string,
 		inSet = volume.Replica
 		maxPerSet = 5
 	case executors.DurabilityDispersion:
-		logger.Info("Creating volume %v dispersion %v+%v",
+		s.Logger().Info("Creating volume %v dispersion %v+%v",
 			volume.Name, volume.Data, volume.Redundancy)
 		cmd += fmt.Sprintf("disperse-data %v redundancy %v ", volume.Data, volume.Redundancy)
 		inSet = volume.Data + volume.Redundancy
@@ -120,8 +128,8 @@ func (s *CmdExecutor) VolumeExpand(host string,
 			// Mainly because rebalance may fail even if one brick is down for the given volume.
 			// The probability is just too high to undo the work done to create and attach bricks.
 			// Admins should be able to get new size to reflect by executing the rebalance cmd manually.
-			logger.LogError("Unable to start rebalance on the volume %v: %v", volume, err)
-			logger.LogError("Action Required: run rebalance manually on the volume %v", volume)
+			s.Logger().LogError("Unable to start rebalance on the volume %v: %v", volume, err)
+			s.Logger().LogError("Action Required: run rebalance manually on the volume %v", volume)
 			return &executors.Volume{}, nil
 		}
 	}
@@ -129,6 +137,127 @@ func (s *CmdExecutor) VolumeExpand(host string,
 	return &executors.Volume{}, nil
 }
 
+func (s *CmdExecutor) VolumeConvert(host string,
+	vcr *executors.VolumeConvertRequest) (*executors.Volume, error) {
+
+	godbc.Require(vcr != nil)
+	godbc.Require(host != "")
+	godbc.Require(len(vcr.AddBricks) > 0 || len(vcr.RemoveBricks) > 0)
+	godbc.Require(len(vcr.AddBricks) == 0 || len(vcr.RemoveBricks) == 0)
+	godbc.Require(vcr.Name != "")
+
+	// Gluster changes the replica count of all brick sets at once, so
+	// one brick of every set is given in the same command
+	if len(vcr.RemoveBricks) > 0 {
+		cmd := fmt.Sprintf("gluster --mode=script volume remove-brick %v replica %v ",
+			vcr.Name, vcr.Replica-1)
+		for _, brick := range vcr.RemoveBricks {
+			cmd += fmt.Sprintf("%v:%v ", brick.Host, brick.Path)
+		}
+		commands := []string{cmd + "force"}
+		_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
+		if err != nil {
+			return nil, err
+		}
+		return &executors.Volume{}, nil
+	}
+
+	cmd := fmt.Sprintf("gluster --mode=script volume add-brick %v replica %v ",
+		vcr.Name, vcr.Replica)
+	if vcr.Arbiter {
+		cmd += "arbiter 1 "
+	}
+	for _, brick := range vcr.AddBricks {
+		cmd += fmt.Sprintf("%v:%v ", brick.Host, brick.Path)
+	}
+	commands := []string{cmd}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	// The self-heal daemon populates the new bricks on its own, start
+	// a full heal so that it does not wait for the files to be accessed
+	commands = []string{fmt.Sprintf("gluster --mode=script volume heal %v full", vcr.Name)}
+	_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
+	if err != nil {
+		s.Logger().LogError("Unable to start heal on the volume %v: %v", vcr.Name, err)
+		s.Logger().LogError("Action Required: run heal manually on the volume %v", vcr.Name)
+	}
+
+	return &executors.Volume{}, nil
+}
+
+// VolumeSync mounts the source and target volumes on the host and
+// copies the files of the source volume with rsync. It can be run
+// again to copy the changes made since the last run.
+func (s *CmdExecutor) VolumeSync(host string,
+	vsr *executors.VolumeSyncRequest) error {
+
+	godbc.Require(vsr != nil)
+	godbc.Require(host != "")
+	godbc.Require(vsr.Name != "")
+	godbc.Require(vsr.SourceHost != "")
+	godbc.Require(vsr.TargetHost != "")
+
+	dir := fmt.Sprintf("%v/%v", volumeSyncDir, vsr.Name)
+	source := dir + "/source"
+	target := dir + "/target"
+
+	// Mounts may have been left behind by a sync that did not finish
+	s.volumeSyncUnmount(host, source, target)
+	defer s.volumeSyncUnmount(host, source, target)
+
+	commands := []string{
+		fmt.Sprintf("mkdir -p %v %v", source, target),
+		fmt.Sprintf("mount -t glusterfs %v:/%v %v",
+			vsr.SourceHost, vsr.Name, source),
+		fmt.Sprintf("mount -t glusterfs %v:/%v %v",
+			vsr.TargetHost, vsr.Name, target),
+		fmt.Sprintf("rsync -aHAX --delete %v/ %v/", source, target),
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands,
+		volumeSyncTimeout)
+	if err != nil {
+		s.Logger().LogError("Unable to copy volume %v from %v to %v: %v",
+			vsr.Name, vsr.SourceHost, vsr.TargetHost, err)
+		return err
+	}
+	return nil
+}
+
+// VolumeReadOnly turns the read-only translator of the volume on or
+// off. Clients that have the volume mounted keep reading from it.
+func (s *CmdExecutor) VolumeReadOnly(host string,
+	volume string, readOnly bool) error {
+
+	godbc.Require(host != "")
+	godbc.Require(volume != "")
+
+	value := "off"
+	if readOnly {
+		value = "on"
+	}
+	commands := []string{
+		fmt.Sprintf("gluster --mode=script volume set %v features.read-only %v",
+			volume, value),
+	}
+	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
+	if err != nil {
+		s.Logger().LogError("Unable to set read-only %v on volume %v: %v",
+			value, volume, err)
+		return err
+	}
+	return nil
+}
+
+func (s *CmdExecutor) volumeSyncUnmount(host string, mounts ...string) {
+	for _, m := range mounts {
+		commands := []string{fmt.Sprintf("umount %v", m)}
+		s.RemoteExecutor.RemoteCommandExecute(host, commands, 5)
+	}
+}
+
 func (s *CmdExecutor) VolumeDestroy(host string, volume string) error {
 	godbc.Require(host != "")
 	godbc.Require(volume != "")
@@ -141,7 +270,7 @@ func (s *CmdExecutor) VolumeDestroy(host string, volume string) error {
 
 	_, err := s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
 	if err != nil {
-		logger.LogError("Unable to stop volume %v: %v", volume, err)
+		s.Logger().LogError("Unable to stop volume %v: %v", volume, err)
 	}
 
 	commands = []string{
@@ -150,7 +279,7 @@ func (s *CmdExecutor) VolumeDestroy(host string, volume string) error {
 
 	_, err = s.RemoteExecutor.RemoteCommandExecute(host, commands, 10)
 	if err != nil {
-		return logger.Err(fmt.Errorf("Unable to delete volume %v: %v", volume, err))
+		return s.Logger().Err(fmt.Errorf("Unable to delete volume %v: %v", volume, err))
 	}
 
 	return nil
@@ -272,7 +401,7 @@ func (s *CmdExecutor) VolumeInfo(host string, volume string) (*executors.Volume,
 	if err != nil {
 		return nil, fmt.Errorf("Unable to determine volume info of volume name: %v", volume)
 	}
-	logger.Debug("%+v\n", volumeInfo)
+	s.Logger().Debug("%+v\n", volumeInfo)
 	return &volumeInfo.VolInfo.Volumes.VolumeList[0], nil
 }
 
@@ -288,7 +417,7 @@ func (s *CmdExecutor) VolumeReplaceBrick(host string, volume string, oldBrick *e
 	}
 	_, err := s.RemoteExecutor.RemoteCommandExecute(host, command, 10)
 	if err != nil {
-		return logger.Err(fmt.Errorf("Unable to replace brick %v:%v with %v:%v for volume %v", oldBrick.Host, oldBrick.Path, newBrick.Host, newBrick.Path, volume))
+		return s.Logger().Err(fmt.Errorf("Unable to replace brick %v:%v with %v:%v for volume %v", oldBrick.Host, oldBrick.Path, newBrick.Host, newBrick.Path, volume))
 	}
 
 	return nil
@@ -351,14 +480,14 @@ func (s *CmdExecutor) VolumeSnapshot(host string, vsr *executors.VolumeSnapshotR
 	if err != nil {
 		return nil, fmt.Errorf("Unable to parse output of creating snapshot of volume %v: %v", vsr.Volume, err)
 	}
-	logger.Debug("snapCreate: %+v\n", snapCreate)
+	s.Logger().Debug("snapCreate: %+v\n", snapCreate)
 
 	if snapCreate.OpRet != 0 {
 		return nil, fmt.Errorf("Failed to create snapshot of volume %v: %v", vsr.Volume, snapCreate.OpErrStr)
 	}
 
 	snap := &snapCreate.SnapCreate.Snapshot
-	logger.Debug("snapshot: %+v\n", snap)
+	s.Logger().Debug("snapshot: %+v\n", snap)
 
 	return snap, nil
 }
@@ -388,6 +517,39 @@ func (s *CmdExecutor) HealInfo(host string, volume string) (*executors.HealInfo,
 	if err != nil {
 		return nil, fmt.Errorf("Unable to determine heal info of volume : %v", volume)
 	}
-	logger.Debug("%+v\n", healInfo)
+	s.Logger().Debug("%+v\n", healInfo)
 	return &healInfo.HealInfo, nil
 }
+
+func (s *CmdExecutor) VolumeStatus(host string, volume string) (*executors.VolumeStatus, error) {
+
+	godbc.Require(volume != "")
+	godbc.Require(host != "")
+
+	type CliOutput struct {
+		OpRet     int    `xml:"opRet"`
+		OpErrno   int    `xml:"opErrno"`
+		OpErrStr  string `xml:"opErrstr"`
+		VolStatus struct {
+			Volumes struct {
+				VolumeList []executors.VolumeStatus `xml:"volume"`
+			} `xml:"volumes"`
+		} `xml:"volStatus"`
+	}
+
+	command := []string{
+		fmt.Sprintf("gluster --mode=script volume status %v --xml", volume),
+	}
+
+	output, err := s.RemoteExecutor.RemoteCommandExecute(host, command, 10)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get status of volume : %v", volume)
+	}
+	var volStatus CliOutput
+	err = xml.Unmarshal([]byte(output[0]), &volStatus)
+	if err != nil || len(volStatus.VolStatus.Volumes.VolumeList) == 0 {
+		return nil, fmt.Errorf("Unable to determine status of volume : %v", volume)
+	}
+	s.Logger().Debug("%+v\n", volStatus)
+	return &volStatus.VolStatus.Volumes.VolumeList[0], nil
+}
@@ -0,0 +1,248 @@
+//
+// Copyright (c) 2018 The heketi Authors
+//
+// This file is licensed to you under your choice of the GNU Lesser
+// General Public License, version 3 or any later version (LGPLv3 or
+// later), or the GNU General Public License, version 2 (GPLv2), in all
+// cases as published by the Free Software Foundation.
+//
+
+package cmdexec
+
+import (
+	"testing"
+
+	"github.com/heketi/heketi/executors"
+	"github.com/heketi/tests"
+)
+
+func TestSshExecVolumeStatus(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	xml := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cliOutput>
+  <opRet>0</opRet>
+  <opErrno>0</opErrno>
+  <opErrstr/>
+  <volStatus>
+    <volumes>
+      <volume>
+        <volName>vol_abc</volName>
+        <nodeCount>3</nodeCount>
+        <node>
+          <hostname>192.168.10.100</hostname>
+          <path>/var/lib/heketi/mounts/vg_1/brick_1/brick</path>
+          <peerid>8cc4a3a4-c5b5-4e5f-9d7a-2d3f3e56a6a1</peerid>
+          <status>1</status>
+          <port>49152</port>
+          <ports>
+            <tcp>49152</tcp>
+            <rdma>N/A</rdma>
+          </ports>
+          <pid>1234</pid>
+        </node>
+        <node>
+          <hostname>192.168.10.101</hostname>
+          <path>/var/lib/heketi/mounts/vg_2/brick_2/brick</path>
+          <peerid>6a1f3d7e-4dbe-4b61-bd42-52b3b96b7f7c</peerid>
+          <status>0</status>
+          <port>N/A</port>
+          <ports>
+            <tcp>N/A</tcp>
+            <rdma>N/A</rdma>
+          </ports>
+          <pid>-1</pid>
+        </node>
+        <node>
+          <hostname>Self-heal Daemon</hostname>
+          <path>localhost</path>
+          <peerid>8cc4a3a4-c5b5-4e5f-9d7a-2d3f3e56a6a1</peerid>
+          <status>1</status>
+          <port>N/A</port>
+          <ports>
+            <tcp>N/A</tcp>
+            <rdma>N/A</rdma>
+          </ports>
+          <pid>2345</pid>
+        </node>
+        <tasks/>
+      </volume>
+    </volumes>
+  </volStatus>
+</cliOutput>`
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		tests.Assert(t, len(commands) == 1)
+		tests.Assert(t, commands[0] ==
+			"gluster --mode=script volume status vol_abc --xml", commands)
+		return []string{xml}, nil
+	}
+
+	vs, err := s.VolumeStatus("host", "vol_abc")
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, vs.VolumeName == "vol_abc", vs.VolumeName)
+	tests.Assert(t, len(vs.Nodes) == 3, vs.Nodes)
+	tests.Assert(t, vs.Nodes[0].Status == 1, vs.Nodes[0])
+	tests.Assert(t, vs.Nodes[0].Port == "49152", vs.Nodes[0])
+	tests.Assert(t, vs.Nodes[0].Pid == 1234, vs.Nodes[0])
+	tests.Assert(t, vs.Nodes[1].Status == 0, vs.Nodes[1])
+	tests.Assert(t, vs.Nodes[1].Port == "N/A", vs.Nodes[1])
+	tests.Assert(t, vs.Nodes[2].Path == "localhost", vs.Nodes[2])
+
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		return []string{"Volume vol_abc does not exist"}, nil
+	}
+	_, err = s.VolumeStatus("host", "vol_abc")
+	tests.Assert(t, err != nil)
+}
+
+func TestSshExecVolumeConvert(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	var executed [][]string
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		executed = append(executed, commands)
+		return nil, nil
+	}
+
+	// replica 2 to replica 3
+	vcr := &executors.VolumeConvertRequest{
+		Name:    "vol_abc",
+		Replica: 3,
+		AddBricks: []executors.BrickInfo{
+			{Host: "h3", Path: "/b3"},
+			{Host: "h1", Path: "/b6"},
+		},
+	}
+	_, err = s.VolumeConvert("host", vcr)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(executed) == 2, executed)
+	tests.Assert(t, len(executed[0]) == 1, executed[0])
+	tests.Assert(t, executed[0][0] == "gluster --mode=script volume "+
+		"add-brick vol_abc replica 3 h3:/b3 h1:/b6 ", executed[0])
+	tests.Assert(t, executed[1][0] == "gluster --mode=script volume "+
+		"heal vol_abc full", executed[1])
+
+	// replica 3 to arbiter, the bricks are removed and added by
+	// separate requests
+	executed = nil
+	vcr.Arbiter = true
+	vcr.AddBricks = nil
+	vcr.RemoveBricks = []executors.BrickInfo{
+		{Host: "h3", Path: "/b3"},
+		{Host: "h1", Path: "/b6"},
+	}
+	_, err = s.VolumeConvert("host", vcr)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(executed) == 1, executed)
+	tests.Assert(t, len(executed[0]) == 1, executed[0])
+	tests.Assert(t, executed[0][0] == "gluster --mode=script volume "+
+		"remove-brick vol_abc replica 2 h3:/b3 h1:/b6 force", executed[0])
+
+	executed = nil
+	vcr.RemoveBricks = nil
+	vcr.AddBricks = []executors.BrickInfo{
+		{Host: "h3", Path: "/b7"},
+		{Host: "h1", Path: "/b8"},
+	}
+	_, err = s.VolumeConvert("host", vcr)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(executed) == 2, executed)
+	tests.Assert(t, len(executed[0]) == 1, executed[0])
+	tests.Assert(t, executed[0][0] == "gluster --mode=script volume "+
+		"add-brick vol_abc replica 3 arbiter 1 h3:/b7 h1:/b8 ", executed[0])
+	tests.Assert(t, executed[1][0] == "gluster --mode=script volume "+
+		"heal vol_abc full", executed[1])
+}
+
+func TestSshExecVolumeReadOnly(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	var executed []string
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		executed = append(executed, commands...)
+		return nil, nil
+	}
+
+	err = s.VolumeReadOnly("host", "vol_abc", true)
+	tests.Assert(t, err == nil, err)
+	err = s.VolumeReadOnly("host", "vol_abc", false)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, len(executed) == 2, executed)
+	tests.Assert(t, executed[0] == "gluster --mode=script volume set "+
+		"vol_abc features.read-only on", executed[0])
+	tests.Assert(t, executed[1] == "gluster --mode=script volume set "+
+		"vol_abc features.read-only off", executed[1])
+}
+
+func TestSshExecVolumeSync(t *testing.T) {
+	f := NewCommandFaker()
+	s, err := NewFakeExecutor(f)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s != nil)
+
+	var executed [][]string
+	var timeouts []int
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+
+		tests.Assert(t, host == "host:22", host)
+		executed = append(executed, commands)
+		timeouts = append(timeouts, timeoutMinutes)
+		return nil, nil
+	}
+
+	err = s.VolumeSync("host", &executors.VolumeSyncRequest{
+		Name:       "vol_abc",
+		SourceHost: "h1",
+		TargetHost: "h2",
+	})
+	tests.Assert(t, err == nil, err)
+	// stale mounts are unmounted before and after the copy
+	tests.Assert(t, len(executed) == 5, executed)
+	tests.Assert(t, executed[0][0] == "umount /var/lib/heketi/sync/vol_abc/source",
+		executed[0])
+	tests.Assert(t, executed[1][0] == "umount /var/lib/heketi/sync/vol_abc/target",
+		executed[1])
+	sync := executed[2]
+	tests.Assert(t, len(sync) == 4, sync)
+	tests.Assert(t, sync[1] == "mount -t glusterfs h1:/vol_abc "+
+		"/var/lib/heketi/sync/vol_abc/source", sync)
+	tests.Assert(t, sync[2] == "mount -t glusterfs h2:/vol_abc "+
+		"/var/lib/heketi/sync/vol_abc/target", sync)
+	tests.Assert(t, sync[3] == "rsync -aHAX --delete "+
+		"/var/lib/heketi/sync/vol_abc/source/ "+
+		"/var/lib/heketi/sync/vol_abc/target/", sync)
+	tests.Assert(t, timeouts[2] == volumeSyncTimeout, timeouts)
+	tests.Assert(t, executed[3][0] == "umount /var/lib/heketi/sync/vol_abc/source",
+		executed[3])
+}
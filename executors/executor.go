@@ -9,7 +9,11 @@
 
 package executors
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+
+	"github.com/heketi/heketi/pkg/utils"
+)
 
 type Executor interface {
 	GlusterdCheck(host string) error
@@ -18,12 +22,28 @@ type Executor interface {
 	DeviceSetup(host, device, vgid string, destroy bool) (*DeviceInfo, error)
 	GetDeviceInfo(host, device, vgid string) (*DeviceInfo, error)
 	DeviceTeardown(host, device, vgid string) error
+	DeviceDiscover(host string) ([]DiscoveredDevice, error)
+	DeviceHealth(host, device, vgid string) (*DeviceHealth, error)
 	BrickCreate(host string, brick *BrickRequest) (*BrickInfo, error)
 	BrickDestroy(host string, brick *BrickRequest) (bool, error)
 	VolumeCreate(host string, volume *VolumeRequest) (*Volume, error)
 	VolumeDestroy(host string, volume string) error
 	VolumeDestroyCheck(host, volume string) error
 	VolumeExpand(host string, volume *VolumeRequest) (*Volume, error)
+	VolumeConvert(host string, vcr *VolumeConvertRequest) (*Volume, error)
+	VolumeSync(host string, vsr *VolumeSyncRequest) error
+	VolumeReadOnly(host string, volume string, readOnly bool) error
+	GeoReplicationSecret(host string) (string, error)
+	GeoReplicationAddSecret(host string, gr *GeoReplicationRequest, secret string) error
+	GeoReplicationCreate(host string, gr *GeoReplicationRequest) error
+	GeoReplicationAction(host string, gr *GeoReplicationRequest, action string) error
+	GeoReplicationStatus(host string, gr *GeoReplicationRequest) (*GeoReplicationStatus, error)
+	GeoReplicationDestroy(host string, gr *GeoReplicationRequest) error
+	VolumeQuotaEnable(host string, volume string) error
+	VolumeQuotaDisable(host string, volume string) error
+	VolumeQuotaSetLimit(host string, volume string, ql *QuotaLimitRequest) error
+	VolumeQuotaRemoveLimit(host string, volume string, path string) error
+	VolumeQuotaList(host string, volume string) ([]QuotaLimit, error)
 	VolumeReplaceBrick(host string, volume string, oldBrick *BrickInfo, newBrick *BrickInfo) error
 	VolumeInfo(host string, volume string) (*Volume, error)
 	VolumeClone(host string, vsr *VolumeCloneRequest) (*Volume, error)
@@ -32,11 +52,62 @@ type Executor interface {
 	SnapshotCloneBlockVolume(host string, scr *SnapshotCloneRequest) (*BlockVolumeInfo, error)
 	SnapshotDestroy(host string, snapshot string) error
 	HealInfo(host string, volume string) (*HealInfo, error)
+	VolumeStatus(host string, volume string) (*VolumeStatus, error)
 	SetLogLevel(level string)
 	BlockVolumeCreate(host string, blockVolume *BlockVolumeRequest) (*BlockVolumeInfo, error)
 	BlockVolumeDestroy(host string, blockHostingVolumeName string, blockVolumeName string) error
 }
 
+// LoggingExecutor is implemented by executors whose loggers can be
+// managed separately.
+type LoggingExecutor interface {
+	// Loggers returns the loggers of the executor keyed by name
+	Loggers() map[string]*utils.Logger
+}
+
+// RequestExecutor is implemented by executors which can tag the logs
+// of the commands they run with the id of the request they run them for.
+type RequestExecutor interface {
+	WithRequestId(id string) Executor
+	RequestId() string
+}
+
+// requestExecutor carries the request id for executors which do
+// not implement RequestExecutor.
+type requestExecutor struct {
+	Executor
+	id string
+}
+
+func (r *requestExecutor) WithRequestId(id string) Executor {
+	return WithRequestId(r.Executor, id)
+}
+
+func (r *requestExecutor) RequestId() string {
+	return r.id
+}
+
+// WithRequestId returns an executor running the commands of e on
+// behalf of the request id.
+func WithRequestId(e Executor, id string) Executor {
+	if id == "" {
+		return e
+	}
+	if re, ok := e.(RequestExecutor); ok {
+		return re.WithRequestId(id)
+	}
+	return &requestExecutor{Executor: e, id: id}
+}
+
+// RequestId returns the id of the request the executor runs commands
+// for, if any.
+func RequestId(e Executor) string {
+	if re, ok := e.(RequestExecutor); ok {
+		return re.RequestId()
+	}
+	return ""
+}
+
 // Enumerate durability types
 type DurabilityType int
 
@@ -51,6 +122,42 @@ type DeviceInfo struct {
 	// Size in KB
 	Size       uint64
 	ExtentSize uint64
+
+	// Persistent identifiers of the disk currently behind the
+	// device path. Any of them may be empty if the host does
+	// not provide it.
+	ById   string
+	Wwn    string
+	Serial string
+	PvUuid string
+}
+
+// Describes an unused block device found on a host
+type DiscoveredDevice struct {
+	Name string
+	// Size in KB
+	Size   uint64
+	Model  string
+	Serial string
+}
+
+// Describes the health of a device and its volume group
+type DeviceHealth struct {
+	TotalExtents uint64
+	FreeExtents  uint64
+	ThinPools    []ThinPoolUsage
+
+	// SMART overall health, empty if it could not be read
+	SmartPassed *bool
+}
+
+// Usage of a thin pool in percent
+type ThinPoolUsage struct {
+	Name            string
+	DataPercent     float64
+	MetadataPercent float64
+	// Size of the pool in KB
+	Size uint64
 }
 
 // Brick description
@@ -63,6 +170,9 @@ type BrickRequest struct {
 	Gid              int64
 	// Path is the brick mountpoint (named Path for symmetry with BrickInfo)
 	Path string
+	// Autoextend is set if the thin pool is smaller than the brick and
+	// has to be extended by LVM as data is written to it
+	Autoextend bool
 }
 
 // Returns information about the location of the brick
@@ -86,6 +196,28 @@ type VolumeRequest struct {
 	Arbiter bool
 }
 
+// VolumeConvertRequest changes the replica count of a volume. Either
+// the bricks to remove are taken out of the volume, leaving it with
+// one brick less than Replica in each set, or one of the bricks to add
+// is added to each brick set. The two steps are separate requests so
+// that the caller knows which of them completed.
+type VolumeConvertRequest struct {
+	Name         string
+	Replica      int
+	Arbiter      bool
+	RemoveBricks []BrickInfo
+	AddBricks    []BrickInfo
+}
+
+// VolumeSyncRequest copies the files of a volume to a volume with
+// the same name on another cluster. Files missing from the source
+// volume are deleted from the target volume.
+type VolumeSyncRequest struct {
+	Name       string
+	SourceHost string
+	TargetHost string
+}
+
 type VolumeCloneRequest struct {
 	Volume string
 	Clone  string
@@ -216,6 +348,71 @@ type HealInfo struct {
 	Bricks  HealInfoBricks `xml:"bricks"`
 }
 
+// Status of a brick or of a daemon such as the self-heal daemon,
+// daemons have the daemon name as hostname and localhost as path
+type BrickStatus struct {
+	Hostname string `xml:"hostname"`
+	Path     string `xml:"path"`
+	PeerId   string `xml:"peerid"`
+	Status   int    `xml:"status"`
+	Port     string `xml:"port"`
+	Pid      int    `xml:"pid"`
+}
+
+// QuotaLimitRequest sets the limit of a directory of a volume
+type QuotaLimitRequest struct {
+	Path string
+	// Hard limit in bytes
+	HardLimit uint64
+	// Soft limit in percent of the hard limit, the default of the
+	// volume is kept if 0
+	SoftLimitPercent int
+}
+
+// QuotaLimit is the limit of a directory of a volume and its usage,
+// sizes are in bytes
+type QuotaLimit struct {
+	Path              string
+	HardLimit         uint64
+	SoftLimitPercent  int
+	Used              uint64
+	Available         uint64
+	SoftLimitExceeded bool
+	HardLimitExceeded bool
+}
+
+// GeoReplicationRequest identifies the geo-replication session of
+// the master volume with the slave volume, reached through the slave
+// host.
+type GeoReplicationRequest struct {
+	MasterVolume string
+	SlaveHost    string
+	SlaveVolume  string
+	Force        bool
+}
+
+// Status of the worker replicating a brick of the master volume
+type GeoReplicationPair struct {
+	MasterNode  string `xml:"master_node"`
+	MasterBrick string `xml:"master_brick"`
+	SlaveUser   string `xml:"slave_user"`
+	Slave       string `xml:"slave"`
+	SlaveNode   string `xml:"slave_node"`
+	Status      string `xml:"status"`
+	CrawlStatus string `xml:"crawl_status"`
+	LastSynced  string `xml:"last_synced"`
+}
+
+type GeoReplicationStatus struct {
+	Pairs []GeoReplicationPair
+}
+
+type VolumeStatus struct {
+	XMLName    xml.Name      `xml:"volume"`
+	VolumeName string        `xml:"volName"`
+	Nodes      []BrickStatus `xml:"node"`
+}
+
 type BlockVolumeRequest struct {
 	Name              string
 	Size              int
@@ -15,6 +15,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	restclient "k8s.io/client-go/rest"
@@ -24,6 +25,7 @@ import (
 	"k8s.io/kubernetes/pkg/client/unversioned/remotecommand"
 	kubeletcmd "k8s.io/kubernetes/pkg/kubelet/server/remotecommand"
 
+	"github.com/heketi/heketi/executors"
 	"github.com/heketi/heketi/executors/cmdexec"
 	"github.com/heketi/heketi/pkg/kubernetes"
 	"github.com/heketi/heketi/pkg/utils"
@@ -154,6 +156,37 @@ func NewKubeExecutor(config *KubeConfig) (*KubeExecutor, error) {
 	return k, nil
 }
 
+// WithRequestId returns an executor running commands like k on
+// behalf of the request id.
+func (k *KubeExecutor) WithRequestId(id string) executors.Executor {
+	r := &KubeExecutor{
+		config:     k.config,
+		namespace:  k.namespace,
+		kube:       k.kube,
+		rest:       k.rest,
+		kubeConfig: k.kubeConfig,
+	}
+	k.InitRequest(&r.CmdExecutor, r, id)
+	return r
+}
+
+// Loggers returns the loggers of the commands and of their transport
+// to the pods.
+func (k *KubeExecutor) Loggers() map[string]*utils.Logger {
+	loggers := k.CmdExecutor.Loggers()
+	loggers["kubeexec"] = logger
+	return loggers
+}
+
+// requestLogger returns the logger adding the id of the request the
+// commands are run for.
+func (k *KubeExecutor) requestLogger() *utils.Logger {
+	if id := k.RequestId(); id != "" {
+		return logger.With("request_id", id)
+	}
+	return logger
+}
+
 func (k *KubeExecutor) RemoteCommandExecute(host string,
 	commands []string,
 	timeoutMinutes int) ([]string, error) {
@@ -163,6 +196,7 @@ func (k *KubeExecutor) RemoteCommandExecute(host string,
 	defer k.FreeConnection(host)
 
 	// Execute
+	defer cmdexec.ObserveCommandDuration(host, time.Now())
 	return k.ConnectAndExec(host,
 		"pods",
 		commands,
@@ -195,7 +229,7 @@ func (k *KubeExecutor) ConnectAndExec(host, resource string,
 	// Get container name
 	podSpec, err := k.kube.Core().Pods(k.namespace).Get(podName, v1.GetOptions{})
 	if err != nil {
-		return nil, logger.LogError("Unable to get pod spec for %v: %v",
+		return nil, k.requestLogger().LogError("Unable to get pod spec for %v: %v",
 			podName, err)
 	}
 	containerName := podSpec.Spec.Containers[0].Name
@@ -224,7 +258,7 @@ func (k *KubeExecutor) ConnectAndExec(host, resource string,
 		// Create SPDY connection
 		exec, err := remotecommand.NewExecutor(k.kubeConfig, "POST", req.URL())
 		if err != nil {
-			logger.Err(err)
+			k.requestLogger().Err(err)
 			return nil, fmt.Errorf("Unable to setup a session with %v", podName)
 		}
 
@@ -239,11 +273,11 @@ func (k *KubeExecutor) ConnectAndExec(host, resource string,
 			Stderr:             &berr,
 		})
 		if err != nil {
-			logger.LogError("Failed to run command [%v] on %v: Err[%v]: Stdout [%v]: Stderr [%v]",
+			k.requestLogger().LogError("Failed to run command [%v] on %v: Err[%v]: Stdout [%v]: Stderr [%v]",
 				command, podName, err, b.String(), berr.String())
 			return nil, fmt.Errorf("Unable to execute command on %v: %v", podName, berr.String())
 		}
-		logger.Debug("Host: %v Pod: %v Command: %v\nResult: %v", host, podName, command, b.String())
+		k.requestLogger().Debug("Host: %v Pod: %v Command: %v\nResult: %v", host, podName, command, b.String())
 		buffers[index] = b.String()
 
 	}
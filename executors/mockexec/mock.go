@@ -20,10 +20,26 @@ type MockExecutor struct {
 	MockPeerDetach               func(exec_host, newnode string) error
 	MockDeviceSetup              func(host, device, vgid string, destroy bool) (*executors.DeviceInfo, error)
 	MockDeviceTeardown           func(host, device, vgid string) error
+	MockDeviceDiscover           func(host string) ([]executors.DiscoveredDevice, error)
+	MockDeviceHealth             func(host, device, vgid string) (*executors.DeviceHealth, error)
 	MockBrickCreate              func(host string, brick *executors.BrickRequest) (*executors.BrickInfo, error)
 	MockBrickDestroy             func(host string, brick *executors.BrickRequest) (bool, error)
 	MockVolumeCreate             func(host string, volume *executors.VolumeRequest) (*executors.Volume, error)
 	MockVolumeExpand             func(host string, volume *executors.VolumeRequest) (*executors.Volume, error)
+	MockVolumeConvert            func(host string, vcr *executors.VolumeConvertRequest) (*executors.Volume, error)
+	MockVolumeSync               func(host string, vsr *executors.VolumeSyncRequest) error
+	MockVolumeReadOnly           func(host string, volume string, readOnly bool) error
+	MockGeoReplicationSecret     func(host string) (string, error)
+	MockGeoReplicationAddSecret  func(host string, gr *executors.GeoReplicationRequest, secret string) error
+	MockGeoReplicationCreate     func(host string, gr *executors.GeoReplicationRequest) error
+	MockGeoReplicationAction     func(host string, gr *executors.GeoReplicationRequest, action string) error
+	MockGeoReplicationStatus     func(host string, gr *executors.GeoReplicationRequest) (*executors.GeoReplicationStatus, error)
+	MockGeoReplicationDestroy    func(host string, gr *executors.GeoReplicationRequest) error
+	MockVolumeQuotaEnable        func(host string, volume string) error
+	MockVolumeQuotaDisable       func(host string, volume string) error
+	MockVolumeQuotaSetLimit      func(host string, volume string, ql *executors.QuotaLimitRequest) error
+	MockVolumeQuotaRemoveLimit   func(host string, volume string, path string) error
+	MockVolumeQuotaList          func(host string, volume string) ([]executors.QuotaLimit, error)
 	MockVolumeDestroy            func(host string, volume string) error
 	MockVolumeDestroyCheck       func(host, volume string) error
 	MockVolumeReplaceBrick       func(host string, volume string, oldBrick *executors.BrickInfo, newBrick *executors.BrickInfo) error
@@ -34,6 +50,7 @@ type MockExecutor struct {
 	MockSnapshotCloneBlockVolume func(host string, volume *executors.SnapshotCloneRequest) (*executors.BlockVolumeInfo, error)
 	MockSnapshotDestroy          func(host string, snapshot string) error
 	MockHealInfo                 func(host string, volume string) (*executors.HealInfo, error)
+	MockVolumeStatus             func(host string, volume string) (*executors.VolumeStatus, error)
 	MockBlockVolumeCreate        func(host string, blockVolume *executors.BlockVolumeRequest) (*executors.BlockVolumeInfo, error)
 	MockBlockVolumeDestroy       func(host string, blockHostingVolumeName string, blockVolumeName string) error
 }
@@ -64,6 +81,18 @@ func NewMockExecutor() (*MockExecutor, error) {
 		return nil
 	}
 
+	m.MockDeviceDiscover = func(host string) ([]executors.DiscoveredDevice, error) {
+		return []executors.DiscoveredDevice{}, nil
+	}
+
+	m.MockDeviceHealth = func(host, device, vgid string) (*executors.DeviceHealth, error) {
+		return &executors.DeviceHealth{
+			TotalExtents: 128000,
+			FreeExtents:  128000,
+			ThinPools:    []executors.ThinPoolUsage{},
+		}, nil
+	}
+
 	m.MockBrickCreate = func(host string, brick *executors.BrickRequest) (*executors.BrickInfo, error) {
 		b := &executors.BrickInfo{
 			Path: "/mockpath",
@@ -84,6 +113,75 @@ func NewMockExecutor() (*MockExecutor, error) {
 		return &executors.Volume{}, nil
 	}
 
+	m.MockVolumeConvert = func(host string, vcr *executors.VolumeConvertRequest) (*executors.Volume, error) {
+		return &executors.Volume{}, nil
+	}
+
+	m.MockVolumeSync = func(host string, vsr *executors.VolumeSyncRequest) error {
+		return nil
+	}
+
+	m.MockVolumeReadOnly = func(host string, volume string, readOnly bool) error {
+		return nil
+	}
+
+	m.MockGeoReplicationSecret = func(host string) (string, error) {
+		return "command=\"/usr/libexec/glusterfs/gsyncd\" ssh-rsa AAAA root@" + host, nil
+	}
+
+	m.MockGeoReplicationAddSecret = func(host string, gr *executors.GeoReplicationRequest, secret string) error {
+		return nil
+	}
+
+	m.MockGeoReplicationCreate = func(host string, gr *executors.GeoReplicationRequest) error {
+		return nil
+	}
+
+	m.MockGeoReplicationAction = func(host string, gr *executors.GeoReplicationRequest, action string) error {
+		return nil
+	}
+
+	m.MockGeoReplicationStatus = func(host string, gr *executors.GeoReplicationRequest) (*executors.GeoReplicationStatus, error) {
+		return &executors.GeoReplicationStatus{
+			Pairs: []executors.GeoReplicationPair{
+				executors.GeoReplicationPair{
+					MasterNode:  host,
+					MasterBrick: "/brick",
+					SlaveUser:   "root",
+					Slave:       gr.SlaveHost + "::" + gr.SlaveVolume,
+					SlaveNode:   gr.SlaveHost,
+					Status:      "Active",
+					CrawlStatus: "Changelog Crawl",
+					LastSynced:  "N/A",
+				},
+			},
+		}, nil
+	}
+
+	m.MockGeoReplicationDestroy = func(host string, gr *executors.GeoReplicationRequest) error {
+		return nil
+	}
+
+	m.MockVolumeQuotaEnable = func(host string, volume string) error {
+		return nil
+	}
+
+	m.MockVolumeQuotaDisable = func(host string, volume string) error {
+		return nil
+	}
+
+	m.MockVolumeQuotaSetLimit = func(host string, volume string, ql *executors.QuotaLimitRequest) error {
+		return nil
+	}
+
+	m.MockVolumeQuotaRemoveLimit = func(host string, volume string, path string) error {
+		return nil
+	}
+
+	m.MockVolumeQuotaList = func(host string, volume string) ([]executors.QuotaLimit, error) {
+		return []executors.QuotaLimit{}, nil
+	}
+
 	m.MockVolumeDestroy = func(host string, volume string) error {
 		return nil
 	}
@@ -157,6 +255,10 @@ func NewMockExecutor() (*MockExecutor, error) {
 		return &executors.HealInfo{}, nil
 	}
 
+	m.MockVolumeStatus = func(host string, volume string) (*executors.VolumeStatus, error) {
+		return &executors.VolumeStatus{VolumeName: volume}, nil
+	}
+
 	m.MockBlockVolumeCreate = func(host string, blockVolume *executors.BlockVolumeRequest) (*executors.BlockVolumeInfo, error) {
 		var blockVolumeInfo executors.BlockVolumeInfo
 		blockVolumeInfo.BlockHosts = blockVolume.BlockHosts
@@ -209,6 +311,14 @@ func (m *MockExecutor) DeviceTeardown(host, device, vgid string) error {
 	return m.MockDeviceTeardown(host, device, vgid)
 }
 
+func (m *MockExecutor) DeviceDiscover(host string) ([]executors.DiscoveredDevice, error) {
+	return m.MockDeviceDiscover(host)
+}
+
+func (m *MockExecutor) DeviceHealth(host, device, vgid string) (*executors.DeviceHealth, error) {
+	return m.MockDeviceHealth(host, device, vgid)
+}
+
 func (m *MockExecutor) BrickCreate(host string, brick *executors.BrickRequest) (*executors.BrickInfo, error) {
 	return m.MockBrickCreate(host, brick)
 }
@@ -225,6 +335,62 @@ func (m *MockExecutor) VolumeExpand(host string, volume *executors.VolumeRequest
 	return m.MockVolumeExpand(host, volume)
 }
 
+func (m *MockExecutor) VolumeConvert(host string, vcr *executors.VolumeConvertRequest) (*executors.Volume, error) {
+	return m.MockVolumeConvert(host, vcr)
+}
+
+func (m *MockExecutor) VolumeSync(host string, vsr *executors.VolumeSyncRequest) error {
+	return m.MockVolumeSync(host, vsr)
+}
+
+func (m *MockExecutor) VolumeReadOnly(host string, volume string, readOnly bool) error {
+	return m.MockVolumeReadOnly(host, volume, readOnly)
+}
+
+func (m *MockExecutor) GeoReplicationSecret(host string) (string, error) {
+	return m.MockGeoReplicationSecret(host)
+}
+
+func (m *MockExecutor) GeoReplicationAddSecret(host string, gr *executors.GeoReplicationRequest, secret string) error {
+	return m.MockGeoReplicationAddSecret(host, gr, secret)
+}
+
+func (m *MockExecutor) GeoReplicationCreate(host string, gr *executors.GeoReplicationRequest) error {
+	return m.MockGeoReplicationCreate(host, gr)
+}
+
+func (m *MockExecutor) GeoReplicationAction(host string, gr *executors.GeoReplicationRequest, action string) error {
+	return m.MockGeoReplicationAction(host, gr, action)
+}
+
+func (m *MockExecutor) GeoReplicationStatus(host string, gr *executors.GeoReplicationRequest) (*executors.GeoReplicationStatus, error) {
+	return m.MockGeoReplicationStatus(host, gr)
+}
+
+func (m *MockExecutor) GeoReplicationDestroy(host string, gr *executors.GeoReplicationRequest) error {
+	return m.MockGeoReplicationDestroy(host, gr)
+}
+
+func (m *MockExecutor) VolumeQuotaEnable(host string, volume string) error {
+	return m.MockVolumeQuotaEnable(host, volume)
+}
+
+func (m *MockExecutor) VolumeQuotaDisable(host string, volume string) error {
+	return m.MockVolumeQuotaDisable(host, volume)
+}
+
+func (m *MockExecutor) VolumeQuotaSetLimit(host string, volume string, ql *executors.QuotaLimitRequest) error {
+	return m.MockVolumeQuotaSetLimit(host, volume, ql)
+}
+
+func (m *MockExecutor) VolumeQuotaRemoveLimit(host string, volume string, path string) error {
+	return m.MockVolumeQuotaRemoveLimit(host, volume, path)
+}
+
+func (m *MockExecutor) VolumeQuotaList(host string, volume string) ([]executors.QuotaLimit, error) {
+	return m.MockVolumeQuotaList(host, volume)
+}
+
 func (m *MockExecutor) VolumeDestroy(host string, volume string) error {
 	return m.MockVolumeDestroy(host, volume)
 }
@@ -265,6 +431,10 @@ func (m *MockExecutor) HealInfo(host string, volume string) (*executors.HealInfo
 	return m.MockHealInfo(host, volume)
 }
 
+func (m *MockExecutor) VolumeStatus(host string, volume string) (*executors.VolumeStatus, error) {
+	return m.MockVolumeStatus(host, volume)
+}
+
 func (m *MockExecutor) BlockVolumeCreate(host string, blockVolume *executors.BlockVolumeRequest) (*executors.BlockVolumeInfo, error) {
 	return m.MockBlockVolumeCreate(host, blockVolume)
 }
@@ -14,7 +14,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
+	"github.com/heketi/heketi/executors"
 	"github.com/heketi/heketi/executors/cmdexec"
 	"github.com/heketi/heketi/pkg/utils"
 	"github.com/heketi/heketi/pkg/utils/ssh"
@@ -135,6 +137,23 @@ func NewSshExecutor(config *SshConfig) (*SshExecutor, error) {
 	return s, nil
 }
 
+// WithRequestId returns an executor running commands like s on
+// behalf of the request id.
+func (s *SshExecutor) WithRequestId(id string) executors.Executor {
+	r := &SshExecutor{
+		private_keyfile: s.private_keyfile,
+		user:            s.user,
+		exec:            s.exec,
+		config:          s.config,
+		port:            s.port,
+	}
+	s.InitRequest(&r.CmdExecutor, r, id)
+	if e, ok := s.exec.(*ssh.SshExec); ok {
+		r.exec = e.WithLogger(r.Logger())
+	}
+	return r
+}
+
 func (s *SshExecutor) RemoteCommandExecute(host string,
 	commands []string,
 	timeoutMinutes int) ([]string, error) {
@@ -144,6 +163,7 @@ func (s *SshExecutor) RemoteCommandExecute(host string,
 	defer s.FreeConnection(host)
 
 	// Execute
+	defer cmdexec.ObserveCommandDuration(host, time.Now())
 	return s.exec.ConnectAndExec(host+":"+s.port, commands, timeoutMinutes, s.config.Sudo)
 }
 
@@ -13,6 +13,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/heketi/heketi/executors"
 	"github.com/heketi/heketi/executors/cmdexec"
 	"github.com/heketi/heketi/pkg/utils"
 	"github.com/heketi/tests"
@@ -204,3 +205,54 @@ func TestSshExecutorEnvVariables(t *testing.T) {
 	tests.Assert(t, s.exec != nil)
 
 }
+
+func TestSshExecWithRequestId(t *testing.T) {
+
+	f := NewFakeSsh()
+	defer tests.Patch(&sshNew,
+		func(logger *utils.Logger, user string, file string) (Ssher, error) {
+			return f, nil
+		}).Restore()
+
+	config := &SshConfig{
+		PrivateKeyFile: "xkeyfile",
+		User:           "xuser",
+		Port:           "100",
+		CmdConfig: cmdexec.CmdConfig{
+			Fstab: "xfstab",
+		},
+	}
+
+	s, err := NewSshExecutor(config)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s.RequestId() == "")
+
+	e := executors.WithRequestId(s, "abc")
+	r, ok := e.(*SshExecutor)
+	tests.Assert(t, ok)
+	tests.Assert(t, r != s)
+	tests.Assert(t, executors.RequestId(r) == "abc")
+	tests.Assert(t, r.Fstab == config.Fstab)
+	tests.Assert(t, r.port == config.Port)
+	tests.Assert(t, executors.RequestId(s) == "")
+
+	// commands are run through the executor of the request
+	var hosts []string
+	f.FakeConnectAndExec = func(host string,
+		commands []string,
+		timeoutMinutes int,
+		useSudo bool) ([]string, error) {
+		hosts = append(hosts, host)
+		return []string{""}, nil
+	}
+	err = r.GlusterdCheck("host1")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(hosts) == 1 && hosts[0] == "host1:100", hosts)
+
+	// connections are throttled together
+	r.AccessConnection("host1")
+	_, ok = s.Throttlemap["host1"]
+	tests.Assert(t, ok)
+	tests.Assert(t, len(r.Throttlemap) == 0)
+	r.FreeConnection("host1")
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/heketi/heketi/apps/glusterfs"
 	"github.com/heketi/heketi/middleware"
+	"github.com/heketi/heketi/pkg/utils"
 	"github.com/spf13/cobra"
 	"github.com/urfave/negroni"
 
@@ -34,6 +35,7 @@ type Config struct {
 	EnableTls            bool                     `json:"enable_tls"`
 	CertFile             string                   `json:"cert_file"`
 	KeyFile              string                   `json:"key_file"`
+	EnableMetrics        bool                     `json:"enable_metrics"`
 }
 
 var (
@@ -269,6 +271,11 @@ func setWithEnvVariables(options *Config) {
 	if "" != env {
 		options.BackupDbToKubeSecret = true
 	}
+
+	env = os.Getenv("HEKETI_ENABLE_METRICS")
+	if "" != env {
+		options.EnableMetrics = true
+	}
 }
 
 func setupApp(fp *os.File) (a *glusterfs.App) {
@@ -327,14 +334,18 @@ func main() {
 	// Substitute values using any set environment variables
 	setWithEnvVariables(&options)
 
-	// Use negroni to add middleware.  Here we add two
-	// middlewares: Recovery and Logger, which come with
-	// Negroni
-	n := negroni.New(negroni.NewRecovery(), negroni.NewLogger())
-
 	// Setup a new GlusterFS application
 	app := setupApp(fp)
 
+	// Use negroni to add middleware.  Here we add two
+	// middlewares: Recovery and Logger, which come with
+	// Negroni. The Logger writes text, so it is left out
+	// when the application logs JSON.
+	n := negroni.New(negroni.NewRecovery())
+	if utils.GetLogFormat() == utils.LOG_FORMAT_TEXT {
+		n.Use(negroni.NewLogger())
+	}
+
 	// Add /hello router
 	router := mux.NewRouter()
 	router.Methods("GET").Path("/hello").Name("Hello").HandlerFunc(
@@ -344,6 +355,16 @@ func main() {
 			fmt.Fprint(w, "Hello from Heketi")
 		})
 
+	// Add /metrics router if enabled. Like /hello it does not
+	// require authentication so that it can be scraped by
+	// Prometheus, but it exposes the ids of the topology so it
+	// is only served on request.
+	if options.EnableMetrics {
+		router.Methods("GET").Path("/metrics").Name("Metrics").Handler(
+			app.MetricsHandler())
+		fmt.Println("Metrics enabled")
+	}
+
 	// Create a router and do not allow any routes
 	// unless defined.
 	heketiRouter := mux.NewRouter().StrictSlash(true)
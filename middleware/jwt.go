@@ -10,6 +10,7 @@
 package middleware
 
 import (
+	stdcontext "context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -26,9 +27,12 @@ var (
 	required_claims = []string{"iss", "iat", "exp"}
 )
 
+type tenantKey struct{}
+
 type JwtAuth struct {
-	adminKey []byte
-	userKey  []byte
+	adminKey    []byte
+	userKey     []byte
+	tenantClaim string
 }
 
 type Issuer struct {
@@ -38,6 +42,10 @@ type Issuer struct {
 type JwtAuthConfig struct {
 	Admin Issuer `json:"admin"`
 	User  Issuer `json:"user"`
+
+	// Claim naming the tenant of the requests, the issuer is the
+	// tenant if empty or if the token does not have the claim
+	TenantClaim string `json:"tenant_claim"`
 }
 
 func generate_qsh(r *http.Request) string {
@@ -58,6 +66,7 @@ func NewJwtAuth(config *JwtAuthConfig) *JwtAuth {
 	j := &JwtAuth{}
 	j.adminKey = []byte(config.Admin.PrivateKey)
 	j.userKey = []byte(config.User.PrivateKey)
+	j.tenantClaim = config.TenantClaim
 
 	return j
 }
@@ -139,8 +148,33 @@ func (j *JwtAuth) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.Ha
 	}
 
 	// Store token in request for other middleware to access
+	r = WithTenant(r, j.tenant(claims))
 	context.Set(r, "jwt", token)
 
 	// Everything passes call next middleware
 	next(w, r)
 }
+
+// WithTenant returns the request tagged with the tenant it is made for
+func WithTenant(r *http.Request, tenant string) *http.Request {
+	return r.WithContext(
+		stdcontext.WithValue(r.Context(), tenantKey{}, tenant))
+}
+
+// Tenant returns the tenant the request is made for, empty if the
+// request did not go through the JWT middleware.
+func Tenant(r *http.Request) string {
+	tenant, _ := r.Context().Value(tenantKey{}).(string)
+	return tenant
+}
+
+// tenant returns the tenant of the claims of a token
+func (j *JwtAuth) tenant(claims jwt.MapClaims) string {
+	if j.tenantClaim != "" {
+		if t, ok := claims[j.tenantClaim].(string); ok && t != "" {
+			return t
+		}
+	}
+	issuer, _ := claims["iss"].(string)
+	return issuer
+}
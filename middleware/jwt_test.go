@@ -395,6 +395,61 @@ func TestJwt(t *testing.T) {
 	tests.Assert(t, called == true)
 }
 
+func TestJwtTenant(t *testing.T) {
+	// Setup jwt
+	c := &JwtAuthConfig{}
+	c.Admin.PrivateKey = "Key"
+	c.User.PrivateKey = "UserKey"
+	c.TenantClaim = "tenant"
+	j := NewJwtAuth(c)
+	tests.Assert(t, j != nil)
+
+	// Setup middleware framework
+	n := negroni.New(j)
+	tests.Assert(t, n != nil)
+
+	// Save the tenant set by the jwt middleware
+	tenant := ""
+	mw := func(rw http.ResponseWriter, r *http.Request) {
+		tenant = Tenant(r)
+		rw.WriteHeader(http.StatusOK)
+	}
+	n.UseHandlerFunc(mw)
+
+	// Create test server
+	ts := httptest.NewServer(n)
+
+	// Generate qsh
+	qshstring := "GET&/"
+	hash := sha256.New()
+	hash.Write([]byte(qshstring))
+
+	send := func(claims jwt.MapClaims) {
+		claims["iss"] = "user"
+		claims["iat"] = time.Now().Unix()
+		claims["exp"] = time.Now().Add(time.Second * 10).Unix()
+		claims["qsh"] = hex.EncodeToString(hash.Sum(nil))
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenString, err := token.SignedString([]byte("UserKey"))
+		tests.Assert(t, err == nil)
+
+		req, err := http.NewRequest("GET", ts.URL, nil)
+		tests.Assert(t, err == nil)
+		req.Header.Set("Authorization", "bearer "+tokenString)
+		r, err := http.DefaultClient.Do(req)
+		tests.Assert(t, err == nil)
+		tests.Assert(t, r.StatusCode == http.StatusOK)
+	}
+
+	// The tenant claim names the tenant
+	send(jwt.MapClaims{"tenant": "team-a"})
+	tests.Assert(t, tenant == "team-a", tenant)
+
+	// Without the claim the issuer is the tenant
+	send(jwt.MapClaims{})
+	tests.Assert(t, tenant == "user", tenant)
+}
+
 func TestJwtUnknownUser(t *testing.T) {
 
 	// Setup jwt
@@ -18,8 +18,11 @@ package api
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
@@ -37,6 +40,10 @@ var (
 	blockVolNameRe = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
 
 	tagNameRe = regexp.MustCompile("^[a-zA-Z0-9_.-]+$")
+
+	// Quota paths are directories from the root of the volume, they
+	// are restricted like device names
+	quotaPathRe = regexp.MustCompile("^/[a-zA-Z0-9_./-]*$")
 )
 
 // ValidateUUID is written this way because heketi UUID does not
@@ -50,6 +57,20 @@ func ValidateUUID(value interface{}) error {
 	return nil
 }
 
+// ValidateQuotaPath checks the path of a directory given for a quota
+// limit
+func ValidateQuotaPath(value interface{}) error {
+	s, _ := value.(string)
+	err := validation.Validate(s, validation.Required, validation.Match(quotaPathRe))
+	if err != nil {
+		return err
+	}
+	if strings.Contains(s, "..") {
+		return fmt.Errorf("%v must not refer to parent directories", s)
+	}
+	return nil
+}
+
 // State
 type EntryState string
 
@@ -58,6 +79,10 @@ const (
 	EntryStateOnline  EntryState = "online"
 	EntryStateOffline EntryState = "offline"
 	EntryStateFailed  EntryState = "failed"
+
+	// Set by the server on nodes whose glusterd has failed the
+	// health checks, may not be requested by clients
+	EntryStateUnreachable EntryState = "unreachable"
 )
 
 func ValidateEntryState(value interface{}) error {
@@ -86,6 +111,35 @@ func ValidateDurabilityType(value interface{}) error {
 	return nil
 }
 
+// AllocationStrategy orders the devices the bricks of a volume are
+// placed on
+type AllocationStrategy string
+
+const (
+	// Devices in the order of the ring balanced across zones
+	AllocationSimple AllocationStrategy = "simple"
+	// Devices with the highest percentage of free space first
+	AllocationLeastUsed AllocationStrategy = "least-used"
+	// Devices with the lowest percentage of free space first
+	AllocationPack AllocationStrategy = "pack"
+	// Devices in a random order weighted by their allocation weight tag
+	AllocationWeighted AllocationStrategy = "weighted"
+
+	// Tag of nodes and devices with the weight used by the weighted
+	// strategy, a number which defaults to 1
+	AllocationWeightTag = "allocation.weight"
+)
+
+func ValidateAllocationStrategy(value interface{}) error {
+	s, _ := value.(AllocationStrategy)
+	err := validation.Validate(s, validation.In(AllocationSimple,
+		AllocationLeastUsed, AllocationPack, AllocationWeighted))
+	if err != nil {
+		return fmt.Errorf("%v is not a valid allocation strategy", s)
+	}
+	return nil
+}
+
 // Common
 type StateRequest struct {
 	State EntryState `json:"state"`
@@ -177,6 +231,14 @@ func (devAddReq DeviceAddRequest) Validate() error {
 	)
 }
 
+// Persistent identifiers of the disk backing a device
+type DeviceIdentity struct {
+	ById   string `json:"by_id,omitempty"`
+	Wwn    string `json:"wwn,omitempty"`
+	Serial string `json:"serial,omitempty"`
+	PvUuid string `json:"pv_uuid,omitempty"`
+}
+
 type DeviceInfo struct {
 	Device
 	Storage StorageSize `json:"storage"`
@@ -185,8 +247,38 @@ type DeviceInfo struct {
 
 type DeviceInfoResponse struct {
 	DeviceInfo
-	State  EntryState  `json:"state"`
-	Bricks []BrickInfo `json:"bricks"`
+	Identity DeviceIdentity `json:"identity"`
+	State    EntryState     `json:"state"`
+	Bricks   []BrickInfo    `json:"bricks"`
+	// Only set if the device health monitor is enabled
+	Health *DeviceHealth `json:"health,omitempty"`
+}
+
+// An unused block device found on a node
+type DiscoveredDevice struct {
+	Name string `json:"name"`
+	// Size in KB
+	Size   uint64 `json:"size"`
+	Model  string `json:"model"`
+	Serial string `json:"serial"`
+}
+
+type DeviceDiscoverResponse struct {
+	NodeId  string             `json:"node"`
+	Devices []DiscoveredDevice `json:"devices"`
+}
+
+// Adds all the discovered devices of a node
+type DeviceDiscoverAddRequest struct {
+	Tags        map[string]string `json:"tags,omitempty"`
+	DestroyData bool              `json:"destroydata,omitempty"`
+}
+
+func (req DeviceDiscoverAddRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Tags, validation.By(ValidateTags)),
+		validation.Field(&req.DestroyData, validation.In(true, false)),
+	)
 }
 
 // Node
@@ -213,8 +305,113 @@ type NodeInfo struct {
 
 type NodeInfoResponse struct {
 	NodeInfo
-	State       EntryState           `json:"state"`
-	DevicesInfo []DeviceInfoResponse `json:"devices"`
+	State         EntryState           `json:"state"`
+	DevicesInfo   []DeviceInfoResponse `json:"devices"`
+	FailureDomain FailureDomain        `json:"failure_domain"`
+	Health        NodeHealth           `json:"health"`
+}
+
+// Node tags used to label the failure domains of a node
+const (
+	FailureDomainRegionTag = "failure-domain.region"
+	FailureDomainRackTag   = "failure-domain.rack"
+)
+
+// FailureDomain describes the failure domains wider than the node
+// itself. A rack is always considered to be part of its region.
+type FailureDomain struct {
+	Region string `json:"region,omitempty"`
+	Rack   string `json:"rack,omitempty"`
+}
+
+func NewFailureDomainFromTags(tags map[string]string) FailureDomain {
+	return FailureDomain{
+		Region: tags[FailureDomainRegionTag],
+		Rack:   tags[FailureDomainRackTag],
+	}
+}
+
+// SetTags stores the failure domain in the given node tags
+func (fd FailureDomain) SetTags(tags map[string]string) {
+	if fd.Region != "" {
+		tags[FailureDomainRegionTag] = fd.Region
+	}
+	if fd.Rack != "" {
+		tags[FailureDomainRackTag] = fd.Rack
+	}
+}
+
+type NodeHealthState string
+
+const (
+	NodeHealthUnknown NodeHealthState = "unknown"
+	NodeHealthUp      NodeHealthState = "up"
+	NodeHealthDown    NodeHealthState = "down"
+)
+
+// NodeHealth is the result of the most recent check of glusterd on
+// a node by the server's node health monitor. The state is unknown
+// if the monitor is not enabled or has not checked the node yet.
+type NodeHealth struct {
+	State NodeHealthState `json:"state"`
+	// Unix time of the most recent check, omitted if never checked
+	LastUpdate int64 `json:"last_update,omitempty"`
+}
+
+type NodeHealthResponse struct {
+	NodeHealth
+	NodeId    string `json:"node_id"`
+	ClusterId string `json:"cluster"`
+	Hostname  string `json:"hostname"`
+}
+
+type HealthResponse struct {
+	MonitorEnabled bool                 `json:"monitor_enabled"`
+	Nodes          []NodeHealthResponse `json:"nodes"`
+
+	// Only set if the device health monitor is enabled
+	DeviceMonitorEnabled bool                   `json:"device_monitor_enabled"`
+	Devices              []DeviceHealthResponse `json:"devices,omitempty"`
+}
+
+type DeviceHealthState string
+
+const (
+	DeviceHealthUnknown DeviceHealthState = "unknown"
+	DeviceHealthOk      DeviceHealthState = "ok"
+	DeviceHealthWarning DeviceHealthState = "warning"
+	DeviceHealthFailed  DeviceHealthState = "failed"
+)
+
+type ThinPoolUsage struct {
+	Name            string  `json:"name"`
+	DataPercent     float64 `json:"data_percent"`
+	MetadataPercent float64 `json:"metadata_percent"`
+	// Size of the pool in KB
+	Size uint64 `json:"size,omitempty"`
+}
+
+// DeviceHealth is the result of the most recent check of a device
+// by the server's device health monitor. Devices with nearly full
+// thin pools are in the warning state and devices failing their
+// SMART self-assessment in the failed state.
+type DeviceHealth struct {
+	State DeviceHealthState `json:"state"`
+	// Unix time of the most recent check, omitted if never checked
+	LastUpdate   int64           `json:"last_update,omitempty"`
+	TotalExtents uint64          `json:"total_extents,omitempty"`
+	FreeExtents  uint64          `json:"free_extents,omitempty"`
+	ThinPools    []ThinPoolUsage `json:"thin_pools,omitempty"`
+	// SMART overall health, omitted if it could not be read
+	SmartPassed *bool    `json:"smart_passed,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+type DeviceHealthResponse struct {
+	DeviceHealth
+	DeviceId string `json:"device_id"`
+	NodeId   string `json:"node_id"`
+	Name     string `json:"name"`
 }
 
 // Cluster
@@ -237,22 +434,74 @@ type TopologyInfoResponse struct {
 
 type ClusterCreateRequest struct {
 	ClusterFlags
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+func (clusterCreateReq ClusterCreateRequest) Validate() error {
+	return validation.ValidateStruct(&clusterCreateReq,
+		validation.Field(&clusterCreateReq.Tags, validation.By(ValidateTags)),
+	)
 }
 
 type ClusterSetFlagsRequest struct {
 	ClusterFlags
 }
 
+const (
+	// Largest overcommit ratio a cluster may be set to
+	ClusterMaxOvercommitRatio = 10.0
+	// Physical usage limit of clusters which do not set one
+	ClusterDefaultPhysicalUsageLimit = 90.0
+)
+
+// ClusterOvercommit allows the bricks of a cluster to reserve less
+// space on the devices than their size, relying on the thin pools
+// being mostly empty.
+type ClusterOvercommit struct {
+	// Devices admit bricks up to their size times the ratio.
+	// Zero or one disables overcommit.
+	OvercommitRatio float64 `json:"overcommit_ratio,omitempty"`
+	// Percent of a device actually used by data at or above which
+	// no new bricks are placed on it. Zero uses the default.
+	PhysicalUsageLimit float64 `json:"physical_usage_limit,omitempty"`
+}
+
+type ClusterSetOvercommitRequest struct {
+	ClusterOvercommit
+}
+
+func (req ClusterSetOvercommitRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.OvercommitRatio, validation.By(validateOvercommitRatio)),
+		validation.Field(&req.PhysicalUsageLimit, validation.Min(0.0), validation.Max(100.0)),
+	)
+}
+
+func validateOvercommitRatio(value interface{}) error {
+	r, _ := value.(float64)
+	if r != 0 && (r < 1 || r > ClusterMaxOvercommitRatio) {
+		return fmt.Errorf("must be 0 or between 1 and %v",
+			ClusterMaxOvercommitRatio)
+	}
+	return nil
+}
+
 type ClusterInfoResponse struct {
 	Id      string           `json:"id"`
 	Nodes   sort.StringSlice `json:"nodes"`
 	Volumes sort.StringSlice `json:"volumes"`
 	ClusterFlags
-	BlockVolumes sort.StringSlice `json:"blockvolumes"`
+	BlockVolumes sort.StringSlice  `json:"blockvolumes"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	ClusterOvercommit
 }
 
 type ClusterListResponse struct {
 	Clusters []string `json:"clusters"`
+	// Info of the clusters if the list was expanded
+	Info []ClusterInfoResponse `json:"info,omitempty"`
+	// Token continuing the list, empty on the last page
+	Next string `json:"next,omitempty"`
 }
 
 // Durabilities
@@ -285,6 +534,20 @@ type VolumeCreateRequest struct {
 		Enable bool    `json:"enable"`
 		Factor float32 `json:"factor"`
 	} `json:"snapshot"`
+	Selector *PlacementSelector `json:"selector,omitempty"`
+	Tags     map[string]string  `json:"tags,omitempty"`
+	// Strategy used to choose the devices of the bricks, the
+	// default of the server is used if empty
+	Allocation AllocationStrategy `json:"allocation,omitempty"`
+	// Limits on the size and number of the bricks, saved with the
+	// volume so that expansions keep the same brick shape
+	BrickPolicy *BrickPolicy `json:"brick_policy,omitempty"`
+	// Only report where the bricks would be placed
+	DryRun bool `json:"dry_run,omitempty"`
+	// Limit the usage of the volume slightly below its size with a
+	// quota on its root directory, kept in line with the size when
+	// the volume is expanded
+	RootQuota bool `json:"root_quota,omitempty"`
 }
 
 func (volCreateRequest VolumeCreateRequest) Validate() error {
@@ -296,16 +559,47 @@ func (volCreateRequest VolumeCreateRequest) Validate() error {
 		validation.Field(&volCreateRequest.Gid, validation.Skip),
 		validation.Field(&volCreateRequest.GlusterVolumeOptions, validation.Skip),
 		validation.Field(&volCreateRequest.Block, validation.In(true, false)),
+		validation.Field(&volCreateRequest.Selector),
+		validation.Field(&volCreateRequest.Tags, validation.By(ValidateTags)),
+		validation.Field(&volCreateRequest.Allocation, validation.By(ValidateAllocationStrategy)),
+		validation.Field(&volCreateRequest.BrickPolicy),
 		// This is possibly a bug in validation lib, ignore next two lines for now
 		// validation.Field(&volCreateRequest.Snapshot.Enable, validation.In(true, false)),
 		// validation.Field(&volCreateRequest.Snapshot.Factor, validation.Min(1.0)),
 	)
 }
 
+// BrickPolicy narrows the limits of the server on the bricks of a
+// volume. Limits which are zero are those of the server.
+type BrickPolicy struct {
+	// Smallest and largest size of a brick in GiB
+	MinBrickSize int `json:"min_brick_size,omitempty"`
+	MaxBrickSize int `json:"max_brick_size,omitempty"`
+	// Largest number of brick sets, the distribute count
+	MaxBrickSets int `json:"max_brick_sets,omitempty"`
+}
+
+func (bp BrickPolicy) Validate() error {
+	err := validation.ValidateStruct(&bp,
+		validation.Field(&bp.MinBrickSize, validation.Min(0)),
+		validation.Field(&bp.MaxBrickSize, validation.Min(0)),
+		validation.Field(&bp.MaxBrickSets, validation.Min(0)),
+	)
+	if err != nil {
+		return err
+	}
+	if bp.MaxBrickSize != 0 && bp.MinBrickSize > bp.MaxBrickSize {
+		return fmt.Errorf("min_brick_size must not be larger than max_brick_size")
+	}
+	return nil
+}
+
 type VolumeInfo struct {
 	VolumeCreateRequest
 	Id      string `json:"id"`
 	Cluster string `json:"cluster"`
+	// Tenant the volume was created for, if any
+	Tenant string `json:"tenant,omitempty"`
 	Mount   struct {
 		GlusterFS struct {
 			Hosts      []string          `json:"hosts"`
@@ -326,6 +620,334 @@ type VolumeInfoResponse struct {
 
 type VolumeListResponse struct {
 	Volumes []string `json:"volumes"`
+	// Info of the volumes if the list was expanded
+	Info []VolumeInfoResponse `json:"info,omitempty"`
+	// Token continuing the list, empty on the last page
+	Next string `json:"next,omitempty"`
+}
+
+type BrickStatus struct {
+	Id       string `json:"id"`
+	NodeId   string `json:"node"`
+	DeviceId string `json:"device"`
+	Host     string `json:"host"`
+	Path     string `json:"path"`
+	Online   bool   `json:"online"`
+	Pid      int    `json:"pid,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	// Number of entries pending heal on the brick, omitted if
+	// unknown or if the volume is neither replicated nor dispersed
+	PendingHeals *uint64 `json:"pending_heals,omitempty"`
+}
+
+// VolumeStatusResponse reports the status of the bricks of a volume
+// as seen by gluster. A volume is degraded if any of its bricks is
+// offline or has entries pending heal.
+type VolumeStatusResponse struct {
+	Id       string        `json:"id"`
+	Name     string        `json:"name"`
+	Cluster  string        `json:"cluster"`
+	Degraded bool          `json:"degraded"`
+	Bricks   []BrickStatus `json:"bricks"`
+	// Set if the status of the volume could not be determined
+	Error string `json:"error,omitempty"`
+}
+
+type ClusterStatusResponse struct {
+	Id              string                 `json:"id"`
+	VolumeCount     int                    `json:"volume_count"`
+	DegradedVolumes []VolumeStatusResponse `json:"degraded_volumes"`
+}
+
+// VolumeCapacity is the size of the largest volume of a durability
+// type which can currently be placed in a cluster
+type VolumeCapacity struct {
+	Name       string               `json:"name"`
+	Durability VolumeDurabilityInfo `json:"durability"`
+	Arbiter    bool                 `json:"arbiter,omitempty"`
+	// Size in GB, zero if no volume of this type can be placed
+	MaxSize int `json:"max_size"`
+}
+
+type ClusterCapacityResponse struct {
+	Id             string           `json:"id"`
+	Storage        StorageSize      `json:"storage"`
+	SnapshotFactor float32          `json:"snapshot_factor"`
+	Volumes        []VolumeCapacity `json:"volumes"`
+}
+
+// BrickPlan is a brick that would be created for a volume
+type BrickPlan struct {
+	Device string `json:"device"`
+	Node   string `json:"node"`
+	// Size in KB
+	Size uint64 `json:"size"`
+}
+
+type BrickSetPlan struct {
+	Bricks []BrickPlan `json:"bricks"`
+}
+
+// DeviceRejection is the reason a device was not used for a brick
+type DeviceRejection struct {
+	Device string `json:"device"`
+	Node   string `json:"node"`
+	Reason string `json:"reason"`
+}
+
+// ClusterRejection explains why a volume can not be placed in a cluster
+type ClusterRejection struct {
+	Id    string `json:"id"`
+	Error string `json:"error"`
+	// Size in KB of the bricks of the last placement tried
+	BrickSize uint64            `json:"brick_size,omitempty"`
+	Devices   []DeviceRejection `json:"devices,omitempty"`
+}
+
+// VolumePlanResponse reports where the bricks of a volume would be
+// placed, or why the volume can not be placed
+type VolumePlanResponse struct {
+	Feasible  bool               `json:"feasible"`
+	Cluster   string             `json:"cluster,omitempty"`
+	BrickSets []BrickSetPlan     `json:"brick_sets,omitempty"`
+	Rejected  []ClusterRejection `json:"rejected_clusters,omitempty"`
+}
+
+// Geo-replication
+
+// VolumeQuotaRequest enables or disables the directory quotas of a
+// volume. Disabling quotas removes the limits of the volume.
+type VolumeQuotaRequest struct {
+	Enable bool `json:"enable"`
+}
+
+func (vqr VolumeQuotaRequest) Validate() error {
+	return validation.ValidateStruct(&vqr,
+		validation.Field(&vqr.Enable, validation.In(true, false)),
+	)
+}
+
+// VolumeQuotaLimitRequest sets the limit of a directory of a volume
+type VolumeQuotaLimitRequest struct {
+	// Path of the directory from the root of the volume
+	Path string `json:"path"`
+	// Hard limit in bytes
+	HardLimit uint64 `json:"hard_limit"`
+	// Usage, in percent of the hard limit, above which gluster logs
+	// warnings. The default of the volume is used if 0.
+	SoftLimitPercent int `json:"soft_limit_percent,omitempty"`
+}
+
+func (vql VolumeQuotaLimitRequest) Validate() error {
+	return validation.ValidateStruct(&vql,
+		validation.Field(&vql.Path, validation.By(ValidateQuotaPath)),
+		validation.Field(&vql.HardLimit, validation.Required),
+		validation.Field(&vql.SoftLimitPercent, validation.Min(0), validation.Max(100)),
+	)
+}
+
+// VolumeQuotaLimitInfo is the limit of a directory and its usage
+type VolumeQuotaLimitInfo struct {
+	Path              string `json:"path"`
+	HardLimit         uint64 `json:"hard_limit"`
+	SoftLimitPercent  int    `json:"soft_limit_percent"`
+	Used              uint64 `json:"used"`
+	Available         uint64 `json:"available"`
+	SoftLimitExceeded bool   `json:"soft_limit_exceeded"`
+	HardLimitExceeded bool   `json:"hard_limit_exceeded"`
+}
+
+type VolumeQuotaResponse struct {
+	Id      string                 `json:"id"`
+	Enabled bool                   `json:"enabled"`
+	Limits  []VolumeQuotaLimitInfo `json:"limits"`
+}
+
+// GeoReplicationState is the state heketi last set the session to
+type GeoReplicationState string
+
+const (
+	GeoReplicationCreated GeoReplicationState = "created"
+	GeoReplicationStarted GeoReplicationState = "started"
+	GeoReplicationStopped GeoReplicationState = "stopped"
+	GeoReplicationPaused  GeoReplicationState = "paused"
+)
+
+// GeoReplicationAction changes the state of a geo-replication session
+type GeoReplicationAction string
+
+const (
+	GeoReplicationStart  GeoReplicationAction = "start"
+	GeoReplicationStop   GeoReplicationAction = "stop"
+	GeoReplicationPause  GeoReplicationAction = "pause"
+	GeoReplicationResume GeoReplicationAction = "resume"
+)
+
+// GeoReplicationCreateRequest creates a session replicating the files
+// of the master volume to the slave volume, which may be in another
+// cluster.
+type GeoReplicationCreateRequest struct {
+	MasterVolumeId string `json:"master_volume"`
+	SlaveVolumeId  string `json:"slave_volume"`
+	// Start the session once it is created
+	Start bool `json:"start,omitempty"`
+}
+
+func (req GeoReplicationCreateRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.MasterVolumeId, validation.Required, validation.By(ValidateUUID)),
+		validation.Field(&req.SlaveVolumeId, validation.Required, validation.By(ValidateUUID)),
+	)
+}
+
+type GeoReplicationActionRequest struct {
+	Action GeoReplicationAction `json:"action"`
+	// Passed on to gluster, e.g. to stop a session whose slave is
+	// unreachable. The state recorded by heketi is not checked.
+	Force bool `json:"force,omitempty"`
+}
+
+func (req GeoReplicationActionRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Action, validation.Required,
+			validation.In(GeoReplicationStart, GeoReplicationStop,
+				GeoReplicationPause, GeoReplicationResume)),
+	)
+}
+
+type GeoReplicationSessionInfo struct {
+	Id             string              `json:"id"`
+	MasterVolumeId string              `json:"master_volume"`
+	MasterVolume   string              `json:"master_volume_name"`
+	MasterCluster  string              `json:"master_cluster"`
+	SlaveVolumeId  string              `json:"slave_volume"`
+	SlaveVolume    string              `json:"slave_volume_name"`
+	SlaveCluster   string              `json:"slave_cluster"`
+	SlaveHost      string              `json:"slave_host"`
+	State          GeoReplicationState `json:"state"`
+}
+
+type GeoReplicationSessionListResponse struct {
+	Sessions []string `json:"sessions"`
+}
+
+// GeoReplicationWorkerStatus is the status gluster reports for the
+// worker replicating one brick of the master volume
+type GeoReplicationWorkerStatus struct {
+	MasterNode  string `json:"master_node"`
+	MasterBrick string `json:"master_brick"`
+	SlaveNode   string `json:"slave_node"`
+	Status      string `json:"status"`
+	CrawlStatus string `json:"crawl_status"`
+	LastSynced  string `json:"last_synced"`
+}
+
+type GeoReplicationStatusResponse struct {
+	GeoReplicationSessionInfo
+	Workers []GeoReplicationWorkerStatus `json:"workers"`
+}
+
+// TenantLimits caps the capacity provisioned for a tenant. Limits
+// left at 0 or empty are not enforced.
+type TenantLimits struct {
+	// Total size in GiB of the volumes and block volumes
+	MaxSize int `json:"max_size_gb,omitempty"`
+	// Number of volumes and block volumes
+	MaxVolumes int `json:"max_volumes,omitempty"`
+	// Clusters the volumes may be placed on
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// TenantUsage is the capacity provisioned for a tenant, including
+// the volumes and expansions being created
+type TenantUsage struct {
+	Size         int `json:"size_gb"`
+	Volumes      int `json:"volumes"`
+	BlockVolumes int `json:"blockvolumes"`
+}
+
+type TenantInfoResponse struct {
+	Id     string       `json:"id"`
+	Limits TenantLimits `json:"limits"`
+	Usage  TenantUsage  `json:"usage"`
+}
+
+// EventType is the kind of change reported by an event
+type EventType string
+
+const (
+	EventCreate EventType = "create"
+	EventUpdate EventType = "update"
+	EventDelete EventType = "delete"
+)
+
+// Event records a change to an entry or to the state of an operation
+type Event struct {
+	Seq      uint64    `json:"seq"`
+	Time     int64     `json:"time"`
+	Type     EventType `json:"type"`
+	Resource string    `json:"resource"`
+	Id       string    `json:"id"`
+	// Set for events of operations
+	Operation string `json:"operation,omitempty"`
+	// Set for events of operations and of node state changes
+	// made by the health monitor
+	State string `json:"state,omitempty"`
+}
+
+type EventListResponse struct {
+	Events []Event `json:"events"`
+	// Sequence number of the most recent event
+	LastSeq uint64 `json:"last_seq"`
+	// True if events following the requested sequence number
+	// are no longer available
+	Truncated bool `json:"truncated"`
+}
+
+// WebhookEvent is the kind of notification sent to webhooks
+type WebhookEvent string
+
+const (
+	WebhookVolumeCreated   WebhookEvent = "volume_created"
+	WebhookVolumeDeleted   WebhookEvent = "volume_deleted"
+	WebhookOperationFailed WebhookEvent = "operation_failed"
+	WebhookDeviceFailed    WebhookEvent = "device_failed"
+	WebhookNodeUnhealthy   WebhookEvent = "node_unhealthy"
+)
+
+// WebhookNotification is the JSON body posted to webhooks
+type WebhookNotification struct {
+	Id         string       `json:"id"`
+	Event      WebhookEvent `json:"event"`
+	Time       int64        `json:"time"`
+	Resource   string       `json:"resource"`
+	ResourceId string       `json:"resource_id"`
+	Message    string       `json:"message,omitempty"`
+}
+
+type WebhookInfo struct {
+	Url string `json:"url"`
+	// Kinds of notifications sent to the webhook, all if empty
+	Events    []WebhookEvent `json:"events"`
+	Queued    int            `json:"queued"`
+	Delivered uint64         `json:"delivered"`
+	Failed    uint64         `json:"failed"`
+}
+
+// WebhookFailure describes a notification which could not be
+// delivered after all retries
+type WebhookFailure struct {
+	Url          string              `json:"url"`
+	Notification WebhookNotification `json:"notification"`
+	Attempts     int                 `json:"attempts"`
+	Error        string              `json:"error"`
+	Time         int64               `json:"time"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []WebhookInfo `json:"webhooks"`
+	// Most recent failures, oldest first
+	Failures []WebhookFailure `json:"failures"`
 }
 
 type VolumeExpandRequest struct {
@@ -348,15 +970,69 @@ func (vcr VolumeCloneRequest) Validate() error {
 	)
 }
 
+// VolumeConvertRequest changes the durability of an existing replica
+// volume. Replica 2 volumes can be converted to replica 3 or arbiter
+// volumes, and replica 3 volumes to arbiter volumes.
+type VolumeConvertRequest struct {
+	Replica int  `json:"replica"`
+	Arbiter bool `json:"arbiter,omitempty"`
+}
+
+func (vcr VolumeConvertRequest) Validate() error {
+	return validation.ValidateStruct(&vcr,
+		validation.Field(&vcr.Replica, validation.Required, validation.In(3)),
+	)
+}
+
+// VolumeMigrateRequest moves a volume to another cluster. A copy of
+// the volume is created on the cluster and the data is copied to it,
+// the volume keeps its id once the migration is cut over.
+type VolumeMigrateRequest struct {
+	ClusterId string `json:"cluster"`
+}
+
+func (vmr VolumeMigrateRequest) Validate() error {
+	return validation.ValidateStruct(&vmr,
+		validation.Field(&vmr.ClusterId, validation.Required, validation.By(ValidateUUID)),
+	)
+}
+
+// MigrationState is the step a volume migration has reached
+type MigrationState string
+
+const (
+	// The volume is being created on the target cluster
+	MigrationCreating MigrationState = "creating"
+	// The data is being copied to the target volume
+	MigrationCopying MigrationState = "copying"
+	// The data has been copied, waiting for the cutover
+	MigrationReady MigrationState = "ready"
+	// The changes since the copy are being copied to the target volume
+	MigrationCutover MigrationState = "cutover"
+	// The source volume is being deleted
+	MigrationRetiring MigrationState = "retiring"
+)
+
+type VolumeMigrationInfo struct {
+	// Id of the volume being migrated
+	Id string `json:"id"`
+	// Id of the volume on the target cluster until the cutover
+	Target    string         `json:"target"`
+	ClusterId string         `json:"cluster"`
+	State     MigrationState `json:"state"`
+}
+
 // BlockVolume
 
 type BlockVolumeCreateRequest struct {
 	// Size in GiB
-	Size     int      `json:"size"`
-	Clusters []string `json:"clusters,omitempty"`
-	Name     string   `json:"name"`
-	Hacount  int      `json:"hacount,omitempty"`
-	Auth     bool     `json:"auth,omitempty"`
+	Size     int                `json:"size"`
+	Clusters []string           `json:"clusters,omitempty"`
+	Name     string             `json:"name"`
+	Hacount  int                `json:"hacount,omitempty"`
+	Auth     bool               `json:"auth,omitempty"`
+	Selector *PlacementSelector `json:"selector,omitempty"`
+	Tags     map[string]string  `json:"tags,omitempty"`
 }
 
 func (blockVolCreateReq BlockVolumeCreateRequest) Validate() error {
@@ -366,6 +1042,8 @@ func (blockVolCreateReq BlockVolumeCreateRequest) Validate() error {
 		validation.Field(&blockVolCreateReq.Name, validation.Match(blockVolNameRe)),
 		validation.Field(&blockVolCreateReq.Hacount, validation.Min(1)),
 		validation.Field(&blockVolCreateReq.Auth, validation.Skip),
+		validation.Field(&blockVolCreateReq.Selector),
+		validation.Field(&blockVolCreateReq.Tags, validation.By(ValidateTags)),
 	)
 }
 
@@ -384,6 +1062,8 @@ type BlockVolumeInfo struct {
 	} `json:"blockvolume"`
 	Cluster            string `json:"cluster,omitempty"`
 	BlockHostingVolume string `json:"blockhostingvolume,omitempty"`
+	// Tenant the block volume was created for, if any
+	Tenant string `json:"tenant,omitempty"`
 }
 
 type BlockVolumeInfoResponse struct {
@@ -392,11 +1072,78 @@ type BlockVolumeInfoResponse struct {
 
 type BlockVolumeListResponse struct {
 	BlockVolumes []string `json:"blockvolumes"`
+	// Info of the block volumes if the list was expanded
+	Info []BlockVolumeInfoResponse `json:"info,omitempty"`
+	// Token continuing the list, empty on the last page
+	Next string `json:"next,omitempty"`
+}
+
+// ListQuery filters and pages the lists of volumes, block volumes
+// and clusters. Fields left empty do not filter the list. Block
+// volumes are not filtered by Durability and Block, clusters are
+// only filtered by Block and Tags.
+type ListQuery struct {
+	Name       string
+	NamePrefix string
+	Cluster    string
+	Durability DurabilityType
+	// Block hosting volumes, or clusters allowing block volumes
+	Block *bool
+	// Tag names, or name:value pairs, the objects must have
+	Tags []string
+	// Return the info of the objects with their ids
+	Expand bool
+	// Maximum number of objects returned, all if 0
+	Limit int
+	// Token returned with the previous page
+	Continue string
+}
+
+// Values returns the query parameters of the list request
+func (q *ListQuery) Values() url.Values {
+	v := url.Values{}
+	if q.Name != "" {
+		v.Set("name", q.Name)
+	}
+	if q.NamePrefix != "" {
+		v.Set("name_prefix", q.NamePrefix)
+	}
+	if q.Cluster != "" {
+		v.Set("cluster", q.Cluster)
+	}
+	if q.Durability != "" {
+		v.Set("durability", string(q.Durability))
+	}
+	if q.Block != nil {
+		v.Set("block", strconv.FormatBool(*q.Block))
+	}
+	for _, tag := range q.Tags {
+		v.Add("tag", tag)
+	}
+	if q.Expand {
+		v.Set("expand", "true")
+	}
+	if q.Limit != 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Continue != "" {
+		v.Set("continue", q.Continue)
+	}
+	return v
 }
 
 type LogLevelInfo struct {
 	// should contain one or more logger to log-level-name mapping
 	LogLevel map[string]string `json:"loglevel"`
+	// replaces the current filter if set
+	Filter *LogFilter `json:"filter,omitempty"`
+}
+
+// LogFilter limits the info and debug messages to those about the
+// volumes and nodes. Nothing is filtered if both are empty.
+type LogFilter struct {
+	Volumes []string `json:"volumes"`
+	Nodes   []string `json:"nodes"`
 }
 
 type TagsChangeType string
@@ -448,6 +1195,64 @@ func ValidateTags(v interface{}) error {
 	return nil
 }
 
+type PlacementSelectorOperator string
+
+const (
+	PlacementSelectorIn    PlacementSelectorOperator = "in"
+	PlacementSelectorNotIn PlacementSelectorOperator = "notin"
+)
+
+// PlacementSelectorExpression matches the value of a tag against a
+// set of values. A tag that is not set never matches "in" and
+// always matches "notin".
+type PlacementSelectorExpression struct {
+	Key      string                    `json:"key"`
+	Operator PlacementSelectorOperator `json:"operator"`
+	Values   []string                  `json:"values"`
+}
+
+func (e PlacementSelectorExpression) Validate() error {
+	return validation.ValidateStruct(&e,
+		validation.Field(&e.Key, validation.Required,
+			validation.RuneLength(1, 32), validation.Match(tagNameRe)),
+		validation.Field(&e.Operator, validation.Required,
+			validation.In(PlacementSelectorIn, PlacementSelectorNotIn)),
+		validation.Field(&e.Values, validation.Required),
+	)
+}
+
+func (e PlacementSelectorExpression) String() string {
+	return fmt.Sprintf("%v %v (%v)",
+		e.Key, e.Operator, strings.Join(e.Values, ","))
+}
+
+// PlacementSelector restricts the devices bricks may be placed on.
+// The tags of a device, combined with the tags of its node, must
+// contain every tag of MatchTags and satisfy every expression.
+type PlacementSelector struct {
+	MatchTags        map[string]string             `json:"match_tags,omitempty"`
+	MatchExpressions []PlacementSelectorExpression `json:"match_expressions,omitempty"`
+}
+
+func (ps PlacementSelector) Validate() error {
+	return validation.ValidateStruct(&ps,
+		validation.Field(&ps.MatchTags, validation.By(ValidateTags)),
+		validation.Field(&ps.MatchExpressions),
+	)
+}
+
+func (ps PlacementSelector) String() string {
+	terms := []string{}
+	for k, v := range ps.MatchTags {
+		terms = append(terms, k+"="+v)
+	}
+	sort.Strings(terms)
+	for _, e := range ps.MatchExpressions {
+		terms = append(terms, e.String())
+	}
+	return strings.Join(terms, ", ")
+}
+
 // Constructors
 
 func NewVolumeInfoResponse() *VolumeInfoResponse {
@@ -498,6 +1303,28 @@ func (v *VolumeInfoResponse) String() string {
 			v.Snapshot.Factor)
 	}
 
+	if bp := v.BrickPolicy; bp != nil {
+		if bp.MinBrickSize != 0 {
+			s += fmt.Sprintf("Min Brick Size: %v\n", bp.MinBrickSize)
+		}
+		if bp.MaxBrickSize != 0 {
+			s += fmt.Sprintf("Max Brick Size: %v\n", bp.MaxBrickSize)
+		}
+		if bp.MaxBrickSets != 0 {
+			s += fmt.Sprintf("Max Brick Sets: %v\n", bp.MaxBrickSets)
+		}
+	}
+
+	if v.RootQuota {
+		s += "Root Quota: true\n"
+	}
+
+	if v.Tenant != "" {
+		s += fmt.Sprintf("Tenant: %v\n", v.Tenant)
+	}
+
+	s += tagsString(v.Tags)
+
 	/*
 		s += "\nBricks:\n"
 		for _, b := range v.Bricks {
@@ -550,6 +1377,12 @@ func (v *BlockVolumeInfoResponse) String() string {
 		v.BlockVolume.Password,
 		v.BlockHostingVolume)
 
+	if v.Tenant != "" {
+		s += fmt.Sprintf("Tenant: %v\n", v.Tenant)
+	}
+
+	s += tagsString(v.Tags)
+
 	/*
 		s += "\nBricks:\n"
 		for _, b := range v.Bricks {
@@ -568,3 +1401,20 @@ func (v *BlockVolumeInfoResponse) String() string {
 
 	return s
 }
+
+// tagsString formats tags for the String functions
+func tagsString(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(tags))
+	for k := range tags {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	s := "Tags:\n"
+	for _, k := range names {
+		s += fmt.Sprintf("  %v: %v\n", k, tags[k])
+	}
+	return s
+}
@@ -10,12 +10,15 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/lpabon/godbc"
 )
@@ -32,20 +35,124 @@ const (
 	LEVEL_DEBUG
 )
 
+type LogFormat int
+
+// Log formats
+const (
+	// Lines of text starting with the prefix of the logger and the level
+	LOG_FORMAT_TEXT LogFormat = iota
+	// One JSON object per line
+	LOG_FORMAT_JSON
+)
+
 var (
 	stderr io.Writer = os.Stderr
 	stdout io.Writer = os.Stdout
+
+	// LogFormat of all loggers, read and written atomically as it is
+	// set while loggers are in use
+	logFormat int32
+
+	// Strings of which the info and debug messages must contain one
+	logFilter atomic.Value
+
+	levelNames = map[LogLevel]string{
+		LEVEL_CRITICAL: "critical",
+		LEVEL_ERROR:    "error",
+		LEVEL_WARNING:  "warning",
+		LEVEL_INFO:     "info",
+		LEVEL_DEBUG:    "debug",
+	}
 )
 
+// SetLogFormat sets the format of the messages of all loggers.
+// The format is either "text", the default, or "json".
+func SetLogFormat(format string) error {
+	switch format {
+	case "", "text":
+		atomic.StoreInt32(&logFormat, int32(LOG_FORMAT_TEXT))
+	case "json":
+		atomic.StoreInt32(&logFormat, int32(LOG_FORMAT_JSON))
+	default:
+		return fmt.Errorf("Unknown log format: %v", format)
+	}
+	return nil
+}
+
+// GetLogFormat returns the format of the messages of all loggers.
+func GetLogFormat() LogFormat {
+	return LogFormat(atomic.LoadInt32(&logFormat))
+}
+
+// SetLogFilter limits the info and debug messages of all loggers to
+// those which contain one of the matches as a whole word, either in
+// the message or in the values added with With. Critical, error and warning messages
+// are not filtered. An empty list of matches removes the filter.
+func SetLogFilter(matches []string) {
+	logFilter.Store(append([]string{}, matches...))
+}
+
+// GetLogFilter returns the matches set by SetLogFilter.
+func GetLogFilter() []string {
+	matches, _ := logFilter.Load().([]string)
+	return append([]string{}, matches...)
+}
+
+// ParseLogLevel returns the level with the name, one of none,
+// critical, error, warning, info or debug.
+func ParseLogLevel(name string) (LogLevel, error) {
+	if name == "none" {
+		return LEVEL_NOLOG, nil
+	}
+	for level, n := range levelNames {
+		if n == name {
+			return level, nil
+		}
+	}
+	return LEVEL_NOLOG, fmt.Errorf("invalid log level: %s", name)
+}
+
+func (level LogLevel) String() string {
+	if level == LEVEL_NOLOG {
+		return "none"
+	}
+	if n, ok := levelNames[level]; ok {
+		return n
+	}
+	return "(unknown)"
+}
+
+type logField struct {
+	key   string
+	value interface{}
+}
+
 type Logger struct {
 	critlog, errorlog, infolog *log.Logger
 	debuglog, warninglog       *log.Logger
 
-	level LogLevel
+	// JSON messages are written without any prefix
+	jsonlog, jsonerrlog *log.Logger
+
+	// LogLevel of the logger, or levelInherit. It is read and written
+	// atomically as levels are changed while the logger is in use.
+	level int32
+
+	component string
+	fields    []logField
+	parent    *Logger
+	// Loggers returned by With share the level of their parent
+	shared bool
 }
 
-func logWithLongFile(l *log.Logger, format string, v ...interface{}) {
-	_, file, line, _ := runtime.Caller(2)
+// Level of the loggers returned by Component which use the level of
+// their parent until their own level is set
+const levelInherit int32 = -1
+
+// callerFile returns the file and line of the caller of the logging
+// function, skip frames above the caller of callerFile.
+func callerFile(skip int) string {
+	_, file, line, _ := runtime.Caller(skip + 1)
 
 	// Shorten the path.
 	// From
@@ -57,8 +164,124 @@ func logWithLongFile(l *log.Logger, format string, v ...interface{}) {
 		i = 0
 	}
 
-	l.Print(fmt.Sprintf("%v:%v: ", file[i:], line) +
-		fmt.Sprintf(format, v...))
+	return fmt.Sprintf("%v:%v", file[i:], line)
+}
+
+// output writes the message at the level. It must be called directly
+// by the exported logging functions for the file of their caller to
+// be found.
+func (l *Logger) output(level LogLevel, withFile bool,
+	format string, v ...interface{}) {
+
+	msg := fmt.Sprintf(format, v...)
+	if level >= LEVEL_INFO && !l.matchesFilter(msg) {
+		return
+	}
+	file := ""
+	if withFile {
+		file = callerFile(2)
+	}
+
+	if GetLogFormat() == LOG_FORMAT_JSON {
+		l.outputJson(level, file, msg)
+		return
+	}
+
+	if file != "" {
+		msg = file + ": " + msg
+	}
+	for _, f := range l.fields {
+		msg += fmt.Sprintf(" %v=%v", f.key, f.value)
+	}
+	switch level {
+	case LEVEL_CRITICAL:
+		l.critlog.Print(msg)
+	case LEVEL_ERROR:
+		l.errorlog.Print(msg)
+	case LEVEL_WARNING:
+		l.warninglog.Print(msg)
+	case LEVEL_INFO:
+		l.infolog.Print(msg)
+	default:
+		l.debuglog.Print(msg)
+	}
+}
+
+func (l *Logger) matchesFilter(msg string) bool {
+	matches, _ := logFilter.Load().([]string)
+	if len(matches) == 0 {
+		return true
+	}
+	for _, m := range matches {
+		if containsWord(msg, m) {
+			return true
+		}
+		for _, f := range l.fields {
+			if containsWord(fmt.Sprint(f.value), m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsWord returns true if s contains word on word boundaries, so
+// that node1 matches "node1" and "node1.example.com" but not "node10".
+// Underscores are boundaries as ids are embedded in names like vol_<id>.
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for i := 0; i+len(word) <= len(s); {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isWordChar(s[start-1])) &&
+			(end == len(s) || !isWordChar(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+	return false
+}
+
+func isWordChar(c byte) bool {
+	return c == '-' ||
+		('0' <= c && c <= '9') ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z')
+}
+
+func (l *Logger) outputJson(level LogLevel, file, msg string) {
+	m := map[string]interface{}{}
+	for _, f := range l.fields {
+		m[f.key] = f.value
+	}
+	m["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	m["level"] = levelNames[level]
+	m["component"] = l.component
+	m["message"] = msg
+	if file != "" {
+		m["file"] = file
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{
+			"level":     levelNames[level],
+			"component": l.component,
+			"message":   msg,
+			"error":     err.Error(),
+		})
+	}
+
+	if level <= LEVEL_ERROR {
+		l.jsonerrlog.Print(string(b))
+	} else {
+		l.jsonlog.Print(string(b))
+	}
 }
 
 // Create a new logger
@@ -69,9 +292,9 @@ func NewLogger(prefix string, level LogLevel) *Logger {
 	l := &Logger{}
 
 	if level == LEVEL_NOLOG {
-		l.level = LEVEL_DEBUG
+		l.level = int32(LEVEL_DEBUG)
 	} else {
-		l.level = level
+		l.level = int32(level)
 	}
 
 	l.critlog = log.New(stderr, prefix+" CRITICAL ", log.LstdFlags)
@@ -79,6 +302,9 @@ func NewLogger(prefix string, level LogLevel) *Logger {
 	l.warninglog = log.New(stdout, prefix+" WARNING ", log.LstdFlags)
 	l.infolog = log.New(stdout, prefix+" INFO ", log.LstdFlags)
 	l.debuglog = log.New(stdout, prefix+" DEBUG ", log.LstdFlags)
+	l.jsonerrlog = log.New(stderr, "", 0)
+	l.jsonlog = log.New(stdout, "", 0)
+	l.component = strings.Trim(prefix, "[]")
 
 	godbc.Ensure(l.critlog != nil)
 	godbc.Ensure(l.errorlog != nil)
@@ -91,25 +317,85 @@ func NewLogger(prefix string, level LogLevel) *Logger {
 
 // Return current level
 func (l *Logger) Level() LogLevel {
-	return l.level
+	if l.shared {
+		return l.parent.Level()
+	}
+	level := atomic.LoadInt32(&l.level)
+	if level == levelInherit {
+		return l.parent.Level()
+	}
+	return LogLevel(level)
 }
 
 // Set level
 func (l *Logger) SetLevel(level LogLevel) {
-	l.level = level
+	if l.shared {
+		l.parent.SetLevel(level)
+		return
+	}
+	atomic.StoreInt32(&l.level, int32(level))
+}
+
+// ResetLevel makes a logger returned by Component use the level of
+// its parent again.
+func (l *Logger) ResetLevel() {
+	if l.shared {
+		l.parent.ResetLevel()
+		return
+	}
+	if l.parent != nil {
+		atomic.StoreInt32(&l.level, levelInherit)
+	}
+}
+
+// With returns a logger adding the key and value to the messages
+// logged through it. The returned logger shares the level of l.
+func (l *Logger) With(key string, value interface{}) *Logger {
+	n := l.child()
+	n.fields = append(append([]logField{}, l.fields...),
+		logField{key: key, value: value})
+	n.shared = true
+	return n
+}
+
+// Component returns a logger for a part of the component of l. It
+// writes messages like l, and uses the level of l until its own level
+// is set.
+func (l *Logger) Component(name string) *Logger {
+	n := l.child()
+	n.component = l.component + "." + name
+	n.level = levelInherit
+	return n
+}
+
+// child returns a logger writing messages like l, with l as parent.
+// The level of l is not copied, it may be changed concurrently.
+func (l *Logger) child() *Logger {
+	return &Logger{
+		critlog:    l.critlog,
+		errorlog:   l.errorlog,
+		infolog:    l.infolog,
+		debuglog:   l.debuglog,
+		warninglog: l.warninglog,
+		jsonlog:    l.jsonlog,
+		jsonerrlog: l.jsonerrlog,
+		component:  l.component,
+		fields:     l.fields,
+		parent:     l,
+	}
 }
 
 // Log critical information
 func (l *Logger) Critical(format string, v ...interface{}) {
-	if l.level >= LEVEL_CRITICAL {
-		logWithLongFile(l.critlog, format, v...)
+	if l.Level() >= LEVEL_CRITICAL {
+		l.output(LEVEL_CRITICAL, true, format, v...)
 	}
 }
 
 // Log error string
 func (l *Logger) LogError(format string, v ...interface{}) error {
-	if l.level >= LEVEL_ERROR {
-		logWithLongFile(l.errorlog, format, v...)
+	if l.Level() >= LEVEL_ERROR {
+		l.output(LEVEL_ERROR, true, format, v...)
 	}
 
 	return fmt.Errorf(format, v...)
@@ -117,8 +403,8 @@ func (l *Logger) LogError(format string, v ...interface{}) error {
 
 // Log error variable
 func (l *Logger) Err(err error) error {
-	if l.level >= LEVEL_ERROR {
-		logWithLongFile(l.errorlog, "%v", err)
+	if l.Level() >= LEVEL_ERROR {
+		l.output(LEVEL_ERROR, true, "%v", err)
 	}
 
 	return err
@@ -126,15 +412,15 @@ func (l *Logger) Err(err error) error {
 
 // Log warning information
 func (l *Logger) Warning(format string, v ...interface{}) {
-	if l.level >= LEVEL_WARNING {
-		l.warninglog.Printf(format, v...)
+	if l.Level() >= LEVEL_WARNING {
+		l.output(LEVEL_WARNING, false, format, v...)
 	}
 }
 
 // Log error variable as a warning
 func (l *Logger) WarnErr(err error) error {
-	if l.level >= LEVEL_WARNING {
-		logWithLongFile(l.warninglog, "%v", err)
+	if l.Level() >= LEVEL_WARNING {
+		l.output(LEVEL_WARNING, true, "%v", err)
 	}
 
 	return err
@@ -142,14 +428,14 @@ func (l *Logger) WarnErr(err error) error {
 
 // Log string
 func (l *Logger) Info(format string, v ...interface{}) {
-	if l.level >= LEVEL_INFO {
-		l.infolog.Printf(format, v...)
+	if l.Level() >= LEVEL_INFO {
+		l.output(LEVEL_INFO, false, format, v...)
 	}
 }
 
 // Log string as debug
 func (l *Logger) Debug(format string, v ...interface{}) {
-	if l.level >= LEVEL_DEBUG {
-		logWithLongFile(l.debuglog, format, v...)
+	if l.Level() >= LEVEL_DEBUG {
+		l.output(LEVEL_DEBUG, true, format, v...)
 	}
 }
@@ -11,6 +11,7 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/heketi/tests"
@@ -24,11 +25,11 @@ func TestLogLevel(t *testing.T) {
 	defer tests.Patch(&stdout, &testbuffer).Restore()
 
 	l := NewLogger("[testing]", LEVEL_INFO)
-	tests.Assert(t, LEVEL_INFO == l.level)
+	tests.Assert(t, int32(LEVEL_INFO) == l.level)
 	tests.Assert(t, LEVEL_INFO == l.Level())
 
 	l.SetLevel(LEVEL_CRITICAL)
-	tests.Assert(t, LEVEL_CRITICAL == l.level)
+	tests.Assert(t, int32(LEVEL_CRITICAL) == l.level)
 	tests.Assert(t, LEVEL_CRITICAL == l.Level())
 
 }
@@ -200,3 +201,156 @@ func TestLogErr(t *testing.T) {
 	l.Err(ErrSample)
 	tests.Assert(t, testbuffer.Len() == 0)
 }
+
+func TestLogWith(t *testing.T) {
+	var testbuffer bytes.Buffer
+
+	defer tests.Patch(&stdout, &testbuffer).Restore()
+
+	l := NewLogger("[testing]", LEVEL_INFO)
+	rl := l.With("request_id", "abc").With("node", 1)
+
+	rl.Info("Hello %v", "World")
+	tests.Assert(t, strings.Contains(testbuffer.String(), "[testing] INFO "), testbuffer.String())
+	tests.Assert(t, strings.Contains(testbuffer.String(), "Hello World request_id=abc node=1"), testbuffer.String())
+	testbuffer.Reset()
+
+	// the parent is not changed
+	l.Info("Hello")
+	tests.Assert(t, !strings.Contains(testbuffer.String(), "request_id"), testbuffer.String())
+	testbuffer.Reset()
+
+	// the level is shared with the parent
+	l.SetLevel(LEVEL_WARNING)
+	tests.Assert(t, rl.Level() == LEVEL_WARNING)
+	rl.Info("TEXT")
+	tests.Assert(t, testbuffer.Len() == 0)
+}
+
+func TestLogJson(t *testing.T) {
+	var testbuffer, errbuffer bytes.Buffer
+
+	defer tests.Patch(&stdout, &testbuffer).Restore()
+	defer tests.Patch(&stderr, &errbuffer).Restore()
+	defer tests.Patch(&logFormat, logFormat).Restore()
+
+	tests.Assert(t, SetLogFormat("yaml") != nil)
+	err := SetLogFormat("json")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, GetLogFormat() == LOG_FORMAT_JSON)
+
+	l := NewLogger("[testing]", LEVEL_DEBUG)
+	l.With("request_id", "abc").Debug("Hello\nWorld")
+
+	// a single line
+	tests.Assert(t, strings.Count(testbuffer.String(), "\n") == 1, testbuffer.String())
+	var m map[string]interface{}
+	err = json.Unmarshal(testbuffer.Bytes(), &m)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, m["level"] == "debug", m)
+	tests.Assert(t, m["component"] == "testing", m)
+	tests.Assert(t, m["message"] == "Hello\nWorld", m)
+	tests.Assert(t, m["request_id"] == "abc", m)
+	tests.Assert(t, strings.Contains(m["file"].(string), "log_test.go"), m)
+	tests.Assert(t, m["time"] != nil, m)
+
+	l.Err(errors.New("BAD"))
+	m = nil
+	err = json.Unmarshal(errbuffer.Bytes(), &m)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, m["level"] == "error", m)
+	tests.Assert(t, m["message"] == "BAD", m)
+	_, ok := m["request_id"]
+	tests.Assert(t, !ok, m)
+}
+
+func TestLogComponent(t *testing.T) {
+	var testbuffer bytes.Buffer
+
+	defer tests.Patch(&stdout, &testbuffer).Restore()
+	defer tests.Patch(&logFormat, int32(LOG_FORMAT_JSON)).Restore()
+
+	l := NewLogger("[testing]", LEVEL_INFO)
+	c := l.Component("api")
+
+	// the level of the parent is used until set
+	tests.Assert(t, c.Level() == LEVEL_INFO)
+	l.SetLevel(LEVEL_WARNING)
+	tests.Assert(t, c.Level() == LEVEL_WARNING)
+
+	c.SetLevel(LEVEL_DEBUG)
+	tests.Assert(t, c.Level() == LEVEL_DEBUG)
+	tests.Assert(t, l.Level() == LEVEL_WARNING)
+	tests.Assert(t, c.With("request_id", "abc").Level() == LEVEL_DEBUG)
+
+	c.Debug("Hello")
+	var m map[string]interface{}
+	err := json.Unmarshal(testbuffer.Bytes(), &m)
+	tests.Assert(t, err == nil, err)
+	tests.Assert(t, m["component"] == "testing.api", m)
+	testbuffer.Reset()
+
+	l.Info("TEXT")
+	tests.Assert(t, testbuffer.Len() == 0)
+
+	c.ResetLevel()
+	tests.Assert(t, c.Level() == LEVEL_WARNING)
+	c.Debug("TEXT")
+	tests.Assert(t, testbuffer.Len() == 0)
+}
+
+func TestLogFilter(t *testing.T) {
+	var testbuffer, errbuffer bytes.Buffer
+
+	defer tests.Patch(&stdout, &testbuffer).Restore()
+	defer tests.Patch(&stderr, &errbuffer).Restore()
+	defer SetLogFilter(nil)
+
+	l := NewLogger("[testing]", LEVEL_DEBUG)
+
+	SetLogFilter([]string{"node1", "vol2"})
+	tests.Assert(t, len(GetLogFilter()) == 2)
+
+	l.Debug("Checking node1")
+	l.Info("Creating vol3")
+	l.With("host", "node1.example.com").Info("Running command")
+	l.Info("Deleting vol2")
+	l.Info("Checking node10")
+	l.With("volume", "vol20").Info("Expanding volume")
+	l.Info("Mounting brick_vol2")
+	output := testbuffer.String()
+	tests.Assert(t, strings.Contains(output, "Checking node1"), output)
+	tests.Assert(t, !strings.Contains(output, "vol3"), output)
+	tests.Assert(t, strings.Contains(output, "Running command"), output)
+	tests.Assert(t, strings.Contains(output, "Deleting vol2"), output)
+	tests.Assert(t, !strings.Contains(output, "node10"), output)
+	tests.Assert(t, !strings.Contains(output, "Expanding volume"), output)
+	tests.Assert(t, strings.Contains(output, "brick_vol2"), output)
+	testbuffer.Reset()
+
+	// only info and debug messages are filtered
+	l.Warning("Unable to reach node3")
+	tests.Assert(t, strings.Contains(testbuffer.String(), "node3"), testbuffer.String())
+	l.LogError("Failed on node3")
+	tests.Assert(t, strings.Contains(errbuffer.String(), "node3"), errbuffer.String())
+	testbuffer.Reset()
+
+	SetLogFilter(nil)
+	tests.Assert(t, len(GetLogFilter()) == 0)
+	l.Info("Creating vol3")
+	tests.Assert(t, strings.Contains(testbuffer.String(), "vol3"), testbuffer.String())
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for _, name := range []string{
+		"none", "critical", "error", "warning", "info", "debug"} {
+
+		level, err := ParseLogLevel(name)
+		tests.Assert(t, err == nil, err)
+		tests.Assert(t, level.String() == name, level, name)
+	}
+
+	_, err := ParseLogLevel("verdant")
+	tests.Assert(t, err != nil)
+	tests.Assert(t, LogLevel(800).String() == "(unknown)")
+}
@@ -188,3 +188,11 @@ func (s *SshExec) ConnectAndExec(host string, commands []string, timeoutMinutes
 
 	return buffers, nil
 }
+
+// WithLogger returns an executor running commands like s and logging
+// through the logger.
+func (s *SshExec) WithLogger(logger *utils.Logger) *SshExec {
+	n := *s
+	n.logger = logger
+	return &n
+}